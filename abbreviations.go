@@ -0,0 +1,77 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// customAbbreviations holds the abbreviations registered for custom points,
+// by lowercase name
+var customAbbreviations = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: map[string]string{}}
+
+// RegisterPlanetAbbreviation registers the abbreviation charts label a
+// point name with, such as "PP" for "pranapada", so that custom points need
+// no Display of their own. It holds in every locale and is consulted before
+// the built-in abbreviations, so it can relabel a built-in planet too.
+// Registering a name again replaces its abbreviation.
+func RegisterPlanetAbbreviation(name, abbrev string) error {
+	return RegisterPlanetAbbreviations(map[string]string{name: abbrev})
+}
+
+// RegisterPlanetAbbreviations registers several abbreviations like
+// RegisterPlanetAbbreviation, either all of them or, when one is invalid,
+// none
+func RegisterPlanetAbbreviations(abbrevs map[string]string) error {
+	for name, abbrev := range abbrevs {
+		if name == "" {
+			return fmt.Errorf("planet name is required")
+		}
+		if abbrev == "" {
+			return fmt.Errorf("empty abbreviation for %s", name)
+		}
+	}
+	customAbbreviations.Lock()
+	defer customAbbreviations.Unlock()
+	for name, abbrev := range abbrevs {
+		customAbbreviations.m[strings.ToLower(name)] = abbrev
+	}
+	return nil
+}
+
+// customAbbreviationNames returns the names abbreviations are registered
+// for, lowercase and in order
+func customAbbreviationNames() []string {
+	customAbbreviations.RLock()
+	defer customAbbreviations.RUnlock()
+	return slices.Sorted(maps.Keys(customAbbreviations.m))
+}
+
+// customAbbreviation returns the abbreviation registered for a lowercase
+// name, if any
+func customAbbreviation(name string) (string, bool) {
+	customAbbreviations.RLock()
+	defer customAbbreviations.RUnlock()
+	abbrev, ok := customAbbreviations.m[name]
+	return abbrev, ok
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRegisterPlanetAbbreviation(t *testing.T) {
+	if err := RegisterPlanetAbbreviation("Pranapada", "PP"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetPlanetAbbreviation("pranapada"); got != "PP" {
+		t.Errorf("GetPlanetAbbreviation(pranapada) = %q, want PP", got)
+	}
+	if got := GetPlanetAbbreviationIn("PRANAPADA", LocaleIAST); got != "PP" {
+		t.Errorf("GetPlanetAbbreviationIn(PRANAPADA, %s) = %q, want PP", LocaleIAST, got)
+	}
+	if err := RegisterPlanetAbbreviations(map[string]string{"test_bhrigu_bindu": "BB", "test_sree_lagna": "SrL"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetPlanetAbbreviation("test_sree_lagna"); got != "SrL" {
+		t.Errorf("GetPlanetAbbreviation(test_sree_lagna) = %q, want SrL", got)
+	}
+
+	for _, bad := range []map[string]string{{"": "X"}, {"test_empty": ""}} {
+		if err := RegisterPlanetAbbreviations(bad); err == nil {
+			t.Errorf("RegisterPlanetAbbreviations(%q) succeeded, want an error", bad)
+		}
+	}
+	// Nothing is registered when any abbreviation is invalid
+	if err := RegisterPlanetAbbreviations(map[string]string{"test_partial": "TP", "test_partial_empty": ""}); err == nil {
+		t.Error("RegisterPlanetAbbreviations with an empty abbreviation succeeded")
+	}
+	if got := GetPlanetAbbreviation("test_partial"); got != "" {
+		t.Errorf("GetPlanetAbbreviation(test_partial) = %q after a failed registration, want empty", got)
+	}
+}
+
+func TestGenerateChart_RegisteredAbbreviation(t *testing.T) {
+	if err := RegisterPlanetAbbreviation("test_bhrigu", "BB"); err != nil {
+		t.Fatal(err)
+	}
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Planets["test_bhrigu"] = &Planet{Rashi: "scorpio"}
+		if labels := planetLabels(t, input); !slices.Contains(labels, "BB") {
+			t.Errorf("%s: planet labels %q lack BB", chartType, labels)
+		}
+		result, err := GenerateChartResult(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Scorpio is the 3rd house from a Virgo lagna
+		if !slices.Contains(result.Houses[3], "test_bhrigu") {
+			t.Errorf("%s: house 3 holds %v, want test_bhrigu", chartType, result.Houses[3])
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("%s: warnings %q, want none", chartType, result.Warnings)
+		}
+	}
+}
+
+func TestRegisterPlanetAbbreviation_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		name := fmt.Sprintf("test_concurrent_point_%d", i)
+		go func() {
+			defer wg.Done()
+			if err := RegisterPlanetAbbreviation(name, "C"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			input := houseScoresInput(ChartTypeSouth)
+			input.Planets[name] = &Planet{Rashi: "leo", Display: "C"}
+			if _, err := GenerateChart(input); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	want := map[string]string{}
+	got := map[string]string{}
+	for i := range 8 {
+		name := fmt.Sprintf("test_concurrent_point_%d", i)
+		want[name], got[name] = "C", GetPlanetAbbreviation(name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("abbreviations = %v, want %v", got, want)
+	}
+}
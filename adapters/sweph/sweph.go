@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package sweph provides positions for parashari.BuildChartInput from the
+// Swiss Ephemeris.
+//
+// The ephemeris is the Swiss Ephemeris C library, linked through cgo when
+// the package is built with the sweph tag:
+//
+//	go build -tags sweph
+//
+// Without the tag the package still builds, keeping the core free of the C
+// dependency, but its Provider reports ErrNotBuilt.
+package sweph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrNotBuilt is returned by a Provider in a binary built without the sweph tag
+var ErrNotBuilt = errors.New("sweph: built without the sweph tag, so the Swiss Ephemeris is not linked")
+
+// Ayanamsa selects the sidereal zodiac positions are given in. The zero
+// value is Lahiri.
+type Ayanamsa int
+
+const (
+	AyanamsaLahiri Ayanamsa = iota
+	AyanamsaFaganBradley
+	AyanamsaRaman
+	AyanamsaKrishnamurti
+	AyanamsaTrueChitra
+)
+
+// sidModes maps each Ayanamsa to its Swiss Ephemeris SE_SIDM_* constant
+var sidModes = map[Ayanamsa]int{
+	AyanamsaLahiri:       1,
+	AyanamsaFaganBradley: 0,
+	AyanamsaRaman:        3,
+	AyanamsaKrishnamurti: 5,
+	AyanamsaTrueChitra:   27,
+}
+
+// bodies maps the planets a Provider returns to their Swiss Ephemeris
+// SE_* numbers. Ketu is not a body of its own; it lies opposite Rahu.
+var bodies = []struct {
+	name string
+	id   int
+}{
+	{"sun", 0},
+	{"moon", 1},
+	{"mercury", 2},
+	{"venus", 3},
+	{"mars", 4},
+	{"jupiter", 5},
+	{"saturn", 6},
+}
+
+// Swiss Ephemeris numbers of the lunar nodes
+const (
+	meanNode = 10
+	trueNode = 11
+)
+
+// ephemeris is the part of the Swiss Ephemeris a Provider uses
+type ephemeris interface {
+	// position returns the sidereal longitude of a body in degrees and its
+	// daily motion at Julian day jd (UT), in the zodiac of SE_SIDM_* sidMode
+	position(jd float64, body int, sidMode int) (longitude, speed float64, err error)
+	// ascendant returns the sidereal longitude of the ascendant at Julian
+	// day jd (UT) for a place at lat, lon
+	ascendant(jd, lat, lon float64, sidMode int) (float64, error)
+}
+
+// Provider is a parashari.PositionProvider and parashari.SpeedProvider
+// backed by the Swiss Ephemeris. The library keeps global state, so calls
+// are serialized; a Provider is safe for concurrent use.
+type Provider struct {
+	// Ayanamsa selects the sidereal zodiac, Lahiri by default
+	Ayanamsa Ayanamsa
+	// TrueNode places Rahu and Ketu by the true rather than the mean node
+	TrueNode bool
+
+	eph ephemeris
+}
+
+// New returns a Provider reading the ephemeris files in ephePath, or using
+// the library's built-in Moshier ephemeris when ephePath is empty
+func New(ephePath string, ayanamsa Ayanamsa) *Provider {
+	// Setting the path changes the library's global state too
+	mu.Lock()
+	defer mu.Unlock()
+	return &Provider{Ayanamsa: ayanamsa, eph: newSwissEphemeris(ephePath)}
+}
+
+// mu serializes calls into the Swiss Ephemeris, which is not safe for
+// concurrent use
+var mu sync.Mutex
+
+// GetPositions returns the sidereal longitudes of the planets, Rahu and Ketu
+// at t, and of the ascendant at lat, lon
+func (p *Provider) GetPositions(t time.Time, lat, lon float64) (map[string]float64, float64, error) {
+	longitudes, _, ascendant, err := p.compute(t, lat, lon)
+	return longitudes, ascendant, err
+}
+
+// GetSpeeds returns the daily motion of the planets, Rahu and Ketu at t
+func (p *Provider) GetSpeeds(t time.Time, lat, lon float64) (map[string]float64, error) {
+	_, speeds, _, err := p.compute(t, lat, lon)
+	return speeds, err
+}
+
+// compute asks the ephemeris for every body and the ascendant at t
+func (p *Provider) compute(t time.Time, lat, lon float64) (longitudes, speeds map[string]float64, ascendant float64, err error) {
+	if p.eph == nil {
+		return nil, nil, 0, errors.New("sweph: Provider not created with New")
+	}
+	sidMode, ok := sidModes[p.Ayanamsa]
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("sweph: unknown ayanamsa %d", p.Ayanamsa)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	jd := julianDay(t)
+	longitudes = make(map[string]float64, len(bodies)+2)
+	speeds = make(map[string]float64, len(bodies)+2)
+	for _, b := range bodies {
+		if longitudes[b.name], speeds[b.name], err = p.eph.position(jd, b.id, sidMode); err != nil {
+			return nil, nil, 0, fmt.Errorf("sweph: %s: %w", b.name, err)
+		}
+	}
+	node := meanNode
+	if p.TrueNode {
+		node = trueNode
+	}
+	rahu, speed, err := p.eph.position(jd, node, sidMode)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("sweph: rahu: %w", err)
+	}
+	longitudes["rahu"], speeds["rahu"] = rahu, speed
+	longitudes["ketu"], speeds["ketu"] = math.Mod(rahu+180, 360), speed
+
+	if ascendant, err = p.eph.ascendant(jd, lat, lon, sidMode); err != nil {
+		return nil, nil, 0, fmt.Errorf("sweph: ascendant: %w", err)
+	}
+	return longitudes, speeds, ascendant, nil
+}
+
+// julianDay returns the Julian day (UT) of t, in the proleptic Gregorian
+// calendar before 1582. It takes the seconds and nanoseconds apart since
+// t.UnixNano overflows outside about 1678 to 2262.
+func julianDay(t time.Time) float64 {
+	const unixEpoch = 2440587.5 // Julian day of 1970-01-01 00:00 UTC
+	seconds := float64(t.Unix()) + float64(t.Nanosecond())/1e9
+	return unixEpoch + seconds/(24*60*60)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sweph
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+// j2000 is noon UT on 2000-01-01, Julian day 2451545.0
+var j2000 = time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// recordedEphemeris replays positions recorded from the Swiss Ephemeris, so
+// the tests run without the library or its files
+type recordedEphemeris struct {
+	jd        float64
+	sidMode   int
+	positions map[int][2]float64 // Longitude and speed by body
+	asc       float64
+	err       error
+}
+
+func (e *recordedEphemeris) position(jd float64, body int, sidMode int) (float64, float64, error) {
+	e.jd, e.sidMode = jd, sidMode
+	if e.err != nil {
+		return 0, 0, e.err
+	}
+	p, ok := e.positions[body]
+	if !ok {
+		return 0, 0, errors.New("body not recorded")
+	}
+	return p[0], p[1], nil
+}
+
+func (e *recordedEphemeris) ascendant(jd, lat, lon float64, sidMode int) (float64, error) {
+	return e.asc, e.err
+}
+
+// j2000Delhi holds the sidereal (Lahiri) positions at j2000 for Delhi,
+// 28.61°N 77.21°E, to a hundredth of a degree
+func j2000Delhi() *recordedEphemeris {
+	return &recordedEphemeris{
+		positions: map[int][2]float64{
+			0:        {256.52, 1.019},  // Sun, Sagittarius
+			1:        {199.47, 12.34},  // Moon, Libra
+			2:        {248.04, 1.556},  // Mercury, Sagittarius
+			3:        {217.72, 1.209},  // Venus, Scorpio
+			4:        {304.11, 0.775},  // Mars, Aquarius
+			5:        {1.40, 0.041},    // Jupiter, Aries
+			6:        {16.55, -0.021},  // Saturn, Aries, retrograde
+			meanNode: {101.19, -0.053}, // Rahu, Cancer
+			trueNode: {99.63, -0.112},
+		},
+		asc: 76.40, // Gemini
+	}
+}
+
+func TestProvider_BuildChartInput(t *testing.T) {
+	eph := j2000Delhi()
+	provider := &Provider{Ayanamsa: AyanamsaLahiri, eph: eph}
+	input, err := parashari.BuildChartInput(provider, j2000, 28.61, 77.21, parashari.ChartTypeNorth)
+	if err != nil {
+		t.Fatalf("BuildChartInput: %v", err)
+	}
+	if eph.jd != 2451545.0 || eph.sidMode != 1 {
+		t.Errorf("Ephemeris asked for day %v with sidereal mode %d, want 2451545 with Lahiri (1)", eph.jd, eph.sidMode)
+	}
+
+	want := map[string]string{
+		"sun": "sagittarius", "moon": "libra", "mercury": "sagittarius", "venus": "scorpio",
+		"mars": "aquarius", "jupiter": "aries", "saturn": "aries", "rahu": "cancer", "ketu": "capricorn",
+	}
+	if len(input.Planets) != len(want) {
+		t.Errorf("Got %d planets, want %d", len(input.Planets), len(want))
+	}
+	for name, rashi := range want {
+		if p := input.Planets[name]; p == nil || p.Rashi != rashi {
+			t.Errorf("%s = %+v, want in %s", name, p, rashi)
+		}
+	}
+	if input.Lagna.Rashi != "gemini" {
+		t.Errorf("Lagna in %s, want gemini", input.Lagna.Rashi)
+	}
+	if !input.Planets["saturn"].IsRetrograde || input.Planets["jupiter"].IsRetrograde {
+		t.Error("Expected only Saturn retrograde")
+	}
+	if ketu := input.Planets["ketu"]; math.Abs(ketu.Degrees-11.19) > 1e-9 {
+		t.Errorf("Ketu at %v°, want opposite Rahu at 11.19°", ketu.Degrees)
+	}
+	if _, err := parashari.GenerateChart(input); err != nil {
+		t.Errorf("Cannot draw the built chart: %v", err)
+	}
+}
+
+func TestProvider_TrueNode(t *testing.T) {
+	provider := &Provider{TrueNode: true, eph: j2000Delhi()}
+	longitudes, _, err := provider.GetPositions(j2000, 28.61, 77.21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if longitudes["rahu"] != 99.63 || math.Abs(longitudes["ketu"]-279.63) > 1e-9 {
+		t.Errorf("Nodes at %v and %v, want the true node at 99.63 and 279.63", longitudes["rahu"], longitudes["ketu"])
+	}
+}
+
+func TestProvider_Errors(t *testing.T) {
+	failing := errors.New("SwissEph file 'sepl_18.se1' not found")
+	eph := j2000Delhi()
+	eph.err = failing
+	if _, _, err := (&Provider{eph: eph}).GetPositions(j2000, 0, 0); !errors.Is(err, failing) {
+		t.Errorf("Error %v does not wrap the ephemeris error", err)
+	}
+	if _, _, err := (&Provider{}).GetPositions(j2000, 0, 0); err == nil {
+		t.Error("Expected an error from a Provider not created with New")
+	}
+}
+
+func TestProvider_DefaultsToLahiri(t *testing.T) {
+	eph := j2000Delhi()
+	provider := &Provider{eph: eph}
+	if _, _, err := provider.GetPositions(j2000, 28.61, 77.21); err != nil {
+		t.Fatalf("GetPositions: %v", err)
+	}
+	if eph.sidMode != 1 {
+		t.Errorf("Zero Ayanamsa asked for sidereal mode %d, want Lahiri (1)", eph.sidMode)
+	}
+}
+
+func TestJulianDay(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{j2000, 2451545},
+		{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 2440587.5},
+		{time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), 2305447.5},
+		{time.Date(1000, 7, 1, 12, 0, 0, 0, time.UTC), 2086484},
+		{time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC), 2634166.5},
+		{time.Date(2000, 1, 1, 18, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)), 2451545.0 + 0.5/24},
+		{j2000.Add(1500 * time.Millisecond), 2451545 + 1.5/86400},
+	}
+	for _, tt := range tests {
+		if got := julianDay(tt.t); math.Abs(got-tt.want) > 1e-8 {
+			t.Errorf("julianDay(%v) = %.8f, want %.8f", tt.t, got, tt.want)
+		}
+	}
+
+	// The provider asks for the day of a date before 1678
+	eph := j2000Delhi()
+	provider := &Provider{eph: eph}
+	if _, _, err := provider.GetPositions(time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), 28.61, 77.21); err != nil {
+		t.Fatal(err)
+	}
+	if eph.jd != 2305447.5 {
+		t.Errorf("GetPositions in 1600 asked for Julian day %.2f, want 2305447.50", eph.jd)
+	}
+}
+
+func TestProvider_UnknownAyanamsa(t *testing.T) {
+	provider := &Provider{Ayanamsa: Ayanamsa(99), eph: j2000Delhi()}
+	if _, _, err := provider.GetPositions(j2000, 28.61, 77.21); err == nil {
+		t.Error("Expected an error for an unknown ayanamsa")
+	}
+}
@@ -0,0 +1,68 @@
+//go:build sweph && cgo
+
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sweph
+
+/*
+#cgo LDFLAGS: -lswe -lm
+#include <stdlib.h>
+#include <swephexp.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// swissEphemeris calls the Swiss Ephemeris C library
+type swissEphemeris struct{}
+
+func newSwissEphemeris(ephePath string) ephemeris {
+	if ephePath != "" {
+		path := C.CString(ephePath)
+		defer C.free(unsafe.Pointer(path))
+		C.swe_set_ephe_path(path)
+	}
+	return swissEphemeris{}
+}
+
+// flags asks for sidereal positions with speeds, from the ephemeris files
+// when they are found and the built-in Moshier ephemeris otherwise
+const flags = C.SEFLG_SWIEPH | C.SEFLG_SPEED | C.SEFLG_SIDEREAL
+
+func (swissEphemeris) position(jd float64, body int, sidMode int) (float64, float64, error) {
+	C.swe_set_sid_mode(C.int32(sidMode), 0, 0)
+	var xx [6]C.double
+	var serr [C.AS_MAXCH]C.char
+	if C.swe_calc_ut(C.double(jd), C.int32(body), flags, &xx[0], &serr[0]) < 0 {
+		return 0, 0, errors.New(C.GoString(&serr[0]))
+	}
+	return float64(xx[0]), float64(xx[3]), nil
+}
+
+func (swissEphemeris) ascendant(jd, lat, lon float64, sidMode int) (float64, error) {
+	C.swe_set_sid_mode(C.int32(sidMode), 0, 0)
+	var cusps [13]C.double
+	var ascmc [10]C.double
+	// The ascendant is the same in every house system; whole signs never fail
+	// at high latitudes
+	if C.swe_houses_ex(C.double(jd), C.SEFLG_SIDEREAL, C.double(lat), C.double(lon), 'W', &cusps[0], &ascmc[0]) < 0 {
+		return 0, errors.New("cannot compute the ascendant")
+	}
+	return float64(ascmc[C.SE_ASC]), nil
+}
@@ -0,0 +1,32 @@
+//go:build !(sweph && cgo)
+
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sweph
+
+// missingEphemeris stands in for the Swiss Ephemeris in binaries built
+// without it
+type missingEphemeris struct{}
+
+func newSwissEphemeris(string) ephemeris { return missingEphemeris{} }
+
+func (missingEphemeris) position(float64, int, int) (float64, float64, error) {
+	return 0, 0, ErrNotBuilt
+}
+
+func (missingEphemeris) ascendant(float64, float64, float64, int) (float64, error) {
+	return 0, ErrNotBuilt
+}
@@ -0,0 +1,29 @@
+//go:build !(sweph && cgo)
+
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sweph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProvider_NotBuilt(t *testing.T) {
+	if _, _, err := New("", AyanamsaLahiri).GetPositions(j2000, 0, 0); !errors.Is(err, ErrNotBuilt) {
+		t.Errorf("Error %v without the sweph tag, want ErrNotBuilt", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// Aspect is the graha drishti of a planet on one house
+type Aspect struct {
+	Planet  string   `json:"planet"`            // Aspecting planet
+	Drishti int      `json:"drishti"`           // Aspected house counted from the planet's own as 1: 7 for the full aspect
+	House   int      `json:"house"`             // Aspected house counted from lagna
+	Rashi   int      `json:"rashi"`             // Aspected rashi number
+	Planets []string `json:"planets,omitempty"` // Planets in the aspected rashi, in name order
+}
+
+// grahas are the nine planets that cast aspects, in their traditional order
+var grahas = []string{"sun", "moon", "mars", "mercury", "jupiter", "venus", "saturn", "rahu", "ketu"}
+
+// ComputeAspects returns the whole-sign Parashari aspects of the nine
+// grahas in the chart, in the order sun, moon, … ketu and within a planet
+// by drishti. Houses are counted from the lagna, Aries when it is missing.
+// Rahu and Ketu aspect the 7th, and also the 5th and 9th when
+// Options.NodeAspects is set. Planets with an unknown rashi, upagrahas and
+// special lagnas cast none.
+func ComputeAspects(input ChartInput) []Aspect {
+	lagnaRashi := chartLagnaRashi(input)
+	houses := planetsByHouse(input)
+
+	var aspects []Aspect
+	for _, graha := range grahas {
+		name, p := findPlanet(input.Planets, graha)
+		if p == nil || p.IsUpagraha || RashiToNumber(p.Rashi) == 0 {
+			continue
+		}
+		rashiNum := RashiToNumber(p.Rashi)
+		for _, n := range grahaDrishti(graha, input.Options.NodeAspects) {
+			aspected := (rashiNum+n-2)%12 + 1
+			house := HouseFromLagna(aspected, lagnaRashi)
+			aspects = append(aspects, Aspect{
+				Planet:  name,
+				Drishti: n,
+				House:   house,
+				Rashi:   aspected,
+				Planets: houses[house],
+			})
+		}
+	}
+	return aspects
+}
+
+// findPlanet returns the planet named name in any case, with its key. Of
+// keys differing only in case, the exact one wins, then the first in
+// sortedPlanetNames order.
+func findPlanet(planets map[string]*Planet, name string) (string, *Planet) {
+	if p, ok := planets[name]; ok {
+		return name, p
+	}
+	for _, key := range sortedPlanetNames(planets) {
+		if strings.EqualFold(key, name) {
+			return key, planets[key]
+		}
+	}
+	return "", nil
+}
+
+// aspectLineColor is the light blue aspect lines are drawn in, faint enough
+// for the labels drawn over them to stay readable
+var aspectLineColor = color.NRGBA{R: 138, G: 164, B: 214, A: 255}
+
+// grahaDrishti returns the houses a planet aspects in ascending order,
+// counted from its own house as 1: every planet aspects the 7th, Mars also
+// the 4th and 8th, Jupiter the 5th and 9th and Saturn the 3rd and 10th.
+// With nodeAspects Rahu and Ketu also aspect the 5th and 9th.
+func grahaDrishti(planetName string, nodeAspects bool) []int {
+	switch strings.ToLower(planetName) {
+	case "mars":
+		return []int{4, 7, 8}
+	case "jupiter":
+		return []int{5, 7, 9}
+	case "saturn":
+		return []int{3, 7, 10}
+	case "rahu", "ketu":
+		if nodeAspects {
+			return []int{5, 7, 9}
+		}
+	}
+	return []int{7}
+}
+
+// aspectedHouses returns the houses, counted from lagna, aspected by a
+// planet in house
+func aspectedHouses(planetName string, house int, nodeAspects bool) []int {
+	counts := grahaDrishti(planetName, nodeAspects)
+	houses := make([]int, len(counts))
+	for i, n := range counts {
+		houses[i] = (house+n-2)%12 + 1
+	}
+	return houses
+}
+
+// validateAspectLines checks that every planet named in aspect_lines is in
+// the chart with a known rashi
+func validateAspectLines(input ChartInput) error {
+	for _, name := range input.Options.AspectLines {
+		p, ok := input.Planets[name]
+		if !ok || p == nil {
+			return fmt.Errorf("aspect_lines: %w", &ErrUnknownPlanet{Name: name})
+		}
+		if RashiToNumber(p.Rashi) == 0 {
+			return fmt.Errorf("aspect_lines: %w", &ErrInvalidRashi{Planet: name, Rashi: p.Rashi})
+		}
+	}
+	return nil
+}
+
+// drawAspectLines draws an arrow from the house of each planet named in
+// AspectLines to every house it aspects, joining the houses' centroids.
+// centroid returns the centroid of a house counted from lagna. Planets that
+// validateAspectLines rejects are skipped.
+func drawAspectLines(dc *gg.Context, input ChartInput, frame chartFrame, lagnaRashi int, centroid func(house int) gg.Point) {
+	if len(input.Options.AspectLines) == 0 {
+		return
+	}
+	dc.SetColor(frame.ink(aspectLineColor))
+	dc.SetLineWidth(frame.px(2))
+	gap, head := frame.px(14), frame.px(12)
+	for _, name := range input.Options.AspectLines {
+		p := input.Planets[name]
+		if p == nil || RashiToNumber(p.Rashi) == 0 {
+			continue
+		}
+		house := HouseFromLagna(RashiToNumber(p.Rashi), lagnaRashi)
+		from := centroid(house)
+		for _, aspected := range aspectedHouses(name, house, input.Options.NodeAspects) {
+			drawArrow(dc, from, centroid(aspected), gap, head)
+		}
+	}
+}
+
+// drawArrow draws a line from one point to another with an arrowhead head
+// long at its end, leaving gap clear at both ends
+func drawArrow(dc *gg.Context, from, to gg.Point, gap, head float64) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length <= 2*gap+head {
+		return
+	}
+	ux, uy := dx/length, dy/length
+	tip := gg.Point{X: to.X - ux*gap, Y: to.Y - uy*gap}
+	base := gg.Point{X: tip.X - ux*head, Y: tip.Y - uy*head}
+	dc.DrawLine(from.X+ux*gap, from.Y+uy*gap, base.X, base.Y)
+	dc.Stroke()
+
+	// The head is as wide as it is long
+	nx, ny := -uy*head/2, ux*head/2
+	dc.MoveTo(tip.X, tip.Y)
+	dc.LineTo(base.X+nx, base.Y+ny)
+	dc.LineTo(base.X-nx, base.Y-ny)
+	dc.ClosePath()
+	dc.Fill()
+}
+
+// nodalAxisColor is the gray the nodal axis is dashed in, fainter than the
+// aspect lines
+var nodalAxisColor = color.NRGBA{R: 175, G: 175, B: 190, A: 255}
+
+// nodalHouses returns the houses of Rahu and Ketu counted from lagna, found
+// in any case. ok is false unless both are in the chart with known rashis.
+func nodalHouses(input ChartInput, lagnaRashi int) (rahu, ketu int, ok bool) {
+	for _, node := range []struct {
+		name  string
+		house *int
+	}{{"rahu", &rahu}, {"ketu", &ketu}} {
+		_, p := findPlanet(input.Planets, node.name)
+		if p == nil || RashiToNumber(p.Rashi) == 0 {
+			return 0, 0, false
+		}
+		*node.house = HouseFromLagna(RashiToNumber(p.Rashi), lagnaRashi)
+	}
+	return rahu, ketu, true
+}
+
+// drawNodalAxis draws the DrawNodalAxis line, dashed, between the centroids
+// of the houses of Rahu and Ketu, leaving the same gap at its ends as the
+// aspect lines. Charts without both nodes, or with both in one house, get
+// none.
+func drawNodalAxis(dc *gg.Context, input ChartInput, frame chartFrame, lagnaRashi int, centroid func(house int) gg.Point) {
+	if !input.Options.DrawNodalAxis {
+		return
+	}
+	rahu, ketu, ok := nodalHouses(input, lagnaRashi)
+	if !ok || rahu == ketu {
+		return
+	}
+	from, to := centroid(rahu), centroid(ketu)
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length, gap := math.Hypot(dx, dy), frame.px(14)
+	if length <= 2*gap {
+		return
+	}
+	ux, uy := dx/length, dy/length
+	dc.SetColor(frame.ink(nodalAxisColor))
+	dc.SetLineWidth(frame.line(1.5))
+	dc.SetDash(frame.px(8), frame.px(6))
+	dc.DrawLine(from.X+ux*gap, from.Y+uy*gap, to.X-ux*gap, to.Y-uy*gap)
+	dc.Stroke()
+	dc.SetDash()
+}
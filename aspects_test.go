@@ -0,0 +1,220 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAspectedHouses(t *testing.T) {
+	tests := []struct {
+		planet string
+		house  int
+		want   []int
+	}{
+		{"sun", 1, []int{7}},
+		{"moon", 10, []int{4}},
+		{"mercury", 6, []int{12}},
+		{"venus", 12, []int{6}},
+		{"rahu", 3, []int{9}},
+		{"mars", 1, []int{4, 7, 8}},
+		{"mars", 9, []int{12, 3, 4}},
+		{"jupiter", 1, []int{5, 7, 9}},
+		{"jupiter", 8, []int{12, 2, 4}},
+		{"saturn", 1, []int{3, 7, 10}},
+		{"saturn", 11, []int{1, 5, 8}},
+	}
+	for _, tt := range tests {
+		if got := aspectedHouses(tt.planet, tt.house, false); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("aspectedHouses(%q, %d) = %v, want %v", tt.planet, tt.house, got, tt.want)
+		}
+	}
+}
+
+// aspectLinesInput returns a chart with Mars, Jupiter and Saturn's special
+// aspects drawn
+func aspectLinesInput(chartType ChartType) ChartInput {
+	return ChartInput{
+		ChartType: chartType,
+		Lagna:     &Planet{Rashi: "cancer"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo"},
+			"moon":    {Rashi: "taurus"},
+			"mars":    {Rashi: "cancer"},
+			"jupiter": {Rashi: "sagittarius"},
+			"saturn":  {Rashi: "aquarius"},
+		},
+		Options: ChartOptions{AspectLines: []string{"mars", "jupiter", "saturn"}},
+	}
+}
+
+func TestGenerateChart_AspectLines(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := aspectLinesInput(chartType)
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_aspect_lines", data)
+
+		// The lines lie beneath the labels, which stay where they were
+		withLines := renderWithBoxes(t, input)
+		input.Options.AspectLines = nil
+		without := renderWithBoxes(t, input)
+		if !reflect.DeepEqual(withLines.boxes, without.boxes) {
+			t.Errorf("%s: aspect lines moved the labels", chartType)
+		}
+		plain, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(plain, data) {
+			t.Errorf("%s: aspect lines were not drawn", chartType)
+		}
+	}
+}
+
+func TestGenerateChart_AspectLinesInvalid(t *testing.T) {
+	input := aspectLinesInput(ChartTypeSouth)
+	input.Options.AspectLines = []string{"pluto"}
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), `aspect_lines: unknown planet "pluto"`) {
+		t.Errorf("GenerateChart(aspect_lines pluto) error = %v, want it named", err)
+	}
+	input.Options.AspectLines = []string{"moon"}
+	input.Planets["moon"].Rashi = "nowhere"
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "unknown rashi") {
+		t.Errorf("GenerateChart(aspect_lines moon in unknown rashi) error = %v", err)
+	}
+}
+
+func TestComputeAspects(t *testing.T) {
+	// Every graha in Aries, so the aspected rashis are the drishti counts
+	planets := map[string]*Planet{}
+	for _, name := range grahas {
+		planets[name] = &Planet{Rashi: "aries"}
+	}
+	tests := []struct {
+		planet string
+		lagna  string
+		node   bool
+		want   [][3]int // Drishti, house, rashi
+	}{
+		{"sun", "aries", false, [][3]int{{7, 7, 7}}},
+		{"sun", "leo", false, [][3]int{{7, 3, 7}}},
+		{"moon", "capricorn", false, [][3]int{{7, 10, 7}}},
+		{"mercury", "pisces", false, [][3]int{{7, 8, 7}}},
+		{"venus", "libra", false, [][3]int{{7, 1, 7}}},
+		{"mars", "aries", false, [][3]int{{4, 4, 4}, {7, 7, 7}, {8, 8, 8}}},
+		{"mars", "scorpio", false, [][3]int{{4, 9, 4}, {7, 12, 7}, {8, 1, 8}}},
+		{"jupiter", "aries", false, [][3]int{{5, 5, 5}, {7, 7, 7}, {9, 9, 9}}},
+		{"jupiter", "cancer", false, [][3]int{{5, 2, 5}, {7, 4, 7}, {9, 6, 9}}},
+		{"saturn", "aries", false, [][3]int{{3, 3, 3}, {7, 7, 7}, {10, 10, 10}}},
+		{"saturn", "aquarius", false, [][3]int{{3, 5, 3}, {7, 9, 7}, {10, 12, 10}}},
+		{"rahu", "aries", false, [][3]int{{7, 7, 7}}},
+		{"rahu", "aries", true, [][3]int{{5, 5, 5}, {7, 7, 7}, {9, 9, 9}}},
+		{"ketu", "gemini", true, [][3]int{{5, 3, 5}, {7, 5, 7}, {9, 7, 9}}},
+	}
+	for _, tt := range tests {
+		input := ChartInput{
+			Lagna:   &Planet{Rashi: tt.lagna},
+			Planets: planets,
+			Options: ChartOptions{NodeAspects: tt.node},
+		}
+		var got [][3]int
+		for _, a := range ComputeAspects(input) {
+			if a.Planet == tt.planet {
+				got = append(got, [3]int{a.Drishti, a.House, a.Rashi})
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s with lagna %s (node aspects %v): aspects %v, want %v", tt.planet, tt.lagna, tt.node, got, tt.want)
+		}
+	}
+}
+
+func TestComputeAspects_ReceivingPlanets(t *testing.T) {
+	input := ChartInput{
+		Lagna: &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"saturn":  {Rashi: "capricorn"},
+			"moon":    {Rashi: "cancer"},
+			"sun":     {Rashi: "cancer"},
+			"mars":    {Rashi: "aries"},
+			"mandi":   {Rashi: "aries", IsUpagraha: true},
+			"jupiter": {Rashi: "unknown"},
+		},
+	}
+	want := []Aspect{
+		{Planet: "sun", Drishti: 7, House: 6, Rashi: 10, Planets: []string{"saturn"}},
+		{Planet: "moon", Drishti: 7, House: 6, Rashi: 10, Planets: []string{"saturn"}},
+		{Planet: "mars", Drishti: 4, House: 12, Rashi: 4, Planets: []string{"moon", "sun"}},
+		{Planet: "mars", Drishti: 7, House: 3, Rashi: 7},
+		{Planet: "mars", Drishti: 8, House: 4, Rashi: 8},
+		{Planet: "saturn", Drishti: 3, House: 8, Rashi: 12},
+		{Planet: "saturn", Drishti: 7, House: 12, Rashi: 4, Planets: []string{"moon", "sun"}},
+		{Planet: "saturn", Drishti: 10, House: 3, Rashi: 7},
+	}
+	if got := ComputeAspects(input); !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeAspects() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestGenerateChart_NodalAxis(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := aspectLinesInput(chartType)
+		input.Planets["rahu"] = &Planet{Rashi: "gemini", IsRetrograde: true}
+		input.Planets["ketu"] = &Planet{Rashi: "sagittarius", IsRetrograde: true}
+		input.Options = ChartOptions{DrawNodalAxis: true, NodeStyle: NodeStyleItalic}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_nodal_axis", data)
+
+		// The axis lies beneath the labels, which stay where they were
+		withAxis := renderWithBoxes(t, input)
+		input.Options.DrawNodalAxis = false
+		if !reflect.DeepEqual(withAxis.boxes, renderWithBoxes(t, input).boxes) {
+			t.Errorf("%s: the nodal axis moved the labels", chartType)
+		}
+		plain, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(plain, data) {
+			t.Errorf("%s: the nodal axis was not drawn", chartType)
+		}
+
+		// With only one node there is no axis to draw
+		input.Options.DrawNodalAxis = true
+		delete(input.Planets, "ketu")
+		withOne, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input.Options.DrawNodalAxis = false
+		without, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(withOne, without) {
+			t.Errorf("%s: an axis was drawn for a single node", chartType)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"slices"
+)
+
+// Avastha is a planet's baladi avastha, the age it has reached in its rashi.
+// A planet gives its full results as yuva, some as kumara or vriddha, little
+// as bala and none as mrita.
+type Avastha string
+
+const (
+	AvasthaBala    Avastha = "bala"    // Infant
+	AvasthaKumara  Avastha = "kumara"  // Youth
+	AvasthaYuva    Avastha = "yuva"    // Adult
+	AvasthaVriddha Avastha = "vriddha" // Old
+	AvasthaMrita   Avastha = "mrita"   // Dead
+)
+
+// baladiOrder is the avasthas of the five 6° bands of an odd rashi, from 0°.
+// Even rashis run through them the other way round.
+var baladiOrder = [5]Avastha{AvasthaBala, AvasthaKumara, AvasthaYuva, AvasthaVriddha, AvasthaMrita}
+
+// avasthaCodes are the letters raised after planets with ShowBaladiAvastha
+var avasthaCodes = map[Avastha]string{
+	AvasthaBala:    "B",
+	AvasthaKumara:  "K",
+	AvasthaYuva:    "Y",
+	AvasthaVriddha: "V",
+	AvasthaMrita:   "M",
+}
+
+// ComputeBaladiAvastha returns the baladi avastha of a planet at degrees
+// within a rashi. The rashi is divided into five bands of 6°: in odd rashis
+// (Aries, Gemini, …) bala from 0°, then kumara, yuva, vriddha and mrita from
+// 24°; in even rashis the other way round, mrita from 0° to bala from 24°.
+// Each band starts at its boundary, so a planet at exactly 6° of Aries is
+// kumara. It returns an empty Avastha for an unknown rashi or degrees
+// outside 0 to under 30.
+func ComputeBaladiAvastha(rashi string, degrees float64) Avastha {
+	rashiNum := RashiToNumber(rashi)
+	if rashiNum == 0 || !(degrees >= 0 && degrees < 30) {
+		return ""
+	}
+	band := int(math.Floor(degrees / 6))
+	if rashiNum%2 == 0 {
+		band = len(baladiOrder) - 1 - band
+	}
+	return baladiOrder[band]
+}
+
+// avasthaCode returns the letter of the baladi avastha of one of the nine
+// grahas, or an empty string for other planets and planets without degrees
+func avasthaCode(planetName string, planet *Planet) string {
+	if planet == nil || planet.Degrees <= 0 || !slices.Contains(grahas, planetName) {
+		return ""
+	}
+	return avasthaCodes[ComputeBaladiAvastha(planet.Rashi, planet.Degrees)]
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeBaladiAvastha(t *testing.T) {
+	tests := []struct {
+		rashi   string
+		degrees float64
+		want    Avastha
+	}{
+		// Odd rashis run from bala to mrita, each band starting at its boundary
+		{"aries", 0, AvasthaBala},
+		{"aries", 5.999, AvasthaBala},
+		{"aries", 6, AvasthaKumara},
+		{"aries", 11.999, AvasthaKumara},
+		{"aries", 12, AvasthaYuva},
+		{"aries", 18, AvasthaVriddha},
+		{"aries", 23.999, AvasthaVriddha},
+		{"aries", 24, AvasthaMrita},
+		{"aries", 29.999, AvasthaMrita},
+		{"aquarius", 12, AvasthaYuva},
+		// Even rashis run the other way round
+		{"taurus", 0, AvasthaMrita},
+		{"taurus", 5.999, AvasthaMrita},
+		{"taurus", 6, AvasthaVriddha},
+		{"taurus", 12, AvasthaYuva},
+		{"taurus", 17.999, AvasthaYuva},
+		{"taurus", 18, AvasthaKumara},
+		{"taurus", 24, AvasthaBala},
+		{"pisces", 29.999, AvasthaBala},
+		// Out of range
+		{"aries", 30, ""},
+		{"aries", -0.5, ""},
+		{"aries", math.NaN(), ""},
+		{"pluto", 10, ""},
+	}
+	for _, tt := range tests {
+		if got := ComputeBaladiAvastha(tt.rashi, tt.degrees); got != tt.want {
+			t.Errorf("ComputeBaladiAvastha(%q, %v) = %q, want %q", tt.rashi, tt.degrees, got, tt.want)
+		}
+	}
+}
+
+func TestLabelFormat_BaladiAvastha(t *testing.T) {
+	input := karakaInput(ChartTypeSouth)
+	input.Options.ShowBaladiAvastha = true
+	// The karaka comes first
+	f := newLabelFormat(input.Options).withKarakas(input)
+	if e := f.entry("rahu", input.Planets["rahu"]); e.superscript != "AK·B" {
+		t.Errorf("rahu superscript = %q, want AK·B", e.superscript)
+	}
+
+	f = newLabelFormat(ChartOptions{ShowBaladiAvastha: true})
+	if e := f.entry("sun", &Planet{Rashi: "leo", Degrees: 14}); e.superscript != "Y" {
+		t.Errorf("sun superscript = %q, want Y", e.superscript)
+	}
+	for name, p := range map[string]*Planet{
+		"moon":  {Rashi: "cancer"},
+		"mandi": {Rashi: "aries", Degrees: 3, IsUpagraha: true},
+	} {
+		if e := f.entry(name, p); e.superscript != "" {
+			t.Errorf("%s superscript = %q, want none", name, e.superscript)
+		}
+	}
+}
+
+func TestGenerateChart_BaladiAvastha(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := karakaInput(chartType)
+		input.Options = ChartOptions{ShowBaladiAvastha: true}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_baladi_avastha", data)
+
+		if overlaps := renderWithBoxes(t, input).overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+	}
+}
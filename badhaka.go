@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import "fmt"
+
+// Modality is whether a rashi is movable (chara), fixed (sthira) or dual
+// (dvisvabhava)
+type Modality string
+
+const (
+	ModalityMovable Modality = "movable" // Aries, Cancer, Libra, Capricorn
+	ModalityFixed   Modality = "fixed"   // Taurus, Leo, Scorpio, Aquarius
+	ModalityDual    Modality = "dual"    // Gemini, Virgo, Sagittarius, Pisces
+)
+
+// DefaultBadhakaFill tints the badhaka house with HighlightBadhaka
+const DefaultBadhakaFill = "#f5d6d6"
+
+// SignModality returns the modality of a rashi number (1-12), or an empty
+// Modality for any other number. The modalities repeat in that order from
+// Aries.
+func SignModality(rashi int) Modality {
+	if rashi < 1 || rashi > 12 {
+		return ""
+	}
+	return [3]Modality{ModalityMovable, ModalityFixed, ModalityDual}[(rashi-1)%3]
+}
+
+// BadhakaHouse returns the badhaka (obstructing) house counted from a lagna
+// rashi: the 11th for a movable lagna, the 9th for a fixed and the 7th for
+// a dual one. It returns 0 for a rashi number outside 1-12.
+func BadhakaHouse(lagnaRashi int) int {
+	switch SignModality(lagnaRashi) {
+	case ModalityMovable:
+		return 11
+	case ModalityFixed:
+		return 9
+	case ModalityDual:
+		return 7
+	}
+	return 0
+}
+
+// BadhakaLord returns the badhakesh, the lord of the badhaka house of a
+// lagna rashi, as a lowercase planet name, or an empty string for a rashi
+// number outside 1-12
+func BadhakaLord(lagnaRashi int) string {
+	house := BadhakaHouse(lagnaRashi)
+	if house == 0 {
+		return ""
+	}
+	return SignLord((lagnaRashi+house-2)%12 + 1)
+}
+
+// validateBadhaka checks that an input asking for its badhaka house or lord
+// has the lagna they are counted from
+func validateBadhaka(input ChartInput) error {
+	if input.Lagna != nil {
+		return nil
+	}
+	switch {
+	case input.Options.HighlightBadhaka:
+		return fmt.Errorf("highlight_badhaka: %w", ErrMissingLagna)
+	case input.Options.MarkBadhakesh:
+		return fmt.Errorf("mark_badhakesh: %w", ErrMissingLagna)
+	}
+	return nil
+}
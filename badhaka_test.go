@@ -0,0 +1,121 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestBadhaka_AllLagnas(t *testing.T) {
+	tests := []struct {
+		lagna    int
+		modality Modality
+		house    int
+		lord     string
+	}{
+		{1, ModalityMovable, 11, "saturn"}, // Aquarius
+		{2, ModalityFixed, 9, "saturn"},    // Capricorn
+		{3, ModalityDual, 7, "jupiter"},    // Sagittarius
+		{4, ModalityMovable, 11, "venus"},  // Taurus
+		{5, ModalityFixed, 9, "mars"},      // Aries
+		{6, ModalityDual, 7, "jupiter"},    // Pisces
+		{7, ModalityMovable, 11, "sun"},    // Leo
+		{8, ModalityFixed, 9, "moon"},      // Cancer
+		{9, ModalityDual, 7, "mercury"},    // Gemini
+		{10, ModalityMovable, 11, "mars"},  // Scorpio
+		{11, ModalityFixed, 9, "venus"},    // Libra
+		{12, ModalityDual, 7, "mercury"},   // Virgo
+	}
+	for _, tt := range tests {
+		if got := SignModality(tt.lagna); got != tt.modality {
+			t.Errorf("SignModality(%d) = %q, want %q", tt.lagna, got, tt.modality)
+		}
+		if got := BadhakaHouse(tt.lagna); got != tt.house {
+			t.Errorf("BadhakaHouse(%d) = %d, want %d", tt.lagna, got, tt.house)
+		}
+		if got := BadhakaLord(tt.lagna); got != tt.lord {
+			t.Errorf("BadhakaLord(%d) = %q, want %q", tt.lagna, got, tt.lord)
+		}
+	}
+	for _, rashi := range []int{0, 13, -1} {
+		if SignModality(rashi) != "" || BadhakaHouse(rashi) != 0 || BadhakaLord(rashi) != "" {
+			t.Errorf("rashi %d got a modality, badhaka house or lord", rashi)
+		}
+	}
+}
+
+func TestHouseFills_Badhaka(t *testing.T) {
+	input := ChartInput{Lagna: &Planet{Rashi: "leo"}, Options: ChartOptions{HighlightBadhaka: true}}
+	badhaka := color.NRGBA{R: 0xf5, G: 0xd6, B: 0xd6, A: 0xff}
+	if fills := houseFills(input); len(fills) != 1 || fills[9] != badhaka {
+		t.Errorf("fills = %v, want only the 9th house in %v", fills, badhaka)
+	}
+
+	// A highlight group listing the house wins
+	input.Options.HighlightHouses = kendraTrikonaHighlights
+	trikona := color.NRGBA{R: 0xd8, G: 0xec, B: 0xd8, A: 0xff}
+	if fills := houseFills(input); fills[9] != trikona {
+		t.Errorf("9th house fill = %v, want the trikona group's %v", fills[9], trikona)
+	}
+
+	// Without a lagna there is no badhaka house
+	input = ChartInput{Options: ChartOptions{HighlightBadhaka: true, BadhakaFill: "#000"}}
+	if fills := houseFills(input); len(fills) != 0 {
+		t.Errorf("fills without a lagna = %v, want none", fills)
+	}
+}
+
+func TestLabelFormat_Badhakesh(t *testing.T) {
+	// Venus rules Libra, the 11th house from a Cancer lagna
+	f := newLabelFormat(ChartOptions{MarkBadhakesh: true}).withLagna(4)
+	if got := f.format("venus", &Planet{Rashi: "aries"}); got != "Ve×" {
+		t.Errorf("badhakesh label = %q, want %q", got, "Ve×")
+	}
+	if got := f.format("saturn", &Planet{Rashi: "aries"}); got != "Sa" {
+		t.Errorf("other planet label = %q, want %q", got, "Sa")
+	}
+	custom := newLabelFormat(ChartOptions{MarkBadhakesh: true, BadhakeshMarker: "!"}).withLagna(4)
+	if got := custom.format("venus", &Planet{Rashi: "aries", IsRetrograde: true}); got != "Ve!R" {
+		t.Errorf("custom marker label = %q, want %q", got, "Ve!R")
+	}
+}
+
+func TestGenerateChart_Badhaka(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "cancer"},
+			Planets: map[string]*Planet{
+				"venus":  {Rashi: "gemini"},
+				"moon":   {Rashi: "cancer"},
+				"saturn": {Rashi: "libra"},
+			},
+			Options: ChartOptions{HighlightBadhaka: true, MarkBadhakesh: true},
+		}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_badhaka", data)
+	}
+
+	input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{BadhakaFill: "red"}}
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "badhaka_fill") {
+		t.Errorf("error = %v, want badhaka_fill rejected", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ChartError is the failure of one chart of a batch
+type ChartError struct {
+	Index int // Position of the chart's input in the batch
+	Err   error
+}
+
+func (e *ChartError) Error() string {
+	return fmt.Sprintf("chart %d: %v", e.Index, e.Err)
+}
+
+func (e *ChartError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the charts of a batch that failed, in input order
+type BatchError struct {
+	Errors []*ChartError
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d charts failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed charts, for errors.Is and errors.As
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Indices returns the positions of the failed charts in the batch
+func (e *BatchError) Indices() []int {
+	indices := make([]int, len(e.Errors))
+	for i, err := range e.Errors {
+		indices[i] = err.Index
+	}
+	return indices
+}
+
+// GenerateCharts generates a chart for each input on up to concurrency
+// workers, runtime.GOMAXPROCS when concurrency is not positive, and returns
+// their PNG bytes in input order. Every chart is attempted: when some fail,
+// the others are still returned, the failed ones are nil and the error is a
+// *BatchError naming them. When ctx is done, charts not yet started are
+// skipped and its error is returned with the charts finished so far.
+func GenerateCharts(ctx context.Context, inputs []ChartInput, concurrency int) ([][]byte, error) {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	concurrency = min(concurrency, len(inputs))
+
+	pngs := make([][]byte, len(inputs))
+	errs := make([]error, len(inputs))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker draws on a renderer of its own
+			r := rendererPool.Get().(*renderer)
+			defer rendererPool.Put(r)
+			for i := range indices {
+				pngs[i], errs[i] = r.generate(inputs[i])
+			}
+		}()
+	}
+
+	started := 0
+dispatch:
+	for i := range inputs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indices <- i:
+			started++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if started < len(inputs) {
+		return pngs, ctx.Err()
+	}
+	var failed []*ChartError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &ChartError{Index: i, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		return pngs, &BatchError{Errors: failed}
+	}
+	return pngs, nil
+}
+
+// GenerateBothCharts draws the North and the South chart of one input and
+// returns their PNG bytes. The input is validated and canonicalized once, then
+// both charts are drawn concurrently, each on a renderer of its own.
+//
+// The input's ChartType is ignored: the North chart is drawn as for
+// ChartTypeNorth and the South as for ChartTypeSouth, and options that only
+// one style draws, such as NakshatraRing or AllowStretch, apply to that one
+// alone. An invalid input is returned as its error, and when drawing fails
+// the error names the chart; either way neither chart is returned.
+func GenerateBothCharts(input ChartInput) (north, south []byte, err error) {
+	input.ChartType = ChartTypeNorth
+	if err := validateInput(input); err != nil {
+		return nil, nil, err
+	}
+	if _, err := CanonicalizeChartInput(&input); err != nil {
+		return nil, nil, err
+	}
+
+	generate := func(chartType ChartType) ([]byte, error) {
+		r := rendererPool.Get().(*renderer)
+		defer rendererPool.Put(r)
+		in := input
+		in.ChartType = chartType
+		img, err := r.drawNormalized(in, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s chart: %w", chartType, err)
+		}
+		return r.encode(img)
+	}
+	var southErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		south, southErr = generate(ChartTypeSouth)
+	}()
+	north, err = generate(ChartTypeNorth)
+	<-done
+	if err = cmp.Or(err, southErr); err != nil {
+		return nil, nil, err
+	}
+	return north, south, nil
+}
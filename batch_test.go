@@ -0,0 +1,146 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// batchInputs returns n charts alternating between the South and North
+// styles, with the lagna moving round the zodiac
+func batchInputs(n int) []ChartInput {
+	inputs := make([]ChartInput, n)
+	for i := range inputs {
+		chartType := ChartTypeSouth
+		if i%2 == 1 {
+			chartType = ChartTypeNorth
+		}
+		rashi := NumberToRashi(i%12 + 1)
+		inputs[i] = ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: rashi},
+			Planets: map[string]*Planet{
+				"sun":  {Rashi: rashi},
+				"moon": {Rashi: NumberToRashi((i+4)%12 + 1), IsRetrograde: i%3 == 0},
+				"mars": {Rashi: NumberToRashi((i+7)%12 + 1), Degrees: float64(i % 30)},
+			},
+			Options: ChartOptions{ShowDegrees: i%4 == 0},
+		}
+	}
+	return inputs
+}
+
+func TestGenerateCharts_MatchesSerialOutput(t *testing.T) {
+	inputs := batchInputs(100)
+	pngs, err := GenerateCharts(context.Background(), inputs, 8)
+	if err != nil {
+		t.Fatalf("Error generating charts: %v", err)
+	}
+	if len(pngs) != len(inputs) {
+		t.Fatalf("Expected %d charts, got %d", len(inputs), len(pngs))
+	}
+	for i, input := range inputs {
+		want, err := (*renderer)(nil).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating chart %d: %v", i, err)
+		}
+		if !bytes.Equal(pngs[i], want) {
+			t.Errorf("Chart %d of the batch differs from the same chart drawn alone", i)
+		}
+	}
+}
+
+func TestGenerateCharts_ReportsFailedIndices(t *testing.T) {
+	inputs := batchInputs(10)
+	inputs[3].ChartType = "east"
+	inputs[7].Planets["mars"].Degrees = 42
+
+	pngs, err := GenerateCharts(context.Background(), inputs, 0)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *BatchError, got %v", err)
+	}
+	if got := batchErr.Indices(); !slices.Equal(got, []int{3, 7}) {
+		t.Errorf("Expected charts 3 and 7 to fail, got %v", got)
+	}
+	var chartErr *ChartError
+	if !errors.As(err, &chartErr) || chartErr.Index != 3 {
+		t.Errorf("Expected errors.As to find chart 3's error, got %v", chartErr)
+	}
+	for i, png := range pngs {
+		if failed := i == 3 || i == 7; failed != (png == nil) {
+			t.Errorf("Chart %d: got %d bytes, failed %v", i, len(png), failed)
+		}
+	}
+}
+
+func TestGenerateCharts_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pngs, err := GenerateCharts(ctx, batchInputs(20), 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(pngs) != 20 {
+		t.Errorf("Expected a result slot for every input, got %d", len(pngs))
+	}
+}
+
+func TestGenerateCharts_Empty(t *testing.T) {
+	pngs, err := GenerateCharts(context.Background(), nil, 4)
+	if err != nil || len(pngs) != 0 {
+		t.Errorf("Expected no charts and no error, got %d charts and %v", len(pngs), err)
+	}
+}
+
+func TestGenerateBothCharts(t *testing.T) {
+	input := batchInputs(1)[0]
+	input.ChartType = ChartTypeSarvashtakavarga // Ignored
+	north, south, err := GenerateBothCharts(input)
+	if err != nil {
+		t.Fatalf("Error generating charts: %v", err)
+	}
+	for chartType, got := range map[ChartType][]byte{ChartTypeNorth: north, ChartTypeSouth: south} {
+		input.ChartType = chartType
+		want, err := (*renderer)(nil).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s chart differs from the same chart drawn alone", chartType)
+		}
+	}
+
+	// An invalid input fails once, before either chart is drawn
+	input.Planets["mars"].Degrees = 42
+	north, south, err = GenerateBothCharts(input)
+	if err == nil || north != nil || south != nil {
+		t.Errorf("Expected an error and no charts for invalid degrees, got %d and %d bytes and %v", len(north), len(south), err)
+	}
+
+	// A chart failing to draw is named, the North first when both do
+	input = batchInputs(1)[0]
+	input.Options.Width, input.Options.Height = 120, 120
+	_, _, err = GenerateBothCharts(input)
+	if err == nil || !strings.HasPrefix(err.Error(), "north chart: ") {
+		t.Errorf("Expected a chart named in the error, got %v", err)
+	}
+}
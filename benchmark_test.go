@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"testing"
+
+	"github.com/fogleman/gg"
+)
+
+func TestDrawText_MatchesGG(t *testing.T) {
+	backgrounds := []color.Color{color.White, color.NRGBA{R: 255, G: 243, B: 196, A: 255}, color.NRGBA{R: 30, G: 60, B: 90, A: 128}}
+	colors := []color.Color{textBlack, lagnaSaffron, specialYellow, houseNumberGray, color.NRGBA{R: 200, A: 100}}
+	for _, bg := range backgrounds {
+		for _, c := range colors {
+			for _, size := range []float64{7.3, 12, 22} {
+				for _, x := range []float64{10, 10.3, 10.77} {
+					face := embeddedFace(matangiBold, size)
+					s := "JuR 17°32' Rohini-2 ल"
+
+					want := gg.NewContext(300, 40)
+					want.SetColor(bg)
+					want.Clear()
+					want.SetFontFace(face)
+					want.SetColor(c)
+					want.DrawStringAnchored(s, x, 25.6, 0.5, 0)
+
+					got := gg.NewContext(300, 40)
+					got.SetColor(bg)
+					got.Clear()
+					drawText(got, face, c, s, x, 25.6, 0.5)
+
+					if n := countDifferentPixels(got.Image(), want.Image()); n > 0 {
+						t.Errorf("drawText differs from gg in %d pixels (background %v, color %v, size %v, x %v)", n, bg, c, size, x)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestGenerateChart_PixelsMatchGoldens checks whole charts drawn by a fresh
+// renderer pixel for pixel, so that drawing does not drift from the stored
+// output. Pixels rather than bytes are compared: PNG encoders may compress
+// the same image differently.
+func TestGenerateChart_PixelsMatchGoldens(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := crowdedHouseInput(chartType)
+		input.Options.Width, input.Options.Height = 1200, 800
+		got, err := (&renderer{}).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, fmt.Sprintf("%s_canvas_1200x800", chartType), got)
+	}
+}
+
+// benchmarkInput is a busy chart: a crowded house, degrees and nakshatras
+func benchmarkInput(chartType ChartType) ChartInput {
+	input := crowdedHouseInput(chartType)
+	input.Options.ShowDegrees = true
+	input.Options.ShowNakshatra = true
+	input.Options.ShowHouseNumbers = true
+	input.CenterText = "Rashi\nD1"
+	for _, p := range input.Planets {
+		p.Degrees, p.Nakshatra, p.Pada = 17.5, "Ashwini", 2
+	}
+	return input
+}
+
+func benchmarkGenerate(b *testing.B, generate func(ChartInput) ([]byte, error), input ChartInput) {
+	b.ReportAllocs()
+	for b.Loop() {
+		data, err := generate(input)
+		if err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateSouthChart(b *testing.B) {
+	benchmarkGenerate(b, GenerateSouthChart, benchmarkInput(ChartTypeSouth))
+}
+
+func BenchmarkGenerateNorthChart(b *testing.B) {
+	benchmarkGenerate(b, GenerateNorthChart, benchmarkInput(ChartTypeNorth))
+}
+
+func BenchmarkGenerateChart(b *testing.B) {
+	input := benchmarkInput(ChartTypeSouth)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := GenerateChart(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeBase64_Buffered encodes a drawn chart the way GenerateChart
+// used to, the whole PNG first and then its base64 copy
+func BenchmarkEncodeBase64_Buffered(b *testing.B) {
+	img, err := (*renderer)(nil).draw(benchmarkInput(ChartTypeSouth), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		data, err := encodePNG(img)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = base64.StdEncoding.EncodeToString(data)
+	}
+}
+
+func BenchmarkEncodeBase64_Streamed(b *testing.B) {
+	img, err := (*renderer)(nil).draw(benchmarkInput(ChartTypeSouth), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := &renderer{}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := r.encodeBase64(img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// defaultChartSize is the side of the square canvas charts are drawn on
+// unless Width or Height say otherwise. Fonts, offsets and line widths are
+// given for this size and scale with the chart.
+const defaultChartSize = 800
+
+// MaxCanvasSize is the largest Width or Height a chart may be drawn at. The
+// canvas is held in memory whole, four bytes a pixel, so this keeps one
+// within 256 MB.
+const MaxCanvasSize = 8192
+
+// The high-contrast style multiplies stroke widths and font sizes by these
+const (
+	highContrastStroke = 2.0
+	highContrastFont   = 1.3
+	highContrastWeight = 0.03 // Offset of the second pass over planet labels, by font size
+)
+
+// minFontSize is the smallest font size, in pixels, a chart may draw text at.
+// Fonts scale with the chart, so this sets the smallest canvas a chart with
+// given options and crowding can be drawn on.
+const minFontSize = 5.0
+
+// checkCanvasSize reports a Width or Height that is negative or larger than
+// MaxCanvasSize
+func (o ChartOptions) checkCanvasSize() error {
+	if o.Width < 0 || o.Height < 0 {
+		return fmt.Errorf("width and height must not be negative: %dx%d", o.Width, o.Height)
+	}
+	if o.Width > MaxCanvasSize || o.Height > MaxCanvasSize {
+		return fmt.Errorf("width and height must be at most %d: %dx%d", MaxCanvasSize, o.Width, o.Height)
+	}
+	return nil
+}
+
+// canvasSize returns the canvas width and height set by the options, each
+// defaultChartSize, or thumbnailSize for thumbnails, when zero
+func (o ChartOptions) canvasSize() (w, h int) {
+	side := defaultChartSize
+	if o.Thumbnail {
+		side = thumbnailSize
+	}
+	w, h = o.Width, o.Height
+	if w == 0 {
+		w = side
+	}
+	if h == 0 {
+		h = side
+	}
+	return w, h
+}
+
+// chartFrame is the part of the canvas a chart is drawn in
+type chartFrame struct {
+	x, y          float64 // Top-left corner
+	width, height float64
+	scale         float64 // Size relative to defaultChartSize, for fonts and offsets
+	contrast      bool    // Draw in the high-contrast style, see ChartOptions.HighContrast
+}
+
+// newChartFrame returns the largest square centered on a w x h canvas, or
+// the whole canvas when stretch is set. Either way its scale follows the
+// shorter side, so text keeps its proportions.
+func newChartFrame(w, h int, stretch bool) chartFrame {
+	side := math.Min(float64(w), float64(h))
+	f := chartFrame{width: side, height: side, scale: side / defaultChartSize}
+	if stretch {
+		f.width, f.height = float64(w), float64(h)
+	}
+	f.x, f.y = (float64(w)-f.width)/2, (float64(h)-f.height)/2
+	return f
+}
+
+// px scales a length given for defaultChartSize to the frame
+func (f chartFrame) px(v float64) float64 {
+	return v * f.scale
+}
+
+// line scales a stroke width like px, thickened in the high-contrast style
+func (f chartFrame) line(v float64) float64 {
+	if f.contrast {
+		v *= highContrastStroke
+	}
+	return f.px(v)
+}
+
+// font scales a font size like px, enlarged in the high-contrast style
+func (f chartFrame) font(v float64) float64 {
+	if f.contrast {
+		v *= highContrastFont
+	}
+	return f.px(v)
+}
+
+// ink returns c, or black in the high-contrast style
+func (f chartFrame) ink(c color.Color) color.Color {
+	if f.contrast {
+		return textBlack
+	}
+	return c
+}
+
+// legibility records the smallest font a chart draws its text with
+type legibility struct {
+	smallest float64
+}
+
+// use records a font size in use
+func (l *legibility) use(size float64) {
+	if l.smallest == 0 || size < l.smallest {
+		l.smallest = size
+	}
+}
+
+// useLayout records the planet font of a house with labels and the smaller
+// font of their second lines, if any
+func (l *legibility) useLayout(layout houseLayout) {
+	if len(layout.labels) == 0 {
+		return
+	}
+	l.use(layout.size)
+	for _, p := range layout.labels {
+		if p.entry.subLabel != "" {
+			l.use(layout.size * subLabelScale)
+			break
+		}
+	}
+}
+
+// check rejects a w x h canvas on which the smallest font fell below
+// minFontSize, naming the shorter side the chart needs instead
+func (l legibility) check(w, h int) error {
+	if l.smallest >= minFontSize {
+		return nil
+	}
+	side := math.Min(float64(w), float64(h))
+	need := int(math.Ceil(side * minFontSize / l.smallest))
+	return fmt.Errorf("canvas %dx%d is too small to keep text legible: the chart needs at least %dpx on its shorter side", w, h, need)
+}
+
+// shiftInto returns how far b must move to lie inside a w x h canvas. A box
+// larger than the canvas keeps its top-left corner on it.
+func (b textBox) shiftInto(w, h int) (dx, dy float64) {
+	if b.right > float64(w) {
+		dx = float64(w) - b.right
+	}
+	if b.left+dx < 0 {
+		dx = -b.left
+	}
+	if b.bottom > float64(h) {
+		dy = float64(h) - b.bottom
+	}
+	if b.top+dy < 0 {
+		dy = -b.top
+	}
+	return dx, dy
+}
+
+// clampLayout moves the labels of a house that stick out of a w x h canvas
+// back onto it. Layouts stay inside their house at any legible size, this
+// only guards the canvas edges against unusual option combinations.
+func clampLayout(dc *gg.Context, layout houseLayout, w, h int) houseLayout {
+	loadMatangiBold(dc, layout.size)
+	lineHeight := planetLineHeight(layout.size)
+	for i, l := range layout.labels {
+		width, top, bottom := labelBox(dc, l, lineHeight)
+		left := l.x - l.ax*width
+		dx, dy := textBox{left: left, top: top, right: left + width, bottom: bottom}.shiftInto(w, h)
+		layout.labels[i].x += dx
+		layout.labels[i].y += dy
+	}
+	return layout
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNewChartFrame(t *testing.T) {
+	tests := []struct {
+		w, h    int
+		stretch bool
+		want    chartFrame
+	}{
+		{800, 800, false, chartFrame{0, 0, 800, 800, 1, false}},
+		{1200, 800, false, chartFrame{200, 0, 800, 800, 1, false}},
+		{800, 1200, false, chartFrame{0, 200, 800, 800, 1, false}},
+		{1200, 800, true, chartFrame{0, 0, 1200, 800, 1, false}},
+		{400, 600, false, chartFrame{0, 100, 400, 400, 0.5, false}},
+	}
+	for _, tt := range tests {
+		if got := newChartFrame(tt.w, tt.h, tt.stretch); got != tt.want {
+			t.Errorf("newChartFrame(%d, %d, %v) = %+v, want %+v", tt.w, tt.h, tt.stretch, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_HighContrast(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Planets["hora lagna"] = &Planet{Rashi: "leo", IsSpecialLagna: true, Display: "HL"}
+		input.Planets["mandi"] = &Planet{Rashi: "capricorn"}
+		input.Options = ChartOptions{
+			HighContrast:     true,
+			ShowHouseScores:  true,
+			ShowHouseNumbers: true,
+			HighlightHouses:  []HouseHighlight{{Houses: []int{1, 5, 9}, Color: "#FFE0B2"}},
+		}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_high_contrast", data)
+
+		// Only black, white and the gray of antialiased edges are drawn:
+		// no highlights and no category colors
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("decoding %s chart: %v", chartType, err)
+		}
+		bounds := img.Bounds()
+	pixels:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if r, g, b, _ := img.At(x, y).RGBA(); r != g || g != b {
+					t.Errorf("%s: pixel at (%d, %d) is colored", chartType, x, y)
+					break pixels
+				}
+			}
+		}
+
+		// The larger text still fits the same geometry
+		boxes := renderWithBoxes(t, input)
+		if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+		for _, b := range boxes.boxes {
+			if b.left < 0 || b.top < 0 || b.right > defaultChartSize || b.bottom > defaultChartSize {
+				t.Errorf("%s: box of %q lies outside the canvas: %+v", chartType, b.text, b)
+			}
+		}
+	}
+}
+
+// inkBounds returns the smallest rectangle holding every non-white pixel
+func inkBounds(img image.Image) image.Rectangle {
+	var ink image.Rectangle
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r&g&b != 0xffff {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return ink
+}
+
+func TestGenerateChart_RectangularCanvas(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		for _, size := range []image.Point{{1200, 800}, {800, 1200}} {
+			input := crowdedHouseInput(chartType)
+			input.Options.Width, input.Options.Height = size.X, size.Y
+			generate := GenerateSouthChart
+			if chartType == ChartTypeNorth {
+				generate = GenerateNorthChart
+			}
+			data, err := generate(input)
+			if err != nil {
+				t.Fatalf("Error generating %s chart at %v: %v", chartType, size, err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Error decoding %s chart: %v", chartType, err)
+			}
+			if got := img.Bounds().Size(); got != size {
+				t.Fatalf("%s chart is %v, want %v", chartType, got, size)
+			}
+
+			// The chart sits in the centered 800px square and stays square
+			frame := newChartFrame(size.X, size.Y, false)
+			square := image.Rect(int(frame.x), int(frame.y), int(frame.x+frame.width), int(frame.y+frame.height))
+			ink := inkBounds(img)
+			if !ink.In(square) {
+				t.Errorf("%s chart at %v drew %v, outside the centered square %v", chartType, size, ink, square)
+			}
+			if d := ink.Dx() - ink.Dy(); d < -2 || d > 2 {
+				t.Errorf("%s chart at %v is not square: %v", chartType, size, ink)
+			}
+			cx, cy := float64(ink.Min.X+ink.Max.X)/2, float64(ink.Min.Y+ink.Max.Y)/2
+			if math.Abs(cx-float64(size.X)/2) > 2 || math.Abs(cy-float64(size.Y)/2) > 2 {
+				t.Errorf("%s chart at %v is centered on (%v, %v), want the canvas center", chartType, size, cx, cy)
+			}
+
+			boxes := renderWithBoxes(t, input)
+			if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+				t.Errorf("%s chart at %v has overlapping labels: %v", chartType, size, overlaps)
+			}
+
+			assertGolden(t, fmt.Sprintf("%s_canvas_%dx%d", chartType, size.X, size.Y), data)
+		}
+	}
+}
+
+func TestSouthChart_AllowStretch(t *testing.T) {
+	input := crowdedHouseInput(ChartTypeSouth)
+	input.Options.Width, input.Options.Height = 1200, 800
+	input.Options.AllowStretch = true
+	input.CenterText = "Rashi Chart\nStretched"
+	data, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating stretched chart: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error decoding stretched chart: %v", err)
+	}
+
+	// The grid keeps the 40px padding on every side of the wide canvas
+	if ink := inkBounds(img); ink.Min.X > 41 || ink.Max.X < 1159 || ink.Min.Y > 41 || ink.Max.Y < 759 {
+		t.Errorf("Stretched grid spans %v, want it to fill the canvas inside the padding", ink)
+	}
+	boxes := renderWithBoxes(t, input)
+	if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+		t.Errorf("Stretched chart has overlapping labels: %v", overlaps)
+	}
+	assertGolden(t, "south_allow_stretch", data)
+}
+
+func TestGenerateChart_RejectsIllegibleSize(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets:   map[string]*Planet{"sun": {Rashi: "leo"}},
+	}
+	input.Options.Width, input.Options.Height = 120, 120
+	_, err := GenerateChart(input)
+	if err == nil || !strings.Contains(err.Error(), "too small") {
+		t.Fatalf("Expected a 120px chart to be rejected as too small, got %v", err)
+	}
+
+	// The error names a size that works
+	var need int
+	if _, scanErr := fmt.Sscanf(err.Error()[strings.Index(err.Error(), "at least"):], "at least %dpx", &need); scanErr != nil {
+		t.Fatalf("Error %q does not name the size needed: %v", err, scanErr)
+	}
+	input.Options.Width, input.Options.Height = need, need
+	if _, err := GenerateChart(input); err != nil {
+		t.Errorf("Expected a %dpx chart to render, got %v", need, err)
+	}
+}
+
+func TestGenerateChart_RejectsOversizedCanvas(t *testing.T) {
+	sizes := [][2]int{{100000, 100000}, {MaxCanvasSize + 1, 800}, {800, MaxCanvasSize + 1}}
+	for _, size := range sizes {
+		input := ChartInput{ChartType: ChartTypeSouth, Planets: map[string]*Planet{"sun": {Rashi: "leo"}}}
+		input.Options.Width, input.Options.Height = size[0], size[1]
+		if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "at most") {
+			t.Errorf("GenerateChart at %dx%d: error %v, want one naming the largest size", size[0], size[1], err)
+		}
+		if _, err := GenerateSouthChart(input); err == nil {
+			t.Errorf("GenerateSouthChart accepted %dx%d", size[0], size[1])
+		}
+		if _, err := GenerateNorthChart(input); err == nil {
+			t.Errorf("GenerateNorthChart accepted %dx%d", size[0], size[1])
+		}
+	}
+}
+
+func TestTextBox_ShiftInto(t *testing.T) {
+	tests := []struct {
+		box    textBox
+		dx, dy float64
+	}{
+		{textBox{left: 10, top: 10, right: 50, bottom: 30}, 0, 0},
+		{textBox{left: -5, top: 10, right: 35, bottom: 30}, 5, 0},
+		{textBox{left: 80, top: 90, right: 120, bottom: 110}, -20, -10},
+		{textBox{left: -10, top: -10, right: 150, bottom: 20}, 10, 10},
+	}
+	for _, tt := range tests {
+		if dx, dy := tt.box.shiftInto(100, 100); dx != tt.dx || dy != tt.dy {
+			t.Errorf("shiftInto(%+v) = (%v, %v), want (%v, %v)", tt.box, dx, dy, tt.dx, tt.dy)
+		}
+	}
+}
+
+// TestGenerateChart_SizeSweep renders crowded charts with long labels on
+// canvases from 50 to 3000px. Each must either be rejected as too small or
+// keep all of its text on the canvas, and none may panic.
+func TestGenerateChart_SizeSweep(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		for side := 50; side <= 3000; side = side * 3 / 2 {
+			for _, size := range []image.Point{{side, side}, {side, side / 2}, {side / 3, side}} {
+				input := crowdedHouseInput(chartType)
+				input.Options.Width, input.Options.Height = size.X, size.Y
+				input.Options.AllowStretch = size.X > size.Y
+				input.Options.RashiLabelMode = RashiLabelSanskritName
+				input.Options.ShowHouseNumbers = true
+				input.Options.ShowDegrees = true
+				input.Options.LagnaMarkerStyle = LagnaMarkerLetter
+				input.CenterText = "Rashi"
+
+				boxes := &chartBoxes{}
+				var err error
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Fatalf("%s chart at %v panicked: %v", chartType, size, r)
+						}
+					}()
+					if chartType == ChartTypeNorth {
+						_, err = renderNorthChart(nil, input, boxes)
+					} else {
+						_, err = renderSouthChart(nil, input, boxes)
+					}
+				}()
+				if err != nil {
+					if !strings.Contains(err.Error(), "too small") {
+						t.Errorf("%s chart at %v: %v", chartType, size, err)
+					}
+					continue
+				}
+				for _, b := range boxes.boxes {
+					if b.left < 0 || b.top < 0 || b.right > float64(size.X) || b.bottom > float64(size.Y) {
+						t.Errorf("%s chart at %v: box of %q lies outside the canvas: %+v", chartType, size, b.text, b)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"slices"
+	"strings"
+)
+
+// PointInfo describes a planet or point the library knows by name, as its
+// charts label it
+type PointInfo struct {
+	Name         string        `json:"name"`             // Key in ChartInput.Planets, such as "sun"
+	Abbreviation string        `json:"abbreviation"`     // Abbreviation in the default locale, "Su"
+	FullName     string        `json:"full_name"`        // Full name in the default locale, "Sun"
+	Letter       string        `json:"letter,omitempty"` // Letter of the letter display mode, for the grahas
+	Category     PointCategory `json:"category"`
+	// Locales holds the names in every registered locale by tag
+	Locales map[string]LocalizedName `json:"locales"`
+}
+
+// LocalizedName is the abbreviation and full name of a point in a locale
+type LocalizedName struct {
+	Abbreviation string `json:"abbreviation"`
+	FullName     string `json:"full_name"`
+}
+
+// SupportedPlanets returns the planets and points the library knows: the
+// lagna, the grahas in their traditional order, then the upagrahas and the
+// points registered with RegisterPlanetAbbreviation in alphabetical order.
+// Their names come from the tables the charts are labelled from.
+func SupportedPlanets() []PointInfo {
+	names := append([]string{"lagna"}, grahas...)
+	var rest []string
+	for name := range planetAbbreviations {
+		if !slices.Contains(names, name) {
+			rest = append(rest, name)
+		}
+	}
+	for _, name := range customAbbreviationNames() {
+		if !slices.Contains(names, name) && !slices.Contains(rest, name) {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	names = append(names, rest...)
+
+	def, _ := lookupLocale("")
+	tags := Locales()
+	points := make([]PointInfo, len(names))
+	for i, name := range names {
+		category := PlanetCategory(name, nil)
+		if name == "lagna" {
+			category = PointLagna
+		}
+		points[i] = PointInfo{
+			Name:         name,
+			Abbreviation: def.abbreviation(name),
+			FullName:     def.fullName(name),
+			Letter:       PlanetLetter(name),
+			Category:     category,
+			Locales:      make(map[string]LocalizedName, len(tags)),
+		}
+		for _, tag := range tags {
+			if loc, ok := lookupLocale(tag); ok {
+				points[i].Locales[tag] = LocalizedName{Abbreviation: loc.abbreviation(name), FullName: loc.fullName(name)}
+			}
+		}
+	}
+	return points
+}
+
+// IsKnownPlanet reports whether the library knows a planet or point by name,
+// in any case: a graha, the lagna, an upagraha or a point registered with
+// RegisterPlanetAbbreviation. Charts draw unknown points unlabelled unless
+// they set Display.
+func IsKnownPlanet(name string) bool {
+	name = strings.ToLower(name)
+	if _, ok := planetAbbreviations[name]; ok {
+		return true
+	}
+	_, ok := customAbbreviation(name)
+	return ok
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"testing"
+)
+
+func TestSupportedPlanets(t *testing.T) {
+	if err := RegisterPlanetAbbreviation("test_catalog_point", "TC"); err != nil {
+		t.Fatal(err)
+	}
+	points := SupportedPlanets()
+	byName := map[string]PointInfo{}
+	for _, p := range points {
+		if _, dup := byName[p.Name]; dup {
+			t.Errorf("%s listed twice", p.Name)
+		}
+		byName[p.Name] = p
+	}
+
+	// The catalog covers every name the charts label
+	for name := range planetAbbreviations {
+		p, ok := byName[name]
+		if !ok {
+			t.Errorf("%s missing from SupportedPlanets", name)
+			continue
+		}
+		if p.Abbreviation != GetPlanetAbbreviation(name) {
+			t.Errorf("%s abbreviation %q, want %q", name, p.Abbreviation, GetPlanetAbbreviation(name))
+		}
+		if !IsKnownPlanet(name) {
+			t.Errorf("IsKnownPlanet(%q) = false", name)
+		}
+	}
+	for name, full := range planetFullNames {
+		if byName[name].FullName != full {
+			t.Errorf("%s full name %q, want %q", name, byName[name].FullName, full)
+		}
+	}
+
+	if points[0].Name != "lagna" || points[0].Category != PointLagna || points[1].Name != "sun" {
+		t.Errorf("catalog starts %+v, %+v, want the lagna then the sun", points[0], points[1])
+	}
+	for name, want := range map[string]PointCategory{"jupiter": PointGraha, "rahu": PointNode, "gulika": PointUpagraha, "test_catalog_point": PointCustom} {
+		if got := byName[name].Category; got != want {
+			t.Errorf("%s category %q, want %q", name, got, want)
+		}
+	}
+	if got := byName["test_catalog_point"].Abbreviation; got != "TC" {
+		t.Errorf("registered point abbreviation %q, want TC", got)
+	}
+	if byName["mercury"].Letter != "B" || byName["mandi"].Letter != "" {
+		t.Errorf("letters %q, %q, want B and none", byName["mercury"].Letter, byName["mandi"].Letter)
+	}
+
+	iast := byName["venus"].Locales[LocaleIAST]
+	if iast.Abbreviation != "Śu" || iast.FullName != "Śukra" {
+		t.Errorf("venus in %s = %+v, want Śu, Śukra", LocaleIAST, iast)
+	}
+	if len(byName["sun"].Locales) != len(Locales()) {
+		t.Errorf("sun named in %d locales, want %d", len(byName["sun"].Locales), len(Locales()))
+	}
+}
+
+func TestIsKnownPlanet(t *testing.T) {
+	for _, name := range []string{"sun", "Jupiter", "LAGNA", "mandi", "upagraha"} {
+		if !IsKnownPlanet(name) {
+			t.Errorf("IsKnownPlanet(%q) = false", name)
+		}
+	}
+	for _, name := range []string{"", "pluto", "pranapada_unregistered", " sun"} {
+		if IsKnownPlanet(name) {
+			t.Errorf("IsKnownPlanet(%q) = true", name)
+		}
+	}
+}
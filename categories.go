@@ -0,0 +1,179 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"cmp"
+	"image/color"
+	"slices"
+	"strings"
+)
+
+// PointCategory is the kind of point a house entry stands for, which decides
+// its color and where in the house it is listed
+type PointCategory string
+
+const (
+	// PointLagna is the lagna itself, in saffron before the planets
+	PointLagna PointCategory = "lagna"
+	// PointGraha is one of the seven grahas, Sun to Saturn, in black
+	PointGraha PointCategory = "graha"
+	// PointNode is Rahu or Ketu, the chhaya grahas, in black like the grahas
+	PointNode PointCategory = "node"
+	// PointUpagraha is an upagraha such as Mandi or Gulika, in brown below
+	// the grahas
+	PointUpagraha PointCategory = "upagraha"
+	// PointSpecialLagna is a special lagna such as the hora lagna, in yellow
+	// in the column right of the planets
+	PointSpecialLagna PointCategory = "special_lagna"
+	// PointArudhaPada is an arudha pada such as the AL or UL, in purple in
+	// the right column below the special lagnas
+	PointArudhaPada PointCategory = "arudha_pada"
+	// PointCustom is any other point, such as pranapada, in black below the
+	// upagrahas
+	PointCustom PointCategory = "custom"
+)
+
+// Lanes of a house, the columns its entries are listed in
+const (
+	laneMain = iota // The planets, right-aligned
+	laneSide        // Right of the planets, left-aligned
+)
+
+// categoryStyle is how the entries of a category are drawn in a house
+type categoryStyle struct {
+	title string // Name in the color key
+	color color.Color
+	lane  int
+	rank  int // Order within the lane, lowest first
+	print printStyle
+}
+
+// printStyle is how the entries of a category are told apart in grayscale,
+// where their colors are lost
+type printStyle struct {
+	shade       color.Color
+	open, close string // Written around the label
+	underline   bool
+}
+
+// plain reports whether the style leaves entries as the grahas are drawn
+func (s printStyle) plain() bool {
+	return s == printStyle{shade: textBlack}
+}
+
+// wrap returns a label with the style's brackets around it
+func (s printStyle) wrap(label string) string {
+	return s.open + label + s.close
+}
+
+// categoryStyles holds the style of every category. Categories sharing a
+// rank are listed together in alphabetical order.
+var categoryStyles = map[PointCategory]categoryStyle{
+	PointLagna:        {"Lagna", lagnaSaffron, laneMain, 0, printStyle{shade: textBlack, underline: true}},
+	PointGraha:        {"Graha", textBlack, laneMain, 1, printStyle{shade: textBlack}},
+	PointNode:         {"Node", textBlack, laneMain, 1, printStyle{shade: textBlack}},
+	PointUpagraha:     {"Upagraha", upagrahaBrown, laneMain, 2, printStyle{shade: printGray}},
+	PointCustom:       {"Other point", textBlack, laneMain, 3, printStyle{shade: textBlack}},
+	PointSpecialLagna: {"Special lagna", specialYellow, laneSide, 0, printStyle{shade: textBlack, open: "[", close: "]"}},
+	PointArudhaPada:   {"Arudha pada", arudhaPurple, laneSide, 1, printStyle{shade: textBlack, open: "(", close: ")"}},
+}
+
+// pointCategories lists the categories in the order their entries are listed
+var pointCategories = []PointCategory{PointLagna, PointGraha, PointNode, PointUpagraha, PointCustom, PointSpecialLagna, PointArudhaPada}
+
+// CategoryColors returns the color charts draw the points of each category
+// in, so that interfaces can build a key of their own
+func CategoryColors() map[PointCategory]color.Color {
+	colors := make(map[PointCategory]color.Color, len(categoryStyles))
+	for c, style := range categoryStyles {
+		colors[c] = style.color
+	}
+	return colors
+}
+
+// upagrahaNames are the upagrahas known without the IsUpagraha flag
+var upagrahaNames = map[string]bool{
+	"upaketu": true, "mandi": true, "gulika": true, "yamaghantaka": true,
+	"ardhaprahara": true, "kala": true, "dhuma": true, "vyatipata": true,
+	"parivesha": true, "indrachapa": true, "upagraha": true,
+}
+
+// PlanetCategory returns the category of a planet of the chart: its Category
+// when set, else a special lagna or upagraha when flagged so, else a graha,
+// node or upagraha by name, else a custom point
+func PlanetCategory(planetName string, planet *Planet) PointCategory {
+	name := strings.ToLower(planetName)
+	switch {
+	case planet != nil && planet.Category != "":
+		return planet.Category
+	case planet != nil && planet.IsSpecialLagna:
+		return PointSpecialLagna
+	case planet != nil && planet.IsUpagraha, upagrahaNames[name]:
+		return PointUpagraha
+	case name == "rahu" || name == "ketu":
+		return PointNode
+	case slices.Contains(grahas, name):
+		return PointGraha
+	}
+	return PointCustom
+}
+
+// validCategory reports whether c may be set as a planet's Category
+func validCategory(c PointCategory) bool {
+	_, ok := categoryStyles[c]
+	return ok && c != PointLagna
+}
+
+// color returns the color entries of the category are drawn in
+func (c PointCategory) color() color.Color {
+	if style, ok := categoryStyles[c]; ok {
+		return style.color
+	}
+	return textBlack
+}
+
+// printStyle returns how entries of the category are drawn in grayscale
+func (c PointCategory) printStyle() printStyle {
+	if style, ok := categoryStyles[c]; ok {
+		return style.print
+	}
+	return printStyle{shade: textBlack}
+}
+
+// compareCategories orders categories as their entries are listed: by lane,
+// then by rank within it
+func compareCategories(a, b PointCategory) int {
+	sa, sb := categoryStyles[a], categoryStyles[b]
+	return cmp.Or(cmp.Compare(sa.lane, sb.lane), cmp.Compare(sa.rank, sb.rank))
+}
+
+// splitLanes appends the entries of a house to the lanes they are listed
+// in, each lane in the order of its categories, entries of one rank keeping
+// their order
+func splitLanes(entries, main, side []planetEntry) ([]planetEntry, []planetEntry) {
+	slices.SortStableFunc(entries, func(a, b planetEntry) int {
+		return compareCategories(a.category, b.category)
+	})
+	for _, e := range entries {
+		if categoryStyles[e.category].lane == laneSide {
+			side = append(side, e)
+		} else {
+			main = append(main, e)
+		}
+	}
+	return main, side
+}
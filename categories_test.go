@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlanetCategory(t *testing.T) {
+	tests := []struct {
+		name   string
+		planet *Planet
+		want   PointCategory
+	}{
+		{"sun", &Planet{Rashi: "aries"}, PointGraha},
+		{"Saturn", nil, PointGraha},
+		{"rahu", &Planet{Rashi: "aries"}, PointNode},
+		{"gulika", &Planet{Rashi: "aries"}, PointUpagraha},
+		{"dhuma", &Planet{Rashi: "aries", IsUpagraha: true}, PointUpagraha},
+		{"hora_lagna", &Planet{Rashi: "aries", IsSpecialLagna: true}, PointSpecialLagna},
+		{"al", &Planet{Rashi: "aries", Category: PointArudhaPada}, PointArudhaPada},
+		{"pranapada", &Planet{Rashi: "aries"}, PointCustom},
+		// Category wins over the flags
+		{"moon", &Planet{Rashi: "aries", IsSpecialLagna: true, Category: PointCustom}, PointCustom},
+	}
+	for _, tt := range tests {
+		if got := PlanetCategory(tt.name, tt.planet); got != tt.want {
+			t.Errorf("PlanetCategory(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLanes(t *testing.T) {
+	entries := []planetEntry{
+		{name: "gulika", category: PointUpagraha},
+		{name: "al", category: PointArudhaPada},
+		{name: "jupiter", category: PointGraha},
+		{name: "hora_lagna", category: PointSpecialLagna},
+		{name: "ketu", category: PointNode},
+		{name: "lagna", category: PointLagna},
+		{name: "sun", category: PointGraha},
+	}
+	main, side := splitLanes(entries, nil, nil)
+	names := func(entries []planetEntry) []string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.name)
+		}
+		return names
+	}
+	// Grahas and nodes share a rank, so they keep their order
+	if got, want := names(main), []string{"lagna", "jupiter", "ketu", "sun", "gulika"}; !slices.Equal(got, want) {
+		t.Errorf("main lane = %q, want %q", got, want)
+	}
+	if got, want := names(side), []string{"hora_lagna", "al"}; !slices.Equal(got, want) {
+		t.Errorf("side lane = %q, want %q", got, want)
+	}
+}
+
+// categoriesInput crowds grahas, upagrahas, a special lagna and arudha padas
+// into the lagna's house
+func categoriesInput(chartType ChartType) ChartInput {
+	return ChartInput{
+		ChartType: chartType,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":        {Rashi: "leo"},
+			"mercury":    {Rashi: "leo", IsRetrograde: true},
+			"ketu":       {Rashi: "leo"},
+			"mandi":      {Rashi: "leo", IsUpagraha: true},
+			"gulika":     {Rashi: "leo", IsUpagraha: true},
+			"hora_lagna": {Rashi: "leo", IsSpecialLagna: true, Display: "HL"},
+			"al":         {Rashi: "leo", Category: PointArudhaPada, Display: "AL"},
+			"ul":         {Rashi: "leo", Category: PointArudhaPada, Display: "UL"},
+			"moon":       {Rashi: "taurus"},
+		},
+	}
+}
+
+func TestGenerateChart_Categories(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := categoriesInput(chartType)
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_categories", data)
+
+		for category, planet := range map[PointCategory]string{PointUpagraha: "mandi", PointArudhaPada: "al", PointSpecialLagna: "hora_lagna"} {
+			if colored := labelColors(t, input, category.color()); !colored[planet] {
+				t.Errorf("%s: %s is not in the %s color", chartType, planet, category)
+			}
+		}
+	}
+
+	input := categoriesInput(ChartTypeSouth)
+	input.Planets["al"].Category = "moon"
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for an unsupported category")
+	}
+	input.Planets["al"].Category = PointLagna
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for a planet in the lagna category")
+	}
+}
+
+func TestPrintStyles(t *testing.T) {
+	// Every category drawn in a color of its own keeps a print style of its
+	// own, so grayscale loses no distinction
+	seen := map[printStyle]PointCategory{}
+	for _, c := range pointCategories {
+		style := c.printStyle()
+		if c.color() == textBlack {
+			if !style.plain() {
+				t.Errorf("%s is black but has the print style %+v", c, style)
+			}
+			continue
+		}
+		if style.plain() {
+			t.Errorf("%s has no print style", c)
+		}
+		if other, ok := seen[style]; ok {
+			t.Errorf("%s and %s share the print style %+v", c, other, style)
+		}
+		seen[style] = c
+	}
+}
+
+func TestGenerateChart_Grayscale(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := categoriesInput(chartType)
+		input.Options = ChartOptions{Grayscale: true, ShowColorKey: true}
+		data, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_grayscale", data)
+
+		labels := map[string]string{}
+		var key []string
+		for _, l := range layout.Labels {
+			switch l.Kind {
+			case string(labelPlanet):
+				labels[l.Planet] = l.Text
+			case string(labelColorKey):
+				key = append(key, l.Text)
+			}
+		}
+		for planet, want := range map[string]string{"lagna": "Asc", "sun": "Su", "mandi": "Mn", "hora_lagna": "[HL]", "al": "(AL)"} {
+			if labels[planet] != want {
+				t.Errorf("%s: %s labelled %q, want %q", chartType, planet, labels[planet], want)
+			}
+		}
+		if want := []string{"Lagna", "Upagraha", "[Special lagna]", "(Arudha pada)"}; !slices.Equal(key, want) {
+			t.Errorf("%s: key names %q, want %q", chartType, key, want)
+		}
+		if colored := labelColors(t, input, printGray); !colored["mandi"] || colored["sun"] {
+			t.Errorf("%s: labels in the upagraha gray %v, want mandi alone", chartType, colored)
+		}
+
+		// Nothing is left in color
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bounds := img.Bounds()
+	pixels:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if r, g, b, _ := img.At(x, y).RGBA(); r != g || g != b {
+					t.Errorf("%s: pixel at (%d, %d) is colored", chartType, x, y)
+					break pixels
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateChart_NodeStyle(t *testing.T) {
+	input := categoriesInput(ChartTypeSouth)
+	input.Planets["rahu"] = &Planet{Rashi: "gemini"}
+	input.Planets["ketu"] = &Planet{Rashi: "sagittarius"}
+	input.Options.NodeStyle = NodeStyleShaded
+	colored := labelColors(t, input, nodeSlate)
+	for name, shaded := range colored {
+		if want := name == "rahu" || name == "ketu"; shaded != want {
+			t.Errorf("%s: drawn in the node shade %v, want %v", name, shaded, want)
+		}
+	}
+	if key := newColorKey(ChartInput{Planets: input.Planets, Options: ChartOptions{ShowColorKey: true, NodeStyle: NodeStyleShaded}}); key == nil || !slices.Contains(key.categories, PointNode) {
+		t.Errorf("Color key %+v, want the shaded nodes in it", key)
+	}
+
+	// High contrast keeps them black, and italics keep their color
+	input.Options.HighContrast = true
+	for name, shaded := range labelColors(t, input, nodeSlate) {
+		if shaded {
+			t.Errorf("%s: drawn in the node shade in high contrast", name)
+		}
+	}
+	input.Options.HighContrast = false
+	input.Options.NodeStyle = NodeStyleItalic
+	for name, shaded := range labelColors(t, input, nodeSlate) {
+		if shaded {
+			t.Errorf("%s: italic node drawn in the node shade", name)
+		}
+	}
+
+	input.Options.NodeStyle = "bold"
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "unsupported node_style: bold") {
+		t.Errorf("GenerateChart(node_style bold) error = %v", err)
+	}
+}
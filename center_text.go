@@ -0,0 +1,222 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+)
+
+// centerTextSize is the font size of the South chart's center text, which
+// shrinks in whole points down to minCenterTextSize when the text is too
+// long. Both are given for a chart of defaultChartSize.
+const (
+	centerTextSize    = 18.0
+	minCenterTextSize = 10.0
+)
+
+// centerTextWidth is the width of the South chart's center text at
+// defaultChartSize: the two center cells less a margin on either side
+const centerTextWidth = (defaultChartSize-2*40)/2 - 2*10
+
+// CenterTextFromDasha composes the conventional center text of a running
+// dasha: "Dasa: Venus", "Bhukti: Saturn", "Antara: Mercury" for the maha,
+// antara and pratyantara lords, then "Balance: 3y 2m 10d" for the balance
+// of dasha at birth, one per line. Empty parts are left out. Lines too wide
+// for the chart's center at the full center text size are cut short with
+// "…", so the block is never wrapped or shrunk.
+func CenterTextFromDasha(maha, antara, pratyantara, balance string) string {
+	dc := gg.NewContext(1, 1)
+	loadMatangiRegular(dc, centerTextSize)
+	var lines []string
+	for _, part := range []struct{ label, value string }{
+		{"Dasa", maha},
+		{"Bhukti", antara},
+		{"Antara", pratyantara},
+		{"Balance", balance},
+	} {
+		if value := strings.TrimSpace(part.value); value != "" {
+			lines = append(lines, ellipsize(dc, part.label+": "+value, centerTextWidth))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CenterLine is a line of the South chart's center text with a style of its
+// own, such as a bold name over a smaller date and place of birth
+type CenterLine struct {
+	Text string `json:"text"`
+	Bold bool   `json:"bold,omitempty"`
+	// Size is the font size for a chart of the default 800px, 18 when zero.
+	// Lines too long for the center wrap, and the block shrinks as a whole,
+	// keeping the sizes in proportion.
+	Size  float64 `json:"size,omitempty"`
+	Color string  `json:"color,omitempty"` // Text color as "#RRGGBB" or "#RRGGBBAA", black when empty
+}
+
+// maxCenterLineSize is the largest size of a CenterLine, twice that of the
+// plain center text
+const maxCenterLineSize = 2 * centerTextSize
+
+// maxCenterTextRunes bounds the length of the center text, or of its
+// CenterLines together, well beyond what the chart's center can hold at
+// minCenterTextSize
+const maxCenterTextRunes = 2000
+
+// centerLines returns the center text of input, its CenterLines or else the
+// lines of its CenterText in the plain style, and the name of the field the
+// text came from
+func centerLines(input ChartInput) ([]CenterLine, string) {
+	if len(input.CenterLines) > 0 {
+		return input.CenterLines, "center_lines"
+	}
+	if input.CenterText == "" {
+		return nil, "center_text"
+	}
+	var lines []CenterLine
+	for _, line := range strings.Split(input.CenterText, "\n") {
+		lines = append(lines, CenterLine{Text: line})
+	}
+	return lines, "center_text"
+}
+
+// validateCenterLines checks the sizes and colors of the center lines, and
+// that they do not come with a CenterText as well
+func validateCenterLines(input ChartInput) error {
+	if len(input.CenterLines) > 0 && input.CenterText != "" {
+		return errors.New("center_text and center_lines cannot both be set")
+	}
+	lines, field := centerLines(input)
+	runes := 0
+	for _, line := range lines {
+		runes += utf8.RuneCountInString(line.Text)
+	}
+	if runes > maxCenterTextRunes {
+		return fmt.Errorf("%s: %d characters, at most %d fit the chart center", field, runes, maxCenterTextRunes)
+	}
+	for i, line := range input.CenterLines {
+		if line.Size < 0 || line.Size > maxCenterLineSize {
+			return fmt.Errorf("center_lines[%d]: size %v out of range 0-%v", i, line.Size, maxCenterLineSize)
+		}
+		if line.Color != "" {
+			if _, err := parseHexColor(line.Color); err != nil {
+				return fmt.Errorf("center_lines[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// centerTextRow is a line of the center text as wrapped to its area, with
+// the font and color it is drawn in
+type centerTextRow struct {
+	text  string
+	face  *embeddedFont
+	size  float64
+	color color.Color
+}
+
+// metrics returns the vertical metrics of the row's font
+func (r centerTextRow) metrics() textMetrics {
+	return faceMetrics(r.face, r.size)
+}
+
+// centerTextLayout is the center text wrapped to its area, and the size the
+// plain center text is drawn at, which the sized lines are scaled with
+type centerTextLayout struct {
+	rows []centerTextRow
+	size float64
+}
+
+// height is the height of the rows stacked one under another, each as tall
+// as its own font's line
+func (l centerTextLayout) height() float64 {
+	var h float64
+	for _, row := range l.rows {
+		h += row.metrics().lineHeight()
+	}
+	return h
+}
+
+// layoutCenterText wraps lines to the width of the area, each in its own
+// font, and picks the largest size at which the wrapped rows also fit its
+// height, for a chart scale times defaultChartSize. Lines with a size of
+// their own shrink in proportion to the plain ones.
+func layoutCenterText(dc *gg.Context, lines []CenterLine, width, height, scale float64) (centerTextLayout, error) {
+	for step := centerTextSize; step >= minCenterTextSize; step-- {
+		layout := centerTextLayout{size: step * scale}
+		for _, line := range lines {
+			row := centerTextRow{face: matangiRegular, size: layout.size, color: textBlack}
+			if line.Bold {
+				row.face = matangiBold
+			}
+			if line.Size > 0 {
+				row.size = line.Size * step / centerTextSize * scale
+			}
+			if c, err := parseHexColor(line.Color); line.Color != "" && err == nil {
+				row.color = c
+			}
+			dc.SetFontFace(embeddedFace(row.face, row.size))
+			for _, text := range wrapLine(dc, line.Text, width) {
+				row.text = text
+				layout.rows = append(layout.rows, row)
+			}
+		}
+		if layout.height() <= height {
+			return layout, nil
+		}
+	}
+	return centerTextLayout{}, fmt.Errorf("does not fit the chart center at %vpx", minCenterTextSize*scale)
+}
+
+// textWrapper measures and word-wraps text in a font, as a gg.Context does
+// in its current one
+type textWrapper interface {
+	stringMeasurer
+	WordWrap(s string, width float64) []string
+}
+
+// wrapLine breaks a line at spaces into lines no wider than width, measured
+// in the current font. Words wider than width on their own are broken
+// between characters, down to one character per line. An empty line stays a single empty line.
+func wrapLine(dc textWrapper, line string, width float64) []string {
+	var lines []string
+	for _, l := range dc.WordWrap(line, width) {
+		runes := []rune(strings.TrimSpace(l))
+		for len(runes) > 1 {
+			n := longestFitting(len(runes), func(n int) bool {
+				w, _ := dc.MeasureString(string(runes[:n]))
+				return w <= width
+			})
+			if n == len(runes) {
+				break
+			}
+			n = max(n, 1)
+			lines = append(lines, string(runes[:n]))
+			runes = runes[n:]
+		}
+		lines = append(lines, string(runes))
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	return lines
+}
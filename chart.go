@@ -17,11 +17,11 @@ package parashari
 
 import (
 	"bytes"
-	"encoding/base64"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"image"
-	"image/png"
+	"io"
+	"sort"
 	"strings"
 )
 
@@ -33,16 +33,75 @@ const (
 	ChartTypeSouth ChartType = "south"
 	ChartTypeEast  ChartType = "east"
 	ChartTypeWest  ChartType = "west"
+	// ChartTypeSarvashtakavarga draws only the HouseScores of all 12 rashis,
+	// large on the South grid
+	ChartTypeSarvashtakavarga ChartType = "sarvashtakavarga"
+	// ChartTypeSuryaKalanala draws the Surya Kalanala Chakra: the 28
+	// nakshatras on spokes counted from the Sun's, with the planets on them
+	ChartTypeSuryaKalanala ChartType = "surya_kalanala"
 )
 
 // Planet represents a planet in the chart
 type Planet struct {
-	Rashi          string `json:"rashi"`
-	IsRetrograde   bool   `json:"is_retrograde"`
-	IsCombust      bool   `json:"is_combust"`
-	IsUpagraha     bool   `json:"upagraha,omitempty"`
-	Display        string `json:"display,omitempty"` // Custom display name
-	IsSpecialLagna bool   `json:"is_special_lagna,omitempty"`
+	Rashi          string  `json:"rashi"`
+	IsRetrograde   bool    `json:"is_retrograde"`
+	IsCombust      bool    `json:"is_combust"`
+	IsUpagraha     bool    `json:"upagraha,omitempty"`
+	Display        string  `json:"display,omitempty"`          // Custom display name
+	IsSpecialLagna bool    `json:"is_special_lagna,omitempty"` // Special lagna, drawn in its own column (see IsSpecialLagna)
+	IsExalted      bool    `json:"is_exalted,omitempty"`
+	IsDebilitated  bool    `json:"is_debilitated,omitempty"`
+	NavamsaRashi   string  `json:"navamsa_rashi,omitempty"`     // Rashi in the D9 chart, used to flag vargottama
+	Degrees        float64 `json:"degrees,omitempty"`           // Degrees within the rashi, under 30; 0 when not known
+	Nakshatra      string  `json:"nakshatra,omitempty"`         // Nakshatra name, e.g. "Rohini"
+	Pada           int     `json:"pada,omitempty"`              // Nakshatra pada 1-4, 0 when unknown
+	SpeedDegPerDay float64 `json:"speed_deg_per_day,omitempty"` // Daily motion, negative when retrograde, 0 when unknown
+	StarLord       string  `json:"star_lord,omitempty"`         // KP star (nakshatra) lord, e.g. "saturn"
+	SubLord        string  `json:"sub_lord,omitempty"`          // KP sub lord, e.g. "mercury"
+
+	// Category overrides the category PlanetCategory derives from the
+	// other fields, such as "arudha_pada" for an arudha pada
+	Category PointCategory `json:"category,omitempty"`
+
+	// RetrogradeSet marks IsRetrograde as given rather than left unset, so
+	// a planet set not retrograde stays direct whatever its speed (see
+	// NormalizeChartInput). Decoding JSON sets it when is_retrograde is
+	// present.
+	RetrogradeSet bool `json:"-"`
+}
+
+// UnmarshalJSON decodes a planet, setting RetrogradeSet when is_retrograde
+// is present. Unknown fields are an error, as they are in Schema, since a
+// decoder's DisallowUnknownFields does not reach into UnmarshalJSON.
+func (p *Planet) UnmarshalJSON(data []byte) error {
+	type plain Planet
+	fields := struct {
+		*plain
+		IsRetrograde *bool `json:"is_retrograde"`
+	}{plain: (*plain)(p)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fields); err != nil {
+		return err
+	}
+	if fields.IsRetrograde != nil {
+		p.IsRetrograde, p.RetrogradeSet = *fields.IsRetrograde, true
+	}
+	return nil
+}
+
+// MarshalJSON encodes a planet, leaving is_retrograde out when it is false
+// and not set, so the planet decodes to one still marked from its speed
+func (p Planet) MarshalJSON() ([]byte, error) {
+	type plain Planet
+	fields := struct {
+		plain
+		IsRetrograde *bool `json:"is_retrograde,omitempty"`
+	}{plain: plain(p)}
+	if p.IsRetrograde || p.RetrogradeSet {
+		fields.IsRetrograde = &p.IsRetrograde
+	}
+	return json.Marshal(fields)
 }
 
 // ChartInput contains all the data needed to generate a chart
@@ -51,78 +110,179 @@ type ChartInput struct {
 	Planets    map[string]*Planet `json:"planets"`
 	Lagna      *Planet            `json:"lagna,omitempty"`
 	CenterText string             `json:"center_text,omitempty"` // Text to display in center of chart
+	Options    ChartOptions       `json:"options,omitempty"`     // Optional rendering settings
+	// CenterLines replace CenterText with lines styled one by one, such as
+	// a bold name over a smaller date and place of birth
+	CenterLines []CenterLine `json:"center_lines,omitempty"`
+	// HouseScores are ashtakavarga bindus (0-56) keyed by rashi number,
+	// such as the sarvashtakavarga, printed in each house with
+	// Options.ShowHouseScores. SecondaryHouseScores, such as one planet's
+	// bhinnashtakavarga, go on a second row beneath them.
+	HouseScores          map[int]int `json:"house_scores,omitempty"`
+	SecondaryHouseScores map[int]int `json:"secondary_house_scores,omitempty"`
+	// Strengths are shadbala in rupas keyed by planet name, drawn as bars
+	// beneath the chart against each planet's RequiredShadbala. Planets
+	// without an entry get no bar.
+	Strengths map[string]float64 `json:"strengths,omitempty"`
+	// Panchanga is the panchanga at birth, printed with
+	// Options.ShowPanchanga
+	Panchanga *Panchanga `json:"panchanga,omitempty"`
+	// Native is the native's name and birth details, printed with
+	// Options.ShowNativeInfo
+	Native *NativeInfo `json:"native,omitempty"`
 }
 
-// RashiToNumber converts rashi name to number (1-12)
+// RashiToNumber converts rashi name to number (1-12), accepting the names
+// ParseRashi does, or returns 0 for anything else
 func RashiToNumber(rashi string) int {
-	rashiMap := map[string]int{
-		"aries":       1,
-		"taurus":      2,
-		"gemini":      3,
-		"cancer":      4,
-		"leo":         5,
-		"virgo":       6,
-		"libra":       7,
-		"scorpio":     8,
-		"sagittarius": 9,
-		"capricorn":   10,
-		"aquarius":    11,
-		"pisces":      12,
-	}
-	r := strings.ToLower(rashi)
-	if num, ok := rashiMap[r]; ok {
-		return num
-	}
-	return 0
+	num, _ := ParseRashi(rashi)
+	return num
+}
+
+// rashiNumbers maps lowercase rashi names to their numbers
+var rashiNumbers = map[string]int{
+	"aries":       1,
+	"taurus":      2,
+	"gemini":      3,
+	"cancer":      4,
+	"leo":         5,
+	"virgo":       6,
+	"libra":       7,
+	"scorpio":     8,
+	"sagittarius": 9,
+	"capricorn":   10,
+	"aquarius":    11,
+	"pisces":      12,
 }
 
 // NumberToRashi converts rashi number to name
 func NumberToRashi(num int) string {
-	rashiMap := map[int]string{
-		1:  "aries",
-		2:  "taurus",
-		3:  "gemini",
-		4:  "cancer",
-		5:  "leo",
-		6:  "virgo",
-		7:  "libra",
-		8:  "scorpio",
-		9:  "sagittarius",
-		10: "capricorn",
-		11: "aquarius",
-		12: "pisces",
-	}
-	return rashiMap[num]
-}
-
-// GetPlanetAbbreviation returns the abbreviation for a planet or upagraha
+	return rashiNames[num]
+}
+
+// rashiNames maps rashi numbers to their lowercase names
+var rashiNames = map[int]string{
+	1:  "aries",
+	2:  "taurus",
+	3:  "gemini",
+	4:  "cancer",
+	5:  "leo",
+	6:  "virgo",
+	7:  "libra",
+	8:  "scorpio",
+	9:  "sagittarius",
+	10: "capricorn",
+	11: "aquarius",
+	12: "pisces",
+}
+
+// HouseFromLagna returns the house (bhava) number 1-12 that a rashi occupies,
+// counted from the lagna rashi (which is house 1)
+func HouseFromLagna(rashiNum, lagnaRashi int) int {
+	return (rashiNum-lagnaRashi+12)%12 + 1
+}
+
+// NumberToSanskritRashi converts rashi number to its Sanskrit name
+func NumberToSanskritRashi(num int) string {
+	return sanskritRashiNames[num]
+}
+
+// sanskritRashiNames maps rashi numbers to their Sanskrit names
+var sanskritRashiNames = map[int]string{
+	1:  "Mesha",
+	2:  "Vrishabha",
+	3:  "Mithuna",
+	4:  "Karka",
+	5:  "Simha",
+	6:  "Kanya",
+	7:  "Tula",
+	8:  "Vrishchika",
+	9:  "Dhanu",
+	10: "Makara",
+	11: "Kumbha",
+	12: "Meena",
+}
+
+// GetPlanetAbbreviation returns the abbreviation for a planet or upagraha in
+// the default locale (see SetDefaultLocale)
 func GetPlanetAbbreviation(planetName string) string {
-	abbrevMap := map[string]string{
-		// Planets
-		"sun":     "Su",
-		"moon":    "Mo",
-		"mars":    "Ma",
-		"mercury": "Me",
-		"jupiter": "Ju",
-		"venus":   "Ve",
-		"saturn":  "Sa",
-		"rahu":    "Ra",
-		"ketu":    "Ke",
-		"lagna":   "Asc",
-		// Upagrahas
-		"upaketu":      "Up",
-		"mandi":        "Mn",
-		"gulika":       "Gu",
-		"yamaghantaka": "Ya",
-		"ardhaprahara": "Ar",
-		"kala":         "Ka",
-		"dhuma":        "Dh",
-		"vyatipata":    "Vy",
-		"parivesha":    "Pa",
-		"indrachapa":   "In",
-		"upagraha":     "Up", // Generic fallback
-	}
-	return abbrevMap[strings.ToLower(planetName)]
+	return GetPlanetAbbreviationIn(planetName, "")
+}
+
+// planetAbbreviations maps lowercase planet and upagraha names to their
+// English abbreviations. Its names are the planets the library knows.
+var planetAbbreviations = map[string]string{
+	// Planets
+	"sun":     "Su",
+	"moon":    "Mo",
+	"mars":    "Ma",
+	"mercury": "Me",
+	"jupiter": "Ju",
+	"venus":   "Ve",
+	"saturn":  "Sa",
+	"rahu":    "Ra",
+	"ketu":    "Ke",
+	"lagna":   "Asc",
+	// Upagrahas
+	"upaketu":      "Up",
+	"mandi":        "Mn",
+	"gulika":       "Gu",
+	"yamaghantaka": "Ya",
+	"ardhaprahara": "Ar",
+	"kala":         "Ka",
+	"dhuma":        "Dh",
+	"vyatipata":    "Vy",
+	"parivesha":    "Pa",
+	"indrachapa":   "In",
+	"upagraha":     "Up", // Generic fallback
+}
+
+// planetFullNames are the English full names of the planets and upagrahas
+var planetFullNames = map[string]string{
+	"sun":     "Sun",
+	"moon":    "Moon",
+	"mars":    "Mars",
+	"mercury": "Mercury",
+	"jupiter": "Jupiter",
+	"venus":   "Venus",
+	"saturn":  "Saturn",
+	"rahu":    "Rahu",
+	"ketu":    "Ketu",
+	"lagna":   "Ascendant",
+
+	"upaketu":      "Upaketu",
+	"mandi":        "Mandi",
+	"gulika":       "Gulika",
+	"yamaghantaka": "Yamaghantaka",
+	"ardhaprahara": "Ardhaprahara",
+	"kala":         "Kala",
+	"dhuma":        "Dhuma",
+	"vyatipata":    "Vyatipata",
+	"parivesha":    "Parivesha",
+	"indrachapa":   "Indrachapa",
+}
+
+// planetLetters are the single letters the letter display mode names the
+// grahas by, the same in every locale. Where English initials clash the
+// Sanskrit names decide: the Moon keeps M, so Mars is A (Angaraka) and
+// Mercury B (Budha), and the Sun keeps S, so Saturn is N (Shani).
+var planetLetters = map[string]string{
+	"sun":     "S",
+	"moon":    "M",
+	"mars":    "A",
+	"mercury": "B",
+	"jupiter": "J",
+	"venus":   "V",
+	"saturn":  "N",
+	"rahu":    "R",
+	"ketu":    "K",
+}
+
+// PlanetLetter returns the single letter the letter display mode names a
+// graha by, or an empty string for other names, which keep their
+// abbreviations in that mode
+func PlanetLetter(planetName string) string {
+	return planetLetters[strings.ToLower(planetName)]
 }
 
 // GetPlanetDisplayName returns the display name for a planet
@@ -134,8 +294,20 @@ func GetPlanetDisplayName(planetName string, planet *Planet) string {
 	return GetPlanetAbbreviation(planetName)
 }
 
+// IsSpecialLagna reports whether the planet of input named planetName is a
+// special lagna (such as the hora or ghati lagna), flagged IsSpecialLagna.
+// Charts draw special lagnas in their own column, in yellow, whatever their
+// labels read.
+func IsSpecialLagna(planetName string, input ChartInput) bool {
+	p := input.Planets[planetName]
+	return p != nil && p.IsSpecialLagna
+}
+
 // IsSpecialLagnaAbbrev checks if an abbreviation corresponds to a special lagna
 // by looking through the input.Planets map
+//
+// Deprecated: labels do not identify planets: a planet whose Display matches
+// a special lagna's is taken for one. Use IsSpecialLagna with the planet's name.
 func IsSpecialLagnaAbbrev(abbrev string, input ChartInput) bool {
 	// Remove retrograde and combust suffixes for matching
 	abbrevClean := strings.TrimSuffix(abbrev, "R")
@@ -154,37 +326,150 @@ func IsSpecialLagnaAbbrev(abbrev string, input ChartInput) bool {
 	return false
 }
 
-// GenerateChart generates a chart image and returns it as a base64-encoded PNG string
-func GenerateChart(input ChartInput) (string, error) {
-	if input.ChartType == "" {
-		return "", errors.New("chart_type is required")
+// sortedPlanetNames returns the keys of a planet map in alphabetical order, so
+// labels sharing a house are laid out the same way every time
+func sortedPlanetNames(planets map[string]*Planet) []string {
+	names := make([]string, 0, len(planets))
+	for name := range planets {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	var img []byte
-	var err error
+// validatePlanets checks that the lagna and every planet hold in-range values
+func validatePlanets(input ChartInput) error {
+	if input.Lagna != nil {
+		if RashiToNumber(input.Lagna.Rashi) == 0 {
+			return &ErrInvalidRashi{Planet: "lagna", Rashi: input.Lagna.Rashi}
+		}
+		if err := validatePlanet(input.Lagna); err != nil {
+			return fmt.Errorf("lagna: %w", err)
+		}
+	}
+	for _, name := range sortedPlanetNames(input.Planets) {
+		if p := input.Planets[name]; p != nil {
+			if RashiToNumber(p.Rashi) == 0 {
+				return &ErrInvalidRashi{Planet: name, Rashi: p.Rashi}
+			}
+			if err := validatePlanet(p); err != nil {
+				return fmt.Errorf("planet %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
 
-	switch input.ChartType {
-	case ChartTypeSouth:
-		img, err = GenerateSouthChart(input)
-	case ChartTypeNorth:
-		img, err = GenerateNorthChart(input)
-	default:
-		return "", fmt.Errorf("unsupported chart type: %s", input.ChartType)
+// validatePlanet checks a single planet's degrees, nakshatra pada, category
+// and KP lords
+func validatePlanet(p *Planet) error {
+	if !validDegrees(p.Degrees) {
+		return fmt.Errorf("degrees %v out of range [0, 30)", p.Degrees)
+	}
+	if p.Pada < 0 || p.Pada > 4 {
+		return fmt.Errorf("pada %d out of range 1-4", p.Pada)
 	}
+	if p.Category != "" && !validCategory(p.Category) {
+		return fmt.Errorf("unsupported category: %s", p.Category)
+	}
+	if err := validateKPLord("star_lord", p.StarLord); err != nil {
+		return err
+	}
+	return validateKPLord("sub_lord", p.SubLord)
+}
 
+// GenerateChart generates a chart image and returns it as a base64-encoded PNG
+// string. It draws the chart as GenerateChartResult does, keeping only the
+// image.
+//
+// Output is deterministic: planets are placed in a fixed order, whatever the
+// order of the Planets map, so identical inputs yield identical PNG bytes
+// from every generator, call after call.
+func GenerateChart(input ChartInput) (string, error) {
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	return r.generateBase64(input)
+}
+
+// GenerateBlankChart draws an empty chart of chartType, its frame and rashi
+// numbers without a lagna or planets, as a worksheet to fill in by hand. It
+// is the chart GenerateChart draws for ChartInput{ChartType: chartType}.
+// Sarvashtakavarga charts are nothing without their scores and have none.
+func GenerateBlankChart(chartType ChartType) ([]byte, error) {
+	if chartType == ChartTypeSarvashtakavarga {
+		return nil, fmt.Errorf("%w: %s has no blank chart", ErrUnsupportedChartType, chartType)
+	}
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	img, err := r.draw(ChartInput{ChartType: chartType}, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate chart: %w", err)
+		return nil, err
 	}
+	return r.encode(img)
+}
+
+// WriteChart generates a chart image and writes it to w as PNG, without
+// holding the encoded image in memory
+func WriteChart(w io.Writer, input ChartInput) error {
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	return r.write(w, input)
+}
 
-	// Encode to base64
-	base64Str := base64.StdEncoding.EncodeToString(img)
-	return base64Str, nil
+// GenerateChartPNGs generates a chart for each input in turn and returns
+// their PNG bytes in the same order. The charts share one canvas and one
+// encoding buffer, so a batch allocates far less than as many GenerateChart
+// calls. It stops at the first input that fails, naming its index.
+func GenerateChartPNGs(inputs []ChartInput) ([][]byte, error) {
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	pngs := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		img, err := r.generate(input)
+		if err != nil {
+			return nil, fmt.Errorf("chart %d: %w", i, err)
+		}
+		pngs[i] = img
+	}
+	return pngs, nil
+}
+
+// validateInput checks the chart type, options and planets of an input
+func validateInput(input ChartInput) error {
+	if input.ChartType == "" {
+		return ErrMissingChartType
+	}
+	if err := input.Options.validate(); err != nil {
+		return err
+	}
+	if err := validatePlanets(input); err != nil {
+		return err
+	}
+	if err := validateBadhaka(input); err != nil {
+		return err
+	}
+	if err := validateCenterLines(input); err != nil {
+		return err
+	}
+	if err := validateNative(input); err != nil {
+		return err
+	}
+	if err := validateDistinctLabels(input); err != nil {
+		return err
+	}
+	if err := validateHouseScores(input); err != nil {
+		return err
+	}
+	if err := validateStrengths(input); err != nil {
+		return err
+	}
+	return validateAspectLines(input)
 }
 
 // Helper function to encode image to PNG bytes
 func encodePNG(img image.Image) ([]byte, error) {
 	var buf bytes.Buffer
-	err := png.Encode(&buf, img)
+	err := pngEncoder.Encode(&buf, img)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+// ChartLayout describes where a chart put its houses and text, so that a
+// frontend can map points on the image back to the chart, for example to
+// show a tooltip for the planet under the pointer. Coordinates are pixels
+// from the top-left corner of the image.
+type ChartLayout struct {
+	Width  int           `json:"width"`
+	Height int           `json:"height"`
+	Houses []HouseLayout `json:"houses"`
+	Labels []LabelLayout `json:"labels"`
+}
+
+// HouseLayout is the region of the chart a house is drawn in
+type HouseLayout struct {
+	House   int          `json:"house"` // Counted from the lagna, which is house 1
+	Rashi   int          `json:"rashi"`
+	Polygon [][2]float64 `json:"polygon"` // Corners in drawing order
+}
+
+// LabelLayout is a piece of text drawn on the chart and its bounding box
+type LabelLayout struct {
+	// Kind is one of "rashi", "house_number", "lagna_marker", "planet",
+	// "house_score", "center_text", "strength" for the planets and values
+	// of the strength bars beneath the chart, "dasha" for the cells of the
+	// dasha table beside it, "nakshatra" for the nakshatras of the ring
+	// around the North chart, "house_lord" for the lords of the houses' rashis,
+	// "panchanga" for the labels and values of the panchanga, "native" for
+	// those of the native's details, "version_stamp"
+	// for the version stamp beneath the chart, "color_key" for the category
+	// names of the color key or, in thumbnails,
+	// "hidden_count" for the "+N" of planets a house has no room for
+	Kind   string  `json:"kind"`
+	Text   string  `json:"text"`
+	Planet string  `json:"planet,omitempty"` // Planet name of planet labels, "lagna" for the lagna
+	House  int     `json:"house,omitempty"`  // Zero for text outside the houses
+	Rashi  int     `json:"rashi,omitempty"`  // Zero for text outside the houses
+	Left   float64 `json:"left"`
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+}
+
+// GenerateChartWithLayout generates a chart like GenerateChart and returns
+// its PNG bytes together with the layout of what it drew
+func GenerateChartWithLayout(input ChartInput) ([]byte, *ChartLayout, error) {
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	var boxes chartBoxes
+	img, err := r.draw(input, &boxes)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := r.encode(img)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := img.Bounds()
+	return data, boxes.layout(input, bounds.Dx(), bounds.Dy()), nil
+}
+
+// layout converts the recorded boxes of a chart of input into a ChartLayout
+func (c *chartBoxes) layout(input ChartInput, w, h int) *ChartLayout {
+	lagnaRashi := chartLagnaRashi(input)
+	l := &ChartLayout{Width: w, Height: h, Houses: []HouseLayout{}, Labels: []LabelLayout{}}
+	regionRashi := make(map[int]int, len(c.houses))
+	for _, house := range c.houses {
+		regionRashi[house.region] = house.rashi
+		polygon := make([][2]float64, len(house.polygon))
+		for i, p := range house.polygon {
+			polygon[i] = [2]float64{p.X, p.Y}
+		}
+		l.Houses = append(l.Houses, HouseLayout{
+			House:   HouseFromLagna(house.rashi, lagnaRashi),
+			Rashi:   house.rashi,
+			Polygon: polygon,
+		})
+	}
+	for _, b := range c.boxes {
+		label := LabelLayout{Kind: string(b.kind), Text: b.text, Planet: b.planet,
+			Left: b.left, Top: b.top, Right: b.right, Bottom: b.bottom}
+		if rashi, ok := regionRashi[b.house]; ok {
+			label.House, label.Rashi = HouseFromLagna(rashi, lagnaRashi), rashi
+		}
+		l.Labels = append(l.Labels, label)
+	}
+	return l
+}
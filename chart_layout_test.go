@@ -0,0 +1,153 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"testing"
+)
+
+func TestGenerateChartWithLayout(t *testing.T) {
+	for _, thumbnail := range []bool{false, true} {
+		for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+			input := thumbnailInput(chartType)
+			input.Options.Thumbnail = thumbnail
+			data, layout, err := GenerateChartWithLayout(input)
+			if err != nil {
+				t.Fatalf("%s (thumbnail %v): %v", chartType, thumbnail, err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); layout.Width != b.Dx() || layout.Height != b.Dy() {
+				t.Errorf("%s (thumbnail %v): layout is %dx%d, image %dx%d", chartType, thumbnail, layout.Width, layout.Height, b.Dx(), b.Dy())
+			}
+			assertLayout(t, input, layout)
+		}
+	}
+}
+
+// assertLayout checks that every planet and the lagna are labeled exactly
+// once, in the house of their rashi, and that every house and label lies
+// on the canvas
+func assertLayout(t *testing.T, input ChartInput, layout *ChartLayout) {
+	t.Helper()
+	name := string(input.ChartType)
+	if input.Options.Thumbnail {
+		name += " thumbnail"
+	}
+
+	want := map[string]int{"lagna": RashiToNumber(input.Lagna.Rashi)}
+	for planetName, p := range input.Planets {
+		want[planetName] = RashiToNumber(p.Rashi)
+	}
+	seen := map[string]int{}
+	for _, l := range layout.Labels {
+		if l.Left < 0 || l.Top < 0 || l.Right > float64(layout.Width) || l.Bottom > float64(layout.Height) || l.Left > l.Right || l.Top > l.Bottom {
+			t.Errorf("%s: %s label %q box (%.1f, %.1f)-(%.1f, %.1f) is off the %dx%d canvas",
+				name, l.Kind, l.Text, l.Left, l.Top, l.Right, l.Bottom, layout.Width, layout.Height)
+		}
+		if l.Kind != "planet" {
+			continue
+		}
+		seen[l.Planet]++
+		if l.Rashi != want[l.Planet] {
+			t.Errorf("%s: %s labeled in rashi %d, want %d", name, l.Planet, l.Rashi, want[l.Planet])
+		}
+		if house := HouseFromLagna(l.Rashi, want["lagna"]); l.House != house {
+			t.Errorf("%s: %s labeled in house %d, want %d", name, l.Planet, l.House, house)
+		}
+	}
+	for planetName := range want {
+		if seen[planetName] != 1 {
+			t.Errorf("%s: %s labeled %d times, want once", name, planetName, seen[planetName])
+		}
+	}
+
+	if len(layout.Houses) != 12 {
+		t.Fatalf("%s: %d houses, want 12", name, len(layout.Houses))
+	}
+	houses := map[int]bool{}
+	for _, h := range layout.Houses {
+		houses[h.House] = true
+		if h.House != HouseFromLagna(h.Rashi, want["lagna"]) {
+			t.Errorf("%s: house %d holds rashi %d", name, h.House, h.Rashi)
+		}
+		for _, p := range h.Polygon {
+			if p[0] < 0 || p[1] < 0 || p[0] > float64(layout.Width) || p[1] > float64(layout.Height) {
+				t.Errorf("%s: house %d corner %v is off the canvas", name, h.House, p)
+			}
+		}
+	}
+	if len(houses) != 12 {
+		t.Errorf("%s: houses %v, want each of 1-12 once", name, houses)
+	}
+}
+
+func TestGenerateChartWithLayout_Template(t *testing.T) {
+	data, err := json.Marshal(gridLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerTestLayout(t, "layout-export-grid", data)
+	input := thumbnailInput("layout-export-grid")
+	input.Options.Thumbnail = false
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLayout(t, input, layout)
+}
+
+func TestGenerateChartWithLayout_CenterTextAndJSON(t *testing.T) {
+	input := thumbnailInput(ChartTypeSouth)
+	input.Options.Thumbnail = false
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var center []LabelLayout
+	for _, l := range layout.Labels {
+		if l.Kind == "center_text" {
+			center = append(center, l)
+		}
+	}
+	if len(center) != 1 || center[0].Text != "Rashi" || center[0].House != 0 {
+		t.Errorf("center text labels = %+v, want one for %q outside the houses", center, "Rashi")
+	}
+
+	data, err := json.Marshal(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ChartLayout
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Labels) != len(layout.Labels) || len(got.Houses) != 12 {
+		t.Errorf("layout lost entries in JSON: %s", data)
+	}
+}
+
+func TestGenerateChartWithLayout_InvalidInput(t *testing.T) {
+	data, layout, err := GenerateChartWithLayout(ChartInput{ChartType: "unknown"})
+	if err == nil || data != nil || layout != nil {
+		t.Errorf("GenerateChartWithLayout(unknown type) = %d bytes, %v, %v; want an error", len(data), layout, err)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestHouseFromLagna_AllLagnas(t *testing.T) {
+	for lagna := 1; lagna <= 12; lagna++ {
+		seen := map[int]bool{}
+		for rashi := 1; rashi <= 12; rashi++ {
+			house := HouseFromLagna(rashi, lagna)
+			if house < 1 || house > 12 {
+				t.Fatalf("HouseFromLagna(%d, %d) = %d, out of range", rashi, lagna, house)
+			}
+			if seen[house] {
+				t.Fatalf("HouseFromLagna(%d, %d) = %d, house assigned twice", rashi, lagna, house)
+			}
+			seen[house] = true
+
+			// Counting forward from the lagna rashi must land on the rashi itself
+			if got := (lagna+house-2)%12 + 1; got != rashi {
+				t.Errorf("HouseFromLagna(%d, %d) = %d, counting back gives rashi %d", rashi, lagna, house, got)
+			}
+		}
+		if got := HouseFromLagna(lagna, lagna); got != 1 {
+			t.Errorf("HouseFromLagna(%d, %d) = %d, lagna rashi must be house 1", lagna, lagna, got)
+		}
+	}
+}
+
+func TestHouseFromLagna_Examples(t *testing.T) {
+	tests := []struct {
+		rashi, lagna, want int
+	}{
+		{5, 5, 1},   // Leo lagna, Leo is the 1st
+		{8, 5, 4},   // Scorpio is 4th from Leo
+		{4, 5, 12},  // Cancer is 12th from Leo
+		{1, 12, 2},  // Aries is 2nd from Pisces
+		{12, 1, 12}, // Pisces is 12th from Aries
+		{7, 1, 7},   // Libra is 7th from Aries
+	}
+	for _, tt := range tests {
+		if got := HouseFromLagna(tt.rashi, tt.lagna); got != tt.want {
+			t.Errorf("HouseFromLagna(%d, %d) = %d, want %d", tt.rashi, tt.lagna, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateBlankChart(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		data, err := GenerateBlankChart(chartType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_blank", data)
+
+		// The same chart as an empty input, or one with a nil lagna and no planets
+		for _, input := range []ChartInput{
+			{ChartType: chartType},
+			{ChartType: chartType, Planets: map[string]*Planet{}},
+			{ChartType: chartType, Planets: map[string]*Planet{"sun": nil}},
+		} {
+			encoded, err := GenerateChart(input)
+			if err != nil {
+				t.Fatalf("%s: %v", chartType, err)
+			}
+			if encoded != base64.StdEncoding.EncodeToString(data) {
+				t.Errorf("%s: chart of %+v differs from the blank chart", chartType, input)
+			}
+		}
+	}
+	if _, err := GenerateBlankChart(ChartTypeSarvashtakavarga); !errors.Is(err, ErrUnsupportedChartType) {
+		t.Errorf("blank sarvashtakavarga chart error = %v, want ErrUnsupportedChartType", err)
+	}
+	if _, err := GenerateBlankChart("east"); !errors.Is(err, ErrUnsupportedChartType) {
+		t.Errorf("blank east chart error = %v, want ErrUnsupportedChartType", err)
+	}
+}
+
+func TestGenerateChart_NilInputs(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		// A nil planet map draws the lagna alone
+		input := ChartInput{ChartType: chartType, Lagna: &Planet{Rashi: "leo"}}
+		result, err := GenerateChartResult(input)
+		if err != nil {
+			t.Fatalf("%s: %v", chartType, err)
+		}
+		if len(result.Houses) != 0 || len(result.Warnings) != 0 || result.LagnaRashi != 5 {
+			t.Errorf("%s: result %+v, want the lagna alone", chartType, result)
+		}
+		if labels := planetLabels(t, input); len(labels) != 1 || labels[0] != "Asc" {
+			t.Errorf("%s: labels %q, want Asc alone", chartType, labels)
+		}
+
+		// Every option that reads the planets copes without them
+		input.Options = ChartOptions{
+			ShowHouseLords: true, ShowConjunctions: true, ShowCharaKarakas: true, ShowDigbala: true,
+			MarkBadhakesh: true, HighlightBadhaka: true, ShowColorKey: true, NakshatraRing: true,
+		}
+		if _, err := GenerateChart(input); err != nil {
+			t.Errorf("%s with options: %v", chartType, err)
+		}
+		input.Options.Thumbnail = true
+		if _, err := GenerateChart(input); err != nil {
+			t.Errorf("%s thumbnail: %v", chartType, err)
+		}
+	}
+
+	// Blank charts draw the rashi numbers and nothing else
+	_, layout, err := GenerateChartWithLayout(ChartInput{ChartType: ChartTypeNorth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layout.Labels {
+		if l.Kind != string(labelRashi) {
+			t.Errorf("blank chart has a %s label %q", l.Kind, l.Text)
+		}
+	}
+	if len(layout.Labels) != 12 {
+		t.Errorf("blank chart has %d labels, want the 12 rashi numbers", len(layout.Labels))
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the input: its planets, lagna, maps, slices
+// and option values are copied too, so the copy can be changed, such as to
+// derive a moon chart or a varga, without touching the original.
+func (input ChartInput) Clone() ChartInput {
+	c := input
+	if input.Planets != nil {
+		c.Planets = make(map[string]*Planet, len(input.Planets))
+		for name, p := range input.Planets {
+			c.Planets[name] = p.clone()
+		}
+	}
+	c.Lagna = input.Lagna.clone()
+	c.CenterLines = slices.Clone(input.CenterLines)
+	c.HouseScores = maps.Clone(input.HouseScores)
+	c.SecondaryHouseScores = maps.Clone(input.SecondaryHouseScores)
+	c.Strengths = maps.Clone(input.Strengths)
+	if input.Panchanga != nil {
+		panchanga := *input.Panchanga
+		c.Panchanga = &panchanga
+	}
+	if input.Native != nil {
+		native := *input.Native
+		c.Native = &native
+	}
+	c.Options = input.Options.clone()
+	return c
+}
+
+// clone returns a copy of the planet, or nil for a nil planet
+func (p *Planet) clone() *Planet {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// clone returns a copy of the options sharing no slices or pointers with them
+func (o ChartOptions) clone() ChartOptions {
+	c := o
+	if o.HighlightHouses != nil {
+		c.HighlightHouses = make([]HouseHighlight, len(o.HighlightHouses))
+		for i, h := range o.HighlightHouses {
+			h.Houses = slices.Clone(h.Houses)
+			c.HighlightHouses[i] = h
+		}
+	}
+	c.AspectLines = slices.Clone(o.AspectLines)
+	c.DashaTable = slices.Clone(o.DashaTable)
+	if o.LagnaLabel != nil {
+		label := *o.LagnaLabel
+		c.LagnaLabel = &label
+	}
+	return c
+}
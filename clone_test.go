@@ -0,0 +1,74 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func cloneInput() ChartInput {
+	label := "La"
+	return ChartInput{
+		ChartType:            ChartTypeSouth,
+		Lagna:                &Planet{Rashi: "leo", Degrees: 10},
+		Planets:              map[string]*Planet{"sun": {Rashi: "leo"}, "moon": {Rashi: "taurus", NavamsaRashi: "cancer"}},
+		CenterLines:          []CenterLine{{Text: "Arjun", Bold: true}},
+		HouseScores:          map[int]int{1: 30},
+		SecondaryHouseScores: map[int]int{1: 4},
+		Strengths:            map[string]float64{"sun": 6.5},
+		Panchanga:            &Panchanga{Tithi: "Shukla Panchami"},
+		Native:               &NativeInfo{Name: "Arjun", DateOfBirth: time.Date(1990, 8, 15, 0, 0, 0, 0, time.UTC)},
+		Options: ChartOptions{
+			HighlightHouses: []HouseHighlight{{Houses: []int{1, 5}, Color: "#FFE0B2"}},
+			AspectLines:     []string{"sun"},
+			DashaTable:      []DashaPeriod{{Lord: "Venus"}},
+			LagnaLabel:      &label,
+		},
+	}
+}
+
+func TestChartInput_Clone(t *testing.T) {
+	original := cloneInput()
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("clone = %+v, want %+v", clone, original)
+	}
+
+	// Changing everything the clone holds leaves the original as it was
+	clone.Lagna.Rashi = "virgo"
+	clone.Planets["sun"].IsRetrograde = true
+	clone.Planets["mars"] = &Planet{Rashi: "aries"}
+	clone.CenterLines[0].Text = "Bhima"
+	clone.HouseScores[1] = 20
+	clone.SecondaryHouseScores[1] = 2
+	clone.Strengths["sun"] = 1
+	clone.Panchanga.Tithi = "Amavasya"
+	clone.Native.Name = "Bhima"
+	clone.Options.HighlightHouses[0].Houses[0] = 7
+	clone.Options.AspectLines[0] = "moon"
+	clone.Options.DashaTable[0].Lord = "Sun"
+	*clone.Options.LagnaLabel = "L"
+	if !reflect.DeepEqual(original, cloneInput()) {
+		t.Errorf("changing the clone changed the original: %+v", original)
+	}
+
+	// Nil fields stay nil
+	if got := (ChartInput{}).Clone(); !reflect.DeepEqual(got, ChartInput{}) {
+		t.Errorf("clone of an empty input = %+v", got)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build js && wasm
+
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Command vedicchart-wasm renders charts in the browser. Built with
+// GOOS=js GOARCH=wasm, it registers a global JavaScript function
+//
+//	generateChart(json) -> {png: "<base64 PNG>"} or {error: "<message>"}
+//
+// that takes the JSON form of parashari.ChartInput. See index.html for a
+// page that loads it.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+func main() {
+	js.Global().Set("generateChart", js.FuncOf(generateChart))
+	// Keep the functions registered for the life of the page
+	select {}
+}
+
+// generateChart is the JavaScript generateChart function
+func generateChart(_ js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return result("", fmt.Errorf("generateChart takes one argument, the chart JSON"))
+	}
+	return result(render(args[0].String()))
+}
+
+// render draws the chart described by a ChartInput in JSON and returns it
+// as a base64-encoded PNG
+func render(chartJSON string) (string, error) {
+	var input parashari.ChartInput
+	if err := json.Unmarshal([]byte(chartJSON), &input); err != nil {
+		return "", fmt.Errorf("invalid chart JSON: %w", err)
+	}
+	return parashari.GenerateChart(input)
+}
+
+// result returns the JavaScript object generateChart answers with
+func result(png string, err error) map[string]any {
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return map[string]any{"png": png}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Command vedicchart renders a Vedic astrology chart from JSON.
+//
+// The input matches the JSON form of parashari.ChartInput and is read from
+// a file or from stdin:
+//
+//	vedicchart -in chart.json -out chart.png -type south -size 1200
+//	cat chart.json | vedicchart -out chart.png
+//
+// The output format follows the extension of -out: .png, or .svg for an SVG
+// with houses and planets marked for CSS and tooltips (see
+// parashari.GenerateChartSVG). Charts written to stdout are PNG. Invalid input exits with status 1 and a message on stderr; bad
+// flags exit with status 2. -version prints the library version.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and streams, returning
+// its exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("vedicchart", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := flags.String("in", "-", "input JSON file, or - for stdin")
+	out := flags.String("out", "-", "output file, its extension picking the format (.png or .svg), or - for PNG on stdout")
+	chartType := flags.String("type", "", "chart type, overriding the input's chart_type: north or south")
+	size := flags.Int("size", 0, "canvas width and height in pixels, overriding the input's options")
+	showVersion := flags.Bool("version", false, "print the library version and exit")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: vedicchart [-in chart.json] [-out chart.png] [-type south|north] [-size pixels] [-version]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *showVersion {
+		fmt.Fprintln(stdout, "vedicchart", parashari.Version())
+		return 0
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "vedicchart: unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		flags.Usage()
+		return 2
+	}
+	if *size < 0 {
+		fmt.Fprintf(stderr, "vedicchart: -size must not be negative: %d\n", *size)
+		return 2
+	}
+
+	if err := render(*in, *out, parashari.ChartType(*chartType), *size, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "vedicchart: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// render reads the chart input, applies the flag overrides and writes the
+// chart
+func render(in, out string, chartType parashari.ChartType, size int, stdin io.Reader, stdout io.Writer) error {
+	input, err := readInput(in, stdin)
+	if err != nil {
+		return err
+	}
+	if chartType != "" {
+		input.ChartType = chartType
+	}
+	if size > 0 {
+		input.Options.Width, input.Options.Height = size, size
+	}
+
+	if out == "-" {
+		return parashari.WriteChart(stdout, input)
+	}
+	format, err := outputFormat(out)
+	if err != nil {
+		return err
+	}
+	// Draw the whole chart before touching out, so a chart that cannot be
+	// drawn leaves an existing image as it was
+	var data []byte
+	if format == "svg" {
+		data, err = parashari.GenerateChartSVG(input)
+	} else {
+		var buf bytes.Buffer
+		err = parashari.WriteChart(&buf, input)
+		data = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
+
+// readInput decodes the chart input from a file, or from stdin for "-"
+func readInput(in string, stdin io.Reader) (parashari.ChartInput, error) {
+	var input parashari.ChartInput
+	r := stdin
+	name := "stdin"
+	if in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return input, err
+		}
+		defer f.Close()
+		r, name = f, in
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return input, fmt.Errorf("invalid chart JSON in %s: %w", name, err)
+	}
+	return input, nil
+}
+
+// outputFormat returns the format an output file's extension names, "png"
+// or "svg", rejecting those the library cannot write
+func outputFormat(out string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(out)); ext {
+	case ".png", ".svg":
+		return ext[1:], nil
+	default:
+		return "", fmt.Errorf("cannot write %s: unsupported output format %q, use a .png or .svg file", out, ext)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+// TestMain runs the command itself when the tests start the test binary
+// with VEDICCHART_RUN_MAIN set, so the integration tests exercise the real
+// binary, flags, streams and exit status without building it separately
+func TestMain(m *testing.M) {
+	if os.Getenv("VEDICCHART_RUN_MAIN") == "1" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+const chartJSON = `{
+	"chart_type": "south",
+	"lagna": {"rashi": "leo"},
+	"planets": {
+		"sun": {"rashi": "leo", "is_combust": false},
+		"saturn": {"rashi": "libra", "is_retrograde": true}
+	},
+	"center_text": "Rashi"
+}`
+
+// vedicchart runs the command with args and stdin, returning its stdout,
+// stderr and exit status
+func vedicchart(t *testing.T, stdin string, args ...string) (stdout []byte, stderr string, status int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "VEDICCHART_RUN_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		status = exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("Error running vedicchart: %v", err)
+	}
+	return out.Bytes(), errOut.String(), status
+}
+
+// decodeSize decodes a PNG and returns its width and height
+func decodeSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Output is not a PNG: %v", err)
+	}
+	return img.Bounds().Dx(), img.Bounds().Dy()
+}
+
+func TestVedicchart_FileToFile(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "chart.json")
+	if err := os.WriteFile(in, []byte(chartJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "chart.png")
+
+	_, stderr, status := vedicchart(t, "", "-in", in, "-out", out, "-type", "north", "-size", "600")
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := decodeSize(t, data); w != 600 || h != 600 {
+		t.Errorf("Chart is %dx%d, want 600x600", w, h)
+	}
+}
+
+func TestVedicchart_SVG(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "chart.SVG")
+	_, stderr, status := vedicchart(t, chartJSON, "-out", out, "-size", "600")
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svg struct {
+		XMLName xml.Name
+		Width   string `xml:"width,attr"`
+	}
+	if err := xml.Unmarshal(data, &svg); err != nil {
+		t.Fatalf("Output is not XML: %v", err)
+	}
+	if svg.XMLName.Local != "svg" || svg.Width != "600" {
+		t.Errorf("Output is <%s width=%q>, want <svg width=\"600\">", svg.XMLName.Local, svg.Width)
+	}
+	for _, id := range []string{`id="house-1"`, `id="planet-saturn"`, `class="planet retrograde"`} {
+		if !bytes.Contains(data, []byte(id)) {
+			t.Errorf("SVG lacks %s", id)
+		}
+	}
+}
+
+func TestVedicchart_StdinToStdout(t *testing.T) {
+	stdout, stderr, status := vedicchart(t, chartJSON)
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, stderr)
+	}
+	if w, h := decodeSize(t, stdout); w != 800 || h != 800 {
+		t.Errorf("Chart is %dx%d, want 800x800", w, h)
+	}
+}
+
+func TestVedicchart_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		status int
+		want   string
+	}{
+		{"malformed JSON", `{"chart_type": `, nil, 1, "invalid chart JSON in stdin"},
+		{"unknown field", `{"chart_type": "south", "planet": {}}`, nil, 1, `unknown field "planet"`},
+		{"unknown planet field", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "retro": true}}}`, nil, 1, `unknown field "retro"`},
+		{"bad degrees", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "degrees": 45}}}`, nil, 1, "planet sun: degrees 45 out of range"},
+		{"unsupported type", chartJSON, []string{"-type", "east"}, 1, "unsupported chart type: east"},
+		{"unknown format", chartJSON, []string{"-out", filepath.Join(dir, "chart.gif")}, 1, `unsupported output format ".gif"`},
+		{"missing input", "", []string{"-in", filepath.Join(dir, "missing.json")}, 1, "missing.json"},
+		{"bad flag", chartJSON, []string{"-colour", "red"}, 2, "flag provided but not defined"},
+		{"negative size", chartJSON, []string{"-size", "-5"}, 2, "-size must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, status := vedicchart(t, tt.stdin, tt.args...)
+			if status != tt.status {
+				t.Errorf("Exit status %d, want %d (stderr: %s)", status, tt.status, stderr)
+			}
+			if !strings.Contains(stderr, tt.want) {
+				t.Errorf("stderr %q does not mention %q", stderr, tt.want)
+			}
+			if len(stdout) != 0 {
+				t.Errorf("Wrote %d bytes to stdout on failure", len(stdout))
+			}
+		})
+	}
+}
+
+func TestVedicchart_FailureLeavesNoFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "chart.png")
+	_, _, status := vedicchart(t, chartJSON, "-out", out, "-size", "40")
+	if status != 1 {
+		t.Fatalf("Exit status %d, want 1 for an illegible canvas", status)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file after a failure, got %v", err)
+	}
+}
+
+func TestVedicchart_FailureKeepsExistingFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "chart.png")
+	old := []byte("an earlier chart")
+	if err := os.WriteFile(out, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, status := vedicchart(t, `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "degrees": 45}}}`, "-out", out)
+	if status != 1 {
+		t.Fatalf("Exit status %d, want 1 for bad degrees", status)
+	}
+	if data, err := os.ReadFile(out); err != nil || !bytes.Equal(data, old) {
+		t.Errorf("Output file after a failure = %q, %v; want it left as it was", data, err)
+	}
+}
+
+func TestVedicchart_Version(t *testing.T) {
+	stdout, stderr, status := vedicchart(t, "", "-version")
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, stderr)
+	}
+	if want := "vedicchart " + parashari.Version() + "\n"; string(stdout) != want {
+		t.Errorf("-version printed %q, want %q", stdout, want)
+	}
+}
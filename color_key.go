@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// colorKey is the panel beneath the chart naming the colors of the
+// categories it draws, or in grayscale their print styles
+type colorKey struct {
+	categories  []PointCategory
+	grayscale   bool
+	shadedNodes bool // Nodes are drawn in nodeSlate, see NodeStyleShaded
+}
+
+// color returns the color a category's points are drawn in
+func (k *colorKey) color(c PointCategory) color.Color {
+	if c == PointNode && k.shadedNodes {
+		return nodeSlate
+	}
+	return c.color()
+}
+
+// newColorKey returns the color key of a chart, or nil when it is not shown
+// or every point is drawn like the grahas
+func newColorKey(input ChartInput) *colorKey {
+	if !input.Options.ShowColorKey {
+		return nil
+	}
+	present := map[PointCategory]bool{}
+	if input.Lagna != nil {
+		f := newLabelFormat(input.Options)
+		present[PointLagna] = lagnaLabel(f.locale, f.mode, f.lagnaLabel, input.Lagna) != ""
+	}
+	for name, p := range input.Planets {
+		if p != nil && RashiToNumber(p.Rashi) != 0 {
+			present[PlanetCategory(name, p)] = true
+		}
+	}
+	key := &colorKey{
+		grayscale:   input.Options.Grayscale,
+		shadedNodes: input.Options.NodeStyle == NodeStyleShaded && !input.Options.HighContrast,
+	}
+	for _, c := range pointCategories {
+		plain := key.color(c) == textBlack
+		if key.grayscale {
+			plain = c.printStyle().plain()
+		}
+		if present[c] && !plain {
+			key.categories = append(key.categories, c)
+		}
+	}
+	if len(key.categories) == 0 {
+		return nil
+	}
+	return key
+}
+
+// Sizes of the color key, for a chart of defaultChartSize
+const (
+	colorKeyTextSize = 13
+	colorKeySwatch   = 11
+	colorKeyMargin   = 8  // Above and below the row
+	colorKeyGap      = 20 // Between the entries
+)
+
+func (k *colorKey) side() panelSide { return panelBelow }
+
+func (k *colorKey) extent(frame chartFrame) float64 {
+	return 2*frame.px(colorKeyMargin) + regularMetrics(frame.px(colorKeyTextSize)).lineHeight()
+}
+
+// draw draws the entries in a row from the left of the chart's grid, each a
+// swatch followed by the category's name, or in grayscale the name drawn in
+// the category's print style
+func (k *colorKey) draw(dc *gg.Context, frame chartFrame, _, top, _, _ float64, boxes *chartBoxes) {
+	size := frame.px(colorKeyTextSize)
+	face, m := embeddedFace(matangiRegular, size), regularMetrics(size)
+	swatch := frame.px(colorKeySwatch)
+	x := frame.x + frame.px(40) // Aligned with the chart's grid
+	baseline := m.baseline(top + frame.px(colorKeyMargin) + m.lineHeight()/2)
+	for _, c := range k.categories {
+		title := categoryStyles[c].title
+		if k.grayscale {
+			style := c.printStyle()
+			title = style.wrap(title)
+			drawText(dc, face, style.shade, title, x, baseline, 0)
+			if style.underline {
+				drawUnderline(dc, style.shade, x, float64(font.MeasureString(face, title))/64, baseline, m.descent, size)
+			}
+		} else {
+			// Swatches are centered on the names' capitals
+			dc.SetColor(k.color(c))
+			dc.DrawRectangle(x, baseline-m.capHeight/2-swatch/2, swatch, swatch)
+			dc.Fill()
+			x += swatch + swatch/2
+			drawText(dc, face, textBlack, title, x, baseline, 0)
+		}
+		w := float64(font.MeasureString(face, title)) / 64
+		boxes.add(textBox{text: title, kind: labelColorKey, left: x, top: baseline - m.capHeight, right: x + w, bottom: baseline + m.descent})
+		x += w + frame.px(colorKeyGap)
+	}
+}
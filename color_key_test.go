@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCategoryColors(t *testing.T) {
+	colors := CategoryColors()
+	if len(colors) != len(pointCategories) {
+		t.Errorf("CategoryColors has %d categories, want %d", len(colors), len(pointCategories))
+	}
+	if colors[PointLagna] != lagnaSaffron || colors[PointSpecialLagna] != specialYellow || colors[PointGraha] != textBlack {
+		t.Errorf("CategoryColors = %v", colors)
+	}
+	// The map is a copy
+	colors[PointGraha] = specialYellow
+	if PointGraha.color() != textBlack {
+		t.Error("changing CategoryColors changed the graha color")
+	}
+}
+
+func TestNewColorKey(t *testing.T) {
+	input := categoriesInput(ChartTypeSouth)
+	input.Options.ShowColorKey = true
+	want := []PointCategory{PointLagna, PointUpagraha, PointSpecialLagna, PointArudhaPada}
+	if got := newColorKey(input); got == nil || !slices.Equal(got.categories, want) {
+		t.Errorf("key = %v, want %v", got, want)
+	}
+
+	// Without its label the lagna is not drawn, nor keyed
+	empty := ""
+	input.Options.LagnaLabel = &empty
+	if got := newColorKey(input); got == nil || slices.Contains(got.categories, PointLagna) {
+		t.Errorf("key = %v, want no lagna", got)
+	}
+
+	// Black points alone need no key
+	plain := ChartInput{
+		ChartType: ChartTypeSouth,
+		Planets:   map[string]*Planet{"sun": {Rashi: "leo"}, "rahu": {Rashi: "virgo"}, "pranapada": {Rashi: "leo"}},
+		Options:   ChartOptions{ShowColorKey: true},
+	}
+	if got := newColorKey(plain); got != nil {
+		t.Errorf("key = %v, want none", got)
+	}
+}
+
+func TestGenerateChart_ColorKey(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := categoriesInput(chartType)
+		input.Options.ShowColorKey = true
+		data, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_color_key", data)
+		var titles []string
+		for _, l := range layout.Labels {
+			if l.Kind == string(labelColorKey) {
+				titles = append(titles, l.Text)
+			}
+		}
+		if want := []string{"Lagna", "Upagraha", "Special lagna", "Arudha pada"}; !slices.Equal(titles, want) {
+			t.Errorf("%s: key names %q, want %q", chartType, titles, want)
+		}
+		if layout.Height <= defaultChartSize {
+			t.Errorf("%s: canvas height %d, want the key beneath the chart", chartType, layout.Height)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// Text colors, equal to those gg's SetRGB sets for the same components
+var (
+	textBlack       = color.NRGBA{A: 255}
+	lagnaSaffron    = color.NRGBA{R: 255, G: 153, B: 51, A: 255}  // SetRGB(1.0, 0.6, 0.2)
+	specialYellow   = color.NRGBA{R: 255, G: 216, A: 255}         // SetRGB(1.0, 0.85, 0.0)
+	houseNumberGray = color.NRGBA{R: 140, G: 140, B: 140, A: 255} // SetRGB(0.55, 0.55, 0.55)
+	upagrahaBrown   = color.NRGBA{R: 130, G: 90, B: 50, A: 255}
+	arudhaPurple    = color.NRGBA{R: 120, G: 60, B: 150, A: 255}
+	printGray       = color.NRGBA{R: 100, G: 100, B: 100, A: 255} // Dark enough to survive a laser printer
+	nodeSlate       = color.NRGBA{R: 70, G: 90, B: 140, A: 255}
+)
+
+// parseHexColor parses a "#RGB", "#RRGGBB" or "#RRGGBBAA" color string
+func parseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: expected #RGB, #RRGGBB or #RRGGBBAA", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// fillPolygon fills a closed polygon with a color
+func fillPolygon(dc *gg.Context, poly []gg.Point, c color.Color) {
+	dc.NewSubPath()
+	for _, p := range poly {
+		dc.LineTo(p.X, p.Y)
+	}
+	dc.ClosePath()
+	dc.SetColor(c)
+	dc.Fill()
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// CombustionOrb is how close in longitude a planet must come to the Sun to
+// be combust, with a separate orb while the planet is retrograde
+type CombustionOrb struct {
+	Direct     float64
+	Retrograde float64
+}
+
+// combustionOrbs are the standard orbs in degrees. Mercury and Venus, which
+// come closest to the Earth while retrograde, have narrower orbs then. The
+// Sun, Rahu and Ketu are never combust.
+var combustionOrbs = map[string]CombustionOrb{
+	"moon":    {12, 12},
+	"mars":    {17, 17},
+	"mercury": {14, 12},
+	"jupiter": {11, 11},
+	"venus":   {10, 8},
+	"saturn":  {15, 15},
+}
+
+// CombustionOrbs returns the standard orbs ComputeCombustion judges by,
+// keyed by lowercase planet name. The map is a copy; changing it changes
+// nothing else.
+func CombustionOrbs() map[string]CombustionOrb {
+	return maps.Clone(combustionOrbs)
+}
+
+// ComputeCombustion reports for each planet of CombustionOrbs among
+// longitudes (sidereal or tropical degrees, keyed by name) whether it lies
+// within its orb of the Sun, using the retrograde orb for planets flagged in
+// retrograde. Distances are measured the short way around the zodiac, so
+// 359° and 5° are 6° apart. Without the Sun in longitudes nothing is
+// combust and the result is empty. Names are matched in any case and the
+// result is keyed like longitudes.
+func ComputeCombustion(longitudes map[string]float64, retrograde map[string]bool) map[string]bool {
+	combust := map[string]bool{}
+	sun, ok := lookupFold(longitudes, "sun")
+	if !ok {
+		return combust
+	}
+	for name, longitude := range longitudes {
+		orbs, ok := combustionOrbs[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		orb := orbs.Direct
+		if r, _ := lookupFold(retrograde, name); r {
+			orb = orbs.Retrograde
+		}
+		combust[name] = angularDistance(longitude, sun) <= orb
+	}
+	return combust
+}
+
+// ApplyCombustion sets IsCombust on each planet of the input that
+// ComputeCombustion judges from longitudes and the planets' retrograde
+// flags, clearing it on those outside their orb. Planets missing from
+// longitudes keep their flag.
+func ApplyCombustion(input *ChartInput, longitudes map[string]float64) {
+	retrograde := make(map[string]bool, len(input.Planets))
+	for _, name := range sortedPlanetNames(input.Planets) {
+		if p := input.Planets[name]; p != nil {
+			retrograde[strings.ToLower(name)] = p.IsRetrograde
+		}
+	}
+	combust := ComputeCombustion(longitudes, retrograde)
+	for name, p := range input.Planets {
+		if c, ok := lookupFold(combust, name); ok && p != nil {
+			p.IsCombust = c
+		}
+	}
+}
+
+// angularDistance returns the separation of two longitudes the short way
+// around the zodiac, 0-180°
+func angularDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// lookupFold returns the value of a key in m matched in any case, trying
+// the exact key first, then the keys in sorted order
+func lookupFold[V any](m map[string]V, key string) (V, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if strings.EqualFold(k, key) {
+			return m[k], true
+		}
+	}
+	var zero V
+	return zero, false
+}
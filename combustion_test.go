@@ -0,0 +1,131 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeCombustion_Orbs(t *testing.T) {
+	tests := []struct {
+		planet     string
+		retrograde bool
+		orb        float64
+	}{
+		{"moon", false, 12},
+		{"mars", false, 17},
+		{"mercury", false, 14},
+		{"mercury", true, 12},
+		{"jupiter", false, 11},
+		{"venus", false, 10},
+		{"venus", true, 8},
+		{"saturn", false, 15},
+	}
+	const sun = 100.0
+	for _, tt := range tests {
+		for _, side := range []float64{-1, 1} {
+			for _, c := range []struct {
+				offset float64
+				want   bool
+			}{{tt.orb - 0.01, true}, {tt.orb + 0.01, false}} {
+				longitudes := map[string]float64{"sun": sun, tt.planet: sun + side*c.offset}
+				got := ComputeCombustion(longitudes, map[string]bool{tt.planet: tt.retrograde})
+				if got[tt.planet] != c.want {
+					t.Errorf("%s (retrograde %v) %+.2f° from the Sun: combust %v, want %v",
+						tt.planet, tt.retrograde, side*c.offset, got[tt.planet], c.want)
+				}
+			}
+		}
+	}
+}
+
+func TestCombustionOrbs_IsACopy(t *testing.T) {
+	orbs := CombustionOrbs()
+	if len(orbs) != 6 || orbs["mercury"] != (CombustionOrb{14, 12}) {
+		t.Errorf("CombustionOrbs() = %v", orbs)
+	}
+	orbs["mars"] = CombustionOrb{1, 1}
+	delete(orbs, "moon")
+
+	got := ComputeCombustion(map[string]float64{"sun": 100, "mars": 110, "moon": 105}, nil)
+	if !got["mars"] || !got["moon"] {
+		t.Errorf("Changing the returned orbs changed ComputeCombustion: %v", got)
+	}
+	if again := CombustionOrbs(); again["mars"] != (CombustionOrb{17, 17}) || len(again) != 6 {
+		t.Errorf("Changing the returned orbs changed the next call: %v", again)
+	}
+}
+
+func TestComputeCombustion(t *testing.T) {
+	tests := []struct {
+		name       string
+		longitudes map[string]float64
+		retrograde map[string]bool
+		want       map[string]bool
+	}{
+		{
+			name:       "wrap around 0°",
+			longitudes: map[string]float64{"sun": 355, "moon": 5, "mars": 340, "saturn": 12},
+			want:       map[string]bool{"moon": true, "mars": true, "saturn": false},
+		},
+		{
+			name:       "Sun, nodes and upagrahas are never combust",
+			longitudes: map[string]float64{"sun": 10, "rahu": 10, "ketu": 190, "mandi": 11},
+			want:       map[string]bool{},
+		},
+		{
+			name:       "no Sun",
+			longitudes: map[string]float64{"moon": 10, "mars": 11},
+			want:       map[string]bool{},
+		},
+		{
+			name:       "names in any case",
+			longitudes: map[string]float64{"Sun": 200, "Venus": 209},
+			retrograde: map[string]bool{"VENUS": true},
+			want:       map[string]bool{"Venus": false},
+		},
+	}
+	for _, tt := range tests {
+		if got := ComputeCombustion(tt.longitudes, tt.retrograde); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ComputeCombustion() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCombustion(t *testing.T) {
+	input := ChartInput{
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "aries"},
+			"mercury": {Rashi: "aries", IsRetrograde: true},
+			"venus":   {Rashi: "pisces", IsCombust: true},
+			"jupiter": {Rashi: "aries"},
+			"saturn":  {Rashi: "libra", IsCombust: true},
+		},
+	}
+	ApplyCombustion(&input, map[string]float64{
+		"sun":     10,
+		"mercury": 23,  // 13°: inside the direct orb, outside the retrograde one
+		"venus":   355, // 15°
+		"jupiter": 1,   // 9°
+	})
+	want := map[string]bool{"sun": false, "mercury": false, "venus": false, "jupiter": true, "saturn": true}
+	for name, combust := range want {
+		if input.Planets[name].IsCombust != combust {
+			t.Errorf("%s IsCombust = %v, want %v", name, input.Planets[name].IsCombust, combust)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"sort"
+
+	"github.com/fogleman/gg"
+)
+
+// DefaultConjunctionOrb is the orb in degrees within which planets in the
+// same rashi form a tight conjunction unless ConjunctionOrb says otherwise
+const DefaultConjunctionOrb = 3.0
+
+// Conjunction is a group of planets in one rashi whose degrees lie within
+// the orb of each other, or of a planet between them
+type Conjunction struct {
+	Rashi   int      `json:"rashi"`
+	Planets []string `json:"planets"` // In order of degrees
+	Spread  float64  `json:"spread"`  // Degrees from the first planet to the last
+}
+
+// conjunctionOrb returns the orb the options ask for
+func (o ChartOptions) conjunctionOrb() float64 {
+	if o.ConjunctionOrb > 0 {
+		return o.ConjunctionOrb
+	}
+	return DefaultConjunctionOrb
+}
+
+// ComputeConjunctions returns the tight conjunctions among the nine grahas
+// in the chart: planets in the same rashi, sorted by degrees, chained
+// together while each lies within Options.ConjunctionOrb (DefaultConjunctionOrb
+// when zero) of the one before. Planets in different rashis never
+// conjoin, however close their longitudes, so 29.5° of one rashi and 0.5°
+// of the next stay apart. Planets without degrees (Degrees 0) are left out.
+// Groups are ordered by rashi, then degrees.
+func ComputeConjunctions(input ChartInput) []Conjunction {
+	type placed struct {
+		name    string
+		degrees float64
+	}
+	byRashi := map[int][]placed{}
+	for _, graha := range grahas {
+		name, p := findPlanet(input.Planets, graha)
+		if p == nil || p.IsUpagraha || p.Degrees <= 0 || RashiToNumber(p.Rashi) == 0 {
+			continue
+		}
+		byRashi[RashiToNumber(p.Rashi)] = append(byRashi[RashiToNumber(p.Rashi)], placed{name, p.Degrees})
+	}
+
+	orb := input.Options.conjunctionOrb()
+	var groups []Conjunction
+	for rashiNum := 1; rashiNum <= 12; rashiNum++ {
+		planets := byRashi[rashiNum]
+		sort.SliceStable(planets, func(i, j int) bool { return planets[i].degrees < planets[j].degrees })
+		for start := 0; start < len(planets); {
+			end := start + 1
+			for end < len(planets) && planets[end].degrees-planets[end-1].degrees <= orb {
+				end++
+			}
+			if end-start > 1 {
+				g := Conjunction{Rashi: rashiNum, Spread: planets[end-1].degrees - planets[start].degrees}
+				for _, p := range planets[start:end] {
+					g.Planets = append(g.Planets, p.name)
+				}
+				groups = append(groups, g)
+			}
+			start = end
+		}
+	}
+	return groups
+}
+
+// withConjunctions returns the format grouping the planets of each
+// conjunction when the chart shows them
+func (f labelFormat) withConjunctions(input ChartInput) labelFormat {
+	if !input.Options.ShowConjunctions {
+		return f
+	}
+	f.conjunctions = map[string]conjunctionSlot{}
+	for i, g := range ComputeConjunctions(input) {
+		for j, name := range g.Planets {
+			f.conjunctions[name] = conjunctionSlot{group: i, index: j}
+		}
+	}
+	return f
+}
+
+// conjunctionSlot is a planet's place in the conjunctions of a chart
+type conjunctionSlot struct {
+	group int // Index of the conjunction
+	index int // Index of the planet in its conjunction, by degrees
+}
+
+// conjunctionGroup returns the conjunction a house entry belongs to
+func (f labelFormat) conjunctionGroup(e planetEntry) (int, bool) {
+	slot, ok := f.conjunctions[e.name]
+	return slot.group, ok
+}
+
+// groupConjunctions reorders the entries of a house so that the planets of
+// each conjunction follow each other in order of degrees, from where the
+// first of them stood. Entries outside conjunctions keep their order.
+func (f labelFormat) groupConjunctions(entries []planetEntry) []planetEntry {
+	if len(f.conjunctions) == 0 {
+		return entries
+	}
+	ordered := make([]planetEntry, 0, len(entries))
+	placed := map[int]bool{}
+	for _, e := range entries {
+		group, ok := f.conjunctionGroup(e)
+		if !ok {
+			ordered = append(ordered, e)
+			continue
+		}
+		if placed[group] {
+			continue
+		}
+		placed[group] = true
+		start := len(ordered)
+		for _, other := range entries {
+			if g, ok := f.conjunctionGroup(other); ok && g == group {
+				ordered = append(ordered, other)
+			}
+		}
+		members := ordered[start:]
+		sort.SliceStable(members, func(i, j int) bool {
+			return f.conjunctions[members[i].name].index < f.conjunctions[members[j].name].index
+		})
+	}
+	return append(entries[:0], ordered...)
+}
+
+// drawConjunctions brackets the labels of each conjunction in a house: a
+// thin bracket beside each run of them in one column, on the side the
+// column is aligned on, with a line joining the brackets when a wrapped
+// column splits the group
+func (f labelFormat) drawConjunctions(dc *gg.Context, layout houseLayout) {
+	// Inline rows keep conjunct planets together without brackets
+	if len(f.conjunctions) == 0 || layout.inline {
+		return
+	}
+	boxes := houseBoxes(dc, layout, 0)
+	gap, tick := layout.size/6, layout.size/4
+	dc.SetColor(houseNumberGray)
+	if f.contrast {
+		dc.SetColor(textBlack)
+	}
+	dc.SetLineWidth(math.Max(1, layout.size/14))
+
+	drawn := map[int]bool{}
+	for i, l := range layout.labels {
+		group, ok := f.conjunctionGroup(l.entry)
+		if !ok || drawn[group] {
+			continue
+		}
+		drawn[group] = true
+
+		// Runs of the group's labels sharing a column anchor
+		var mids []gg.Point
+		for j := i; j < len(layout.labels); {
+			if g, ok := f.conjunctionGroup(layout.labels[j].entry); !ok || g != group {
+				j++
+				continue
+			}
+			run := layout.labels[j]
+			top, bottom, left, right := boxes[j].top, boxes[j].bottom, boxes[j].left, boxes[j].right
+			k := j + 1
+			for ; k < len(layout.labels); k++ {
+				next := layout.labels[k]
+				if g, ok := f.conjunctionGroup(next.entry); !ok || g != group || next.x != run.x || next.ax != run.ax {
+					break
+				}
+				top, bottom = math.Min(top, boxes[k].top), math.Max(bottom, boxes[k].bottom)
+				left, right = math.Min(left, boxes[k].left), math.Max(right, boxes[k].right)
+			}
+			// Right-aligned and centered columns take the bracket on their
+			// right, left-aligned ones on their left
+			x, dir := right+gap, -1.0
+			if run.ax == 0 {
+				x, dir = left-gap, 1.0
+			}
+			dc.MoveTo(x+dir*tick, top)
+			dc.LineTo(x, top)
+			dc.LineTo(x, bottom)
+			dc.LineTo(x+dir*tick, bottom)
+			dc.Stroke()
+			mids = append(mids, gg.Point{X: x, Y: (top + bottom) / 2})
+			j = k
+		}
+		for k := 1; k < len(mids); k++ {
+			dc.DrawLine(mids[k-1].X, mids[k-1].Y, mids[k].X, mids[k].Y)
+			dc.Stroke()
+		}
+	}
+	dc.SetRGB(0, 0, 0) // Reset to black
+}
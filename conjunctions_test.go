@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeConjunctions(t *testing.T) {
+	tests := []struct {
+		name    string
+		planets map[string]*Planet
+		orb     float64
+		want    []Conjunction
+	}{
+		{
+			name: "opposite ends of a rashi",
+			planets: map[string]*Planet{
+				"moon": {Rashi: "taurus", Degrees: 0.5}, "jupiter": {Rashi: "taurus", Degrees: 29.5},
+			},
+		},
+		{
+			name: "across a rashi boundary",
+			planets: map[string]*Planet{
+				"sun": {Rashi: "aries", Degrees: 29.5}, "mercury": {Rashi: "taurus", Degrees: 0.5},
+			},
+		},
+		{
+			name: "chained within the orb",
+			planets: map[string]*Planet{
+				"venus": {Rashi: "aries", Degrees: 14.5}, "sun": {Rashi: "aries", Degrees: 10},
+				"mercury": {Rashi: "aries", Degrees: 12}, "mars": {Rashi: "aries", Degrees: 27},
+				"saturn": {Rashi: "libra", Degrees: 5}, "rahu": {Rashi: "libra", Degrees: 8},
+			},
+			want: []Conjunction{
+				{Rashi: 1, Planets: []string{"sun", "mercury", "venus"}, Spread: 4.5},
+				{Rashi: 7, Planets: []string{"saturn", "rahu"}, Spread: 3},
+			},
+		},
+		{
+			name: "custom orb",
+			planets: map[string]*Planet{
+				"sun": {Rashi: "aries", Degrees: 10}, "mercury": {Rashi: "aries", Degrees: 12},
+				"saturn": {Rashi: "libra", Degrees: 5}, "rahu": {Rashi: "libra", Degrees: 5.5},
+			},
+			orb:  1,
+			want: []Conjunction{{Rashi: 7, Planets: []string{"saturn", "rahu"}, Spread: 0.5}},
+		},
+		{
+			name: "without degrees",
+			planets: map[string]*Planet{
+				"sun": {Rashi: "aries"}, "moon": {Rashi: "aries"}, "mars": {Rashi: "aries", Degrees: 1},
+				"venus": {Rashi: "leo", Degrees: 2}, "saturn": {Rashi: "leo", Degrees: 3},
+			},
+			want: []Conjunction{{Rashi: 5, Planets: []string{"venus", "saturn"}, Spread: 1}},
+		},
+		{
+			name: "upagrahas and unknown rashis",
+			planets: map[string]*Planet{
+				"sun": {Rashi: "aries", Degrees: 10}, "mandi": {Rashi: "aries", Degrees: 10, IsUpagraha: true},
+				"moon": {Rashi: "", Degrees: 10}, "mars": {Rashi: "", Degrees: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		input := ChartInput{Planets: tt.planets, Options: ChartOptions{ConjunctionOrb: tt.orb}}
+		if got := ComputeConjunctions(input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ComputeConjunctions() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGroupConjunctions(t *testing.T) {
+	input := ChartInput{
+		Planets: map[string]*Planet{
+			"jupiter": {Rashi: "aries", Degrees: 20},
+			"mars":    {Rashi: "aries", Degrees: 11},
+			"moon":    {Rashi: "aries", Degrees: 3},
+			"sun":     {Rashi: "aries", Degrees: 10},
+			"venus":   {Rashi: "aries", Degrees: 21},
+		},
+		Options: ChartOptions{ShowConjunctions: true},
+	}
+	f := newLabelFormat(input.Options).withConjunctions(input)
+	var entries []planetEntry
+	for _, name := range sortedPlanetNames(input.Planets) {
+		entries = append(entries, f.entry(name, input.Planets[name]))
+	}
+	var got []string
+	for _, e := range f.groupConjunctions(entries) {
+		got = append(got, e.name)
+	}
+	want := []string{"jupiter", "venus", "sun", "mars", "moon"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupConjunctions order = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateChart_Conjunctions(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "aries", Degrees: 2},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "aries", Degrees: 10},
+				"mercury": {Rashi: "aries", Degrees: 12},
+				"venus":   {Rashi: "aries", Degrees: 14.5},
+				"mars":    {Rashi: "aries", Degrees: 27},
+				"moon":    {Rashi: "taurus", Degrees: 0.5},
+				"jupiter": {Rashi: "taurus", Degrees: 29.5},
+				"saturn":  {Rashi: "libra", Degrees: 5},
+				"rahu":    {Rashi: "libra", Degrees: 6},
+				"ketu":    {Rashi: "aries", Degrees: 6},
+			},
+			Options: ChartOptions{ShowDegrees: true, ShowConjunctions: true},
+		}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_conjunctions", data)
+		if overlaps := renderWithBoxes(t, input).overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+	}
+}
+
+func TestGenerateChart_ConjunctionOrbNegative(t *testing.T) {
+	input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{ConjunctionOrb: -1}}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("GenerateChart accepted a negative conjunction_orb")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// DashaPeriod is a row of the dasha table: the lord of a period, such as
+// "Venus", or "Ve-Sa" for a bhukti, and when the period runs
+type DashaPeriod struct {
+	Lord  string    `json:"lord"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// DefaultDashaDateFormat is the time layout of the dasha table's dates
+const DefaultDashaDateFormat = "2006-01-02"
+
+// dashaHighlight is the row fill of periods running at the reference time
+var dashaHighlight = color.NRGBA{R: 255, G: 236, B: 204, A: 255}
+
+// dashaHeader is the table's header row
+var dashaHeader = [3]string{"Dasha", "Start", "End"}
+
+// validateDashaTable checks that every period has a lord and ends after
+// it starts
+func validateDashaTable(rows []DashaPeriod) error {
+	for i, row := range rows {
+		if row.Lord == "" {
+			return fmt.Errorf("dasha_table: row %d: lord is required", i)
+		}
+		if !row.End.After(row.Start) {
+			return fmt.Errorf("dasha_table: row %d: end %s is not after start %s", i,
+				row.End.Format(time.RFC3339), row.Start.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// dashaTable is the panel of dasha periods right of the chart, its cells
+// already formatted
+type dashaTable struct {
+	cells   [][3]string // Header first
+	running []bool      // Whether each row, header included, runs at the reference time
+}
+
+// newDashaTable returns the table of the options' dasha periods, or nil when
+// there are none
+func newDashaTable(opts ChartOptions) *dashaTable {
+	if len(opts.DashaTable) == 0 {
+		return nil
+	}
+	format := opts.DashaDateFormat
+	if format == "" {
+		format = DefaultDashaDateFormat
+	}
+	t := &dashaTable{cells: [][3]string{dashaHeader}, running: []bool{false}}
+	for _, row := range opts.DashaTable {
+		t.cells = append(t.cells, [3]string{row.Lord, row.Start.Format(format), row.End.Format(format)})
+		ref := opts.DashaReference
+		t.running = append(t.running, !ref.IsZero() && !ref.Before(row.Start) && ref.Before(row.End))
+	}
+	return t
+}
+
+// Sizes of the dasha table, for a chart of defaultChartSize
+const (
+	dashaFontSize = 16
+	dashaRowScale = 1.7 // Row height in line heights
+	dashaCellPad  = 8   // Left and right of each cell's text
+	dashaMargin   = 40  // Right of the table, as the chart's padding
+	// dashaMaxColumn bounds each column's width, padding included; longer
+	// cells are cut short with "…"
+	dashaMaxColumn = 240
+)
+
+func (t *dashaTable) side() panelSide { return panelRight }
+
+// metrics returns the table's font size and row height, shrunk so that
+// every row fits between the top and bottom of the chart's grid, and the
+// widths of its columns at that size, each at most dashaMaxColumn
+func (t *dashaTable) metrics(frame chartFrame) (size, rowHeight float64, widths [3]float64) {
+	size = frame.px(dashaFontSize)
+	rowHeight = dashaRowScale * boldMetrics(size).lineHeight()
+	available := frame.height - 2*frame.px(40)
+	if need := float64(len(t.cells)) * rowHeight; need > available {
+		size *= available / need
+		rowHeight = available / float64(len(t.cells))
+	}
+	face := embeddedFace(matangiBold, size)
+	for _, row := range t.cells {
+		for i, cell := range row {
+			widths[i] = max(widths[i], float64(font.MeasureString(face, cell))/64+2*frame.px(dashaCellPad))
+		}
+	}
+	for i := range widths {
+		widths[i] = min(widths[i], frame.px(dashaMaxColumn))
+	}
+	return size, rowHeight, widths
+}
+
+func (t *dashaTable) extent(frame chartFrame) float64 {
+	_, _, widths := t.metrics(frame)
+	return widths[0] + widths[1] + widths[2] + frame.px(dashaMargin)
+}
+
+// draw draws the header and a row per period in aligned columns from the
+// top of the chart's grid, filling the rows running at the reference time.
+// Running rows and the header are bold. Cells too wide for their column are
+// cut short.
+func (t *dashaTable) draw(dc *gg.Context, frame chartFrame, left, _, _, _ float64, boxes *chartBoxes) {
+	size, rowHeight, widths := t.metrics(frame)
+	regular, bold := embeddedFace(matangiRegular, size), embeddedFace(matangiBold, size)
+	m := boldMetrics(size)
+	tableWidth := widths[0] + widths[1] + widths[2]
+	top := frame.y + frame.px(40)
+
+	for r, row := range t.cells {
+		rowTop := top + float64(r)*rowHeight
+		if t.running[r] {
+			dc.DrawRectangle(left, rowTop, tableWidth, rowHeight)
+			dc.SetColor(dashaHighlight)
+			dc.Fill()
+		}
+		face := regular
+		if r == 0 || t.running[r] {
+			face = bold
+		}
+		baseline := m.baseline(rowTop + rowHeight/2)
+		x := left
+		for i, cell := range row {
+			textX := x + frame.px(dashaCellPad)
+			cell = ellipsize(faceMeasurer{face}, cell, widths[i]-2*frame.px(dashaCellPad))
+			drawText(dc, face, textBlack, cell, textX, baseline, 0)
+			w := float64(font.MeasureString(face, cell)) / 64
+			boxes.add(textBox{text: cell, kind: labelDasha, left: textX, top: baseline - m.capHeight, right: textX + w, bottom: baseline + m.descent})
+			x += widths[i]
+		}
+	}
+
+	// Rule off the header
+	dc.SetRGB(0, 0, 0)
+	dc.SetLineWidth(frame.px(1))
+	dc.DrawLine(left, top+rowHeight, left+tableWidth, top+rowHeight)
+	dc.Stroke()
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dashaPeriods returns the mahadashas from Venus on, starting in 1990
+func dashaPeriods() []DashaPeriod {
+	lords := []struct {
+		name  string
+		years int
+	}{{"Venus", 20}, {"Sun", 6}, {"Moon", 10}, {"Mars", 7}, {"Rahu", 18}, {"Jupiter", 16}}
+	start := time.Date(1990, time.March, 14, 0, 0, 0, 0, time.UTC)
+	var periods []DashaPeriod
+	for _, l := range lords {
+		end := start.AddDate(l.years, 0, 0)
+		periods = append(periods, DashaPeriod{Lord: l.name, Start: start, End: end})
+		start = end
+	}
+	return periods
+}
+
+func TestGenerateChart_DashaTable(t *testing.T) {
+	input := strengthsInput(ChartTypeSouth)
+	input.Strengths = nil
+	input.Options.DashaTable = dashaPeriods()
+	input.Options.DashaReference = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	data, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_dasha_table", data)
+
+	if layout.Width <= defaultChartSize || layout.Height != defaultChartSize {
+		t.Errorf("canvas %dx%d, want wider than %d and as tall", layout.Width, layout.Height, defaultChartSize)
+	}
+	var cells []string
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelDasha) {
+			if l.Left < defaultChartSize {
+				t.Errorf("dasha cell %q drawn over the chart", l.Text)
+			}
+			cells = append(cells, l.Text)
+		}
+	}
+	if len(cells) != 21 || cells[0] != "Dasha" || cells[4] != "1990-03-14" {
+		t.Errorf("dasha cells = %q, want the header and 6 rows of lord, start and end", cells)
+	}
+}
+
+func TestDashaTable_Running(t *testing.T) {
+	opts := ChartOptions{DashaTable: dashaPeriods(), DashaDateFormat: "Jan 2006"}
+	if table := newDashaTable(opts); slices.Contains(table.running, true) {
+		t.Errorf("rows running without a reference time: %v", table.running)
+	}
+
+	// A period runs from its start up to, but not including, its end, so on
+	// the day the Sun period ends only the Moon period runs
+	opts.DashaReference = time.Date(2016, time.March, 14, 0, 0, 0, 0, time.UTC)
+	table := newDashaTable(opts)
+	if want := []bool{false, false, false, true, false, false, false}; !slices.Equal(table.running, want) {
+		t.Errorf("running = %v, want %v", table.running, want)
+	}
+	if got := table.cells[2][1]; got != "Mar 2010" {
+		t.Errorf("start of the Sun period = %q, want %q", got, "Mar 2010")
+	}
+}
+
+func TestGenerateChart_DashaTableFits(t *testing.T) {
+	// A long table shrinks to the height of the chart's grid
+	var periods []DashaPeriod
+	for range 8 {
+		periods = append(periods, dashaPeriods()...)
+	}
+	input := ChartInput{ChartType: ChartTypeNorth, Options: ChartOptions{DashaTable: periods}}
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelDasha) && (l.Top < 40 || l.Bottom > defaultChartSize-40) {
+			t.Fatalf("dasha cell %q at %v-%v, outside the grid's height", l.Text, l.Top, l.Bottom)
+		}
+	}
+}
+
+func TestGenerateChart_DashaTableLongCells(t *testing.T) {
+	periods := dashaPeriods()
+	periods[0].Lord = strings.Repeat("Venus", 200)
+	input := ChartInput{ChartType: ChartTypeNorth, Options: ChartOptions{DashaTable: periods, DashaDateFormat: strings.Repeat("Monday ", 100)}}
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	// Three columns at most dashaMaxColumn wide, and the margin
+	if widest := defaultChartSize + 3*dashaMaxColumn + dashaMargin; layout.Width > widest {
+		t.Errorf("canvas %dpx wide, want at most %d", layout.Width, widest)
+	}
+	for _, l := range layout.Labels {
+		if l.Kind != string(labelDasha) {
+			continue
+		}
+		if l.Right > float64(layout.Width) {
+			t.Errorf("dasha cell %q ends at %v, past the canvas", l.Text, l.Right)
+		}
+		if strings.HasPrefix(l.Text, "VenusVenus") && !strings.HasSuffix(l.Text, ellipsis) {
+			t.Errorf("long lord %q not cut short", l.Text)
+		}
+	}
+}
+
+func TestGenerateChart_DashaTableInvalid(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		row  DashaPeriod
+		want string
+	}{
+		{"no lord", DashaPeriod{Start: start, End: start.AddDate(1, 0, 0)}, "dasha_table: row 0: lord is required"},
+		{"reversed", DashaPeriod{Lord: "Moon", Start: start, End: start.AddDate(-1, 0, 0)}, "dasha_table: row 0: end 1999-01-01T00:00:00Z is not after start"},
+	}
+	for _, tt := range tests {
+		input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{DashaTable: []DashaPeriod{tt.row}}}
+		_, err := GenerateChart(input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/fogleman/gg"
+)
+
+// Colors of the debug overlay, picked to stand apart from anything a chart
+// draws
+var (
+	debugRegionFill    = color.NRGBA{R: 255, G: 0, B: 255, A: 40}
+	debugRegionOutline = color.NRGBA{R: 255, G: 0, B: 255, A: 200}
+	debugLabelOutline  = color.NRGBA{R: 0, G: 170, B: 255, A: 220}
+)
+
+// drawDebugOverlay draws over img the house regions and text boxes recorded
+// in boxes: each region tinted, outlined and labelled with its house counted
+// from lagna ("H1"), and each text box outlined
+func drawDebugOverlay(img image.Image, input ChartInput, boxes *chartBoxes) image.Image {
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	lagnaRashi := chartLagnaRashi(input)
+	dc := gg.NewContextForImage(img)
+
+	dc.SetLineWidth(frame.px(1.5))
+	for _, house := range boxes.houses {
+		for i, p := range house.polygon {
+			if i == 0 {
+				dc.MoveTo(p.X, p.Y)
+			} else {
+				dc.LineTo(p.X, p.Y)
+			}
+		}
+		dc.ClosePath()
+		dc.SetColor(debugRegionFill)
+		dc.FillPreserve()
+		dc.SetColor(debugRegionOutline)
+		dc.Stroke()
+	}
+	loadMatangiBold(dc, frame.px(16))
+	dc.SetColor(debugRegionOutline)
+	for _, house := range boxes.houses {
+		c := polygonCentroid(house.polygon)
+		dc.DrawStringAnchored("H"+strconv.Itoa(HouseFromLagna(house.rashi, lagnaRashi)), c.X, c.Y, 0.5, 0.5)
+	}
+
+	dc.SetColor(debugLabelOutline)
+	dc.SetLineWidth(1)
+	for _, b := range boxes.boxes {
+		dc.DrawRectangle(b.left, b.top, b.right-b.left, b.bottom-b.top)
+		dc.Stroke()
+	}
+	return dc.Image()
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateChart_Debug(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		plain, plainLayout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input.Options.Debug = true
+		data, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(plain, data) {
+			t.Errorf("%s: debug overlay was not drawn", chartType)
+		}
+		// The overlay shows the layout without changing it
+		if !reflect.DeepEqual(layout, plainLayout) {
+			t.Errorf("%s: debug mode changed the layout", chartType)
+		}
+		assertGolden(t, string(chartType)+"_debug", data)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"strconv"
+)
+
+// DegreeFormat controls how a planet's degrees within its sign are printed
+type DegreeFormat string
+
+const (
+	// DegreeFormatDegree prints whole degrees: "17°" (the default)
+	DegreeFormatDegree DegreeFormat = "degree"
+	// DegreeFormatDegreeMinute prints degrees and arc minutes: "17°32'"
+	DegreeFormatDegreeMinute DegreeFormat = "degree_minute"
+)
+
+// formatDegrees returns the degrees within a sign as text. Values are
+// truncated rather than rounded, so 17.99° is still in the 17th degree.
+func formatDegrees(degrees float64, format DegreeFormat) string {
+	// Count whole arc minutes, allowing for binary fractions such as 5.05*60 = 302.99…
+	minutes := int(math.Floor(degrees*60 + 1e-6))
+	if format == DegreeFormatDegreeMinute {
+		pad := ""
+		if minutes%60 < 10 {
+			pad = "0"
+		}
+		return strconv.Itoa(minutes/60) + "°" + pad + strconv.Itoa(minutes%60) + "'"
+	}
+	return strconv.Itoa(minutes/60) + "°"
+}
+
+// validDegrees reports whether degrees lie within a single sign, [0, 30)
+func validDegrees(degrees float64) bool {
+	return degrees >= 0 && degrees < 30
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+)
+
+func TestFormatDegrees(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		format  DegreeFormat
+		want    string
+	}{
+		{17.54, DegreeFormatDegree, "17°"},
+		{17.99, DegreeFormatDegree, "17°"},
+		{0, DegreeFormatDegree, "0°"},
+		{17.54, DegreeFormatDegreeMinute, "17°32'"},
+		{5.05, DegreeFormatDegreeMinute, "5°03'"},
+		{29.999, DegreeFormatDegreeMinute, "29°59'"},
+	}
+	for _, tt := range tests {
+		if got := formatDegrees(tt.degrees, tt.format); got != tt.want {
+			t.Errorf("formatDegrees(%v, %q) = %q, want %q", tt.degrees, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestLabelFormat_Degrees(t *testing.T) {
+	f := newLabelFormat(ChartOptions{ShowDegrees: true})
+	if got := f.format("jupiter", &Planet{Rashi: "cancer", Degrees: 17.54, IsRetrograde: true}); got != "JuR 17°" {
+		t.Errorf("format = %q, want %q", got, "JuR 17°")
+	}
+	if e, _ := f.lagnaEntry(&Planet{Rashi: "leo", Degrees: 3.2}); e.label != "Asc 3°" {
+		t.Errorf("lagnaEntry label = %q, want %q", e.label, "Asc 3°")
+	}
+
+	f = newLabelFormat(ChartOptions{ShowDegrees: true, DegreeFormat: DegreeFormatDegreeMinute})
+	if got := f.format("mars", &Planet{Rashi: "aries", Degrees: 17.54}); got != "Ma 17°32'" {
+		t.Errorf("format = %q, want %q", got, "Ma 17°32'")
+	}
+
+	// Degrees are only printed when asked for
+	if got := FormatPlanetLabel("mars", &Planet{Rashi: "aries", Degrees: 17.54}); got != "Ma" {
+		t.Errorf("FormatPlanetLabel = %q, want %q", got, "Ma")
+	}
+}
+
+func TestLabelFormat_FontSize(t *testing.T) {
+	plain := newLabelFormat(ChartOptions{})
+	degrees := newLabelFormat(ChartOptions{ShowDegrees: true})
+	if got := plain.fontSize(22, 6); got != 22 {
+		t.Errorf("fontSize without degrees = %v, want 22", got)
+	}
+	if got := degrees.fontSize(22, crowdedHouseSize-1); got != 22 {
+		t.Errorf("fontSize for %d labels = %v, want 22", crowdedHouseSize-1, got)
+	}
+	if got := degrees.fontSize(22, crowdedHouseSize); got >= 22 {
+		t.Errorf("fontSize for %d labels = %v, want less than 22", crowdedHouseSize, got)
+	}
+}
+
+func TestFitLabelX(t *testing.T) {
+	tests := []struct {
+		name             string
+		w, x, ax         float64
+		xmin, xmax, want float64
+	}{
+		{"fits", 40, 100, 1, 50, 200, 100},
+		{"past left edge", 80, 100, 1, 50, 200, 130},
+		{"past right edge", 80, 150, 0, 50, 200, 120},
+		{"wider than range", 200, 100, 0.5, 50, 200, 150},
+	}
+	for _, tt := range tests {
+		if got := fitLabelX(tt.w, tt.x, tt.ax, tt.xmin, tt.xmax); got != tt.want {
+			t.Errorf("%s: fitLabelX = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_DegreesValidation(t *testing.T) {
+	for _, degrees := range []float64{-0.5, 30, 45, math.NaN()} {
+		input := ChartInput{
+			ChartType: ChartTypeSouth,
+			Planets:   map[string]*Planet{"sun": {Rashi: "aries", Degrees: degrees}},
+		}
+		if _, err := GenerateChart(input); err == nil {
+			t.Errorf("expected an error for degrees %v", degrees)
+		}
+	}
+
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "aries", Degrees: 31},
+	}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for lagna degrees 31")
+	}
+
+	input = ChartInput{
+		ChartType: ChartTypeSouth,
+		Options:   ChartOptions{ShowDegrees: true, DegreeFormat: "radians"},
+	}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for an unsupported degree_format")
+	}
+}
+
+func TestGenerateChart_ShowDegrees(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo", Degrees: 12.25},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "leo", Degrees: 17.54},
+				"moon":    {Rashi: "taurus", Degrees: 3.5},
+				"jupiter": {Rashi: "cancer", Degrees: 29.9, IsRetrograde: true},
+				"saturn":  {Rashi: "libra", Degrees: 20.1, IsExalted: true},
+			},
+			Options: ChartOptions{ShowDegrees: true, DegreeFormat: DegreeFormatDegreeMinute},
+		}
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_degrees", imageData)
+	}
+}
+
+func TestGenerateChart_ShowDegreesCrowdedHouse(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "aries", Degrees: 1.5},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "aries", Degrees: 10.2},
+				"mercury": {Rashi: "aries", Degrees: 22.75, IsRetrograde: true, IsCombust: true},
+				"venus":   {Rashi: "aries", Degrees: 28.1},
+				"mars":    {Rashi: "aries", Degrees: 5.4},
+			},
+			Options: ChartOptions{ShowDegrees: true, DegreeFormat: DegreeFormatDegreeMinute},
+		}
+		if _, err := GenerateChart(input); err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"slices"
+	"strings"
+)
+
+// Dignity is the strength of a planet based on the rashi it occupies
+type Dignity string
+
+const (
+	DignityNeutral      Dignity = "neutral"
+	DignityExalted      Dignity = "exalted"
+	DignityDebilitated  Dignity = "debilitated"
+	DignityOwnSign      Dignity = "own_sign"
+	DignityMoolatrikona Dignity = "moolatrikona"
+)
+
+// exaltationRashi maps each graha to its exaltation rashi number.
+// Rahu and Ketu follow the common convention of Taurus and Scorpio.
+var exaltationRashi = map[string]int{
+	"sun":     1,  // Aries
+	"moon":    2,  // Taurus
+	"mars":    10, // Capricorn
+	"mercury": 6,  // Virgo
+	"jupiter": 4,  // Cancer
+	"venus":   12, // Pisces
+	"saturn":  7,  // Libra
+	"rahu":    2,  // Taurus
+	"ketu":    8,  // Scorpio
+}
+
+// MoolatrikonaRange is the portion of a rashi that is a planet's
+// moolatrikona, from From up to but not including To degrees
+type MoolatrikonaRange struct {
+	Rashi    int
+	From, To float64
+}
+
+// Contains reports whether degrees within the range's rashi fall in it
+func (r MoolatrikonaRange) Contains(degrees float64) bool {
+	return degrees >= r.From && degrees < r.To
+}
+
+// moolatrikonaRanges maps each of the seven grahas to its moolatrikona
+// portion, following the Brihat Parashara Hora Shastra
+var moolatrikonaRanges = map[string]MoolatrikonaRange{
+	"sun":     {5, 0, 20},  // Leo
+	"moon":    {2, 3, 30},  // Taurus, after its exaltation
+	"mars":    {1, 0, 12},  // Aries
+	"mercury": {6, 15, 20}, // Virgo, after its exaltation
+	"jupiter": {9, 0, 10},  // Sagittarius
+	"venus":   {7, 0, 15},  // Libra
+	"saturn":  {11, 0, 20}, // Aquarius
+}
+
+// ownRashis maps each of the seven grahas to the rashi numbers it rules
+var ownRashis = map[string][]int{
+	"sun":     {5},
+	"moon":    {4},
+	"mars":    {1, 8},
+	"mercury": {3, 6},
+	"jupiter": {9, 12},
+	"venus":   {2, 7},
+	"saturn":  {10, 11},
+}
+
+// SignLord returns the classical lord of a rashi number (1=Aries, 2=Taurus,
+// ...) as a lowercase planet name, such as "mars" for Aries and Scorpio, or
+// an empty string for a number outside 1-12
+func SignLord(rashiNumber int) string {
+	for planet, rashis := range ownRashis {
+		if slices.Contains(rashis, rashiNumber) {
+			return planet
+		}
+	}
+	return ""
+}
+
+// GetDignity returns the dignity of a planet in a rashi using the classical
+// tables. Exaltation and debilitation (the seventh rashi from exaltation) take
+// precedence, so the Moon in Taurus is exalted rather than moolatrikona. Without
+// degrees the whole moolatrikona rashi counts as moolatrikona. Rahu and Ketu
+// are exalted in Taurus and Scorpio respectively and have no own rashi.
+func GetDignity(planetName, rashi string) Dignity {
+	planet := strings.ToLower(planetName)
+	rashiNum := RashiToNumber(rashi)
+	if rashiNum == 0 {
+		return DignityNeutral
+	}
+
+	if exalted, ok := exaltationRashi[planet]; ok {
+		if rashiNum == exalted {
+			return DignityExalted
+		}
+		if rashiNum == (exalted+5)%12+1 {
+			return DignityDebilitated
+		}
+	}
+	if mt, ok := moolatrikonaRanges[planet]; ok && rashiNum == mt.Rashi {
+		return DignityMoolatrikona
+	}
+	for _, own := range ownRashis[planet] {
+		if rashiNum == own {
+			return DignityOwnSign
+		}
+	}
+	return DignityNeutral
+}
+
+// GetMoolatrikona returns the moolatrikona portion of one of the seven
+// grahas; ok is false for other planets
+func GetMoolatrikona(planetName string) (r MoolatrikonaRange, ok bool) {
+	r, ok = moolatrikonaRanges[strings.ToLower(planetName)]
+	return r, ok
+}
+
+// OwnRashis returns the rashi numbers one of the seven grahas rules, nil
+// for other planets
+func OwnRashis(planetName string) []int {
+	return append([]int(nil), ownRashis[strings.ToLower(planetName)]...)
+}
+
+// IsOwnSign reports whether a planet is in a rashi it rules
+func IsOwnSign(planetName, rashi string) bool {
+	rashiNum := RashiToNumber(rashi)
+	for _, own := range ownRashis[strings.ToLower(planetName)] {
+		if rashiNum == own {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMoolatrikona reports whether a planet at degrees within a rashi is in
+// its moolatrikona portion. Unlike GetDignity it needs the degrees: the Sun
+// at 25° Leo is in its own sign, not moolatrikona.
+func IsMoolatrikona(planetName, rashi string, degrees float64) bool {
+	r, ok := GetMoolatrikona(planetName)
+	return ok && RashiToNumber(rashi) == r.Rashi && r.Contains(degrees)
+}
+
+// digbalaHouse maps each graha to the house (counted from lagna) where it
+// gains directional strength
+var digbalaHouse = map[string]int{
+	"jupiter": 1,
+	"mercury": 1,
+	"moon":    4,
+	"venus":   4,
+	"saturn":  7,
+	"sun":     10,
+	"mars":    10,
+}
+
+// HasDigbala reports whether a planet has directional strength (digbala) in
+// the given house counted from lagna: Jupiter and Mercury in the 1st, Moon and
+// Venus in the 4th, Saturn in the 7th, Sun and Mars in the 10th
+func HasDigbala(planet string, houseFromLagna int) bool {
+	house, ok := digbalaHouse[strings.ToLower(planet)]
+	return ok && house == houseFromLagna
+}
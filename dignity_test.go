@@ -0,0 +1,250 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import "testing"
+
+func TestGetDignity_ExaltationDebilitation(t *testing.T) {
+	tests := []struct {
+		planet      string
+		exalted     string
+		debilitated string
+	}{
+		{"sun", "aries", "libra"},
+		{"moon", "taurus", "scorpio"},
+		{"mars", "capricorn", "cancer"},
+		{"mercury", "virgo", "pisces"},
+		{"jupiter", "cancer", "capricorn"},
+		{"venus", "pisces", "virgo"},
+		{"saturn", "libra", "aries"},
+		{"rahu", "taurus", "scorpio"},
+		{"ketu", "scorpio", "taurus"},
+	}
+	for _, tt := range tests {
+		if got := GetDignity(tt.planet, tt.exalted); got != DignityExalted {
+			t.Errorf("GetDignity(%q, %q) = %q, want exalted", tt.planet, tt.exalted, got)
+		}
+		if got := GetDignity(tt.planet, tt.debilitated); got != DignityDebilitated {
+			t.Errorf("GetDignity(%q, %q) = %q, want debilitated", tt.planet, tt.debilitated, got)
+		}
+	}
+}
+
+func TestGetDignity_OwnAndMoolatrikona(t *testing.T) {
+	tests := []struct {
+		planet, rashi string
+		want          Dignity
+	}{
+		{"sun", "leo", DignityMoolatrikona},
+		{"moon", "cancer", DignityOwnSign},
+		{"mars", "aries", DignityMoolatrikona},
+		{"mars", "scorpio", DignityOwnSign},
+		{"mercury", "gemini", DignityOwnSign},
+		{"jupiter", "sagittarius", DignityMoolatrikona},
+		{"jupiter", "pisces", DignityOwnSign},
+		{"venus", "libra", DignityMoolatrikona},
+		{"venus", "taurus", DignityOwnSign},
+		{"saturn", "aquarius", DignityMoolatrikona},
+		{"saturn", "capricorn", DignityOwnSign},
+		{"Saturn", "Capricorn", DignityOwnSign},
+		{"sun", "gemini", DignityNeutral},
+		{"rahu", "aquarius", DignityNeutral},
+		{"mandi", "aries", DignityNeutral},
+		{"sun", "unknown", DignityNeutral},
+	}
+	for _, tt := range tests {
+		if got := GetDignity(tt.planet, tt.rashi); got != tt.want {
+			t.Errorf("GetDignity(%q, %q) = %q, want %q", tt.planet, tt.rashi, got, tt.want)
+		}
+	}
+}
+
+func TestLabelFormat_AutoDignity(t *testing.T) {
+	f := newLabelFormat(ChartOptions{AutoDignity: true})
+	tests := []struct {
+		name   string
+		planet *Planet
+		want   string
+	}{
+		{"sun", &Planet{Rashi: "aries"}, "Su↑"},
+		{"saturn", &Planet{Rashi: "aries", IsRetrograde: true}, "Sa↓R"},
+		{"jupiter", &Planet{Rashi: "gemini"}, "Ju"},
+		{"jupiter", &Planet{Rashi: "gemini", IsExalted: true}, "Ju↑"}, // Explicit flags still apply
+	}
+	for _, tt := range tests {
+		if got := f.format(tt.name, tt.planet); got != tt.want {
+			t.Errorf("format(%q, %+v) = %q, want %q", tt.name, tt.planet, got, tt.want)
+		}
+	}
+
+	// Without the option only explicit flags produce markers
+	if got := newLabelFormat(ChartOptions{}).format("sun", &Planet{Rashi: "aries"}); got != "Su" {
+		t.Errorf("format without AutoDignity = %q, want %q", got, "Su")
+	}
+}
+
+func TestHasDigbala(t *testing.T) {
+	strong := map[string]int{
+		"jupiter": 1, "mercury": 1,
+		"moon": 4, "venus": 4,
+		"saturn": 7,
+		"sun":    10, "mars": 10,
+	}
+	for _, planet := range []string{"sun", "moon", "mars", "mercury", "jupiter", "venus", "saturn", "rahu", "ketu", "mandi"} {
+		for house := 1; house <= 12; house++ {
+			want := strong[planet] == house
+			if got := HasDigbala(planet, house); got != want {
+				t.Errorf("HasDigbala(%q, %d) = %v, want %v", planet, house, got, want)
+			}
+		}
+	}
+	if !HasDigbala("Jupiter", 1) {
+		t.Error("HasDigbala should ignore case")
+	}
+}
+
+func TestLabelFormat_Digbala(t *testing.T) {
+	f := newLabelFormat(ChartOptions{ShowDigbala: true}).withLagna(RashiToNumber("aries"))
+	tests := []struct {
+		name   string
+		planet *Planet
+		want   string
+	}{
+		{"jupiter", &Planet{Rashi: "aries"}, "Ju•"},                        // 1st house
+		{"saturn", &Planet{Rashi: "libra", IsRetrograde: true}, "Sa•R"},    // 7th house
+		{"sun", &Planet{Rashi: "capricorn"}, "Su•"},                        // 10th house
+		{"sun", &Planet{Rashi: "aries"}, "Su"},                             // 1st house
+		{"moon", &Planet{Rashi: "cancer", Display: "Chandra"}, "Chandra•"}, // 4th house
+	}
+	for _, tt := range tests {
+		if got := f.format(tt.name, tt.planet); got != tt.want {
+			t.Errorf("format(%q, %+v) = %q, want %q", tt.name, tt.planet, got, tt.want)
+		}
+	}
+
+	// Without a lagna there is no house to judge digbala from
+	noLagna := newLabelFormat(ChartOptions{ShowDigbala: true})
+	if got := noLagna.format("jupiter", &Planet{Rashi: "aries"}); got != "Ju" {
+		t.Errorf("format without lagna = %q, want %q", got, "Ju")
+	}
+}
+
+func TestIsMoolatrikona(t *testing.T) {
+	tests := []struct {
+		planet, rashi string
+		degrees       float64
+		want          bool
+	}{
+		{"sun", "leo", 0, true},
+		{"sun", "leo", 19.9, true},
+		{"sun", "leo", 20, false},
+		{"moon", "taurus", 2.5, false}, // Exaltation
+		{"moon", "taurus", 3, true},
+		{"mars", "aries", 11.9, true},
+		{"mars", "aries", 12, false},
+		{"mercury", "virgo", 14, false},
+		{"mercury", "virgo", 17, true},
+		{"mercury", "virgo", 21, false},
+		{"jupiter", "sagittarius", 9, true},
+		{"jupiter", "sagittarius", 10, false},
+		{"venus", "libra", 14, true},
+		{"venus", "libra", 16, false},
+		{"Saturn", "Aquarius", 19, true},
+		{"saturn", "capricorn", 10, false},
+		{"rahu", "taurus", 10, false},
+	}
+	for _, tt := range tests {
+		if got := IsMoolatrikona(tt.planet, tt.rashi, tt.degrees); got != tt.want {
+			t.Errorf("IsMoolatrikona(%q, %q, %v) = %v, want %v", tt.planet, tt.rashi, tt.degrees, got, tt.want)
+		}
+	}
+}
+
+func TestIsOwnSign(t *testing.T) {
+	for planet, rashis := range ownRashis {
+		for rashiNum := 1; rashiNum <= 12; rashiNum++ {
+			want := false
+			for _, own := range rashis {
+				want = want || own == rashiNum
+			}
+			if got := IsOwnSign(planet, NumberToRashi(rashiNum)); got != want {
+				t.Errorf("IsOwnSign(%q, %q) = %v, want %v", planet, NumberToRashi(rashiNum), got, want)
+			}
+		}
+	}
+	if IsOwnSign("rahu", "aquarius") || IsOwnSign("mars", "") {
+		t.Error("IsOwnSign should be false for the nodes and unknown rashis")
+	}
+
+	// The exported table is a copy
+	own := OwnRashis("Mars")
+	own[0] = 5
+	if OwnRashis("mars")[0] != 1 {
+		t.Error("OwnRashis returned the table itself")
+	}
+}
+
+func TestLabelFormat_OwnSign(t *testing.T) {
+	f := newLabelFormat(ChartOptions{ShowOwnSign: true})
+	tests := []struct {
+		name   string
+		planet *Planet
+		want   string
+	}{
+		{"sun", &Planet{Rashi: "leo", Degrees: 10}, "˚Su"}, // Moolatrikona
+		{"sun", &Planet{Rashi: "leo", Degrees: 25}, "·Su"}, // Own sign past the moolatrikona
+		{"sun", &Planet{Rashi: "leo"}, "·Su"},              // Without degrees only own sign
+		{"saturn", &Planet{Rashi: "capricorn", IsRetrograde: true}, "·SaR"},
+		{"moon", &Planet{Rashi: "taurus", Degrees: 10}, "˚Mo"}, // Moolatrikona, not own sign
+		{"moon", &Planet{Rashi: "taurus", Degrees: 1}, "Mo"},   // Exaltation
+		{"jupiter", &Planet{Rashi: "gemini"}, "Ju"},
+	}
+	for _, tt := range tests {
+		if got := f.format(tt.name, tt.planet); got != tt.want {
+			t.Errorf("format(%q, %+v) = %q, want %q", tt.name, tt.planet, got, tt.want)
+		}
+	}
+
+	custom := newLabelFormat(ChartOptions{ShowOwnSign: true, OwnSignMarker: "+", MoolatrikonaMarker: "*"})
+	if got := custom.format("mars", &Planet{Rashi: "aries", Degrees: 5}); got != "*Ma" {
+		t.Errorf("format with custom moolatrikona marker = %q, want %q", got, "*Ma")
+	}
+	if got := newLabelFormat(ChartOptions{}).format("sun", &Planet{Rashi: "leo", Degrees: 10}); got != "Su" {
+		t.Errorf("format without ShowOwnSign = %q, want %q", got, "Su")
+	}
+}
+
+func TestGenerateChart_OwnSign(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "gemini"},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "leo", Degrees: 12},
+				"mercury": {Rashi: "gemini", Degrees: 3},
+				"saturn":  {Rashi: "aquarius", Degrees: 25},
+				"jupiter": {Rashi: "sagittarius", Degrees: 4},
+				"moon":    {Rashi: "aries", Degrees: 7},
+			},
+			Options: ChartOptions{ShowOwnSign: true},
+		}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_own_sign", data)
+	}
+}
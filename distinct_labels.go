@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// subscriptDigits replace the digits of the numbers told apart labels end in
+var subscriptDigits = strings.NewReplacer("0", "₀", "1", "₁", "2", "₂", "3", "₃", "4", "₄",
+	"5", "₅", "6", "₆", "7", "₇", "8", "₈", "9", "₉")
+
+// sharedLabel is a name planets in one rashi share
+type sharedLabel struct {
+	rashi int
+	label string
+}
+
+// sharedLabels returns the planets of input by rashi, and so by house, and
+// name in loc and mode, for the names more than one planet of a rashi share.
+// Each group is in alphabetical order of the planets.
+func sharedLabels(input ChartInput, loc *chartLocale, mode DisplayMode) map[sharedLabel][]string {
+	groups := map[sharedLabel][]string{}
+	for _, name := range sortedPlanetNames(input.Planets) {
+		p := input.Planets[name]
+		if p == nil || RashiToNumber(p.Rashi) == 0 {
+			continue
+		}
+		if label := loc.displayName(name, p, mode); label != "" {
+			key := sharedLabel{RashiToNumber(p.Rashi), label}
+			groups[key] = append(groups[key], name)
+		}
+	}
+	for key, names := range groups {
+		if len(names) < 2 {
+			delete(groups, key)
+		}
+	}
+	return groups
+}
+
+// distinctNames returns the names to draw the planets of input sharing a
+// name in their house by: the name followed by a subscript number, counting
+// the planets that share it in alphabetical order ("Mn₁", "Mn₂"). It is nil
+// when every name is distinct.
+func distinctNames(input ChartInput, loc *chartLocale, mode DisplayMode) map[string]string {
+	var names map[string]string
+	for key, planets := range sharedLabels(input, loc, mode) {
+		if names == nil {
+			names = map[string]string{}
+		}
+		for i, name := range planets {
+			names[name] = key.label + subscriptDigits.Replace(strconv.Itoa(i+1))
+		}
+	}
+	return names
+}
+
+// withDistinctNames returns the format telling apart the planets sharing a
+// name in their house
+func (f labelFormat) withDistinctNames(input ChartInput) labelFormat {
+	f.renamed = distinctNames(input, f.locale, f.mode)
+	return f
+}
+
+// validateDistinctLabels rejects charts with strict_labels whose planets
+// share a name in their house
+func validateDistinctLabels(input ChartInput) error {
+	if !input.Options.StrictLabels {
+		return nil
+	}
+	var errs []string
+	for key, planets := range sharedLabels(input, localeFor(input.Options), input.Options.DisplayMode) {
+		errs = append(errs, fmt.Sprintf("planets %s share the label %q in %s", strings.Join(planets, ", "), key.label, NumberToRashi(key.rashi)))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.Sort(errs)
+	return fmt.Errorf("strict_labels: %s", strings.Join(errs, "; "))
+}
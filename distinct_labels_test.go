@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDistinctNames(t *testing.T) {
+	if err := RegisterPlanetAbbreviation("test_clash", "Su"); err != nil {
+		t.Fatal(err)
+	}
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets: map[string]*Planet{
+			"sun":        {Rashi: "aries"},
+			"test_clash": {Rashi: "aries", IsSpecialLagna: true},
+			"mandi":      {Rashi: "taurus", IsUpagraha: true},
+			"moon":       {Rashi: "taurus", Display: "Mn"},
+			"maandi":     {Rashi: "taurus", Display: "Mn", Category: PointCustom},
+			"gulika":     {Rashi: "gemini", IsUpagraha: true},
+			"jupiter":    {Rashi: "cancer", Display: "Gu"},
+		},
+	}
+	want := map[string]string{
+		"sun": "Su₁", "test_clash": "Su₂",
+		"maandi": "Mn₁", "mandi": "Mn₂", "moon": "Mn₃",
+	}
+	got := distinctNames(input, localeFor(input.Options), input.Options.DisplayMode)
+	if !maps.Equal(got, want) {
+		t.Errorf("distinctNames = %v, want %v", got, want)
+	}
+	if got := distinctNames(crowdedHouseInput(ChartTypeSouth), localeFor(ChartOptions{}), ""); got != nil {
+		t.Errorf("distinctNames of distinct labels = %v, want nil", got)
+	}
+
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input.ChartType = chartType
+		labels := planetLabels(t, input)
+		for _, name := range want {
+			if !slices.Contains(labels, name) {
+				t.Errorf("%s: labels %q, want %q", chartType, labels, name)
+			}
+		}
+		if slices.Contains(labels, "Mn") || slices.Contains(labels, "Su") {
+			t.Errorf("%s: labels %q, want no shared label", chartType, labels)
+		}
+
+		result, err := GenerateChartResult(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantWarning := `planet mandi: label "Mn" shared in its house, drawn as "Mn₂"`
+		if !slices.Contains(result.Warnings, wantWarning) {
+			t.Errorf("%s: warnings %q, want %q", chartType, result.Warnings, wantWarning)
+		}
+	}
+}
+
+func TestGenerateChart_StrictLabels(t *testing.T) {
+	input := crowdedHouseInput(ChartTypeSouth)
+	input.Options.StrictLabels = true
+	if _, err := GenerateChart(input); err != nil {
+		t.Fatalf("distinct labels: %v", err)
+	}
+
+	input.Planets["maandi"] = &Planet{Rashi: "aries", Display: "Mn"}
+	_, err := GenerateChart(input)
+	if err == nil || !strings.Contains(err.Error(), `planets maandi, mandi share the label "Mn" in aries`) {
+		t.Errorf("shared label: err = %v", err)
+	}
+
+	// Full names only collide when they are spelled the same
+	input.Options.DisplayMode = DisplayModeFullName
+	input.Planets["maandi"] = &Planet{Rashi: "aries", Display: "Mandi"}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("shared full name: no error")
+	}
+	input.Planets["maandi"].Display = "Maandi"
+	if _, err := GenerateChart(input); err != nil {
+		t.Errorf("distinct full names: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PositionProvider supplies the positions a chart is built from, usually by
+// querying an ephemeris. The library bundles no ephemeris; implement this
+// over the one you use and pass it to BuildChartInput.
+type PositionProvider interface {
+	// GetPositions returns the sidereal longitudes, in degrees, of the
+	// planets at t keyed by name ("sun", "moon", "rahu", ...), and the
+	// longitude of the ascendant at latitude lat and longitude lon
+	GetPositions(t time.Time, lat, lon float64) (planets map[string]float64, ascendant float64, err error)
+}
+
+// SpeedProvider is implemented by PositionProviders that also know how fast
+// the planets move. BuildChartInput uses it to flag retrograde planets.
+type SpeedProvider interface {
+	// GetSpeeds returns the daily motion of the planets at t in degrees per
+	// day, negative while retrograde, keyed like GetPositions
+	GetSpeeds(t time.Time, lat, lon float64) (map[string]float64, error)
+}
+
+// BuildChartInput asks provider for the positions at a birth moment and place
+// and returns a chart input of chartType holding them: each planet and the
+// lagna in its rashi, with its degrees, nakshatra and pada. When provider is
+// also a SpeedProvider, planets moving backwards are marked retrograde. Rahu
+// and Ketu always move backwards, so they are given their speed but not
+// marked.
+func BuildChartInput(provider PositionProvider, t time.Time, lat, lon float64, chartType ChartType) (ChartInput, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 || math.IsNaN(lat) || math.IsNaN(lon) {
+		return ChartInput{}, fmt.Errorf("location %v, %v out of range", lat, lon)
+	}
+	longitudes, ascendant, err := provider.GetPositions(t, lat, lon)
+	if err != nil {
+		return ChartInput{}, fmt.Errorf("getting positions: %w", err)
+	}
+	var speeds map[string]float64
+	if sp, ok := provider.(SpeedProvider); ok {
+		if speeds, err = sp.GetSpeeds(t, lat, lon); err != nil {
+			return ChartInput{}, fmt.Errorf("getting speeds: %w", err)
+		}
+	}
+
+	input := ChartInput{ChartType: chartType, Planets: make(map[string]*Planet, len(longitudes))}
+	if input.Lagna, err = planetAt(ascendant); err != nil {
+		return ChartInput{}, fmt.Errorf("ascendant: %w", err)
+	}
+	for name, longitude := range longitudes {
+		planet, err := planetAt(longitude)
+		if err != nil {
+			return ChartInput{}, fmt.Errorf("planet %s: %w", name, err)
+		}
+		key := strings.ToLower(name)
+		if speed, ok := speeds[name]; ok {
+			planet.SpeedDegPerDay = speed
+			planet.IsRetrograde = speed < 0 && !isNode(key)
+		}
+		input.Planets[key] = planet
+	}
+	return input, nil
+}
+
+// planetAt returns a planet placed at a sidereal longitude in degrees.
+// Longitudes outside 0-360 are wrapped around the zodiac. Degrees of 0 mean
+// none were given, so a planet at the very start of a rashi is placed the
+// smallest step past it, which still prints as 0°.
+func planetAt(longitude float64) (*Planet, error) {
+	if math.IsNaN(longitude) || math.IsInf(longitude, 0) {
+		return nil, fmt.Errorf("longitude %v is not a number of degrees", longitude)
+	}
+	lon := math.Mod(longitude, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	rashiNum := min(int(lon/30)+1, 12) // Guard against rounding just below 360
+	degrees := min(lon-float64(rashiNum-1)*30, math.Nextafter(30, 0))
+	if degrees == 0 {
+		degrees = math.SmallestNonzeroFloat64
+	}
+	nakshatra, pada := NakshatraFromLongitude(lon)
+	starLord, subLord := ComputeKPLords(lon)
+	return &Planet{Rashi: NumberToRashi(rashiNum), Degrees: degrees, Nakshatra: nakshatra, Pada: pada,
+		StarLord: starLord, SubLord: subLord}, nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeProvider returns fixed positions, recording what it was asked for
+type fakeProvider struct {
+	planets   map[string]float64
+	ascendant float64
+	err       error
+
+	t        time.Time
+	lat, lon float64
+}
+
+func (p *fakeProvider) GetPositions(t time.Time, lat, lon float64) (map[string]float64, float64, error) {
+	p.t, p.lat, p.lon = t, lat, lon
+	return p.planets, p.ascendant, p.err
+}
+
+// fakeSpeedProvider is a fakeProvider that also knows the planets' speeds
+type fakeSpeedProvider struct {
+	fakeProvider
+	speeds map[string]float64
+}
+
+func (p *fakeSpeedProvider) GetSpeeds(time.Time, float64, float64) (map[string]float64, error) {
+	return p.speeds, nil
+}
+
+func TestBuildChartInput(t *testing.T) {
+	birth := time.Date(1990, 4, 15, 6, 30, 0, 0, time.UTC)
+	provider := &fakeProvider{
+		planets: map[string]float64{
+			"sun":    0.5,   // Aries 0°30'
+			"moon":   45,    // Taurus 15°
+			"Saturn": 295.2, // Capricorn 25.2°
+			"rahu":   -5,    // Wraps to Pisces 25°
+			"ketu":   530,   // Wraps to Virgo 20°
+		},
+		ascendant: 125, // Leo 5°
+	}
+	input, err := BuildChartInput(provider, birth, 12.97, 77.59, ChartTypeSouth)
+	if err != nil {
+		t.Fatalf("BuildChartInput: %v", err)
+	}
+	if !provider.t.Equal(birth) || provider.lat != 12.97 || provider.lon != 77.59 {
+		t.Errorf("Provider asked for %v at %v, %v", provider.t, provider.lat, provider.lon)
+	}
+	if input.ChartType != ChartTypeSouth {
+		t.Errorf("Chart type %q, want south", input.ChartType)
+	}
+	if input.Lagna.Rashi != "leo" || math.Abs(input.Lagna.Degrees-5) > 1e-9 {
+		t.Errorf("Lagna %+v, want leo at 5°", input.Lagna)
+	}
+
+	want := map[string]struct {
+		rashi     string
+		degrees   float64
+		nakshatra string
+		pada      int
+	}{
+		"sun":    {"aries", 0.5, "Ashwini", 1},
+		"moon":   {"taurus", 15, "Rohini", 2},
+		"saturn": {"capricorn", 25.2, "Dhanishta", 1},
+		"rahu":   {"pisces", 25, "Revati", 3},
+		"ketu":   {"virgo", 20, "Hasta", 4},
+	}
+	if len(input.Planets) != len(want) {
+		t.Errorf("Got %d planets, want %d", len(input.Planets), len(want))
+	}
+	for name, w := range want {
+		p := input.Planets[name]
+		if p == nil {
+			t.Errorf("Missing planet %s", name)
+			continue
+		}
+		if p.Rashi != w.rashi || math.Abs(p.Degrees-w.degrees) > 1e-9 || p.Nakshatra != w.nakshatra || p.Pada != w.pada {
+			t.Errorf("%s = %+v, want %s at %v° in %s-%d", name, p, w.rashi, w.degrees, w.nakshatra, w.pada)
+		}
+		if p.IsRetrograde || p.SpeedDegPerDay != 0 {
+			t.Errorf("%s has motion %+v without a SpeedProvider", name, p)
+		}
+	}
+	if err := validateInput(input); err != nil {
+		t.Errorf("Built input does not validate: %v", err)
+	}
+}
+
+func TestBuildChartInput_Speeds(t *testing.T) {
+	provider := &fakeSpeedProvider{
+		fakeProvider: fakeProvider{planets: map[string]float64{"mars": 100, "venus": 200, "rahu": 300}},
+		speeds:       map[string]float64{"mars": -0.3, "venus": 1.2, "rahu": -0.05},
+	}
+	input, err := BuildChartInput(provider, time.Now(), 0, 0, ChartTypeNorth)
+	if err != nil {
+		t.Fatalf("BuildChartInput: %v", err)
+	}
+	for name, retrograde := range map[string]bool{"mars": true, "venus": false, "rahu": false} {
+		p := input.Planets[name]
+		if p.IsRetrograde != retrograde || p.SpeedDegPerDay != provider.speeds[name] {
+			t.Errorf("%s = %+v, want retrograde %v at %v°/day", name, p, retrograde, provider.speeds[name])
+		}
+	}
+}
+
+func TestBuildChartInput_Errors(t *testing.T) {
+	failing := errors.New("ephemeris files missing")
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		lat, lon float64
+	}{
+		{"provider error", &fakeProvider{err: failing}, 0, 0},
+		{"bad latitude", &fakeProvider{}, 91, 0},
+		{"bad longitude", &fakeProvider{}, 0, -181},
+		{"NaN planet", &fakeProvider{planets: map[string]float64{"sun": math.NaN()}}, 0, 0},
+		{"infinite ascendant", &fakeProvider{ascendant: math.Inf(1)}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildChartInput(tt.provider, time.Now(), tt.lat, tt.lon, ChartTypeSouth); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+	if _, err := BuildChartInput(&fakeProvider{err: failing}, time.Now(), 0, 0, ChartTypeSouth); !errors.Is(err, failing) {
+		t.Errorf("Provider error %v is not wrapped", err)
+	}
+}
+
+func TestPlanetAt_EdgeOfZodiac(t *testing.T) {
+	for _, longitude := range []float64{math.Nextafter(360, 0), -1e-12, 359.9999999999} {
+		p, err := planetAt(longitude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Rashi != "pisces" || !validDegrees(p.Degrees) {
+			t.Errorf("planetAt(%v) = %+v, want pisces below 30°", longitude, p)
+		}
+	}
+}
+
+func TestPlanetAt_StartOfRashi(t *testing.T) {
+	for _, longitude := range []float64{0, 30, 360, -330} {
+		p, err := planetAt(longitude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Degrees of 0 would read as none given
+		if p.Degrees <= 0 || formatDegrees(p.Degrees, DegreeFormatDegreeMinute) != "0°00'" {
+			t.Errorf("planetAt(%v) degrees = %v, want just above 0", longitude, p.Degrees)
+		}
+	}
+	p, _ := planetAt(120)
+	if p.Rashi != "leo" || !IsMoolatrikona("sun", p.Rashi, p.Degrees) {
+		t.Errorf("planetAt(120) = %+v, want the sun's moolatrikona at 0° leo", p)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned, wrapped, by GenerateChart and the other generators, so
+// callers can tell them apart with errors.Is
+var (
+	// ErrUnsupportedChartType is a chart type that is neither built in nor
+	// registered with RegisterLayout
+	ErrUnsupportedChartType = errors.New("unsupported chart type")
+	// ErrMissingChartType is an input without a chart_type
+	ErrMissingChartType = errors.New("chart_type is required")
+	// ErrMissingLagna is an option that needs a lagna on an input without one
+	ErrMissingLagna = errors.New("lagna is required")
+)
+
+// ErrInvalidRashi is a planet, or the lagna, whose rashi is not one of the
+// twelve (see RashiToNumber). Extract it with errors.As.
+type ErrInvalidRashi struct {
+	Planet string // Name of the planet, "lagna" for the lagna
+	Rashi  string
+}
+
+func (e *ErrInvalidRashi) Error() string {
+	if e.Planet == "lagna" {
+		return fmt.Sprintf("lagna: unknown rashi %q", e.Rashi)
+	}
+	return fmt.Sprintf("planet %s: unknown rashi %q", e.Planet, e.Rashi)
+}
+
+// ErrUnknownPlanet is a planet an option names that the chart does not
+// have, or that the library does not know. Extract it with errors.As.
+type ErrUnknownPlanet struct {
+	Name string
+}
+
+func (e *ErrUnknownPlanet) Error() string {
+	return fmt.Sprintf("unknown planet %q", e.Name)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGenerateChart_ErrUnsupportedChartType(t *testing.T) {
+	input := aspectLinesInput("east")
+	if _, err := GenerateChart(input); !errors.Is(err, ErrUnsupportedChartType) {
+		t.Errorf("GenerateChart(east) error = %v, want ErrUnsupportedChartType", err)
+	}
+	if _, err := ExportLayout("east"); !errors.Is(err, ErrUnsupportedChartType) {
+		t.Errorf("ExportLayout(east) error = %v, want ErrUnsupportedChartType", err)
+	}
+}
+
+func TestGenerateChart_ErrMissingChartType(t *testing.T) {
+	input := aspectLinesInput("")
+	if _, err := GenerateChart(input); !errors.Is(err, ErrMissingChartType) {
+		t.Errorf("GenerateChart error = %v, want ErrMissingChartType", err)
+	}
+}
+
+func TestGenerateChart_ErrUnknownPlanet(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ChartInput)
+		want   string
+	}{
+		{"aspect line", func(in *ChartInput) { in.Options.AspectLines = []string{"pluto"} }, "pluto"},
+		{"aspect line planet not in chart", func(in *ChartInput) { in.Options.AspectLines = []string{"venus"} }, "venus"},
+		{"strengths", func(in *ChartInput) { in.Strengths = map[string]float64{"sun": 6, "vulcan": 5} }, "vulcan"},
+	}
+	for _, tt := range tests {
+		input := aspectLinesInput(ChartTypeNorth)
+		tt.modify(&input)
+		_, err := GenerateChart(input)
+		var unknown *ErrUnknownPlanet
+		if !errors.As(err, &unknown) {
+			t.Errorf("%s: error = %v, want an *ErrUnknownPlanet", tt.name, err)
+			continue
+		}
+		if unknown.Name != tt.want {
+			t.Errorf("%s: ErrUnknownPlanet.Name = %q, want %q", tt.name, unknown.Name, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_ErrInvalidRashi(t *testing.T) {
+	input := aspectLinesInput(ChartTypeSouth)
+	input.Planets["saturn"].Rashi = "atlantis"
+	_, err := GenerateChart(input)
+	var invalid *ErrInvalidRashi
+	if !errors.As(err, &invalid) {
+		t.Fatalf("GenerateChart error = %v, want an *ErrInvalidRashi", err)
+	}
+	if invalid.Planet != "saturn" || invalid.Rashi != "atlantis" {
+		t.Errorf("ErrInvalidRashi = %+v, want saturn in atlantis", invalid)
+	}
+
+	input.Lagna.Rashi = "ascendant"
+	_, err = PlanetsByHouse(input)
+	if !errors.As(err, &invalid) || invalid.Planet != "lagna" {
+		t.Errorf("PlanetsByHouse error = %v, want the lagna's *ErrInvalidRashi", err)
+	}
+}
+
+func TestGenerateChart_ErrMissingLagna(t *testing.T) {
+	for _, opts := range []ChartOptions{{HighlightBadhaka: true}, {MarkBadhakesh: true}} {
+		input := aspectLinesInput(ChartTypeSouth)
+		input.Lagna = nil
+		input.Options = opts
+		if _, err := GenerateChart(input); !errors.Is(err, ErrMissingLagna) {
+			t.Errorf("GenerateChart(%+v) without a lagna error = %v, want ErrMissingLagna", opts, err)
+		}
+	}
+}
+
+func TestGenerateCharts_ErrorsUnwrap(t *testing.T) {
+	inputs := []ChartInput{aspectLinesInput(ChartTypeSouth), aspectLinesInput("east"), aspectLinesInput(ChartTypeNorth)}
+	inputs[2].Options.AspectLines = []string{"pluto"}
+	_, err := GenerateCharts(context.Background(), inputs, 0)
+	if !errors.Is(err, ErrUnsupportedChartType) {
+		t.Errorf("GenerateCharts error = %v, want ErrUnsupportedChartType among its errors", err)
+	}
+	var unknown *ErrUnknownPlanet
+	if !errors.As(err, &unknown) || unknown.Name != "pluto" {
+		t.Errorf("GenerateCharts error = %v, want chart 2's *ErrUnknownPlanet", err)
+	}
+}
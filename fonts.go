@@ -17,11 +17,16 @@ package parashari
 
 import (
 	_ "embed"
+	"fmt"
+	"image"
+	"sync"
 
 	"github.com/fogleman/gg"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/opentype"
 	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
 )
 
 // Embed font files into the binary using go:embed
@@ -33,40 +38,287 @@ var matangiRegularFont []byte
 //go:embed fonts/matangi/fonts/ttf/Matangi-Bold.ttf
 var matangiBoldFont []byte
 
-// loadEmbeddedFont loads a font from embedded bytes and sets it on the context
-// If loading fails, falls back to basic font
-func loadEmbeddedFont(dc *gg.Context, fontData []byte, size float64) error {
-	tt, err := opentype.Parse(fontData)
-	if err != nil {
-		dc.SetFontFace(basicfont.Face7x13)
-		return err
+//go:embed fonts/jaini/fonts/ttf/Jaini-Regular.ttf
+var jainiRegularFont []byte
+
+// embeddedFont is a font embedded in the binary. It is parsed once, on first
+// use, and its faces are cached by size, so drawing a chart parses nothing.
+type embeddedFont struct {
+	data       []byte
+	parse      func() (*opentype.Font, error)
+	fallback   *embeddedFont // Draws the runes the font has no glyphs for, when set
+	isFallback bool          // Stands in for other fonts, so falls back to none itself
+}
+
+func newEmbeddedFont(data []byte) *embeddedFont {
+	return &embeddedFont{
+		data:  data,
+		parse: sync.OnceValues(func() (*opentype.Font, error) { return opentype.Parse(data) }),
+	}
+}
+
+var (
+	matangiRegular = newEmbeddedFont(matangiRegularFont)
+	matangiBold    = newEmbeddedFont(matangiBoldFont)
+	// jainiRegular has the IAST letters Matangi lacks (ā, ū, ṅ, ś, ṣ, …),
+	// so Matangi falls back to it
+	jainiRegular = newEmbeddedFont(jainiRegularFont)
+)
+
+func init() {
+	matangiRegular.fallback = jainiRegular
+	matangiBold.fallback = jainiRegular
+	jainiRegular.isFallback = true
+}
+
+// fallbackFonts are the fonts added with AddFallbackFont, in the order they
+// were added
+var fallbackFonts struct {
+	sync.RWMutex
+	fonts []*embeddedFont
+}
+
+// AddFallbackFont adds a TrueType or OpenType font for the text the chart
+// fonts have no glyphs for, such as a Display of "गुरु" or in a script of
+// its own. Each rune is drawn from the first font that has it: the chart's
+// font, then the fonts it embeds for IAST, then the added fonts in the order
+// they were added and finally the basic bitmap font. Fonts are sized so
+// their capitals stand as tall as the chart font's. Charts drawn from then
+// on use the font; it cannot be removed.
+func AddFallbackFont(data []byte) error {
+	f := newEmbeddedFont(data)
+	if _, err := f.parse(); err != nil {
+		return fmt.Errorf("fallback font: %w", err)
 	}
+	f.isFallback = true
+	fallbackFonts.Lock()
+	fallbackFonts.fonts = append(fallbackFonts.fonts, f)
+	fallbackFonts.Unlock()
 
-	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
+	// Faces loaded so far fall back without the font
+	faceCache.Lock()
+	clear(faceCache.faces)
+	faceCache.Unlock()
+	return nil
+}
+
+// fallbacks returns the fonts drawing the runes f has no glyphs for, in the
+// order they are tried
+func (f *embeddedFont) fallbacks() []*embeddedFont {
+	if f.isFallback {
+		return nil
+	}
+	var fonts []*embeddedFont
+	if f.fallback != nil {
+		fonts = append(fonts, f.fallback)
+	}
+	fallbackFonts.RLock()
+	defer fallbackFonts.RUnlock()
+	return append(fonts, fallbackFonts.fonts...)
+}
+
+// faceKey identifies a cached face
+type faceKey struct {
+	font *embeddedFont
+	size float64
+}
+
+// maxCachedFaces bounds faceCache. Sizes scale with the canvas, so every
+// canvas size loads faces of its own; when the cache is full it starts
+// over. Faces handed out before stay usable, they are only no longer shared.
+const maxCachedFaces = 256
+
+// faceCache holds the faces loaded so far. Faces are shared by all charts,
+// including ones drawn concurrently, so each is wrapped in a sharedFace.
+var faceCache = struct {
+	sync.Mutex
+	faces map[faceKey]font.Face
+}{faces: map[faceKey]font.Face{}}
+
+// face returns the cached face of the font at a size, creating it on first
+// use. A font with fallbacks gets a face drawing from all of them, and from
+// the basic font last.
+func (f *embeddedFont) face(size float64) (font.Face, error) {
+	key := faceKey{f, size}
+	faceCache.Lock()
+	face, ok := faceCache.faces[key]
+	faceCache.Unlock()
+	if ok {
+		return face, nil
+	}
+	var fallbacks []font.Face
+	for _, fallback := range f.fallbacks() {
+		// Loaded before taking the lock, which loading them takes too
+		if face, err := fallback.face(size * f.fallbackScale(fallback)); err == nil {
+			fallbacks = append(fallbacks, face)
+		}
+	}
+	if fallbacks != nil {
+		fallbacks = append(fallbacks, basicfont.Face7x13)
+	}
+
+	faceCache.Lock()
+	defer faceCache.Unlock()
+	if face, ok := faceCache.faces[key]; ok {
+		return face, nil
+	}
+	tt, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+	otf, err := opentype.NewFace(tt, &opentype.FaceOptions{
 		Size:    size,
 		DPI:     72,
 		Hinting: font.HintingFull,
 	})
+	if err != nil {
+		return nil, err
+	}
+	shared := &sharedFace{face: otf}
+	if fallbacks != nil {
+		shared.fallbacks, shared.drawnFrom = fallbacks, map[rune]font.Face{}
+	}
+	if len(faceCache.faces) >= maxCachedFaces {
+		clear(faceCache.faces)
+	}
+	faceCache.faces[key] = shared
+	return shared, nil
+}
+
+// fallbackScale returns how much larger than the font a fallback of it is
+// drawn for their capitals to stand equally tall
+func (f *embeddedFont) fallbackScale(fallbackFont *embeddedFont) float64 {
+	primary, err := f.parse()
+	if err != nil {
+		return 1
+	}
+	fallback, err := fallbackFont.parse()
+	if err != nil {
+		return 1
+	}
+	var buf sfnt.Buffer
+	pm, err := primary.Metrics(&buf, fixed.I(100), font.HintingNone)
+	if err != nil {
+		return 1
+	}
+	fm, err := fallback.Metrics(&buf, fixed.I(100), font.HintingNone)
+	if err != nil || fm.CapHeight <= 0 || pm.CapHeight <= 0 {
+		return 1
+	}
+	return float64(pm.CapHeight) / float64(fm.CapHeight)
+}
+
+// sharedFace makes a face safe for concurrent use. Faces rasterize glyphs
+// into a buffer they reuse, so Glyph hands out a copy of the mask. Runes the
+// face has no glyph for are drawn from the first of its fallback faces that
+// has one, or from the face when none does; runes from different faces are
+// not kerned.
+type sharedFace struct {
+	mu        sync.Mutex
+	face      font.Face
+	fallbacks []font.Face
+	drawnFrom map[rune]font.Face // Fallback face each rune seen so far is drawn from, nil for face
+}
+
+// fallbackFor returns the fallback face r is drawn from, or nil when it is
+// drawn from face. s.mu must be held.
+func (s *sharedFace) fallbackFor(r rune) font.Face {
+	if s.fallbacks == nil {
+		return nil
+	}
+	fallback, seen := s.drawnFrom[r]
+	if !seen {
+		if _, ok := s.face.GlyphAdvance(r); !ok {
+			for _, f := range s.fallbacks {
+				if _, ok := f.GlyphAdvance(r); ok {
+					fallback = f
+					break
+				}
+			}
+		}
+		s.drawnFrom[r] = fallback
+	}
+	return fallback
+}
+
+func (s *sharedFace) Close() error { return nil } // Cached faces live as long as the program
+
+func (s *sharedFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	s.mu.Lock()
+	if fallback := s.fallbackFor(r); fallback != nil {
+		s.mu.Unlock()
+		return fallback.Glyph(dot, r)
+	}
+	defer s.mu.Unlock()
+	dr, mask, maskp, advance, ok := s.face.Glyph(dot, r)
+	if !ok {
+		return dr, mask, maskp, advance, ok
+	}
+	if alpha, isAlpha := mask.(*image.Alpha); isAlpha {
+		copied := *alpha
+		copied.Pix = append([]uint8(nil), alpha.Pix...)
+		mask = &copied
+	}
+	return dr, mask, maskp, advance, ok
+}
+
+func (s *sharedFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	s.mu.Lock()
+	if fallback := s.fallbackFor(r); fallback != nil {
+		s.mu.Unlock()
+		return fallback.GlyphBounds(r)
+	}
+	defer s.mu.Unlock()
+	return s.face.GlyphBounds(r)
+}
+
+func (s *sharedFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	s.mu.Lock()
+	if fallback := s.fallbackFor(r); fallback != nil {
+		s.mu.Unlock()
+		return fallback.GlyphAdvance(r)
+	}
+	defer s.mu.Unlock()
+	return s.face.GlyphAdvance(r)
+}
+
+func (s *sharedFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fallbackFor(r0) != nil || s.fallbackFor(r1) != nil {
+		return 0
+	}
+	return s.face.Kern(r0, r1)
+}
+
+func (s *sharedFace) Metrics() font.Metrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.face.Metrics()
+}
+
+// loadEmbeddedFont sets an embedded font at a size on the context
+// If loading fails, falls back to basic font
+func loadEmbeddedFont(dc *gg.Context, f *embeddedFont, size float64) error {
+	face, err := f.face(size)
 	if err != nil {
 		dc.SetFontFace(basicfont.Face7x13)
 		return err
 	}
-
 	dc.SetFontFace(face)
 	return nil
 }
 
 // loadMatangiRegular loads Matangi Regular font from embedded data
 func loadMatangiRegular(dc *gg.Context, size float64) {
-	if err := loadEmbeddedFont(dc, matangiRegularFont, size); err != nil {
+	if err := loadEmbeddedFont(dc, matangiRegular, size); err != nil {
 		// Fallback already set in loadEmbeddedFont
 	}
 }
 
 // loadMatangiBold loads Matangi Bold font from embedded data
 func loadMatangiBold(dc *gg.Context, size float64) {
-	if err := loadEmbeddedFont(dc, matangiBoldFont, size); err != nil {
+	if err := loadEmbeddedFont(dc, matangiBold, size); err != nil {
 		// Fallback already set in loadEmbeddedFont
 	}
 }
-
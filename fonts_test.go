@@ -0,0 +1,186 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+func TestEmbeddedFont_FaceIsCached(t *testing.T) {
+	a, err := matangiBold.face(22)
+	if err != nil {
+		t.Fatalf("Error loading face: %v", err)
+	}
+	b, _ := matangiBold.face(22)
+	if a != b {
+		t.Error("Expected the same face for the same font and size")
+	}
+	if c, _ := matangiRegular.face(22); c == a {
+		t.Error("Expected regular and bold faces to be cached apart")
+	}
+	if d, _ := matangiBold.face(18); d == a {
+		t.Error("Expected faces of different sizes to be cached apart")
+	}
+}
+
+func TestEmbeddedFont_FaceCacheIsBounded(t *testing.T) {
+	// Continuous sizes, as charts of every canvas size ask for
+	for i := range 4 * maxCachedFaces {
+		if _, err := matangiBold.face(8 + float64(i)/100); err != nil {
+			t.Fatalf("Error loading face: %v", err)
+		}
+	}
+	faceCache.Lock()
+	n := len(faceCache.faces)
+	faceCache.Unlock()
+	if n > maxCachedFaces {
+		t.Errorf("Got %d cached faces, want at most %d", n, maxCachedFaces)
+	}
+
+	a, _ := matangiBold.face(22.5)
+	if b, _ := matangiBold.face(22.5); a != b {
+		t.Error("Expected the same face once the cache has started over")
+	}
+}
+
+func TestEmbeddedFont_Fallback(t *testing.T) {
+	face := embeddedFace(matangiBold, 22)
+	fallback := embeddedFace(jainiRegular, 22*matangiBold.fallbackScale(jainiRegular))
+	if fontHasGlyphs(matangiBold, "ṅ") || !fontHasGlyphs(jainiRegular, "ṅ") {
+		t.Fatal("Expected ṅ in Jaini but not in Matangi")
+	}
+	got, ok := face.GlyphAdvance('ṅ')
+	want, _ := fallback.GlyphAdvance('ṅ')
+	if !ok || got != want {
+		t.Errorf("Advance of ṅ = %v, %v, want Jaini's %v", got, ok, want)
+	}
+	// Runes Matangi has are still drawn from it
+	otf, _ := matangiBold.parse()
+	plain, _ := opentype.NewFace(otf, &opentype.FaceOptions{Size: 22, DPI: 72, Hinting: font.HintingFull})
+	if got, want := font.MeasureString(face, "Ma"), font.MeasureString(plain, "Ma"); got != want {
+		t.Errorf("Width of Ma = %v, want Matangi's %v", got, want)
+	}
+}
+
+func TestAddFallbackFont(t *testing.T) {
+	t.Cleanup(func() {
+		fallbackFonts.fonts = nil
+		clear(faceCache.faces)
+	})
+	if fontHasGlyphs(matangiRegular, "Ж") || fontHasGlyphs(jainiRegular, "Ж") {
+		t.Fatal("Expected Ж in neither Matangi nor Jaini")
+	}
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets:   map[string]*Planet{"jupiter": {Rashi: "cancer", Display: "Юп"}, "mars": {Rashi: "leo", Display: "मंगल"}},
+	}
+	before, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := embeddedFace(matangiBold, 22).GlyphAdvance('Ж'); ok {
+		t.Error("Ж drawn before a font with it was added")
+	}
+
+	if err := AddFallbackFont([]byte("not a font")); err == nil {
+		t.Error("Expected an error adding a font that does not parse")
+	}
+	if err := AddFallbackFont(goregular.TTF); err != nil {
+		t.Fatalf("Error adding fallback font: %v", err)
+	}
+	goRegular := fallbackFonts.fonts[0]
+	for _, f := range []*embeddedFont{matangiRegular, matangiBold} {
+		face := embeddedFace(f, 22)
+		got, ok := face.GlyphAdvance('Ж')
+		want, _ := embeddedFace(goRegular, 22*f.fallbackScale(goRegular)).GlyphAdvance('Ж')
+		if !ok || got != want {
+			t.Errorf("Advance of Ж = %v, %v, want Go Regular's %v", got, ok, want)
+		}
+		// IAST letters are still drawn from Jaini, ahead of the added font
+		got, _ = face.GlyphAdvance('ṅ')
+		if want, _ := embeddedFace(jainiRegular, 22*f.fallbackScale(jainiRegular)).GlyphAdvance('ṅ'); got != want {
+			t.Errorf("Advance of ṅ = %v, want Jaini's %v", got, want)
+		}
+	}
+
+	after, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(before, after) {
+		t.Error("Chart unchanged by a fallback font with its glyphs")
+	}
+	assertGolden(t, "south_fallback_font", after)
+}
+
+func TestGenerateChart_ConcurrentCallsShareFaces(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := crowdedHouseInput(chartType)
+		input.Options.ShowDegrees = true
+		want, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+
+		var wg sync.WaitGroup
+		got := make([]string, 8)
+		for i := range got {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got[i], _ = GenerateChart(input)
+			}()
+		}
+		wg.Wait()
+		for i, g := range got {
+			if g != want {
+				t.Errorf("%s chart drawn concurrently (#%d) differs from one drawn alone", chartType, i)
+			}
+		}
+	}
+}
+
+// BenchmarkLoadFont_Parse loads a face the way every draw call used to, by
+// parsing the embedded font first
+func BenchmarkLoadFont_Parse(b *testing.B) {
+	dc := gg.NewContext(1, 1)
+	for b.Loop() {
+		tt, err := opentype.Parse(matangiBoldFont)
+		if err != nil {
+			b.Fatal(err)
+		}
+		face, err := opentype.NewFace(tt, &opentype.FaceOptions{Size: 22, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			b.Fatal(err)
+		}
+		dc.SetFontFace(face)
+	}
+}
+
+func BenchmarkLoadFont_Cached(b *testing.B) {
+	dc := gg.NewContext(1, 1)
+	for b.Loop() {
+		loadMatangiBold(dc, 22)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// zodiacGlyphs maps rashi number (1-12) to its Unicode zodiac symbol
+var zodiacGlyphs = [13]rune{0, '♈', '♉', '♊', '♋', '♌', '♍', '♎', '♏', '♐', '♑', '♒', '♓'}
+
+// RashiGlyph returns the Unicode zodiac symbol for a rashi number (1-12),
+// or an empty string for any other number
+func RashiGlyph(num int) string {
+	if num < 1 || num > 12 {
+		return ""
+	}
+	return string(zodiacGlyphs[num])
+}
+
+// glyphScale is how much larger than the number font a zodiac glyph is drawn.
+// Glyph strokes read smaller than digits at the same pixel size.
+const glyphScale = 1.3
+
+// zodiacGlyphPaths holds the outline of each zodiac glyph.
+// The embedded fonts do not cover the astrological symbol block, so the glyphs
+// are drawn as strokes in a unit box (0,0 top-left to 1,1 bottom-right).
+var zodiacGlyphPaths = [13]func(dc *gg.Context){
+	1: func(dc *gg.Context) { // Aries: two horns curling out of a stem
+		dc.DrawArc(0.3, 0.42, 0.2, 0.75*math.Pi, 2*math.Pi)
+		dc.LineTo(0.5, 0.95)
+		dc.NewSubPath()
+		dc.DrawArc(0.7, 0.42, 0.2, math.Pi, 2.25*math.Pi)
+	},
+	2: func(dc *gg.Context) { // Taurus: circle with horns
+		dc.DrawCircle(0.5, 0.66, 0.24)
+		dc.NewSubPath()
+		dc.DrawArc(0.5, 0.1, 0.32, 0, math.Pi)
+	},
+	3: func(dc *gg.Context) { // Gemini: two pillars between curved bars
+		dc.MoveTo(0.35, 0.2)
+		dc.LineTo(0.35, 0.8)
+		dc.MoveTo(0.65, 0.2)
+		dc.LineTo(0.65, 0.8)
+		dc.MoveTo(0.12, 0.1)
+		dc.QuadraticTo(0.5, 0.32, 0.88, 0.1)
+		dc.MoveTo(0.12, 0.9)
+		dc.QuadraticTo(0.5, 0.68, 0.88, 0.9)
+	},
+	4: func(dc *gg.Context) { // Cancer: two interlocking claws
+		dc.DrawCircle(0.3, 0.38, 0.12)
+		dc.MoveTo(0.3, 0.26)
+		dc.CubicTo(0.45, 0.12, 0.8, 0.15, 0.92, 0.32)
+		dc.NewSubPath()
+		dc.DrawCircle(0.7, 0.62, 0.12)
+		dc.MoveTo(0.7, 0.74)
+		dc.CubicTo(0.55, 0.88, 0.2, 0.85, 0.08, 0.68)
+	},
+	5: func(dc *gg.Context) { // Leo: loop with a curling mane
+		dc.DrawCircle(0.28, 0.68, 0.13)
+		dc.MoveTo(0.38, 0.6)
+		dc.CubicTo(0.15, 0.2, 0.5, 0.0, 0.68, 0.25)
+		dc.CubicTo(0.8, 0.45, 0.55, 0.75, 0.68, 0.88)
+		dc.CubicTo(0.75, 0.95, 0.88, 0.92, 0.92, 0.82)
+	},
+	6: func(dc *gg.Context) { // Virgo: humps with a closed loop
+		drawZodiacHumps(dc)
+		dc.LineTo(0.5, 0.85)
+		dc.MoveTo(0.5, 0.5)
+		dc.CubicTo(0.7, 0.3, 0.92, 0.5, 0.7, 0.72)
+		dc.LineTo(0.45, 0.98)
+	},
+	7: func(dc *gg.Context) { // Libra: base line under a raised arch
+		dc.MoveTo(0.1, 0.85)
+		dc.LineTo(0.9, 0.85)
+		dc.MoveTo(0.1, 0.65)
+		dc.LineTo(0.32, 0.65)
+		dc.DrawArc(0.5, 0.5, 0.22, 0.8*math.Pi, 2.2*math.Pi)
+		dc.MoveTo(0.68, 0.65)
+		dc.LineTo(0.9, 0.65)
+	},
+	8: func(dc *gg.Context) { // Scorpio: humps ending in an arrow tail
+		drawZodiacHumps(dc)
+		dc.LineTo(0.5, 0.8)
+		dc.QuadraticTo(0.55, 0.9, 0.75, 0.88)
+		dc.LineTo(0.9, 0.74)
+		dc.MoveTo(0.9, 0.74)
+		dc.LineTo(0.77, 0.74)
+		dc.MoveTo(0.9, 0.74)
+		dc.LineTo(0.9, 0.87)
+	},
+	9: func(dc *gg.Context) { // Sagittarius: crossed arrow
+		dc.MoveTo(0.12, 0.88)
+		dc.LineTo(0.88, 0.12)
+		dc.MoveTo(0.88, 0.12)
+		dc.LineTo(0.55, 0.12)
+		dc.MoveTo(0.88, 0.12)
+		dc.LineTo(0.88, 0.45)
+		dc.MoveTo(0.3, 0.45)
+		dc.LineTo(0.55, 0.7)
+	},
+	10: func(dc *gg.Context) { // Capricorn: V joined to a looped tail
+		dc.MoveTo(0.08, 0.2)
+		dc.LineTo(0.3, 0.8)
+		dc.LineTo(0.45, 0.3)
+		dc.CubicTo(0.5, 0.95, 0.9, 0.9, 0.85, 0.62)
+		dc.CubicTo(0.8, 0.4, 0.55, 0.5, 0.6, 0.7)
+		dc.CubicTo(0.63, 0.85, 0.5, 0.95, 0.4, 0.95)
+	},
+	11: func(dc *gg.Context) { // Aquarius: two waves
+		for _, y := range []float64{0.38, 0.65} {
+			dc.MoveTo(0.08, y+0.08)
+			dc.LineTo(0.29, y-0.08)
+			dc.LineTo(0.5, y+0.08)
+			dc.LineTo(0.71, y-0.08)
+			dc.LineTo(0.92, y+0.08)
+		}
+	},
+	12: func(dc *gg.Context) { // Pisces: two fishes joined by a band
+		dc.DrawArc(-0.05, 0.5, 0.4, -math.Pi/3, math.Pi/3)
+		dc.NewSubPath()
+		dc.DrawArc(1.05, 0.5, 0.4, 2*math.Pi/3, 4*math.Pi/3)
+		dc.MoveTo(0.2, 0.5)
+		dc.LineTo(0.8, 0.5)
+	},
+}
+
+// drawZodiacHumps draws the three stems and two humps shared by Virgo and Scorpio,
+// leaving the current point at the top of the third stem
+func drawZodiacHumps(dc *gg.Context) {
+	dc.MoveTo(0.1, 0.85)
+	dc.LineTo(0.1, 0.35)
+	dc.DrawArc(0.2, 0.35, 0.1, math.Pi, 2*math.Pi)
+	dc.LineTo(0.3, 0.85)
+	dc.MoveTo(0.3, 0.35)
+	dc.DrawArc(0.4, 0.35, 0.1, math.Pi, 2*math.Pi)
+}
+
+// drawZodiacGlyph strokes the glyph for a rashi number in a size x size box
+// whose top-left corner is at (x, y), using the current color
+func drawZodiacGlyph(dc *gg.Context, num int, x, y, size float64) {
+	if num < 1 || num > 12 {
+		return
+	}
+	dc.Push()
+	dc.Translate(x, y)
+	dc.Scale(size, size)
+	dc.NewSubPath()
+	zodiacGlyphPaths[num](dc)
+	dc.SetLineWidth(math.Max(1, size/12))
+	dc.Stroke()
+	dc.Pop()
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestRashiGlyph(t *testing.T) {
+	if got := RashiGlyph(1); got != "♈" {
+		t.Errorf("RashiGlyph(1) = %q, want ♈", got)
+	}
+	if got := RashiGlyph(12); got != "♓" {
+		t.Errorf("RashiGlyph(12) = %q, want ♓", got)
+	}
+	for _, num := range []int{0, 13, -1} {
+		if got := RashiGlyph(num); got != "" {
+			t.Errorf("RashiGlyph(%d) = %q, want empty", num, got)
+		}
+	}
+}
+
+func TestGenerateChart_RashiGlyphs(t *testing.T) {
+	// One planet per house keeps the golden independent of map iteration order
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "cancer"},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "leo"},
+				"moon":    {Rashi: "taurus"},
+				"jupiter": {Rashi: "sagittarius", IsRetrograde: true},
+			},
+			Options: ChartOptions{RashiLabelMode: RashiLabelGlyph},
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_rashi_glyphs", imageData)
+	}
+}
+
+func TestGenerateChart_InvalidRashiLabelMode(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Options:   ChartOptions{RashiLabelMode: "roman"},
+	}
+	if _, err := GenerateChart(input); err == nil {
+		t.Fatal("Expected error for unsupported rashi label mode")
+	}
+}
+
+func TestRashiLabelText(t *testing.T) {
+	tests := []struct {
+		mode RashiLabelMode
+		num  int
+		want string
+	}{
+		{RashiLabelNumber, 7, "7"},
+		{"", 12, "12"},
+		{RashiLabelGlyph, 5, "♌"},
+		{RashiLabelName, 1, "Aries"},
+		{RashiLabelName, 9, "Sagittarius"},
+		{RashiLabelSanskritName, 2, "Vrishabha"},
+		{RashiLabelSanskritName, 12, "Meena"},
+	}
+	for _, tt := range tests {
+		if got := rashiLabelText(ChartOptions{RashiLabelMode: tt.mode}, tt.num); got != tt.want {
+			t.Errorf("rashiLabelText(%q, %d) = %q, want %q", tt.mode, tt.num, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_RashiNames(t *testing.T) {
+	for _, mode := range []RashiLabelMode{RashiLabelName, RashiLabelSanskritName} {
+		for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+			input := ChartInput{
+				ChartType: chartType,
+				Lagna:     &Planet{Rashi: "sagittarius"},
+				Planets: map[string]*Planet{
+					"sun":  {Rashi: "capricorn"},
+					"moon": {Rashi: "scorpio"},
+				},
+				Options: ChartOptions{RashiLabelMode: mode},
+			}
+
+			base64Image, err := GenerateChart(input)
+			if err != nil {
+				t.Fatalf("Error generating %s chart: %v", chartType, err)
+			}
+			imageData, err := base64.StdEncoding.DecodeString(base64Image)
+			if err != nil {
+				t.Fatalf("Error decoding base64: %v", err)
+			}
+			assertGolden(t, string(chartType)+"_rashi_"+string(mode), imageData)
+		}
+	}
+}
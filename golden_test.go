@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tejzpr/go-vedic-astro-charts/internal/testutil"
+)
+
+// assertGolden compares a rendered PNG pixel by pixel against testdata/golden/<name>.png.
+// Run the tests with UPDATE_GOLDEN=1 to (re)write the golden files. On a
+// mismatch the differing pixels are marked in red in <name>.diff.png, in the
+// temporary directory.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".png")
+
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Error writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading golden file (run with UPDATE_GOLDEN=1 to create it): %v", err)
+	}
+
+	diff, diffImage, err := testutil.CompareImages(got, want, 0)
+	if err != nil {
+		t.Fatalf("Error comparing with golden %s: %v", path, err)
+	}
+	if diff == 0 {
+		return
+	}
+	diffPath := filepath.Join(os.TempDir(), name+".diff.png")
+	if err := os.WriteFile(diffPath, diffImage, 0644); err != nil {
+		t.Errorf("Error writing diff image: %v", err)
+	}
+	t.Errorf("Rendered image differs from golden %s in %.3f%% of pixels, see %s", path, diff*100, diffPath)
+}
+
+// countDifferentPixels returns the number of pixels that differ between two images of equal bounds
+func countDifferentPixels(a, b image.Image) int {
+	n := 0
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				n++
+			}
+		}
+	}
+	return n
+}
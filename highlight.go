@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"maps"
+	"slices"
+)
+
+// HouseHighlight tints a group of houses, e.g. the kendras (1, 4, 7, 10)
+// or the trikonas (1, 5, 9), with a background fill
+type HouseHighlight struct {
+	Houses []int  `json:"houses"` // House numbers counted from lagna (1-12)
+	Color  string `json:"color"`  // Fill color as "#RRGGBB" or "#RRGGBBAA"
+}
+
+// validateHighlights checks house numbers and colors of highlight groups
+func validateHighlights(groups []HouseHighlight) error {
+	for _, g := range groups {
+		if _, err := parseHexColor(g.Color); err != nil {
+			return fmt.Errorf("highlight_houses: %w", err)
+		}
+		for _, house := range g.Houses {
+			if house < 1 || house > 12 {
+				return fmt.Errorf("highlight_houses: house %d out of range 1-12", house)
+			}
+		}
+	}
+	return nil
+}
+
+// sortedFills returns the houses, or rashis, of fills in ascending order.
+// Neighbouring fills share antialiased edges, so painting them in a fixed
+// order keeps the image the same from run to run.
+func sortedFills(fills map[int]color.Color) []int {
+	return slices.Sorted(maps.Keys(fills))
+}
+
+// houseFills resolves the fill color of each house (1-12). The lagna house
+// fill is applied first, then the badhaka house fill and the highlight
+// groups; when a house is listed in several groups the last group wins.
+func houseFills(input ChartInput) map[int]color.Color {
+	fills := map[int]color.Color{}
+	if input.Lagna != nil && input.Options.LagnaHouseFill != "" {
+		if c, err := parseHexColor(input.Options.LagnaHouseFill); err == nil {
+			fills[1] = c
+		}
+	}
+	if input.Lagna != nil && input.Options.HighlightBadhaka {
+		fill := input.Options.BadhakaFill
+		if fill == "" {
+			fill = DefaultBadhakaFill
+		}
+		if c, err := parseHexColor(fill); err == nil {
+			if house := BadhakaHouse(RashiToNumber(input.Lagna.Rashi)); house != 0 {
+				fills[house] = c
+			}
+		}
+	}
+	for _, g := range input.Options.HighlightHouses {
+		c, err := parseHexColor(g.Color)
+		if err != nil {
+			continue // Rejected by validation
+		}
+		for _, house := range g.Houses {
+			fills[house] = c
+		}
+	}
+	return fills
+}
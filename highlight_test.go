@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"image/color"
+	"testing"
+)
+
+var kendraTrikonaHighlights = []HouseHighlight{
+	{Houses: []int{1, 4, 7, 10}, Color: "#fde8c8"},
+	{Houses: []int{1, 5, 9}, Color: "#d8ecd8"},
+}
+
+func TestHouseFills_LastColorWins(t *testing.T) {
+	fills := houseFills(ChartInput{Options: ChartOptions{HighlightHouses: kendraTrikonaHighlights}})
+	if len(fills) != 6 {
+		t.Fatalf("Expected 6 highlighted houses, got %d", len(fills))
+	}
+	trikona := color.NRGBA{R: 0xd8, G: 0xec, B: 0xd8, A: 0xff}
+	kendra := color.NRGBA{R: 0xfd, G: 0xe8, B: 0xc8, A: 0xff}
+	if fills[1] != trikona {
+		t.Errorf("House 1 fill = %v, want last listed color %v", fills[1], trikona)
+	}
+	if fills[4] != kendra {
+		t.Errorf("House 4 fill = %v, want %v", fills[4], kendra)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+	}{
+		{"#ff8800", color.NRGBA{R: 0xff, G: 0x88, B: 0x00, A: 0xff}},
+		{"#f80", color.NRGBA{R: 0xff, G: 0x88, B: 0x00, A: 0xff}},
+		{"#ff880080", color.NRGBA{R: 0xff, G: 0x88, B: 0x00, A: 0x80}},
+		{"00ff00", color.NRGBA{G: 0xff, A: 0xff}},
+	}
+	for _, tt := range tests {
+		got, err := parseHexColor(tt.in)
+		if err != nil {
+			t.Errorf("parseHexColor(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "#12", "#gggggg", "red"} {
+		if _, err := parseHexColor(bad); err == nil {
+			t.Errorf("parseHexColor(%q) expected error", bad)
+		}
+	}
+}
+
+func TestGenerateChart_HighlightHousesValidation(t *testing.T) {
+	for _, groups := range [][]HouseHighlight{
+		{{Houses: []int{0}, Color: "#ffffff"}},
+		{{Houses: []int{13}, Color: "#ffffff"}},
+		{{Houses: []int{1}, Color: "light blue"}},
+	} {
+		input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{HighlightHouses: groups}}
+		if _, err := GenerateChart(input); err == nil {
+			t.Errorf("Expected validation error for %+v", groups)
+		}
+	}
+}
+
+func TestGenerateChart_HighlightHouses(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "gemini"},
+			Planets: map[string]*Planet{
+				"jupiter": {Rashi: "libra"},
+				"saturn":  {Rashi: "pisces"},
+			},
+			Options: ChartOptions{HighlightHouses: kendraTrikonaHighlights},
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_highlight_houses", imageData)
+	}
+}
+
+func TestHouseFills_LagnaHouseFill(t *testing.T) {
+	opts := ChartOptions{LagnaHouseFill: "#ffeedd"}
+
+	fills := houseFills(ChartInput{Lagna: &Planet{Rashi: "virgo"}, Options: opts})
+	if len(fills) != 1 || fills[1] == nil {
+		t.Fatalf("Expected only house 1 to be filled, got %v", fills)
+	}
+
+	if fills := houseFills(ChartInput{Options: opts}); len(fills) != 0 {
+		t.Errorf("Expected no fill without a lagna, got %v", fills)
+	}
+
+	// Explicit highlight groups are applied after the lagna fill
+	opts.HighlightHouses = []HouseHighlight{{Houses: []int{1}, Color: "#000000"}}
+	fills = houseFills(ChartInput{Lagna: &Planet{Rashi: "virgo"}, Options: opts})
+	if fills[1] != (color.NRGBA{A: 0xff}) {
+		t.Errorf("House 1 fill = %v, want highlight group color", fills[1])
+	}
+}
+
+func TestGenerateChart_LagnaHouseFill(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "virgo"},
+			Planets: map[string]*Planet{
+				"mercury": {Rashi: "virgo"},
+			},
+			Options: ChartOptions{LagnaHouseFill: "#ffe9cc"},
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_lagna_house_fill", imageData)
+	}
+}
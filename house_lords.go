@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// houseLordBox returns the box of the lord of a rashi, abbreviated in loc,
+// at a font size, its top at top and anchored horizontally on x by ax (0
+// left, 1 right)
+func houseLordBox(loc *chartLocale, rashiNum, house int, x, top, ax, size float64) textBox {
+	text := loc.abbreviation(SignLord(rashiNum))
+	w := float64(font.MeasureString(embeddedFace(matangiRegular, size), text)) / 64
+	left := x - ax*w
+	return textBox{text: text, house: house, kind: labelHouseLord,
+		left: left, top: top, right: left + w, bottom: top + regularMetrics(size).capHeight}
+}
+
+// drawHouseLord draws a lord label in c, usually the gray of the house numbers
+func drawHouseLord(dc *gg.Context, box textBox, size float64, c color.Color) {
+	drawText(dc, embeddedFace(matangiRegular, size), c, box.text, box.left, box.bottom, 0)
+}
+
+// regionLordBox places the lord label of a region house beside its rashi
+// label: right of it, else left of it, else below or above it, whichever
+// first stays in the region clear of the text already there
+func regionLordBox(loc *chartLocale, poly []gg.Point, number textBox, taken []textBox, rashiNum, house int, size float64) textBox {
+	gap := regularMetrics(size).descent * 2
+	capHeight := regularMetrics(size).capHeight
+	midY := (number.top+number.bottom)/2 - capHeight/2
+	midX := (number.left + number.right) / 2
+	candidates := []textBox{
+		houseLordBox(loc, rashiNum, house, number.right+gap, midY, 0, size),
+		houseLordBox(loc, rashiNum, house, number.left-gap, midY, 1, size),
+		houseLordBox(loc, rashiNum, house, midX, number.bottom+gap, 0.5, size),
+		houseLordBox(loc, rashiNum, house, midX, number.top-gap-capHeight, 0.5, size),
+	}
+	for _, box := range candidates {
+		if !box.insidePolygon(poly) {
+			continue
+		}
+		clear := true
+		for _, t := range taken {
+			if box.overlaps(t) {
+				clear = false
+				break
+			}
+		}
+		if clear {
+			return box
+		}
+	}
+	return candidates[0]
+}
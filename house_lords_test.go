@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import "testing"
+
+func TestSignLord(t *testing.T) {
+	want := []string{"", "mars", "venus", "mercury", "moon", "sun", "mercury",
+		"venus", "mars", "jupiter", "saturn", "saturn", "jupiter", ""}
+	for rashiNum, lord := range want {
+		if got := SignLord(rashiNum); got != lord {
+			t.Errorf("SignLord(%d) = %q, want %q", rashiNum, got, lord)
+		}
+	}
+}
+
+func TestGenerateChart_HouseLords(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo"},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "leo"},
+				"mercury": {Rashi: "leo", IsRetrograde: true},
+				"venus":   {Rashi: "virgo"},
+				"moon":    {Rashi: "scorpio"},
+				"jupiter": {Rashi: "pisces"},
+				"saturn":  {Rashi: "aquarius"},
+				"rahu":    {Rashi: "aries"},
+				"ketu":    {Rashi: "libra"},
+			},
+			HouseScores: map[int]int{1: 31, 5: 24, 8: 28, 11: 35},
+			Options:     ChartOptions{ShowHouseLords: true, ShowHouseNumbers: true, ShowHouseScores: true},
+		}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_house_lords", data)
+
+		boxes := renderWithBoxes(t, input)
+		if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+		lords := 0
+		for _, b := range boxes.boxes {
+			if b.kind != labelHouseLord {
+				continue
+			}
+			lords++
+			for _, h := range boxes.houses {
+				if h.region != b.house {
+					continue
+				}
+				if want := GetPlanetAbbreviation(SignLord(h.rashi)); b.text != want {
+					t.Errorf("%s: house %d lord %q, want %q", chartType, b.house, b.text, want)
+				}
+				if !b.insidePolygon(h.polygon) {
+					t.Errorf("%s: lord %q at %+v, outside house %d", chartType, b.text, b, b.house)
+				}
+			}
+		}
+		if lords != 12 {
+			t.Errorf("%s: %d house lords, want 12", chartType, lords)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// maxHouseScore is the most bindus a rashi can score, in the sarvashtakavarga
+const maxHouseScore = 56
+
+// scoreColor is the muted blue-gray of house scores, quieter than the planets
+var scoreColor = color.NRGBA{R: 112, G: 128, B: 150, A: 255}
+
+// validateHouseScores checks that the house scores are keyed by rashi
+// numbers and hold possible bindu counts
+func validateHouseScores(input ChartInput) error {
+	for _, scores := range []struct {
+		name   string
+		scores map[int]int
+	}{
+		{"house_scores", input.HouseScores},
+		{"secondary_house_scores", input.SecondaryHouseScores},
+	} {
+		for rashiNum, score := range scores.scores {
+			if rashiNum < 1 || rashiNum > 12 {
+				return fmt.Errorf("%s: rashi %d out of range 1-12", scores.name, rashiNum)
+			}
+			if score < 0 || score > maxHouseScore {
+				return fmt.Errorf("%s: score %d for rashi %d out of range 0-%d", scores.name, score, rashiNum, maxHouseScore)
+			}
+		}
+	}
+	return nil
+}
+
+// houseScoreRows returns the scores to print in a rashi's house, the
+// primary one first, or nil when scores are not shown
+func houseScoreRows(input ChartInput, rashiNum int) []string {
+	if !input.Options.ShowHouseScores {
+		return nil
+	}
+	var rows []string
+	for _, scores := range []map[int]int{input.HouseScores, input.SecondaryHouseScores} {
+		if score, ok := scores[rashiNum]; ok {
+			rows = append(rows, strconv.Itoa(score))
+		}
+	}
+	return rows
+}
+
+// scoreRowsHeight returns the height of rows of scores at a font size, from
+// the top of the first row's digits to the baseline of the last
+func scoreRowsHeight(rows int, size float64) float64 {
+	m := regularMetrics(size)
+	return float64(rows-1)*m.lineHeight() + m.capHeight
+}
+
+// scoreRowsBox returns the box rows of scores centered on x would take with
+// the first row's digits starting at top
+func scoreRowsBox(rows []string, x, top, size float64) textBox {
+	face := embeddedFace(matangiRegular, size)
+	w := 0.0
+	for _, row := range rows {
+		w = max(w, float64(font.MeasureString(face, row))/64)
+	}
+	return textBox{left: x - w/2, top: top, right: x + w/2, bottom: top + scoreRowsHeight(len(rows), size)}
+}
+
+// drawHouseScores draws rows of scores centered on x in c, the first row's
+// digits starting at top, and returns their boxes
+func drawHouseScores(dc *gg.Context, rows []string, house int, x, top, size float64, c color.Color) []textBox {
+	face := embeddedFace(matangiRegular, size)
+	m := regularMetrics(size)
+	boxes := make([]textBox, 0, len(rows))
+	for i, row := range rows {
+		baseline := top + float64(i)*m.lineHeight() + m.capHeight
+		drawText(dc, face, c, row, x, baseline, 0.5)
+		w := float64(font.MeasureString(face, row)) / 64
+		boxes = append(boxes, textBox{text: row, house: house, kind: labelScore,
+			left: x - w/2, top: baseline - m.capHeight, right: x + w/2, bottom: baseline})
+	}
+	return boxes
+}
+
+// regionScoreTop returns where the scores of a region house start: just
+// below its rashi label, or above it when they would leave the house there
+func regionScoreTop(poly []gg.Point, number textBox, rows []string, size float64) float64 {
+	gap := regularMetrics(size).descent
+	x := (number.left + number.right) / 2
+	below := number.bottom + gap
+	if scoreRowsBox(rows, x, below, size).insidePolygon(poly) {
+		return below
+	}
+	above := number.top - gap - scoreRowsHeight(len(rows), size)
+	if scoreRowsBox(rows, x, above, size).insidePolygon(poly) {
+		return above
+	}
+	return below
+}
+
+// insidePolygon reports whether all four corners of b lie in a polygon
+func (b textBox) insidePolygon(poly []gg.Point) bool {
+	for _, p := range []gg.Point{{X: b.left, Y: b.top}, {X: b.right, Y: b.top}, {X: b.right, Y: b.bottom}, {X: b.left, Y: b.bottom}} {
+		if !insidePolygon(poly, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+)
+
+// savScores is a sarvashtakavarga, keyed by rashi, adding up to 337
+var savScores = map[int]int{1: 28, 2: 31, 3: 25, 4: 33, 5: 29, 6: 22, 7: 30, 8: 26, 9: 34, 10: 27, 11: 32, 12: 20}
+
+// houseScoresInput returns a chart with SAV and Jupiter's BAV shown, and
+// planets in a few of the scored houses
+func houseScoresInput(chartType ChartType) ChartInput {
+	return ChartInput{
+		ChartType: chartType,
+		Lagna:     &Planet{Rashi: "virgo"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "virgo"},
+			"mercury": {Rashi: "virgo", IsCombust: true},
+			"venus":   {Rashi: "libra"},
+			"moon":    {Rashi: "aries"},
+			"jupiter": {Rashi: "pisces", IsRetrograde: true},
+			"saturn":  {Rashi: "aquarius"},
+			"rahu":    {Rashi: "gemini"},
+			"ketu":    {Rashi: "sagittarius"},
+			"mars":    {Rashi: "cancer"},
+		},
+		HouseScores:          savScores,
+		SecondaryHouseScores: map[int]int{1: 4, 2: 5, 3: 3, 4: 6, 5: 4, 6: 2, 7: 5, 8: 3, 9: 7, 10: 4, 11: 6, 12: 3},
+		Options:              ChartOptions{ShowHouseScores: true},
+	}
+}
+
+func TestGenerateChart_HouseScores(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_house_scores", data)
+
+		boxes := renderWithBoxes(t, input)
+		if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+		scores := 0
+		for _, b := range boxes.boxes {
+			if b.kind == labelScore {
+				scores++
+			}
+		}
+		if scores != 24 {
+			t.Errorf("%s: %d scores drawn, want 24", chartType, scores)
+		}
+	}
+}
+
+func TestGenerateChart_HouseScoresHidden(t *testing.T) {
+	// Scores are only drawn with the option
+	input := houseScoresInput(ChartTypeSouth)
+	input.Options.ShowHouseScores = false
+	for _, b := range renderWithBoxes(t, input).boxes {
+		if b.kind == labelScore {
+			t.Fatalf("score %q drawn without show_house_scores", b.text)
+		}
+	}
+}
+
+func TestGenerateChart_HouseScoresInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   map[int]int
+		secondary map[int]int
+		want      string
+	}{
+		{"above 56", map[int]int{3: 57}, nil, "house_scores: score 57 for rashi 3 out of range 0-56"},
+		{"negative", map[int]int{3: -1}, nil, "house_scores: score -1"},
+		{"rashi 0", map[int]int{0: 20}, nil, "house_scores: rashi 0 out of range 1-12"},
+		{"rashi 13", nil, map[int]int{13: 2}, "secondary_house_scores: rashi 13"},
+	}
+	for _, tt := range tests {
+		input := ChartInput{ChartType: ChartTypeSouth, HouseScores: tt.primary, SecondaryHouseScores: tt.secondary}
+		_, err := GenerateChart(input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	bounds := ChartInput{ChartType: ChartTypeSouth, HouseScores: map[int]int{1: 0, 12: 56}}
+	if _, err := GenerateChart(bounds); err != nil {
+		t.Errorf("scores 0 and 56 rejected: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+// chartLagnaRashi returns the rashi number houses are counted from: the
+// lagna's, or Aries when the lagna is missing or its rashi unknown
+func chartLagnaRashi(input ChartInput) int {
+	if input.Lagna != nil && RashiToNumber(input.Lagna.Rashi) != 0 {
+		return RashiToNumber(input.Lagna.Rashi)
+	}
+	return 1
+}
+
+// planetsByHouse groups the names of the input's planets by house counted
+// from chartLagnaRashi, indexed 1-12, each house in the order of
+// sortedPlanetNames. This is where every chart places its planets. Nil
+// planets and planets with an unknown rashi are skipped.
+func planetsByHouse(input ChartInput) [13][]string {
+	lagnaRashi := chartLagnaRashi(input)
+	var houses [13][]string
+	for _, name := range sortedPlanetNames(input.Planets) {
+		p := input.Planets[name]
+		if p == nil || RashiToNumber(p.Rashi) == 0 {
+			continue
+		}
+		house := HouseFromLagna(RashiToNumber(p.Rashi), lagnaRashi)
+		houses[house] = append(houses[house], name)
+	}
+	return houses
+}
+
+// PlanetsByHouse returns the names of the input's planets grouped by house
+// (1-12) counted from the lagna, as the charts place them, without drawing
+// anything. Houses are counted from Aries when the lagna is missing, and
+// houses without planets are left out. The lagna itself is not listed. A
+// lagna or planet with an unknown rashi is an error, as it is for the charts.
+func PlanetsByHouse(input ChartInput) (map[int][]string, error) {
+	if input.Lagna != nil && RashiToNumber(input.Lagna.Rashi) == 0 {
+		return nil, &ErrInvalidRashi{Planet: "lagna", Rashi: input.Lagna.Rashi}
+	}
+	for _, name := range sortedPlanetNames(input.Planets) {
+		if p := input.Planets[name]; p != nil && RashiToNumber(p.Rashi) == 0 {
+			return nil, &ErrInvalidRashi{Planet: name, Rashi: p.Rashi}
+		}
+	}
+	result := map[int][]string{}
+	for house, names := range planetsByHouse(input) {
+		if len(names) > 0 {
+			result[house] = names
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanetsByHouse(t *testing.T) {
+	planets := map[string]*Planet{
+		"sun":     {Rashi: "leo"},
+		"mercury": {Rashi: "Leo"},
+		"moon":    {Rashi: "aries"},
+		"saturn":  {Rashi: "pisces"},
+		"venus":   nil,
+	}
+	tests := []struct {
+		name  string
+		lagna *Planet
+		want  map[int][]string
+	}{
+		{"leo lagna", &Planet{Rashi: "leo"}, map[int][]string{1: {"mercury", "sun"}, 8: {"saturn"}, 9: {"moon"}}},
+		{"pisces lagna", &Planet{Rashi: "pisces"}, map[int][]string{1: {"saturn"}, 2: {"moon"}, 6: {"mercury", "sun"}}},
+		// Without a lagna, houses count from Aries
+		{"missing lagna", nil, map[int][]string{1: {"moon"}, 5: {"mercury", "sun"}, 12: {"saturn"}}},
+	}
+	for _, tt := range tests {
+		got, err := PlanetsByHouse(ChartInput{ChartType: ChartTypeSouth, Lagna: tt.lagna, Planets: planets})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: PlanetsByHouse = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanetsByHouse_UnknownRashi(t *testing.T) {
+	tests := []struct {
+		name  string
+		input ChartInput
+		want  string
+	}{
+		{"planet", ChartInput{Planets: map[string]*Planet{"sun": {Rashi: "leo"}, "moon": {Rashi: "ophiuchus"}}}, `planet moon: unknown rashi "ophiuchus"`},
+		{"empty planet rashi", ChartInput{Planets: map[string]*Planet{"mars": {}}}, `planet mars: unknown rashi ""`},
+		{"lagna", ChartInput{Lagna: &Planet{Rashi: "ascendant"}}, `lagna: unknown rashi "ascendant"`},
+	}
+	for _, tt := range tests {
+		if _, err := PlanetsByHouse(tt.input); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPlanetsByHouse_MatchesCharts(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		want, err := PlanetsByHouse(input)
+		if err != nil {
+			t.Fatalf("%s: %v", chartType, err)
+		}
+		result, err := GenerateChartResult(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		if !reflect.DeepEqual(result.Houses, want) {
+			t.Errorf("%s: chart placed %v, PlanetsByHouse %v", chartType, result.Houses, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package httpchart serves Vedic astrology charts over HTTP.
+//
+// Handler accepts a POST whose JSON body is shaped like
+// parashari.ChartInput and responds with the chart image:
+//
+//	http.Handle("/chart", &httpchart.Handler{})
+//
+// The size query parameter sets the canvas width and height in pixels and
+// format picks the image format: png (the default), or svg for the SVG of
+// parashari.GenerateChartSVG, with houses and planets marked for CSS and
+// tooltips. Bad requests are answered
+// with a JSON body such as {"status": 422, "error": "..."}, which lists the
+// fields at fault under "errors" when the Handler validates request bodies
+// against parashari.Schema.
+package httpchart
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+const (
+	// DefaultMaxBodyBytes is the largest request body a Handler reads unless
+	// MaxBodyBytes says otherwise
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultMaxSize is the largest image side a Handler draws unless
+	// MaxSize says otherwise
+	DefaultMaxSize = 4096
+	// DefaultMaxTextRunes is the longest string, in characters, a Handler
+	// accepts in a request body unless MaxTextRunes says otherwise
+	DefaultMaxTextRunes = 1000
+	// DefaultCacheControl is the Cache-Control of chart responses unless
+	// CacheControl says otherwise. A chart depends only on its request, so
+	// it may be cached for a day.
+	DefaultCacheControl = "public, max-age=86400"
+	// VersionHeader carries parashari.Version on every response
+	VersionHeader = "X-Vedic-Charts-Version"
+)
+
+// Handler is an http.Handler that draws the chart described by a POSTed
+// ChartInput. The zero value is ready to use and safe for concurrent
+// requests.
+type Handler struct {
+	// MaxBodyBytes limits the size of request bodies, DefaultMaxBodyBytes
+	// when zero
+	MaxBodyBytes int64
+	// MaxSize limits the width and height of the image, DefaultMaxSize when
+	// zero: the canvas, from the size parameter or the input's options, and
+	// the image once panels such as the dasha table extend it
+	MaxSize int
+	// MaxTextRunes limits the length in characters of every string in a
+	// request body, such as the center text, a planet's display or the
+	// svg_style, DefaultMaxTextRunes when zero
+	MaxTextRunes int
+	// CacheControl is sent with every chart, DefaultCacheControl when empty
+	CacheControl string
+	// ValidateSchema checks request bodies against parashari.Schema before
+	// drawing, answering those that do not match with 422 and an error for
+	// each field
+	ValidateSchema bool
+}
+
+// errorResponse is the JSON body of a failed request
+type errorResponse struct {
+	Status int                     `json:"status"`
+	Error  string                  `json:"error"`
+	Errors []*parashari.FieldError `json:"errors,omitempty"`
+}
+
+// requestError is a failure to serve a request, with the status it is
+// answered with
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// badRequest returns an error answered with 400 Bad Request
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// ServeHTTP draws the chart in the request body. Requests that cannot be
+// read, such as malformed JSON or bad query parameters, get 400 Bad
+// Request; well-formed input the chart cannot be drawn from, such as an
+// unsupported chart type, gets 422 Unprocessable Entity. Every response
+// names the library version in its VersionHeader.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(VersionHeader, parashari.Version())
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, &requestError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("method %s not allowed, use POST", r.Method)})
+		return
+	}
+	data, contentType, err := h.render(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", h.cacheControl())
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// render reads the request and draws its chart, returning the image and its
+// content type
+func (h *Handler) render(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	switch format {
+	case "", "png", "svg":
+	default:
+		return nil, "", badRequest("unsupported format %q, use png or svg", format)
+	}
+	size := 0
+	if s := query.Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, "", badRequest("size must be a positive number of pixels: %q", s)
+		}
+		size = n
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes()))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, "", &requestError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("request body is larger than %d bytes", tooLarge.Limit)}
+		}
+		return nil, "", badRequest("reading request body: %v", err)
+	}
+	if h.ValidateSchema {
+		if err := parashari.ValidateChartJSON(body); err != nil {
+			var schemaErr *parashari.SchemaError
+			if errors.As(err, &schemaErr) {
+				return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: err}
+			}
+			return nil, "", badRequest("%v", err)
+		}
+	}
+	var input parashari.ChartInput
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return nil, "", badRequest("invalid chart JSON: %v", err)
+	}
+	var raw any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, "", badRequest("invalid chart JSON: %v", err)
+	}
+	if err := checkText(raw, "", h.maxTextRunes()); err != nil {
+		return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	if size > 0 {
+		input.Options.Width, input.Options.Height = size, size
+	}
+	limit := h.maxSize()
+	if input.Options.Width > limit || input.Options.Height > limit {
+		return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: fmt.Errorf("canvas %dx%d is larger than %dpx a side", input.Options.Width, input.Options.Height, limit)}
+	}
+	// Panels widen or lengthen the canvas, so the image is sized up before
+	// it is drawn
+	width, height, err := parashari.ChartImageSize(input)
+	if err != nil {
+		return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	if width > limit || height > limit {
+		return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: fmt.Errorf("image %dx%d, panels included, is larger than %dpx a side", width, height, limit)}
+	}
+
+	// Draw the whole chart before answering, so a failure can still be
+	// reported with its status
+	if format == "svg" {
+		data, err := parashari.GenerateChartSVG(input)
+		if err != nil {
+			return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: err}
+		}
+		return data, "image/svg+xml", nil
+	}
+	var buf bytes.Buffer
+	if err := parashari.WriteChart(&buf, input); err != nil {
+		return nil, "", &requestError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// checkText returns an error naming the first string in a decoded JSON
+// value, object keys included, longer than limit characters. Object members
+// are checked in the order of their keys.
+func checkText(v any, path string, limit int) error {
+	switch v := v.(type) {
+	case string:
+		if n := utf8.RuneCountInString(v); n > limit {
+			return fmt.Errorf("%s: %d characters, at most %d are accepted", path, n, limit)
+		}
+	case []any:
+		for i, e := range v {
+			if err := checkText(e, fmt.Sprintf("%s[%d]", path, i), limit); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if n := utf8.RuneCountInString(key); n > limit {
+				return fmt.Errorf("%s: key of %d characters, at most %d are accepted", objectPath(path), n, limit)
+			}
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			if err := checkText(v[key], field, limit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// objectPath names the object at path in errors, the body when path is empty
+func objectPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+// writeError answers a request with err as a JSON error body
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		status = reqErr.status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	resp := errorResponse{Status: status, Error: err.Error()}
+	var schemaErr *parashari.SchemaError
+	if errors.As(err, &schemaErr) {
+		resp.Errors = schemaErr.Errors
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) maxBodyBytes() int64 {
+	if h.MaxBodyBytes > 0 {
+		return h.MaxBodyBytes
+	}
+	return DefaultMaxBodyBytes
+}
+
+func (h *Handler) maxSize() int {
+	if h.MaxSize > 0 {
+		return h.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+func (h *Handler) maxTextRunes() int {
+	if h.MaxTextRunes > 0 {
+		return h.MaxTextRunes
+	}
+	return DefaultMaxTextRunes
+}
+
+func (h *Handler) cacheControl() string {
+	if h.CacheControl != "" {
+		return h.CacheControl
+	}
+	return DefaultCacheControl
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package httpchart
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+const chartJSON = `{
+	"chart_type": "north",
+	"lagna": {"rashi": "leo"},
+	"planets": {
+		"sun": {"rashi": "leo"},
+		"saturn": {"rashi": "libra", "is_retrograde": true}
+	}
+}`
+
+// dashaJSON is a chart whose dasha table widens the canvas
+const dashaJSON = `{
+	"chart_type": "south",
+	"options": {"dasha_table": [{"lord": "Venus", "start": "1990-03-14T00:00:00Z", "end": "2010-03-14T00:00:00Z"}]}
+}`
+
+// textJSON returns a south chart body with the given members
+func textJSON(members string) string {
+	return `{"chart_type": "south", ` + members + `}`
+}
+
+// post sends body to a Handler at target and returns the response
+func post(t *testing.T, h *Handler, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+	return rec
+}
+
+// assertError checks that a response is a JSON error with the given status
+// whose message mentions want
+func assertError(t *testing.T, rec *httptest.ResponseRecorder, status int, want string) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("Status %d, want %d (body: %s)", rec.Code, status, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", ct)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Error body is not JSON: %v", err)
+	}
+	if resp.Status != status || !strings.Contains(resp.Error, want) {
+		t.Errorf("Error body %+v, want status %d mentioning %q", resp, status, want)
+	}
+}
+
+func TestHandler_ServesPNG(t *testing.T) {
+	rec := post(t, &Handler{}, "/chart?size=400", chartJSON)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type %q, want image/png", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != DefaultCacheControl {
+		t.Errorf("Cache-Control %q, want %q", cc, DefaultCacheControl)
+	}
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("Body is not a PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 400 || size.Y != 400 {
+		t.Errorf("Chart is %v, want 400x400", size)
+	}
+}
+
+func TestHandler_ServesSVG(t *testing.T) {
+	rec := post(t, &Handler{}, "/chart?size=400&format=svg", chartJSON)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Content-Type %q, want image/svg+xml", ct)
+	}
+	var svg struct {
+		XMLName xml.Name
+		Width   string `xml:"width,attr"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &svg); err != nil {
+		t.Fatalf("Body is not XML: %v", err)
+	}
+	if svg.XMLName.Local != "svg" || svg.Width != "400" {
+		t.Errorf("Body is <%s width=%q>, want <svg width=\"400\">", svg.XMLName.Local, svg.Width)
+	}
+	if !strings.Contains(rec.Body.String(), `<g id="planet-saturn" class="planet retrograde">`) {
+		t.Error("SVG does not mark the retrograde saturn")
+	}
+}
+
+func TestHandler_Version(t *testing.T) {
+	for _, body := range []string{chartJSON, `{"chart_type": `} {
+		rec := post(t, &Handler{}, "/chart", body)
+		if v := rec.Header().Get(VersionHeader); v != parashari.Version() {
+			t.Errorf("%s = %q (status %d), want %q", VersionHeader, v, rec.Code, parashari.Version())
+		}
+	}
+}
+
+func TestHandler_CacheControl(t *testing.T) {
+	rec := post(t, &Handler{CacheControl: "no-cache"}, "/chart", chartJSON)
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Cache-Control %q, want no-cache", cc)
+	}
+}
+
+func TestHandler_MalformedJSON(t *testing.T) {
+	assertError(t, post(t, &Handler{}, "/chart", `{"chart_type": `), http.StatusBadRequest, "invalid chart JSON")
+	assertError(t, post(t, &Handler{}, "/chart", `{"chart_type": "south", "planet": {}}`), http.StatusBadRequest, `unknown field "planet"`)
+	assertError(t, post(t, &Handler{}, "/chart", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "retro": true}}}`), http.StatusBadRequest, `unknown field "retro"`)
+}
+
+func TestHandler_UnsupportedChartType(t *testing.T) {
+	rec := post(t, &Handler{}, "/chart", strings.Replace(chartJSON, `"north"`, `"east"`, 1))
+	assertError(t, rec, http.StatusUnprocessableEntity, "unsupported chart type: east")
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control %q on an error, want no-store", cc)
+	}
+}
+
+func TestHandler_BadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		h      *Handler
+		target string
+		body   string
+		status int
+		want   string
+	}{
+		{"bad size", &Handler{}, "/chart?size=big", chartJSON, http.StatusBadRequest, "size must be a positive number"},
+		{"unknown format", &Handler{}, "/chart?format=gif", chartJSON, http.StatusBadRequest, `unsupported format "gif"`},
+		{"too large a canvas", &Handler{MaxSize: 1000}, "/chart?size=2000", chartJSON, http.StatusUnprocessableEntity, "larger than 1000px"},
+		{"too large with panels", &Handler{MaxSize: 1000}, "/chart?size=1000", dashaJSON, http.StatusUnprocessableEntity, "panels included, is larger than 1000px"},
+		{"long center text", &Handler{}, "/chart", textJSON(`"center_text": "` + strings.Repeat("x", 1001) + `"`), http.StatusUnprocessableEntity, "center_text: 1001 characters, at most 1000"},
+		{"long display", &Handler{MaxTextRunes: 10}, "/chart", textJSON(`"planets": {"sun": {"rashi": "leo", "display": "Suryadevata"}}`), http.StatusUnprocessableEntity, "planets.sun.display: 11 characters, at most 10"},
+		{"long list item", &Handler{MaxTextRunes: 12}, "/chart", textJSON(`"options": {"aspect_lines": ["sun", "Brihaspatiji-x"]}`), http.StatusUnprocessableEntity, "options.aspect_lines[1]: 14 characters"},
+		{"long planet name", &Handler{MaxTextRunes: 10}, "/chart", textJSON(`"planets": {"Brihaspatiji": {"rashi": "leo"}}`), http.StatusUnprocessableEntity, "planets: key of 12 characters"},
+		{"illegible canvas", &Handler{}, "/chart?size=40", chartJSON, http.StatusUnprocessableEntity, "too small to keep text legible"},
+		{"bad degrees", &Handler{}, "/chart", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "degrees": 45}}}`, http.StatusUnprocessableEntity, "out of range"},
+		{"body too large", &Handler{MaxBodyBytes: 16}, "/chart", chartJSON, http.StatusRequestEntityTooLarge, "larger than 16 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertError(t, post(t, tt.h, tt.target, tt.body), tt.status, tt.want)
+		})
+	}
+}
+
+func TestHandler_ValidateSchema(t *testing.T) {
+	h := &Handler{ValidateSchema: true}
+	if rec := post(t, h, "/chart", chartJSON); rec.Code != http.StatusOK {
+		t.Fatalf("Status %d for a valid chart: %s", rec.Code, rec.Body)
+	}
+	assertError(t, post(t, h, "/chart", `{"chart_type": `), http.StatusBadRequest, "invalid chart JSON")
+
+	body := `{"chart_type": "east", "planets": {"sun": {"rashi": "leon", "degrees": 45}}, "optons": {}}`
+	rec := post(t, h, "/chart", body)
+	assertError(t, rec, http.StatusUnprocessableEntity, "chart_type")
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var fields []string
+	for _, err := range resp.Errors {
+		fields = append(fields, err.Field)
+	}
+	want := []string{"chart_type", "optons", "planets.sun.degrees", "planets.sun.rashi"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Errors for fields %q, want %q (body: %s)", fields, want, rec.Body)
+	}
+
+	// Without validation, only the first problem is reported
+	if rec := post(t, &Handler{}, "/chart", body); strings.Contains(rec.Body.String(), `"errors"`) {
+		t.Errorf("Field errors without ValidateSchema: %s", rec.Body)
+	}
+}
+
+func TestHandler_RejectsGet(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&Handler{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chart", nil))
+	assertError(t, rec, http.StatusMethodNotAllowed, "use POST")
+	if allow := rec.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow %q, want POST", allow)
+	}
+}
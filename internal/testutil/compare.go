@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package testutil holds helpers shared by the chart tests, such as the
+// comparison of rendered charts against golden images.
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// diffColor marks the pixels that differ in a diff image
+var diffColor = color.NRGBA{R: 255, A: 255}
+
+// CompareImages decodes two PNGs and compares them pixel by pixel. A pixel
+// differs when any of its channels, alpha included, is more than tolerance
+// apart, as a fraction of full intensity: 0 demands identical pixels and
+// 0.1 lets every channel be off by a tenth.
+//
+// diff is the fraction of pixels that differ, 0 for matching images and 1
+// when every pixel differs. diffImage is a PNG of want faded to light gray
+// with the differing pixels in red, nil when there are none. Images that do
+// not decode or differ in size are an error.
+func CompareImages(got, want []byte, tolerance float64) (diff float64, diffImage []byte, err error) {
+	gotImg, err := png.Decode(bytes.NewReader(got))
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding got: %w", err)
+	}
+	wantImg, err := png.Decode(bytes.NewReader(want))
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding want: %w", err)
+	}
+	bounds := wantImg.Bounds()
+	if gotImg.Bounds().Size() != bounds.Size() {
+		return 0, nil, fmt.Errorf("size %v differs from %v", gotImg.Bounds().Size(), bounds.Size())
+	}
+
+	limit := uint32(tolerance * 0xffff)
+	offset := gotImg.Bounds().Min.Sub(bounds.Min)
+	marked := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	differing := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			w := wantImg.At(x, y)
+			if channelDistance(gotImg.At(x+offset.X, y+offset.Y), w) > limit {
+				differing++
+				marked.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, diffColor)
+				continue
+			}
+			marked.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, faded(w))
+		}
+	}
+	if differing == 0 {
+		return 0, nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, marked); err != nil {
+		return 0, nil, fmt.Errorf("encoding diff: %w", err)
+	}
+	return float64(differing) / float64(bounds.Dx()*bounds.Dy()), buf.Bytes(), nil
+}
+
+// channelDistance returns the largest difference between the channels of
+// two colors, in 16-bit premultiplied intensity
+func channelDistance(a, b color.Color) uint32 {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return max(absDiff(r1, r2), absDiff(g1, g2), absDiff(b1, b2), absDiff(a1, a2))
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// faded returns a color as the light gray the unchanged parts of a diff
+// image are drawn in, keeping a hint of the chart's lines and text
+func faded(c color.Color) color.NRGBA {
+	gray := color.GrayModel.Convert(c).(color.Gray).Y
+	return color.NRGBA{R: 192 + gray/4, G: 192 + gray/4, B: 192 + gray/4, A: 255}
+}
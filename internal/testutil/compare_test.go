@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package testutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encode returns a w x h PNG of white with the given pixels set
+func encode(t *testing.T, w, h int, pixels map[image.Point]color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.White)
+		}
+	}
+	for p, c := range pixels {
+		img.Set(p.X, p.Y, c)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareImages(t *testing.T) {
+	want := encode(t, 4, 5, map[image.Point]color.Color{{1, 1}: color.Black})
+	slightly := encode(t, 4, 5, map[image.Point]color.Color{{1, 1}: color.Gray{Y: 12}})
+	moved := encode(t, 4, 5, map[image.Point]color.Color{{2, 1}: color.Black})
+
+	tests := []struct {
+		name      string
+		got       []byte
+		tolerance float64
+		want      float64
+	}{
+		{"identical", want, 0, 0},
+		{"within tolerance", slightly, 0.1, 0},
+		{"beyond tolerance", slightly, 0.01, 1.0 / 20},
+		{"moved", moved, 0.5, 2.0 / 20},
+	}
+	for _, tt := range tests {
+		diff, diffImage, err := CompareImages(tt.got, want, tt.tolerance)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if diff != tt.want {
+			t.Errorf("%s: diff = %v, want %v", tt.name, diff, tt.want)
+		}
+		if (diffImage != nil) != (tt.want > 0) {
+			t.Errorf("%s: diff image %v, want one only when the images differ", tt.name, diffImage != nil)
+		}
+	}
+
+	// The diff image marks the differing pixels in red over a faded copy
+	_, diffImage, _ := CompareImages(moved, want, 0)
+	img, err := png.Decode(bytes.NewReader(diffImage))
+	if err != nil {
+		t.Fatalf("Error decoding diff image: %v", err)
+	}
+	for _, p := range []image.Point{{1, 1}, {2, 1}} {
+		if c := color.NRGBAModel.Convert(img.At(p.X, p.Y)); c != diffColor {
+			t.Errorf("diff pixel %v = %v, want red", p, c)
+		}
+	}
+	if c := color.NRGBAModel.Convert(img.At(0, 0)); c == diffColor {
+		t.Error("matching pixel marked as differing")
+	}
+}
+
+func TestCompareImages_Errors(t *testing.T) {
+	want := encode(t, 4, 4, nil)
+	if _, _, err := CompareImages(encode(t, 4, 5, nil), want, 0); err == nil {
+		t.Error("images of different sizes compared without error")
+	}
+	if _, _, err := CompareImages([]byte("not a png"), want, 0); err == nil {
+		t.Error("invalid PNG compared without error")
+	}
+}
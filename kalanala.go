@@ -0,0 +1,220 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// Sizes of the Surya Kalanala chart, for a chart of defaultChartSize
+const (
+	kalanalaBand      = 44 // Depth of the band of nakshatra names inside the rim
+	kalanalaHub       = 66 // Radius of the hub
+	kalanalaCountRing = 84 // Radius of the count of each spoke from the Sun
+	kalanalaSpokeFrom = 98 // Where the spokes start
+	kalanalaFontSize  = 14
+)
+
+// Colors of the janma nakshatra's segment of the band and of its spoke
+var (
+	janmaFill  = color.NRGBA{R: 255, G: 236, B: 179, A: 255}
+	janmaSpoke = color.NRGBA{R: 200, G: 130, B: 0, A: 255}
+)
+
+// kalanalaSpoke is what sits on one spoke of the chakra: its nakshatra and
+// the planets in it, by name
+type kalanalaSpoke struct {
+	nakshatra string
+	planets   []string
+}
+
+// kalanalaSpokes returns the 28 spokes of the chakra clockwise from the top,
+// starting at the Sun's nakshatra (Ashwini without a sun), with the lagna
+// and planets on their nakshatras' spokes
+func kalanalaSpokes(input ChartInput) ([28]kalanalaSpoke, error) {
+	start := 0
+	for name, p := range input.Planets {
+		if strings.EqualFold(name, "sun") {
+			nakshatra, err := planetNakshatra(name, p)
+			if err != nil {
+				return [28]kalanalaSpoke{}, err
+			}
+			start = chakraNakshatra(nakshatra)
+		}
+	}
+	var spokes [28]kalanalaSpoke
+	for i := range spokes {
+		spokes[i].nakshatra = chakraNakshatras[(start+i)%28]
+	}
+	place := func(name string, p *Planet) error {
+		nakshatra, err := planetNakshatra(name, p)
+		if err != nil {
+			return err
+		}
+		i := (chakraNakshatra(nakshatra) - start + 28) % 28
+		spokes[i].planets = append(spokes[i].planets, name)
+		return nil
+	}
+	if input.Lagna != nil {
+		if err := place("lagna", input.Lagna); err != nil {
+			return spokes, err
+		}
+	}
+	for _, name := range sortedPlanetNames(input.Planets) {
+		if err := place(name, input.Planets[name]); err != nil {
+			return spokes, err
+		}
+	}
+	return spokes, nil
+}
+
+// renderSuryaKalanalaChart draws the Surya Kalanala Chakra: the 28
+// nakshatras on spokes around a hub, counted clockwise from the Sun's
+// nakshatra at the top, with the lagna and planets written along their
+// nakshatras' spokes and the janma nakshatra, when set, highlighted
+func renderSuryaKalanalaChart(r *renderer, input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	spokes, err := kalanalaSpokes(input)
+	if err != nil {
+		return nil, err
+	}
+	janma := -1
+	if input.Options.JanmaNakshatra != "" {
+		janma = chakraNakshatra(input.Options.JanmaNakshatra)
+	}
+
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, false)
+	cx, cy := frame.x+frame.width/2, frame.y+frame.height/2
+	rim := frame.width/2 - frame.px(40)
+	band := rim - frame.px(kalanalaBand)
+	step := 360.0 / 28
+
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, nil, nil)
+
+	// The janma nakshatra's segment of the band is filled before any line
+	for i, spoke := range spokes {
+		if chakraNakshatra(spoke.nakshatra) != janma {
+			continue
+		}
+		from, to := float64(i)*step-step/2, float64(i)*step+step/2
+		dc.NewSubPath()
+		dc.DrawArc(cx, cy, rim, (from-90)*math.Pi/180, (to-90)*math.Pi/180)
+		dc.DrawArc(cx, cy, band, (to-90)*math.Pi/180, (from-90)*math.Pi/180)
+		dc.ClosePath()
+		dc.SetColor(janmaFill)
+		dc.Fill()
+	}
+
+	dc.SetRGB(0, 0, 0)
+	dc.SetLineWidth(frame.px(2.5))
+	dc.DrawCircle(cx, cy, rim)
+	dc.Stroke()
+	dc.SetLineWidth(frame.px(1))
+	dc.DrawCircle(cx, cy, band)
+	dc.Stroke()
+	dc.DrawCircle(cx, cy, frame.px(kalanalaHub))
+	dc.Stroke()
+	// The band is divided halfway between the spokes
+	for i := range spokes {
+		angle := float64(i)*step - step/2
+		a, b := radialPoint(cx, cy, band, angle), radialPoint(cx, cy, rim, angle)
+		dc.DrawLine(a.X, a.Y, b.X, b.Y)
+	}
+	dc.Stroke()
+
+	size := frame.px(kalanalaFontSize)
+	countSize := frame.px(11)
+	var legible legibility
+	legible.use(countSize)
+	nameFace, nameMetrics := embeddedFace(matangiBold, size), boldMetrics(size)
+	countFace, countMetrics := embeddedFace(matangiRegular, countSize), regularMetrics(countSize)
+	loc := localeFor(input.Options)
+	gap := frame.px(8)
+	for i, spoke := range spokes {
+		angle := float64(i) * step
+		box := drawTurnedText(dc, nameFace, nameMetrics, textBlack, nakshatraAbbreviation(spoke.nakshatra), radialPoint(cx, cy, (band+rim)/2, angle), tangentTurn(angle))
+		box.kind = labelNakshatra
+		boxes.add(box)
+		count := fmt.Sprint(i + 1)
+		box = drawTurnedText(dc, countFace, countMetrics, houseNumberGray, count, radialPoint(cx, cy, frame.px(kalanalaCountRing), angle), 0)
+		box.kind = labelHouseNumber
+		boxes.add(box)
+
+		// The planets run outwards along the spoke from its start, which
+		// breaks around them
+		spokeColor, spokeWidth := color.Color(houseNumberGray), frame.px(1)
+		if chakraNakshatra(spoke.nakshatra) == janma {
+			spokeColor, spokeWidth = janmaSpoke, frame.px(2.5)
+		}
+		dc.SetColor(spokeColor)
+		dc.SetLineWidth(spokeWidth)
+		from := frame.px(kalanalaSpokeFrom)
+		if len(spoke.planets) > 0 {
+			type spokeLabel struct {
+				name, label string
+				c           color.Color
+			}
+			labels := make([]spokeLabel, len(spoke.planets))
+			length := gap * float64(len(labels))
+			for j, name := range spoke.planets {
+				if name == "lagna" {
+					labels[j] = spokeLabel{name, lagnaLabel(loc, input.Options.DisplayMode, input.Options.LagnaLabel, input.Lagna), PointLagna.color()}
+				} else {
+					p := input.Planets[name]
+					labels[j] = spokeLabel{name, loc.displayName(name, p, input.Options.DisplayMode), PlanetCategory(name, p).color()}
+				}
+				length += float64(font.MeasureString(nameFace, labels[j].label)) / 64
+			}
+			// A crowded spoke shrinks its labels to end at the band
+			face, metrics := nameFace, nameMetrics
+			if room := band - from - gap; length > room {
+				shrunk := size * room / length
+				face, metrics = embeddedFace(matangiBold, shrunk), boldMetrics(shrunk)
+				legible.use(shrunk)
+			}
+			from += gap
+			for _, l := range labels {
+				w := float64(font.MeasureString(face, l.label)) / 64
+				box := drawTurnedText(dc, face, metrics, l.c, l.label, radialPoint(cx, cy, from+w/2, angle), radialTurn(angle))
+				box.kind, box.planet = labelPlanet, l.name
+				boxes.add(box)
+				from += w + gap
+			}
+		}
+		if from < band {
+			a, b := radialPoint(cx, cy, from, angle), radialPoint(cx, cy, band, angle)
+			dc.DrawLine(a.X, a.Y, b.X, b.Y)
+			dc.Stroke()
+		}
+	}
+	if err := legible.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+
+	hubFace, hubMetrics := embeddedFace(matangiRegular, countSize), countMetrics
+	for j, line := range []string{"Surya", "Kalanala"} {
+		baseline := hubMetrics.baseline(cy + (float64(j)-0.5)*hubMetrics.lineHeight()*1.2)
+		drawText(dc, hubFace, textBlack, line, cx, baseline, 0.5)
+	}
+	return dc.Image(), nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"math"
+	"strings"
+	"testing"
+)
+
+func kalanalaInput() ChartInput {
+	return ChartInput{
+		ChartType: ChartTypeSuryaKalanala,
+		Lagna:     &Planet{Rashi: "cancer", Degrees: 10},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "aries", Degrees: 28},
+			"moon":    {Rashi: "virgo", Degrees: 15},
+			"mars":    {Rashi: "capricorn", Degrees: 12},
+			"mercury": {Rashi: "taurus", Degrees: 2},
+			"jupiter": {Rashi: "sagittarius", Degrees: 20},
+			"venus":   {Rashi: "taurus", Degrees: 12},
+			"saturn":  {Rashi: "aquarius", Degrees: 25},
+			"rahu":    {Rashi: "taurus", Degrees: 5},
+			"ketu":    {Rashi: "scorpio", Degrees: 5},
+		},
+		Options: ChartOptions{JanmaNakshatra: "Hasta"},
+	}
+}
+
+func TestGenerateChart_SuryaKalanala(t *testing.T) {
+	encoded, err := GenerateChart(kalanalaInput())
+	if err != nil {
+		t.Fatalf("Error generating surya kalanala chart: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("Error decoding chart: %v", err)
+	}
+	assertGolden(t, "surya_kalanala", data)
+
+	boxes := renderWithBoxes(t, kalanalaInput())
+	if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+		t.Errorf("overlapping labels: %v", overlaps)
+	}
+}
+
+func TestKalanalaSpokes(t *testing.T) {
+	spokes, err := kalanalaSpokes(kalanalaInput())
+	if err != nil {
+		t.Fatalf("kalanalaSpokes: %v", err)
+	}
+	// The Sun at 28° Aries is in Krittika, which takes the top spoke, and
+	// Hasta, where the Moon is, is the 11th from it
+	for _, tc := range []struct {
+		spoke     int
+		nakshatra string
+		planets   string
+	}{
+		{0, "Krittika", "mercury,rahu,sun"},
+		{1, "Rohini", "venus"},
+		{5, "Pushya", "lagna"},
+		{10, "Hasta", "moon"},
+		{19, "Abhijit", ""},
+		{27, "Bharani", ""},
+	} {
+		got := spokes[tc.spoke]
+		if got.nakshatra != tc.nakshatra || strings.Join(got.planets, ",") != tc.planets {
+			t.Errorf("spoke %d = %s with %v, want %s with %s", tc.spoke, got.nakshatra, got.planets, tc.nakshatra, tc.planets)
+		}
+	}
+
+	// Without a sun the spokes start at Ashwini
+	spokes, err = kalanalaSpokes(ChartInput{Planets: map[string]*Planet{"moon": {Nakshatra: "abhijit"}}})
+	if err != nil {
+		t.Fatalf("kalanalaSpokes: %v", err)
+	}
+	if spokes[0].nakshatra != "Ashwini" || strings.Join(spokes[21].planets, ",") != "moon" {
+		t.Errorf("spokes without a sun start at %s, with %v on Abhijit", spokes[0].nakshatra, spokes[21].planets)
+	}
+}
+
+func TestRadialTurn(t *testing.T) {
+	// Text along or across any spoke never turns past a quarter either way,
+	// so it is never upside down
+	for angle := 0.0; angle < 360; angle += 360.0 / 28 {
+		for _, turn := range []float64{radialTurn(angle), tangentTurn(angle)} {
+			if math.Abs(turn) > math.Pi/2+1e-9 {
+				t.Errorf("text at %v° turned by %v radians", angle, turn)
+			}
+		}
+	}
+}
+
+func TestGenerateChart_SuryaKalanalaErrors(t *testing.T) {
+	input := kalanalaInput()
+	input.Options.JanmaNakshatra = "Hastha"
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), `janma_nakshatra: unknown nakshatra "Hastha"`) {
+		t.Errorf("unknown janma nakshatra error = %v", err)
+	}
+
+	input = kalanalaInput()
+	input.Planets["moon"].Nakshatra = "Rohinii"
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), `planet moon: unknown nakshatra "Rohinii"`) {
+		t.Errorf("unknown nakshatra error = %v", err)
+	}
+}
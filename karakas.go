@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"sort"
+)
+
+// Chara karakas in order of rank, from the planet furthest into its sign.
+// The eight-karaka scheme, which counts Rahu, adds the Pitrikaraka (PiK).
+var (
+	charaKarakas      = []string{"AK", "AmK", "BK", "MK", "PK", "GK", "DK"}
+	charaKarakasRahu  = []string{"AK", "AmK", "BK", "MK", "PiK", "PK", "GK", "DK"}
+	charaKarakaGrahas = []string{"sun", "moon", "mars", "mercury", "jupiter", "venus", "saturn"}
+)
+
+// ComputeCharaKarakas returns the Jaimini chara karaka of each planet, keyed
+// by planet name: "AK" (Atmakaraka) for the planet furthest into its sign,
+// then "AmK", "BK", "MK", "PK", "GK" and "DK" (Darakaraka) in descending
+// order of longitude within the sign. longitudes are sidereal longitudes in
+// degrees keyed by lowercase planet name; only the Sun to Saturn count, and
+// with includeRahu also Rahu, whose degrees are counted back from the end of
+// its sign (30 less its longitude in the sign) as it moves backwards, adding
+// "PiK" after "MK" for eight karakas. Planets of equal degrees keep their
+// traditional order. Planets missing from longitudes get no karaka, and the
+// lowest karakas go unassigned.
+func ComputeCharaKarakas(longitudes map[string]float64, includeRahu bool) map[string]string {
+	type ranked struct {
+		name    string
+		degrees float64
+	}
+	candidates, karakas := charaKarakaGrahas, charaKarakas
+	if includeRahu {
+		candidates, karakas = append(append([]string{}, charaKarakaGrahas...), "rahu"), charaKarakasRahu
+	}
+	var planets []ranked
+	for _, name := range candidates {
+		lon, ok := longitudes[name]
+		if !ok {
+			continue
+		}
+		degrees := math.Mod(math.Mod(lon, 30)+30, 30)
+		if name == "rahu" {
+			degrees = 30 - degrees
+		}
+		planets = append(planets, ranked{name, degrees})
+	}
+	sort.SliceStable(planets, func(i, j int) bool { return planets[i].degrees > planets[j].degrees })
+	result := make(map[string]string, len(planets))
+	for i, p := range planets {
+		result[p.name] = karakas[i]
+	}
+	return result
+}
+
+// withKarakas returns the format with the chara karakas of the input's
+// planets, from their rashis and degrees, when they are shown. Planets
+// without degrees cannot be ranked, so they get none and the others rank
+// without them.
+func (f labelFormat) withKarakas(input ChartInput) labelFormat {
+	if !input.Options.ShowCharaKarakas {
+		return f
+	}
+	longitudes := map[string]float64{}
+	for name, p := range input.Planets {
+		if p == nil || p.IsUpagraha || p.IsSpecialLagna || p.Degrees <= 0 {
+			continue
+		}
+		if rashiNum := RashiToNumber(p.Rashi); rashiNum != 0 {
+			longitudes[name] = float64(rashiNum-1)*30 + p.Degrees
+		}
+	}
+	f.karakas = ComputeCharaKarakas(longitudes, input.Options.CharaKarakasWithRahu)
+	return f
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// karakaLongitudes are sidereal longitudes with every graha at a different
+// degree of its sign
+var karakaLongitudes = map[string]float64{
+	"sun":     4*30 + 27.5,  // Leo 27°30'
+	"moon":    1*30 + 3.2,   // Taurus 3°12'
+	"mars":    9*30 + 28.1,  // Capricorn 28°06'
+	"mercury": 5*30 + 15,    // Virgo 15°
+	"jupiter": 3*30 + 5,     // Cancer 5°
+	"venus":   11*30 + 27.4, // Pisces 27°24'
+	"saturn":  6*30 + 20,    // Libra 20°
+	"rahu":    2*30 + 1.5,   // Gemini 1°30', 28°30' counted back
+	"ketu":    8*30 + 1.5,
+	"mandi":   29.9,
+}
+
+func TestComputeCharaKarakas(t *testing.T) {
+	tests := []struct {
+		name        string
+		longitudes  map[string]float64
+		includeRahu bool
+		want        map[string]string
+	}{
+		{
+			name:       "seven karakas",
+			longitudes: karakaLongitudes,
+			want: map[string]string{"mars": "AK", "sun": "AmK", "venus": "BK", "saturn": "MK",
+				"mercury": "PK", "jupiter": "GK", "moon": "DK"},
+		},
+		{
+			name:        "eight karakas with Rahu counted back",
+			longitudes:  karakaLongitudes,
+			includeRahu: true,
+			want: map[string]string{"rahu": "AK", "mars": "AmK", "sun": "BK", "venus": "MK",
+				"saturn": "PiK", "mercury": "PK", "jupiter": "GK", "moon": "DK"},
+		},
+		{
+			name:       "missing planets leave the lowest karakas out",
+			longitudes: map[string]float64{"moon": 12, "saturn": 359},
+			want:       map[string]string{"saturn": "AK", "moon": "AmK"},
+		},
+		{
+			name:       "ties keep the traditional order",
+			longitudes: map[string]float64{"venus": 10, "sun": 70, "mars": -20},
+			want:       map[string]string{"sun": "AK", "mars": "AmK", "venus": "BK"},
+		},
+		{
+			name:       "Rahu is ignored in the seven-karaka scheme",
+			longitudes: map[string]float64{"rahu": 0.5, "moon": 10},
+			want:       map[string]string{"moon": "AK"},
+		},
+	}
+	for _, tt := range tests {
+		if got := ComputeCharaKarakas(tt.longitudes, tt.includeRahu); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: ComputeCharaKarakas = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// karakaInput returns a chart of karakaLongitudes in their rashis with a
+// Leo lagna
+func karakaInput(chartType ChartType) ChartInput {
+	input := ChartInput{ChartType: chartType, Lagna: &Planet{Rashi: "leo", Degrees: 12}, Planets: map[string]*Planet{}}
+	for name, lon := range karakaLongitudes {
+		rashiNum := int(lon/30) + 1
+		input.Planets[name] = &Planet{Rashi: NumberToRashi(rashiNum), Degrees: lon - float64(rashiNum-1)*30}
+	}
+	input.Planets["mandi"].IsUpagraha = true
+	input.Options = ChartOptions{ShowCharaKarakas: true, CharaKarakasWithRahu: true}
+	return input
+}
+
+func TestGenerateChart_CharaKarakas(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := karakaInput(chartType)
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_chara_karakas", data)
+		if overlaps := renderWithBoxes(t, input).overlapping(); len(overlaps) > 0 {
+			t.Errorf("%s: overlapping labels: %v", chartType, overlaps)
+		}
+	}
+
+	input := karakaInput(ChartTypeSouth)
+	f := newLabelFormat(input.Options).withKarakas(input)
+	if e := f.entry("rahu", input.Planets["rahu"]); e.superscript != "AK" {
+		t.Errorf("rahu superscript = %q, want AK", e.superscript)
+	}
+	if e := f.entry("mandi", &Planet{Rashi: "aries", IsUpagraha: true}); e.superscript != "" {
+		t.Errorf("upagraha superscript = %q, want none", e.superscript)
+	}
+}
+
+func TestGenerateChart_CharaKarakasWithoutDegrees(t *testing.T) {
+	// Without degrees Rahu would count as 30° and become the Atmakaraka
+	input := karakaInput(ChartTypeSouth)
+	input.Planets["rahu"].Degrees = 0
+	f := newLabelFormat(input.Options).withKarakas(input)
+	if e := f.entry("rahu", input.Planets["rahu"]); e.superscript != "" {
+		t.Errorf("rahu without degrees superscript = %q, want none", e.superscript)
+	}
+	if e := f.entry("mars", input.Planets["mars"]); e.superscript != "AK" {
+		t.Errorf("mars superscript = %q, want AK", e.superscript)
+	}
+
+	result, err := GenerateChartResult(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if want := "show_chara_karakas: rahu has no degrees, left out of the karakas"; !slices.Contains(result.Warnings, want) {
+		t.Errorf("warnings %q, want %q", result.Warnings, want)
+	}
+}
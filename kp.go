@@ -0,0 +1,99 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// vimshottari lists the dasha lords in order with their years, which add up
+// to 120. The nakshatras are ruled by them in turn from Ashwini (Ketu).
+var vimshottari = [9]struct {
+	lord  string
+	years int
+}{
+	{"ketu", 7}, {"venus", 20}, {"sun", 6}, {"moon", 10}, {"mars", 7},
+	{"rahu", 18}, {"jupiter", 16}, {"saturn", 19}, {"mercury", 17},
+}
+
+// nakshatraArcSeconds is the span of a nakshatra, 13°20', in arc seconds.
+// Each year of a lord's dasha is 400" of its sub, so every sub boundary
+// falls on a whole arc second.
+const nakshatraArcSeconds = 48000
+
+// ComputeKPLords returns the star lord and sub lord of a sidereal longitude
+// in degrees, as KP (Krishnamurti Paddhati) divides the zodiac: the star
+// lord rules the nakshatra, and the nakshatra is split into nine subs in
+// proportion to the Vimshottari years, starting with the star lord's own.
+// Lords are lowercase planet names ("saturn"). Longitudes outside 0-360 are
+// wrapped around the zodiac, and a longitude on a boundary belongs to the
+// sub that begins there. A NaN or infinite longitude has no lords, and
+// both are empty.
+func ComputeKPLords(longitude float64) (starLord, subLord string) {
+	if math.IsNaN(longitude) || math.IsInf(longitude, 0) {
+		return "", ""
+	}
+	lon := math.Mod(longitude, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	// Work in arc seconds, rounded to a thousandth so that a boundary given
+	// in degrees, minutes and seconds does not land just short of itself
+	seconds := math.Round(lon*3600*1000) / 1000
+	index := min(int(seconds/nakshatraArcSeconds), 26)
+	star := index % 9
+	into := seconds - float64(index*nakshatraArcSeconds)
+	end := 0
+	for i := range 9 {
+		sub := vimshottari[(star+i)%9]
+		end += sub.years * nakshatraArcSeconds / 120
+		if into < float64(end) {
+			return vimshottari[star].lord, sub.lord
+		}
+	}
+	// Only rounding just below 360 gets here, the end of Revati's last sub
+	return vimshottari[star].lord, vimshottari[(star+8)%9].lord
+}
+
+// validateKPLord checks that a star or sub lord names a Vimshottari lord
+func validateKPLord(field, lord string) error {
+	if lord == "" {
+		return nil
+	}
+	for _, l := range vimshottari {
+		if strings.EqualFold(l.lord, lord) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q is not a dasha lord", field, lord)
+}
+
+// kpLabel returns the KP lords drawn under a planet ("Sa-Me"), abbreviated
+// in the chart's locale, or an empty string when the planet has none
+func kpLabel(p *Planet, loc *chartLocale) string {
+	if p == nil {
+		return ""
+	}
+	var lords []string
+	for _, lord := range []string{p.StarLord, p.SubLord} {
+		if lord != "" {
+			lords = append(lords, loc.abbreviation(lord))
+		}
+	}
+	return strings.Join(lords, "-")
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// dms returns a longitude from signs, degrees, minutes and seconds
+func dms(sign, deg, min, sec int) float64 {
+	return float64(sign*30+deg) + float64(min)/60 + float64(sec)/3600
+}
+
+func TestComputeKPLords(t *testing.T) {
+	// Rows of the standard KP sub lord table, with the boundaries on either side
+	tests := []struct {
+		name      string
+		longitude float64
+		star, sub string
+	}{
+		{"0° Aries", 0, "ketu", "ketu"},
+		{"just before Ke-Ve", dms(0, 0, 46, 39), "ketu", "ketu"},
+		{"Ke-Ve at 0°46'40\" Aries", dms(0, 0, 46, 40), "ketu", "venus"},
+		{"Ke-Su at 3° Aries", dms(0, 3, 0, 0), "ketu", "sun"},
+		{"Ke-Me at 11°26'40\" Aries", dms(0, 11, 26, 40), "ketu", "mercury"},
+		{"Ve-Ve at 13°20' Aries", dms(0, 13, 20, 0), "venus", "venus"},
+		{"Ve-Su at 15°33'20\" Aries", dms(0, 15, 33, 20), "venus", "sun"},
+		{"Mo-Mo at 10° Taurus", dms(1, 10, 0, 0), "moon", "moon"},
+		{"Ra-Ju at 10° Gemini", dms(2, 10, 0, 0), "rahu", "jupiter"},
+		{"Ke-Ma at 5° Leo", dms(4, 5, 0, 0), "ketu", "mars"},
+		{"Ke-Su at 3°20' Sagittarius", dms(8, 3, 20, 0), "ketu", "sun"},
+		{"Me-Sa at 27°53'20\" Pisces", dms(11, 27, 53, 20), "mercury", "saturn"},
+		{"end of Pisces", dms(11, 29, 59, 59), "mercury", "saturn"},
+		{"-1° wraps to Pisces", -1, "mercury", "saturn"},
+		{"360° wraps to Aries", 360, "ketu", "ketu"},
+		{"NaN has no lords", math.NaN(), "", ""},
+		{"+Inf has no lords", math.Inf(1), "", ""},
+		{"-Inf has no lords", math.Inf(-1), "", ""},
+	}
+	for _, tt := range tests {
+		star, sub := ComputeKPLords(tt.longitude)
+		if star != tt.star || sub != tt.sub {
+			t.Errorf("%s: ComputeKPLords(%v) = %s-%s, want %s-%s", tt.name, tt.longitude, star, sub, tt.star, tt.sub)
+		}
+	}
+
+	// Each nakshatra has nine subs, so 243 star-sub pairs run across the
+	// zodiac; KP's table of 249 also splits the six subs that cross a sign
+	changes, prev := 0, ""
+	for sec := 0; sec < 360*3600; sec += 20 {
+		star, sub := ComputeKPLords(float64(sec) / 3600)
+		if pair := star + "-" + sub; pair != prev {
+			changes, prev = changes+1, pair
+		}
+	}
+	if changes != 27*9 {
+		t.Errorf("%d star-sub pairs across the zodiac, want %d", changes, 27*9)
+	}
+}
+
+func TestLabelFormat_KPLords(t *testing.T) {
+	jupiter := &Planet{Rashi: "virgo", Nakshatra: "Hasta", Pada: 1, StarLord: "moon", SubLord: "Mercury"}
+	tests := []struct {
+		opts ChartOptions
+		want string
+	}{
+		{ChartOptions{ShowKPLords: true}, "Mo-Me"},
+		{ChartOptions{ShowKPLords: true, ShowNakshatra: true}, "Hasta-1 Mo-Me"},
+		{ChartOptions{ShowNakshatra: true}, "Hasta-1"},
+		{ChartOptions{}, ""},
+	}
+	for _, tt := range tests {
+		if got := newLabelFormat(tt.opts).entry("jupiter", jupiter).subLabel; got != tt.want {
+			t.Errorf("subLabel with %+v = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+	if got := kpLabel(&Planet{StarLord: "rahu"}, englishLocale); got != "Ra" {
+		t.Errorf("kpLabel with only a star lord = %q, want %q", got, "Ra")
+	}
+}
+
+func TestGenerateChart_KPLordsValidation(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Planets:   map[string]*Planet{"moon": {Rashi: "taurus", SubLord: "pluto"}},
+	}
+	_, err := GenerateChart(input)
+	if err == nil || !strings.Contains(err.Error(), `planet moon: sub_lord "pluto" is not a dasha lord`) {
+		t.Errorf("error = %v, want sub_lord rejected", err)
+	}
+}
+
+func TestGenerateChart_ShowKPLords(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "leo", StarLord: "ketu", SubLord: "venus"},
+		Planets:   map[string]*Planet{},
+	}
+	for name, lon := range map[string]float64{"sun": 125, "moon": 40, "jupiter": 70, "saturn": 187} {
+		star, sub := ComputeKPLords(lon)
+		input.Planets[name] = &Planet{Rashi: NumberToRashi(int(lon/30) + 1), StarLord: star, SubLord: sub}
+	}
+	input.Options.ShowKPLords = true
+	data, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_kp_lords", data)
+}
@@ -0,0 +1,536 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font/sfnt"
+)
+
+const (
+	// DefaultRetrogradeMarker is appended to retrograde planets ("JuR")
+	DefaultRetrogradeMarker = "R"
+	// DefaultCombustMarker is appended to combust planets ("VeC")
+	DefaultCombustMarker = "C"
+	// DefaultExaltedMarker follows the name of exalted planets ("Su↑")
+	DefaultExaltedMarker = "↑"
+	// DefaultDebilitatedMarker follows the name of debilitated planets ("Sa↓")
+	DefaultDebilitatedMarker = "↓"
+	// DefaultDigbalaMarker follows the name of planets with directional strength ("Ju•")
+	DefaultDigbalaMarker = "•"
+	// DefaultStationaryMarker is added before the other status markers of
+	// stationary planets, so a stationary retrograde planet reads "MaSR"
+	DefaultStationaryMarker = "S"
+	// DefaultStationaryThreshold is the daily motion in degrees below which a
+	// planet counts as stationary
+	DefaultStationaryThreshold = 0.01
+	// DefaultOwnSignMarker comes before the name of planets in a rashi they rule ("·Sa")
+	DefaultOwnSignMarker = "·"
+	// DefaultMoolatrikonaMarker comes before the name of planets in their moolatrikona ("˚Su")
+	DefaultMoolatrikonaMarker = "˚"
+	// DefaultBadhakeshMarker follows the name of the lord of the badhaka house ("Sa×")
+	DefaultBadhakeshMarker = "×"
+	// DefaultVargottamaMarker follows the name of vargottama planets in the marker style
+	DefaultVargottamaMarker = "v"
+	// fallbackRetrogradeMarker is used when the planet font cannot draw the configured marker
+	fallbackRetrogradeMarker = "(R)"
+)
+
+const (
+	// crowdedHouseSize is the number of labels from which a house with degrees
+	// shown drops to a smaller planet font
+	crowdedHouseSize = 4
+	// crowdedFontScale shrinks the planet font of crowded houses
+	crowdedFontScale = 0.8
+	// subLabelScale sizes the nakshatra line, and the extra row height it
+	// needs, relative to the planet font and line height
+	subLabelScale = 0.6
+	// superscriptScale sizes the superscript raised after a label
+	superscriptScale = 0.55
+	// nodeSlant is how far italic node labels lean, sideways per unit of height
+	nodeSlant = 0.15
+)
+
+// StatusStyle controls how status markers are combined with the planet name
+type StatusStyle string
+
+const (
+	// StatusStyleSuffix appends markers directly: "JuR", "MeRC" (the default)
+	StatusStyleSuffix StatusStyle = "suffix"
+	// StatusStyleParenthesized lists markers after the name: "Ju (R)", "Me (R,C)"
+	StatusStyleParenthesized StatusStyle = "parenthesized"
+)
+
+// VargottamaStyle controls how vargottama planets are distinguished
+type VargottamaStyle string
+
+const (
+	// VargottamaStyleBox draws a box around the label (the default)
+	VargottamaStyleBox VargottamaStyle = "box"
+	// VargottamaStyleUnderline underlines the label
+	VargottamaStyleUnderline VargottamaStyle = "underline"
+	// VargottamaStyleMarker appends the vargottama marker to the name
+	VargottamaStyleMarker VargottamaStyle = "marker"
+)
+
+// IsVargottama reports whether a planet occupies the same rashi in the D1 and
+// D9 charts. Rashis are compared by number so name variants match.
+func IsVargottama(p *Planet) bool {
+	if p == nil || p.NavamsaRashi == "" {
+		return false
+	}
+	rashiNum := RashiToNumber(p.Rashi)
+	return rashiNum > 0 && rashiNum == RashiToNumber(p.NavamsaRashi)
+}
+
+// IsStationary reports whether a planet's daily motion is below
+// DefaultStationaryThreshold in either direction. A zero speed means the
+// speed is unknown, so such planets are never stationary.
+func IsStationary(p *Planet) bool {
+	return isStationary(p, DefaultStationaryThreshold)
+}
+
+// isStationary reports whether a planet's known speed is below threshold
+func isStationary(p *Planet, threshold float64) bool {
+	return p != nil && p.SpeedDegPerDay != 0 && math.Abs(p.SpeedDegPerDay) < threshold
+}
+
+// planetEntry is a label drawn in a house together with its decorations
+type planetEntry struct {
+	name        string // Planet name, "lagna" for the lagna
+	label       string
+	subLabel    string // Smaller second line, such as the nakshatra
+	superscript string // Raised after the label, such as the chara karaka and baladi avastha
+	vargottama  bool
+	category    PointCategory // Decides the color and lane, whatever the label reads
+}
+
+// labelFormat holds the resolved markers used to build planet labels
+type labelFormat struct {
+	retrograde  string
+	combust     string
+	exalted     string
+	debilitated string
+	style       StatusStyle
+	autoDignity bool
+
+	vargottamaStyle  VargottamaStyle
+	vargottamaMarker string
+
+	digbalaMarker string       // Empty when digbala is not shown
+	badhakesh     string       // Empty when the badhakesh is not marked
+	ownSign       string       // Empty when own sign and moolatrikona are not shown
+	moolatrikona  string       // Empty with ownSign
+	degrees       DegreeFormat // Empty when degrees are not shown
+	nakshatra     bool         // Draw the nakshatra line under each label
+	kpLords       bool         // Draw the KP lords on the line under each label
+	avastha       bool         // Raise the baladi avastha after each label
+	stationary    string       // Empty when stationary planets are not marked
+	threshold     float64      // Stationary speed threshold in degrees per day
+	lagnaRashi    int          // Zero when the chart has no lagna
+	locale        *chartLocale
+	mode          DisplayMode
+	lagnaLabel    *string           // Nil when the lagna is labelled by name
+	contrast      bool              // Draw in black with heavier strokes, see ChartOptions.HighContrast
+	grayscale     bool              // Draw categories in their print styles, see ChartOptions.Grayscale
+	nodeStyle     NodeStyle         // How the node category stands apart, see ChartOptions.NodeStyle
+	renamed       map[string]string // Names of the planets sharing a name in their house, nil when none do
+
+	conjunctions map[string]conjunctionSlot // Nil when conjunctions are not shown
+	karakas      map[string]string          // Chara karaka by planet, nil when not shown
+}
+
+// newLabelFormat resolves the label options for a chart. Markers the planet
+// font has no glyphs for (e.g. ℞) fall back to plain letters.
+func newLabelFormat(opts ChartOptions) labelFormat {
+	f := labelFormat{
+		locale:      localeFor(opts),
+		mode:        opts.DisplayMode,
+		lagnaLabel:  opts.LagnaLabel,
+		contrast:    opts.HighContrast,
+		grayscale:   opts.Grayscale,
+		nodeStyle:   opts.NodeStyle,
+		retrograde:  opts.RetrogradeMarker,
+		combust:     opts.CombustMarker,
+		exalted:     opts.ExaltedMarker,
+		debilitated: opts.DebilitatedMarker,
+		style:       opts.StatusStyle,
+		autoDignity: opts.AutoDignity,
+
+		vargottamaStyle:  opts.VargottamaStyle,
+		vargottamaMarker: opts.VargottamaMarker,
+
+		nakshatra: opts.ShowNakshatra,
+		kpLords:   opts.ShowKPLords,
+		avastha:   opts.ShowBaladiAvastha,
+		threshold: opts.StationaryThreshold,
+	}
+	if f.vargottamaStyle == "" {
+		f.vargottamaStyle = VargottamaStyleBox
+	}
+	if f.vargottamaMarker == "" || !fontHasGlyphs(matangiBold, f.vargottamaMarker) {
+		f.vargottamaMarker = DefaultVargottamaMarker
+	}
+	if opts.ShowDigbala {
+		f.digbalaMarker = opts.DigbalaMarker
+		if f.digbalaMarker == "" || !fontHasGlyphs(matangiBold, f.digbalaMarker) {
+			f.digbalaMarker = DefaultDigbalaMarker
+		}
+	}
+	if opts.MarkBadhakesh {
+		f.badhakesh = opts.BadhakeshMarker
+		if f.badhakesh == "" || !fontHasGlyphs(matangiBold, f.badhakesh) {
+			f.badhakesh = DefaultBadhakeshMarker
+		}
+	}
+	if opts.ShowOwnSign {
+		f.ownSign, f.moolatrikona = opts.OwnSignMarker, opts.MoolatrikonaMarker
+		if f.ownSign == "" || !fontHasGlyphs(matangiBold, f.ownSign) {
+			f.ownSign = DefaultOwnSignMarker
+		}
+		if f.moolatrikona == "" || !fontHasGlyphs(matangiBold, f.moolatrikona) {
+			f.moolatrikona = DefaultMoolatrikonaMarker
+		}
+	}
+	if opts.ShowStationary {
+		f.stationary = opts.StationaryMarker
+		if f.stationary == "" || !fontHasGlyphs(matangiBold, f.stationary) {
+			f.stationary = DefaultStationaryMarker
+		}
+	}
+	if f.threshold == 0 {
+		f.threshold = DefaultStationaryThreshold
+	}
+	if opts.ShowDegrees {
+		f.degrees = opts.DegreeFormat
+		if f.degrees == "" {
+			f.degrees = DegreeFormatDegree
+		}
+	}
+	if f.style == "" {
+		f.style = StatusStyleSuffix
+	}
+
+	switch {
+	case f.retrograde == "":
+		f.retrograde = DefaultRetrogradeMarker
+	case !fontHasGlyphs(matangiBold, f.retrograde):
+		// "Ju(R)" reads well as a suffix, inside parentheses a bare R is enough
+		f.retrograde = fallbackRetrogradeMarker
+		if f.style == StatusStyleParenthesized {
+			f.retrograde = DefaultRetrogradeMarker
+		}
+	}
+	if f.combust == "" || !fontHasGlyphs(matangiBold, f.combust) {
+		f.combust = DefaultCombustMarker
+	}
+	if f.exalted == "" || !fontHasGlyphs(matangiBold, f.exalted) {
+		f.exalted = DefaultExaltedMarker
+	}
+	if f.debilitated == "" || !fontHasGlyphs(matangiBold, f.debilitated) {
+		f.debilitated = DefaultDebilitatedMarker
+	}
+	return f
+}
+
+// withLagna returns the format for a chart whose lagna is in lagnaRashi,
+// which lagna-relative markers such as digbala need
+func (f labelFormat) withLagna(lagnaRashi int) labelFormat {
+	f.lagnaRashi = lagnaRashi
+	return f
+}
+
+// format returns the label for a planet: its display name, then the dignity
+// marker attached to the name, then the status markers ("Ju↑R", "Ju↑ (R,C)")
+// and finally the degrees when they are shown ("Ju↑R 17°")
+func (f labelFormat) format(planetName string, planet *Planet) string {
+	var b strings.Builder
+	f.writeName(&b, planetName, planet)
+	if f.degrees != "" && planet != nil {
+		b.WriteByte(' ')
+		b.WriteString(formatDegrees(planet.Degrees, f.degrees))
+	}
+	return b.String()
+}
+
+// writeName writes the label for a planet without its degrees
+func (f labelFormat) writeName(b *strings.Builder, planetName string, planet *Planet) {
+	if f.ownSign != "" && planet != nil {
+		// The moolatrikona portion needs degrees, zero when unknown
+		switch {
+		case planet.Degrees > 0 && IsMoolatrikona(planetName, planet.Rashi, planet.Degrees):
+			b.WriteString(f.moolatrikona)
+		case IsOwnSign(planetName, planet.Rashi):
+			b.WriteString(f.ownSign)
+		}
+	}
+	if name, ok := f.renamed[planetName]; ok {
+		b.WriteString(name)
+	} else {
+		b.WriteString(f.locale.displayName(planetName, planet, f.mode))
+	}
+	if planet == nil {
+		return
+	}
+
+	exalted, debilitated := planet.IsExalted, planet.IsDebilitated
+	if f.autoDignity && !exalted && !debilitated {
+		switch GetDignity(planetName, planet.Rashi) {
+		case DignityExalted:
+			exalted = true
+		case DignityDebilitated:
+			debilitated = true
+		}
+	}
+	switch {
+	case exalted:
+		b.WriteString(f.exalted)
+	case debilitated:
+		b.WriteString(f.debilitated)
+	}
+	if f.vargottamaStyle == VargottamaStyleMarker && IsVargottama(planet) {
+		b.WriteString(f.vargottamaMarker)
+	}
+	if f.digbalaMarker != "" && f.lagnaRashi > 0 {
+		if rashiNum := RashiToNumber(planet.Rashi); rashiNum > 0 && HasDigbala(planetName, HouseFromLagna(rashiNum, f.lagnaRashi)) {
+			b.WriteString(f.digbalaMarker)
+		}
+	}
+	if f.badhakesh != "" && f.lagnaRashi > 0 && planetName == BadhakaLord(f.lagnaRashi) {
+		b.WriteString(f.badhakesh)
+	}
+
+	markers := make([]string, 0, 3)
+	if f.stationary != "" && isStationary(planet, f.threshold) {
+		markers = append(markers, f.stationary)
+	}
+	if planet.IsRetrograde {
+		markers = append(markers, f.retrograde)
+	}
+	if planet.IsCombust {
+		markers = append(markers, f.combust)
+	}
+	if len(markers) == 0 {
+		return
+	}
+
+	if f.style == StatusStyleParenthesized {
+		b.WriteString(" (")
+	}
+	for i, m := range markers {
+		if i > 0 && f.style == StatusStyleParenthesized {
+			b.WriteByte(',')
+		}
+		b.WriteString(m)
+	}
+	if f.style == StatusStyleParenthesized {
+		b.WriteByte(')')
+	}
+}
+
+// entry returns the house entry for a planet: its label and decorations
+func (f labelFormat) entry(planetName string, planet *Planet) planetEntry {
+	e := planetEntry{
+		name:       planetName,
+		category:   PlanetCategory(planetName, planet),
+		label:      f.format(planetName, planet),
+		vargottama: f.vargottamaStyle != VargottamaStyleMarker && IsVargottama(planet),
+	}
+	if f.grayscale {
+		e.label = e.category.printStyle().wrap(e.label)
+	}
+	e.subLabel = f.subLabel(planet)
+	e.superscript = f.karakas[planetName]
+	if code := avasthaCode(planetName, planet); f.avastha && code != "" {
+		// Both share the superscript, the karaka first: "AK·Y"
+		if e.superscript != "" {
+			e.superscript += "·"
+		}
+		e.superscript += code
+	}
+	return e
+}
+
+// lagnaEntry returns the house entry for the lagna, and false when its
+// label is empty and the house lists no lagna. The lagna is a point, not a
+// planet, so it is never retrograde, combust or otherwise marked.
+func (f labelFormat) lagnaEntry(lagna *Planet) (planetEntry, bool) {
+	label := lagnaLabel(f.locale, f.mode, f.lagnaLabel, lagna)
+	if label == "" {
+		return planetEntry{}, false
+	}
+	if f.degrees != "" && lagna != nil {
+		label += " " + formatDegrees(lagna.Degrees, f.degrees)
+	}
+	if f.grayscale {
+		label = PointLagna.printStyle().wrap(label)
+	}
+	return planetEntry{name: "lagna", label: label, subLabel: f.subLabel(lagna), category: PointLagna}, true
+}
+
+// color returns the color the entries of a category are drawn in: black in
+// the high-contrast style, the category's shade in grayscale, else its color
+func (f labelFormat) color(c PointCategory) color.Color {
+	switch {
+	case f.contrast:
+		return textBlack
+	case f.grayscale:
+		return c.printStyle().shade
+	case c == PointNode && f.nodeStyle == NodeStyleShaded:
+		return nodeSlate
+	}
+	return c.color()
+}
+
+// lagnaLabel returns the label of the lagna: its Display when set, else the
+// lagna_label option when set, else its name in the locale and mode
+func lagnaLabel(loc *chartLocale, mode DisplayMode, option *string, lagna *Planet) string {
+	if option != nil && (lagna == nil || lagna.Display == "") {
+		return *option
+	}
+	return loc.displayName("lagna", lagna, mode)
+}
+
+// subLabel returns the second line under a planet: its nakshatra and KP
+// lords, as far as they are shown and known
+func (f labelFormat) subLabel(p *Planet) string {
+	var parts []string
+	if f.nakshatra {
+		if s := nakshatraLabel(p); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if f.kpLords {
+		if s := kpLabel(p, f.locale); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fontSize returns the planet font size for a house holding count labels.
+// Degrees make every label longer, so crowded houses drop to a smaller size.
+func (f labelFormat) fontSize(base float64, count int) float64 {
+	if f.degrees != "" && count >= crowdedHouseSize {
+		return base * crowdedFontScale
+	}
+	return base
+}
+
+// draw draws a house entry whose first line is centered on y and anchored
+// horizontally at x like DrawStringAnchored, in color c and the planet font at
+// size, boxing or underlining vargottama planets in the current color and
+// font. A second line is drawn below in a smaller regular font.
+func (f labelFormat) draw(dc *gg.Context, e planetEntry, x, y, ax, size float64, c color.Color) {
+	m := boldMetrics(size)
+	baseline := m.baseline(y)
+	if e.category == PointNode && f.nodeStyle == NodeStyleItalic {
+		// Slant the label and its superscript about their middle line,
+		// which keeps them within their box but for a couple of pixels
+		dc.Push()
+		dc.ShearAbout(-nodeSlant, 0, x, y)
+	}
+	if e.superscript != "" {
+		// The label and its superscript are anchored together, the
+		// superscript's top level with the label's capitals
+		labelW, _ := dc.MeasureString(e.label)
+		x -= ax * (e.width(dc) - labelW)
+		supSize := size * superscriptScale
+		supX := x + (1-ax)*labelW
+		drawText(dc, embeddedFace(matangiRegular, supSize), c, e.superscript, supX, baseline-m.capHeight+regularMetrics(supSize).capHeight, 0)
+	}
+	drawText(dc, embeddedFace(matangiBold, size), c, e.label, x, baseline, ax)
+	if f.contrast {
+		// Drawing the label again a hair to the right thickens its strokes
+		drawText(dc, embeddedFace(matangiBold, size), c, e.label, x+size*highContrastWeight, baseline, ax)
+	}
+	if e.category == PointNode && f.nodeStyle == NodeStyleItalic {
+		dc.Pop()
+	}
+	if e.subLabel != "" {
+		subSize := size * subLabelScale
+		// The second line fills the extra row height below the first
+		subTop := y + m.lineHeight()/2
+		drawText(dc, embeddedFace(matangiRegular, subSize), c, e.subLabel, x, subTop+regularMetrics(subSize).capHeight, ax)
+	}
+	w, _ := dc.MeasureString(e.label)
+	left := x - ax*w
+	if f.grayscale && e.category.printStyle().underline {
+		drawUnderline(dc, c, left, w, baseline, m.descent, size)
+	}
+	if !e.vargottama {
+		return
+	}
+
+	dc.SetLineWidth(1)
+	switch f.vargottamaStyle {
+	case VargottamaStyleUnderline:
+		dc.DrawLine(left, baseline+m.descent/2, left+w, baseline+m.descent/2)
+	default:
+		dc.DrawRectangle(left-3, baseline-m.capHeight-2, w+6, m.lineHeight()+4)
+	}
+	dc.Stroke()
+}
+
+// drawUnderline strokes a line in c under text of width w from left, on
+// baseline in a font of size with descent, where the vargottama underline
+// runs, clear of the line below
+func drawUnderline(dc *gg.Context, c color.Color, left, w, baseline, descent, size float64) {
+	y := baseline + descent/2
+	dc.SetColor(c)
+	dc.SetLineWidth(math.Max(1, size/16))
+	dc.DrawLine(left, y, left+w, y)
+	dc.Stroke()
+}
+
+// width returns the width of an entry's first line in the current font,
+// its superscript included. The superscript is measured in the scaled-down
+// current font, which is at least as wide as the regular font it is drawn in.
+func (e planetEntry) width(dc *gg.Context) float64 {
+	w, _ := dc.MeasureString(e.label)
+	if e.superscript != "" {
+		sw, _ := dc.MeasureString(e.superscript)
+		w += sw * superscriptScale
+	}
+	return w
+}
+
+// FormatPlanetLabel returns the label drawn for a planet with the default
+// options: its display name (or abbreviation), "↑" when exalted or "↓" when
+// debilitated, then "R" when retrograde and "C" when combust
+func FormatPlanetLabel(name string, p *Planet) string {
+	return newLabelFormat(ChartOptions{}).format(name, p)
+}
+
+// fontHasGlyphs reports whether a font covers every rune of s
+func fontHasGlyphs(font *embeddedFont, s string) bool {
+	f, err := font.parse()
+	if err != nil {
+		return false
+	}
+	var buf sfnt.Buffer
+	for _, r := range s {
+		idx, err := f.GlyphIndex(&buf, r)
+		if err != nil || idx == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,437 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewLabelFormat_RetrogradeMarker(t *testing.T) {
+	tests := []struct {
+		marker string
+		style  StatusStyle
+		want   string
+	}{
+		{"", "", "R"},
+		{"R", "", "R"},
+		{"(R)", "", "(R)"},
+		{"*", "", "*"},
+		{"℞", "", "(R)"}, // Matangi has no ℞ glyph
+		{"℞", StatusStyleParenthesized, "R"},
+	}
+	for _, tt := range tests {
+		f := newLabelFormat(ChartOptions{RetrogradeMarker: tt.marker, StatusStyle: tt.style})
+		if f.retrograde != tt.want {
+			t.Errorf("retrograde marker for %q (%s) = %q, want %q", tt.marker, tt.style, f.retrograde, tt.want)
+		}
+	}
+}
+
+func TestFormatPlanetLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		planet *Planet
+		want   string
+	}{
+		{"jupiter", &Planet{}, "Ju"},
+		{"jupiter", &Planet{IsRetrograde: true}, "JuR"},
+		{"venus", &Planet{IsCombust: true}, "VeC"},
+		{"mercury", &Planet{IsRetrograde: true, IsCombust: true}, "MeRC"},
+		{"mandi", &Planet{IsUpagraha: true}, "Mn"},
+		{"mandi", &Planet{IsUpagraha: true, IsRetrograde: true}, "MnR"},
+		{"saturn", &Planet{Display: "Shani"}, "Shani"},
+		{"saturn", &Planet{Display: "Shani", IsRetrograde: true, IsCombust: true}, "ShaniRC"},
+		{"sun", nil, "Su"},
+		{"sun", &Planet{IsExalted: true}, "Su↑"},
+		{"saturn", &Planet{IsDebilitated: true}, "Sa↓"},
+		{"jupiter", &Planet{IsExalted: true, IsRetrograde: true, IsCombust: true}, "Ju↑RC"},
+	}
+	for _, tt := range tests {
+		if got := FormatPlanetLabel(tt.name, tt.planet); got != tt.want {
+			t.Errorf("FormatPlanetLabel(%q, %+v) = %q, want %q", tt.name, tt.planet, got, tt.want)
+		}
+	}
+}
+
+func TestLabelFormat_AllCombinations(t *testing.T) {
+	suffix := newLabelFormat(ChartOptions{RetrogradeMarker: "*", CombustMarker: "^"})
+	parens := newLabelFormat(ChartOptions{StatusStyle: StatusStyleParenthesized})
+
+	tests := []struct {
+		retrograde, combust, upagraha bool
+		display                       string
+		wantSuffix, wantParens        string
+	}{
+		{false, false, false, "", "Me", "Me"},
+		{true, false, false, "", "Me*", "Me (R)"},
+		{false, true, false, "", "Me^", "Me (C)"},
+		{true, true, false, "", "Me*^", "Me (R,C)"},
+		{true, true, true, "", "Me*^", "Me (R,C)"},
+		{false, false, true, "Bu", "Bu", "Bu"},
+		{true, false, true, "Bu", "Bu*", "Bu (R)"},
+		{true, true, false, "Bu", "Bu*^", "Bu (R,C)"},
+	}
+	for _, tt := range tests {
+		p := &Planet{IsRetrograde: tt.retrograde, IsCombust: tt.combust, IsUpagraha: tt.upagraha, Display: tt.display}
+		if got := suffix.format("mercury", p); got != tt.wantSuffix {
+			t.Errorf("suffix format of %+v = %q, want %q", p, got, tt.wantSuffix)
+		}
+		if got := parens.format("mercury", p); got != tt.wantParens {
+			t.Errorf("parenthesized format of %+v = %q, want %q", p, got, tt.wantParens)
+		}
+	}
+}
+
+func TestGenerateChart_InvalidStatusStyle(t *testing.T) {
+	input := ChartInput{ChartType: ChartTypeNorth, Options: ChartOptions{StatusStyle: "superscript"}}
+	if _, err := GenerateChart(input); err == nil {
+		t.Fatal("Expected error for unsupported status style")
+	}
+}
+
+func TestLabelFormat_DignityMarkers(t *testing.T) {
+	parens := newLabelFormat(ChartOptions{StatusStyle: StatusStyleParenthesized})
+	if got := parens.format("mars", &Planet{IsDebilitated: true, IsRetrograde: true}); got != "Ma↓ (R)" {
+		t.Errorf("parenthesized debilitated retrograde label = %q, want %q", got, "Ma↓ (R)")
+	}
+
+	custom := newLabelFormat(ChartOptions{ExaltedMarker: "+", DebilitatedMarker: "-"})
+	if got := custom.format("moon", &Planet{IsExalted: true}); got != "Mo+" {
+		t.Errorf("custom exalted label = %q, want %q", got, "Mo+")
+	}
+	if got := custom.format("moon", &Planet{IsDebilitated: true, IsCombust: true}); got != "Mo-C" {
+		t.Errorf("custom debilitated label = %q, want %q", got, "Mo-C")
+	}
+}
+
+func TestIsVargottama(t *testing.T) {
+	tests := []struct {
+		planet *Planet
+		want   bool
+	}{
+		{&Planet{Rashi: "leo", NavamsaRashi: "leo"}, true},
+		{&Planet{Rashi: "leo", NavamsaRashi: "Leo"}, true},
+		{&Planet{Rashi: "cancer", NavamsaRashi: "aries"}, false},
+		{&Planet{Rashi: "cancer"}, false},
+		{&Planet{Rashi: "bogus", NavamsaRashi: "bogus"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsVargottama(tt.planet); got != tt.want {
+			t.Errorf("IsVargottama(%+v) = %v, want %v", tt.planet, got, tt.want)
+		}
+	}
+}
+
+func TestLabelFormat_VargottamaMarker(t *testing.T) {
+	f := newLabelFormat(ChartOptions{VargottamaStyle: VargottamaStyleMarker})
+	sun := &Planet{Rashi: "leo", NavamsaRashi: "leo", IsRetrograde: true}
+	if got := f.format("sun", sun); got != "SuvR" {
+		t.Errorf("marker style label = %q, want %q", got, "SuvR")
+	}
+	if e := f.entry("sun", sun); e.vargottama {
+		t.Error("marker style must not also box the label")
+	}
+	if e := newLabelFormat(ChartOptions{}).entry("sun", sun); !e.vargottama || e.label != "SuR" {
+		t.Errorf("box style entry = %+v, want boxed SuR", e)
+	}
+}
+
+func TestGenerateChart_Vargottama(t *testing.T) {
+	// Sun is vargottama (Leo in D1 and D9), Moon is not
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "taurus"},
+			Planets: map[string]*Planet{
+				"sun":  {Rashi: "leo", NavamsaRashi: "Leo"},
+				"moon": {Rashi: "cancer", NavamsaRashi: "aries"},
+			},
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_vargottama", imageData)
+	}
+}
+
+func TestIsStationary(t *testing.T) {
+	tests := []struct {
+		speed float64
+		want  bool
+	}{
+		{0.005, true},
+		{-0.009, true},
+		{0.01, false},
+		{-0.5, false},
+		{1.2, false},
+		{0, false}, // Unknown speed
+	}
+	for _, tt := range tests {
+		if got := IsStationary(&Planet{Rashi: "aries", SpeedDegPerDay: tt.speed}); got != tt.want {
+			t.Errorf("IsStationary(speed %v) = %v, want %v", tt.speed, got, tt.want)
+		}
+	}
+	if IsStationary(nil) {
+		t.Error("IsStationary(nil) = true, want false")
+	}
+}
+
+func TestLabelFormat_Stationary(t *testing.T) {
+	stationaryRetrograde := &Planet{Rashi: "gemini", SpeedDegPerDay: -0.004, IsRetrograde: true}
+	tests := []struct {
+		name   string
+		opts   ChartOptions
+		planet *Planet
+		want   string
+	}{
+		{"stationary retrograde", ChartOptions{ShowStationary: true}, stationaryRetrograde, "MaSR"},
+		{"parenthesized", ChartOptions{ShowStationary: true, StatusStyle: StatusStyleParenthesized}, stationaryRetrograde, "Ma (S,R)"},
+		{"stationary direct", ChartOptions{ShowStationary: true}, &Planet{Rashi: "gemini", SpeedDegPerDay: 0.003}, "MaS"},
+		{"moving", ChartOptions{ShowStationary: true}, &Planet{Rashi: "gemini", SpeedDegPerDay: 0.6}, "Ma"},
+		{"custom threshold", ChartOptions{ShowStationary: true, StationaryThreshold: 0.1}, &Planet{Rashi: "gemini", SpeedDegPerDay: 0.05}, "MaS"},
+		{"custom marker", ChartOptions{ShowStationary: true, StationaryMarker: "St"}, &Planet{Rashi: "gemini", SpeedDegPerDay: 0.003}, "MaSt"},
+		{"not shown", ChartOptions{}, stationaryRetrograde, "MaR"},
+	}
+	for _, tt := range tests {
+		if got := newLabelFormat(tt.opts).format("mars", tt.planet); got != tt.want {
+			t.Errorf("%s: format = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_InvalidStationaryThreshold(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Options:   ChartOptions{ShowStationary: true, StationaryThreshold: -1},
+	}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for a negative stationary_threshold")
+	}
+}
+
+func TestLabelFormat_DisplayMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ChartOptions
+		planet string
+		p      *Planet
+		want   string
+	}{
+		{"full name", ChartOptions{DisplayMode: DisplayModeFullName}, "jupiter", &Planet{Rashi: "pisces", IsRetrograde: true}, "JupiterR"},
+		{"upagraha", ChartOptions{DisplayMode: DisplayModeFullName}, "mandi", &Planet{Rashi: "aries"}, "Mandi"},
+		{"display wins", ChartOptions{DisplayMode: DisplayModeFullName}, "moon", &Planet{Rashi: "aries", Display: "Chandra"}, "Chandra"},
+		{"locale", ChartOptions{DisplayMode: DisplayModeFullName, Locale: LocaleIAST}, "venus", &Planet{Rashi: "libra"}, "Śukra"},
+		{"locale without full name", ChartOptions{DisplayMode: DisplayModeFullName, Locale: LocaleIAST}, "gulika", &Planet{Rashi: "libra"}, "Gulika"},
+		{"abbreviation", ChartOptions{DisplayMode: DisplayModeAbbreviation}, "jupiter", &Planet{Rashi: "pisces"}, "Ju"},
+		{"letter", ChartOptions{DisplayMode: DisplayModeLetter}, "mars", &Planet{Rashi: "aries", IsRetrograde: true}, "AR"},
+		{"letter in a locale", ChartOptions{DisplayMode: DisplayModeLetter, Locale: LocaleIAST}, "saturn", &Planet{Rashi: "libra"}, "N"},
+		{"letter upagraha", ChartOptions{DisplayMode: DisplayModeLetter}, "mandi", &Planet{Rashi: "aries"}, "Mn"},
+		{"letter display wins", ChartOptions{DisplayMode: DisplayModeLetter}, "moon", &Planet{Rashi: "aries", Display: "Chandra"}, "Chandra"},
+	}
+	for _, tt := range tests {
+		if got := newLabelFormat(tt.opts).format(tt.planet, tt.p); got != tt.want {
+			t.Errorf("%s: format = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateChart_DisplayModeFullName(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := crowdedHouseInput(chartType)
+		input.Options.DisplayMode = DisplayModeFullName
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_display_mode_full_name", data)
+	}
+
+	input := crowdedHouseInput(ChartTypeNorth)
+	input.Options.DisplayMode = "long"
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for an unsupported display_mode")
+	}
+}
+
+func TestPlanetLetter_Unique(t *testing.T) {
+	names := map[string]string{}
+	for _, name := range grahas {
+		letter := PlanetLetter(name)
+		if utf8.RuneCountInString(letter) != 1 {
+			t.Errorf("PlanetLetter(%s) = %q, want a single letter", name, letter)
+		}
+		if other, ok := names[letter]; ok {
+			t.Errorf("%s and %s are both %q", other, name, letter)
+		}
+		names[letter] = name
+	}
+	// Each letter leads back to its graha
+	for letter, name := range names {
+		if got := PlanetLetter(strings.ToUpper(name)); got != letter {
+			t.Errorf("PlanetLetter(%s) = %q, want %q", strings.ToUpper(name), got, letter)
+		}
+	}
+	if got := PlanetLetter("mandi"); got != "" {
+		t.Errorf("PlanetLetter(mandi) = %q, want empty", got)
+	}
+}
+
+// labelColors renders input and returns, by planet, whether its label has
+// any pixel of color c
+func labelColors(t *testing.T, input ChartInput, c color.Color) map[string]bool {
+	t.Helper()
+	data, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBAModel.Convert(c)
+	colored := map[string]bool{}
+	for _, l := range layout.Labels {
+		if l.Kind != string(labelPlanet) {
+			continue
+		}
+		colored[l.Planet] = false
+		box := image.Rect(int(l.Left), int(l.Top), int(l.Right)+1, int(l.Bottom)+1)
+		for y := box.Min.Y; y < box.Max.Y && !colored[l.Planet]; y++ {
+			for x := box.Min.X; x < box.Max.X; x++ {
+				if color.RGBAModel.Convert(img.At(x, y)) == want {
+					colored[l.Planet] = true
+					break
+				}
+			}
+		}
+	}
+	return colored
+}
+
+func TestGenerateChart_LagnaLabel(t *testing.T) {
+	la, empty := "La", ""
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Options = ChartOptions{LagnaLabel: &la}
+		if labels := planetLabels(t, input); !slices.Contains(labels, "La") || slices.Contains(labels, "Asc") {
+			t.Errorf("%s: labels %q, want La in place of Asc", chartType, labels)
+		}
+		if colored := labelColors(t, input, lagnaSaffron); !colored["lagna"] || colored["sun"] {
+			t.Errorf("%s: saffron labels %v, want only the lagna", chartType, colored)
+		}
+
+		// The lagna's Display wins over the option
+		input.Lagna = &Planet{Rashi: "virgo", Display: "Lagna"}
+		if labels := planetLabels(t, input); !slices.Contains(labels, "Lagna") {
+			t.Errorf("%s: labels %q, want the lagna's Display", chartType, labels)
+		}
+
+		input.Lagna = &Planet{Rashi: "virgo", Degrees: 12}
+		// The letter marker is the one with a box in the layout
+		input.Options = ChartOptions{LagnaLabel: &empty, ShowDegrees: true, LagnaMarkerStyle: LagnaMarkerLetter}
+		_, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		markers := 0
+		for _, l := range layout.Labels {
+			if l.Planet == "lagna" {
+				t.Errorf("%s: lagna labelled %q, want no label", chartType, l.Text)
+			}
+			if l.Kind == string(labelMarker) {
+				markers++
+			}
+		}
+		if chartType == ChartTypeSouth && markers == 0 {
+			t.Error("south: lagna marker missing without a lagna label")
+		}
+	}
+
+	input := thumbnailInput(ChartTypeSouth)
+	input.Options.LagnaLabel = &la
+	th := newThumbnail(input, newChartFrame(thumbnailSize, thumbnailSize, false))
+	if labels := th.houseLabels(RashiToNumber("leo")); labels[0].text != "La" {
+		t.Errorf("thumbnail lagna = %q, want La", labels[0].text)
+	}
+}
+
+func TestGenerateChart_LagnaColorByCategory(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Lagna = &Planet{Rashi: "virgo", Display: "Lagna"}
+		// A planet whose label reads like the lagna's stays black
+		input.Planets["sun"] = &Planet{Rashi: "virgo", Display: "Ascella"}
+		saffron := labelColors(t, input, lagnaSaffron)
+		if !saffron["lagna"] {
+			t.Errorf("%s: lagna labelled Lagna is not saffron", chartType)
+		}
+		if saffron["sun"] {
+			t.Errorf("%s: planet labelled Ascella is saffron", chartType)
+		}
+		if black := labelColors(t, input, textBlack); !black["sun"] {
+			t.Errorf("%s: planet labelled Ascella is not black", chartType)
+		}
+	}
+}
+
+func TestGenerateChart_SpecialLagnaByFlag(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Planets["hora_lagna"] = &Planet{Rashi: "virgo", IsSpecialLagna: true, Display: "Hora Lagna"}
+		// Sharing a special lagna's label does not make a planet one
+		input.Planets["ghati_lagna"] = &Planet{Rashi: "virgo", IsSpecialLagna: true, Display: "GL"}
+		input.Planets["sun"] = &Planet{Rashi: "virgo", Display: "GL"}
+		if !IsSpecialLagna("hora_lagna", input) || IsSpecialLagna("sun", input) || IsSpecialLagna("pluto", input) {
+			t.Errorf("%s: IsSpecialLagna misclassifies the planets", chartType)
+		}
+
+		yellow := labelColors(t, input, specialYellow)
+		if !yellow["hora_lagna"] || !yellow["ghati_lagna"] || yellow["sun"] {
+			t.Errorf("%s: yellow labels %v, want the special lagnas only", chartType, yellow)
+		}
+		_, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		boxes := map[string]LabelLayout{}
+		for _, l := range layout.Labels {
+			if l.Kind == string(labelPlanet) {
+				boxes[l.Planet] = l
+			}
+		}
+		// The right-hand column starts clear of the planets
+		for _, planet := range []string{"sun", "mercury", "lagna"} {
+			if boxes["hora_lagna"].Left < boxes[planet].Right {
+				t.Errorf("%s: hora lagna at %.1f is left of %s ending at %.1f", chartType, boxes["hora_lagna"].Left, planet, boxes[planet].Right)
+			}
+		}
+	}
+}
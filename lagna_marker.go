@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// LagnaMarkerStyle controls how the South chart marks the lagna's cell
+type LagnaMarkerStyle string
+
+const (
+	// LagnaMarkerDoubleSlash draws two short parallel diagonals across the
+	// cell's bottom-left corner (the default)
+	LagnaMarkerDoubleSlash LagnaMarkerStyle = "double_slash"
+	// LagnaMarkerSingleSlash draws one longer diagonal across the corner
+	LagnaMarkerSingleSlash LagnaMarkerStyle = "single_slash"
+	// LagnaMarkerFullDiagonal draws one diagonal from the cell's bottom-left
+	// corner to its top-right corner
+	LagnaMarkerFullDiagonal LagnaMarkerStyle = "full_diagonal"
+	// LagnaMarkerLetter writes "ल" (la, for lagna) in the cell's bottom-left corner
+	LagnaMarkerLetter LagnaMarkerStyle = "letter"
+)
+
+// lagnaLetter is the letter drawn by LagnaMarkerLetter
+const lagnaLetter = "ल"
+
+// drawLagnaMarker marks a cell as the lagna with diagonals cutting off its
+// bottom-left corner or crossing the whole cell, or with a letter. Lengths and
+// spacing scale with the cell, and the strokes are clipped to the cell's
+// inside so they never run over its border. The letter is written in the
+// current font, whose size is fontSize, and its box is returned so planet
+// labels can keep clear of it.
+func drawLagnaMarker(dc *gg.Context, rect image.Rectangle, style LagnaMarkerStyle, fontSize float64) []textBox {
+	if style == LagnaMarkerLetter {
+		return drawLagnaLetter(dc, rect, fontSize)
+	}
+
+	cell := float64(min(rect.Dx(), rect.Dy()))
+	lineWidth := math.Max(1, cell/90)
+	// Keep clear of the cell border, which is stroked along the rect's edges
+	inset := lineWidth
+	left := float64(rect.Min.X) + inset
+	bottom := float64(rect.Max.Y) - inset
+
+	// Each diagonal runs at 45° from the left edge to the bottom edge, so it
+	// is described by how far along both edges it starts from the corner
+	var legs []float64
+	switch style {
+	case LagnaMarkerSingleSlash:
+		legs = []float64{cell * 0.15}
+	case LagnaMarkerFullDiagonal:
+		// Drawn corner to corner below, with no corner diagonals
+	default:
+		legs = []float64{cell * 0.085, cell * 0.085 * 1.4}
+	}
+
+	dc.Push()
+	dc.DrawRectangle(left, float64(rect.Min.Y)+inset, float64(rect.Dx())-2*inset, float64(rect.Dy())-2*inset)
+	dc.Clip()
+	dc.SetRGB(0, 0, 0)
+	dc.SetLineWidth(lineWidth)
+	if style == LagnaMarkerFullDiagonal {
+		// Drawn corner to corner, clear of the rashi and house numbers in
+		// the other two corners. The clip stops it at the borders.
+		dc.DrawLine(float64(rect.Min.X), float64(rect.Max.Y), float64(rect.Max.X), float64(rect.Min.Y))
+		dc.Stroke()
+	}
+	for _, leg := range legs {
+		dc.DrawLine(left, bottom-leg, left+leg, bottom)
+		dc.Stroke()
+	}
+	// Pop restores the drawing state but keeps the clip mask
+	dc.ResetClip()
+	dc.Pop()
+	return nil
+}
+
+// drawLagnaLetter writes the lagna letter in the bottom-left corner of a
+// cell, level with the rashi number in the opposite corner, and returns its box
+func drawLagnaLetter(dc *gg.Context, rect image.Rectangle, fontSize float64) []textBox {
+	above, below := regularInk(fontSize, lagnaLetter)
+	x := float64(rect.Min.X) + 8
+	baseline := float64(rect.Max.Y) - regularMetrics(fontSize).descent
+	// Letters that drop below the baseline are raised to clear the border
+	baseline = math.Min(baseline, float64(rect.Max.Y)-below-2)
+	w, _ := dc.MeasureString(lagnaLetter)
+
+	drawText(dc, embeddedFace(matangiRegular, fontSize), textBlack, lagnaLetter, x, baseline, 0)
+	return []textBox{{text: lagnaLetter, left: x, top: baseline - above, right: x + w, bottom: baseline + below}}
+}
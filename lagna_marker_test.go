@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/fogleman/gg"
+)
+
+func TestDrawLagnaMarker_StaysInsideCell(t *testing.T) {
+	for _, style := range []LagnaMarkerStyle{LagnaMarkerDoubleSlash, LagnaMarkerSingleSlash, LagnaMarkerFullDiagonal} {
+		for _, cell := range []int{40, 180, 400} {
+			rect := image.Rect(20, 20, 20+cell, 20+cell)
+			dc := gg.NewContext(cell+40, cell+40)
+			dc.SetRGB(1, 1, 1)
+			dc.Clear()
+			drawLagnaMarker(dc, rect, style, 16)
+
+			// The cell's border pixels are the first column and the last row
+			inside := image.Rect(rect.Min.X+1, rect.Min.Y+1, rect.Max.X-1, rect.Max.Y-1)
+			img := dc.Image()
+			drawn := 0
+			for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+				for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+					if r, g, b, _ := img.At(x, y).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+						continue
+					}
+					drawn++
+					if !(image.Point{X: x, Y: y}).In(inside) {
+						t.Errorf("%s marker in a %dpx cell draws at (%d, %d), on or over the border", style, cell, x, y)
+					}
+				}
+			}
+			if drawn == 0 {
+				t.Errorf("%s marker in a %dpx cell draws nothing", style, cell)
+			}
+		}
+	}
+}
+
+func TestSouthChart_LagnaMarkerCornerCells(t *testing.T) {
+	// The corner cells hold Pisces (12), Gemini (3), Virgo (6) and Sagittarius (9)
+	for _, rashi := range []int{12, 3, 6, 9} {
+		input := ChartInput{
+			ChartType: ChartTypeSouth,
+			Lagna:     &Planet{Rashi: NumberToRashi(rashi)},
+			Planets: map[string]*Planet{
+				"sun":  {Rashi: NumberToRashi(rashi)},
+				"moon": {Rashi: NumberToRashi(rashi)},
+			},
+		}
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating chart: %v", err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, fmt.Sprintf("south_lagna_marker_%d", rashi), imageData)
+	}
+}
+
+func TestSouthChart_LagnaMarkerSingleSlash(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets:   map[string]*Planet{"sun": {Rashi: "aries"}},
+		Options:   ChartOptions{LagnaMarkerStyle: LagnaMarkerSingleSlash},
+	}
+	base64Image, err := GenerateChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	imageData, err := base64.StdEncoding.DecodeString(base64Image)
+	if err != nil {
+		t.Fatalf("Error decoding base64: %v", err)
+	}
+	assertGolden(t, "south_lagna_marker_single_slash", imageData)
+
+	input.Options.LagnaMarkerStyle = "triple"
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("Expected validation error for lagna_marker_style \"triple\"")
+	}
+}
+
+func TestSouthChart_LagnaMarkerFullDiagonal(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "gemini"},
+		Planets:   map[string]*Planet{"sun": {Rashi: "gemini"}},
+		Options:   ChartOptions{LagnaMarkerStyle: LagnaMarkerFullDiagonal},
+	}
+	imageData, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_lagna_marker_full_diagonal", imageData)
+}
+
+func TestSouthChart_LagnaMarkerLetter(t *testing.T) {
+	// A crowded lagna cell, whose planets must keep clear of the letter
+	input := crowdedHouseInput(ChartTypeSouth)
+	input.Options.LagnaMarkerStyle = LagnaMarkerLetter
+
+	boxes := renderWithBoxes(t, input)
+	found := false
+	for _, b := range boxes.boxes {
+		if b.text == lagnaLetter {
+			found = b.house == 1
+		}
+	}
+	if !found {
+		t.Errorf("Lagna letter %q not recorded in the lagna cell", lagnaLetter)
+	}
+	for _, pair := range boxes.overlapping() {
+		t.Errorf("%q overlaps %q", pair[0].text, pair[1].text)
+	}
+
+	imageData, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_lagna_marker_letter", imageData)
+}
@@ -0,0 +1,654 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// planetFontSteps are the planet font sizes, relative to a chart's design
+// size, that a crowded house shrinks through before wrapping into two columns
+var planetFontSteps = []float64{1, 18.0 / 22, 14.0 / 22}
+
+// labelMargin keeps labels clear of the house borders
+const labelMargin = 2.0
+
+// ellipsis ends labels cut short to fit their house
+const ellipsis = "…"
+
+// minColumnGap is how close, in line heights, the planet and special lagna
+// columns may move together when a house is too narrow for the anchor's gap
+const minColumnGap = 0.5
+
+// labelRegion is the part of a house that planet labels may occupy
+type labelRegion struct {
+	top, bottom float64
+	// nudge is how far, in line heights, a row may move sideways to stay in
+	// the region. Rows in slanted regions that move further drift off their column.
+	nudge float64
+	// columnHeight is how much of the region's height one column of labels
+	// can comfortably use
+	columnHeight float64
+	// span returns the horizontal extent of the region along the line y
+	span func(y float64) (xmin, xmax float64, ok bool)
+	// avoid holds the fixed labels of the house, such as its rashi number,
+	// that planet labels must keep clear of
+	avoid []textBox
+}
+
+// avoiding returns the region with the given fixed labels kept clear
+func (r labelRegion) avoiding(boxes ...textBox) labelRegion {
+	r.avoid = append(append([]textBox{}, r.avoid...), boxes...)
+	return r
+}
+
+// rectRegion returns the label region of an axis-aligned rectangle
+func rectRegion(left, top, right, bottom float64) labelRegion {
+	return labelRegion{
+		top:          top,
+		bottom:       bottom,
+		nudge:        math.Inf(1),
+		columnHeight: bottom - top,
+		span: func(y float64) (float64, float64, bool) {
+			return left, right, y >= top && y <= bottom
+		},
+	}
+}
+
+// polygonRegion returns the label region of a convex polygon. A column of
+// labels can comfortably use the band where the polygon is at least half as
+// wide as at its widest.
+func polygonRegion(poly []gg.Point) labelRegion {
+	r := labelRegion{top: math.Inf(1), bottom: math.Inf(-1), nudge: 1}
+	for _, p := range poly {
+		r.top, r.bottom = math.Min(r.top, p.Y), math.Max(r.bottom, p.Y)
+	}
+	r.span = func(y float64) (float64, float64, bool) {
+		return polygonSpanAt(poly, y)
+	}
+	var widths []float64
+	widest := 0.0
+	for y := math.Ceil(r.top); y <= r.bottom; y++ {
+		if xmin, xmax, ok := r.span(y); ok {
+			widths = append(widths, xmax-xmin)
+			widest = math.Max(widest, xmax-xmin)
+		}
+	}
+	for _, w := range widths {
+		if w >= widest/2 {
+			r.columnHeight++
+		}
+	}
+	return r
+}
+
+// polygonSpanAt returns the horizontal extent of a convex polygon along the
+// line y. ok is false when the line misses the polygon.
+func polygonSpanAt(poly []gg.Point, y float64) (xmin, xmax float64, ok bool) {
+	xmin, xmax = math.Inf(1), math.Inf(-1)
+	for i, a := range poly {
+		b := poly[(i+1)%len(poly)]
+		if (y < a.Y && y < b.Y) || (y > a.Y && y > b.Y) {
+			continue
+		}
+		if a.Y == b.Y {
+			xmin, xmax = math.Min(xmin, math.Min(a.X, b.X)), math.Max(xmax, math.Max(a.X, b.X))
+			continue
+		}
+		x := a.X + (y-a.Y)/(b.Y-a.Y)*(b.X-a.X)
+		xmin, xmax = math.Min(xmin, x), math.Max(xmax, x)
+	}
+	return xmin, xmax, xmin <= xmax
+}
+
+// boxSpan returns the horizontal extent of the region usable by a box
+// spanning top to bottom, which for convex regions is the narrower of the
+// spans at its two edges. A fixed label level with the box leaves it the
+// wider side of the span.
+func (r labelRegion) boxSpan(top, bottom float64) (xmin, xmax float64, ok bool) {
+	if top < r.top || bottom > r.bottom {
+		return 0, 0, false
+	}
+	topMin, topMax, topOK := r.span(top)
+	bottomMin, bottomMax, bottomOK := r.span(bottom)
+	if !topOK || !bottomOK {
+		return 0, 0, false
+	}
+	xmin, xmax = math.Max(topMin, bottomMin)+labelMargin, math.Min(topMax, bottomMax)-labelMargin
+	for _, b := range r.avoid {
+		if b.top >= bottom || b.bottom <= top || b.left-labelMargin >= xmax || b.right+labelMargin <= xmin {
+			continue
+		}
+		if b.left-xmin >= xmax-b.right {
+			xmax = b.left - labelMargin
+		} else {
+			xmin = b.right + labelMargin
+		}
+	}
+	return xmin, xmax, xmin < xmax
+}
+
+// houseAnchor is where a house's labels go when they fit: regular planets
+// end at leftX and special lagnas start at rightX, row by row from y
+type houseAnchor struct {
+	leftX, rightX float64
+	y             float64 // Center of the first row
+	middle        bool    // y is the middle of the stack rather than its first row
+	size          float64 // Design planet font size of the chart
+	oneColumn     bool    // Never split the labels into balanced columns
+	spacing       float64 // Row spacing in font sizes, zero for the font's line height
+	inline        bool    // List the labels left to right in wrapping rows
+}
+
+// newHouseAnchor returns the anchor of a house with the row spacing and
+// stacking of the chart's options, leftX to rightX being the gap between
+// the planet and special lagna columns
+func newHouseAnchor(o ChartOptions, frame chartFrame, leftX, rightX, y, size float64) houseAnchor {
+	anchor := houseAnchor{
+		leftX:   leftX,
+		rightX:  rightX,
+		y:       y,
+		size:    size,
+		spacing: o.PlanetLineSpacing,
+		inline:  o.PlanetStacking == PlanetStackingInline,
+	}
+	if o.PlanetLineSpacingPx > 0 {
+		anchor.spacing = frame.px(o.PlanetLineSpacingPx) / size
+	}
+	return anchor
+}
+
+// pitch returns the distance between rows of labels at a font size, never
+// less than the font's line height
+func (a houseAnchor) pitch(size float64) float64 {
+	return math.Max(planetLineHeight(size), a.spacing*size)
+}
+
+// placedLabel is a house entry positioned by layoutHouse
+type placedLabel struct {
+	entry planetEntry
+	x, y  float64 // Anchor point, y is the center of the main line
+	ax    float64 // Horizontal anchor as in DrawStringAnchored
+	comma bool    // Followed by a comma, as labels inside an inline row are
+}
+
+// houseLayout is the planet font size and label positions of a house
+type houseLayout struct {
+	size   float64
+	labels []placedLabel
+	inline bool // The labels run left to right in rows
+}
+
+// layoutHouse places the labels of a house inside region, clear of its fixed
+// labels, starting at the given font size. When there are more labels than a
+// column of the region has rows at that size, they are split into two
+// balanced columns, special lagnas after the planets, unless the anchor
+// keeps to one column. Otherwise, or when the
+// columns do not fit, the planets and special lagnas are stacked side by
+// side. When neither fits the font shrinks through planetFontSteps and both
+// are tried again. Failing that, all labels are stacked in one column
+// (special lagnas below the planets) if the house is tall enough, and
+// otherwise wrap into two columns of equal length, cutting labels still too
+// wide for the house short with an ellipsis. Rows are finally nudged sideways
+// to stay in the region. Inline anchors flow the labels instead (see
+// layoutInline).
+func layoutHouse(dc *gg.Context, left, right []planetEntry, anchor houseAnchor, region labelRegion, size float64) houseLayout {
+	sizes := []float64{size}
+	for _, step := range planetFontSteps[1:] {
+		if smaller := anchor.size * step; smaller < sizes[len(sizes)-1] {
+			sizes = append(sizes, smaller)
+		}
+	}
+	all := append(append([]planetEntry{}, left...), right...)
+	if anchor.inline {
+		return layoutInline(dc, all, anchor, region, sizes)
+	}
+	half := (len(all) + 1) / 2
+	var layout houseLayout
+	for _, size := range sizes {
+		if !anchor.oneColumn && len(all) > regionRows(region, anchor, size) {
+			columns := stackLabels(dc, all[:half], all[half:], anchor, region, size)
+			if fitsRegion(dc, columns, anchor, region) {
+				return clampToRegion(dc, columns, anchor, region)
+			}
+		}
+		layout = stackLabels(dc, left, right, anchor, region, size)
+		if fitsRegion(dc, layout, anchor, region) {
+			return clampToRegion(dc, layout, anchor, region)
+		}
+	}
+
+	// One column centered on the middle of the gap, right-aligned like the planets
+	widest := 0.0
+	for _, e := range all {
+		widest = math.Max(widest, e.width(dc))
+	}
+	column := anchor
+	column.leftX = (anchor.leftX+anchor.rightX)/2 + widest/2
+	stacked := stackLabels(dc, all, nil, column, region, layout.size)
+	if fitsRegion(dc, stacked, column, region) {
+		return clampToRegion(dc, stacked, column, region)
+	}
+	if !fitsHeight(dc, stacked, column, region) {
+		stacked = stackLabels(dc, all[:half], all[half:], anchor, region, layout.size)
+		column = anchor
+	}
+	stacked = ellipsizeRows(dc, stacked, column, region)
+	return clampToRegion(dc, stacked, column, region)
+}
+
+// regionRows returns how many rows of labels at a font size one column of
+// the region holds
+func regionRows(region labelRegion, anchor houseAnchor, size float64) int {
+	lineHeight := planetLineHeight(size)
+	if region.columnHeight < lineHeight {
+		return 0
+	}
+	return int((region.columnHeight-lineHeight)/anchor.pitch(size)) + 1
+}
+
+// layoutInline places the labels of a house in rows read left to right, a
+// comma after each label but the last of its row, starting a new row when
+// the next label would run past the region. Rows are centered on the middle
+// of the anchor's gap and stack like the rows of layoutHouse. The font
+// shrinks through planetFontSteps until the rows fit the region; failing
+// that, labels of rows still too wide are cut short with an ellipsis.
+func layoutInline(dc *gg.Context, all []planetEntry, anchor houseAnchor, region labelRegion, sizes []float64) houseLayout {
+	var layout houseLayout
+	for _, size := range sizes {
+		layout = flowLabels(dc, all, anchor, region, size)
+		if fitsRegion(dc, layout, anchor, region) {
+			return clampToRegion(dc, layout, anchor, region)
+		}
+	}
+	layout = ellipsizeRows(dc, layout, anchor, region)
+	return clampToRegion(dc, layout, anchor, region)
+}
+
+// flowLabels breaks labels into rows as wide as the region at a font size,
+// moving the rows back when they would run past the top or bottom of the
+// region. The rows are broken again once moved, as slanted regions are
+// wider or narrower further down.
+func flowLabels(dc *gg.Context, all []planetEntry, anchor houseAnchor, region labelRegion, size float64) houseLayout {
+	loadMatangiBold(dc, size)
+	lineHeight := planetLineHeight(size)
+	y := anchor.y
+	layout := flowRows(dc, all, anchor, region, size, y)
+	if len(layout.labels) == 0 {
+		return layout
+	}
+	last := layout.labels[len(layout.labels)-1]
+	height := last.y - y + lineHeight
+	for _, l := range layout.labels {
+		if l.y == last.y && l.entry.subLabel != "" {
+			height += lineHeight * subLabelScale
+			break
+		}
+	}
+	if anchor.middle {
+		y -= (height - lineHeight) / 2
+	}
+	if y-lineHeight/2+height > region.bottom {
+		y = region.bottom - height + lineHeight/2
+	}
+	if y-lineHeight/2 < region.top {
+		y = region.top + lineHeight/2
+	}
+	if y == anchor.y {
+		return layout
+	}
+	return flowRows(dc, all, anchor, region, size, y)
+}
+
+// flowRows breaks labels into rows from the row centered on y, in the
+// current font
+func flowRows(dc *gg.Context, all []planetEntry, anchor houseAnchor, region labelRegion, size, y float64) houseLayout {
+	lineHeight := planetLineHeight(size)
+	gap, _ := dc.MeasureString(", ")
+	mid := (anchor.leftX + anchor.rightX) / 2
+	layout := houseLayout{size: size, inline: true}
+
+	for start := 0; start < len(all); {
+		width := math.Inf(1)
+		if xmin, xmax, ok := region.boxSpan(y-lineHeight/2, y+lineHeight/2); ok {
+			width = xmax - xmin
+		}
+		// Take labels while they fit, and always the first
+		end, rowW := start+1, all[start].width(dc)
+		for end < len(all) {
+			w := rowW + gap + all[end].width(dc)
+			if w > width {
+				break
+			}
+			rowW = w
+			end++
+		}
+		x := mid - rowW/2
+		second := false
+		for i := start; i < end; i++ {
+			layout.labels = append(layout.labels, placedLabel{entry: all[i], x: x, y: y, comma: i < end-1})
+			x += all[i].width(dc) + gap
+			second = second || all[i].subLabel != ""
+		}
+		y += anchor.pitch(size)
+		if second {
+			y += lineHeight * subLabelScale
+		}
+		start = end
+	}
+	return layout
+}
+
+// stackLabels lays out two columns of labels row by row at the given size,
+// moving the stack back when it would run past the top or bottom of the region
+func stackLabels(dc *gg.Context, left, right []planetEntry, anchor houseAnchor, region labelRegion, size float64) houseLayout {
+	loadMatangiBold(dc, size)
+	lineHeight := planetLineHeight(size)
+	rows := rowOffsets(left, right, lineHeight, anchor.pitch(size))
+
+	y := anchor.y
+	if len(rows) > 0 {
+		height := rows[len(rows)-1] + lineHeight
+		if anchor.middle {
+			y -= (height - lineHeight) / 2
+		}
+		// Keep the stack between the top and bottom of the region
+		if y-lineHeight/2+height > region.bottom {
+			y = region.bottom - height + lineHeight/2
+		}
+		if y-lineHeight/2 < region.top {
+			y = region.top + lineHeight/2
+		}
+	}
+
+	leftX, rightX := columnAnchors(dc, left, right, anchor, region, y, rows, lineHeight)
+	layout := houseLayout{size: size}
+	for i, e := range left {
+		layout.labels = append(layout.labels, placedLabel{entry: e, x: leftX, y: y + rows[i], ax: 1})
+	}
+	for i, e := range right {
+		layout.labels = append(layout.labels, placedLabel{entry: e, x: rightX, y: y + rows[i], ax: 0})
+	}
+	return layout
+}
+
+// columnAnchors returns where the planet column ends and the special lagna
+// column starts, measuring the labels in the current font. The columns keep
+// the anchor's gap when the rows leave room for it and otherwise close up
+// around its middle, down to minColumnGap line heights. Both columns then move
+// sideways together, as far as needed for every row to fit the region.
+func columnAnchors(dc *gg.Context, left, right []planetEntry, anchor houseAnchor, region labelRegion, y float64, rows []float64, lineHeight float64) (leftX, rightX float64) {
+	type row struct{ wl, wr, xmin, xmax float64 }
+	var spans []row
+	for i := range rows {
+		var r row
+		if i < len(left) {
+			r.wl = left[i].width(dc)
+		}
+		if i < len(right) {
+			r.wr = right[i].width(dc)
+		}
+		var ok bool
+		if r.xmin, r.xmax, ok = region.boxSpan(y+rows[i]-lineHeight/2, y+rows[i]+lineHeight/2); ok {
+			spans = append(spans, r)
+		}
+	}
+
+	// The planet column must end right of every row's planet and the special
+	// lagna column start left of every row's special lagna, which bounds the gap
+	mid := (anchor.leftX + anchor.rightX) / 2
+	gap := anchor.rightX - anchor.leftX
+	minGap := math.Min(gap, minColumnGap*lineHeight)
+	if len(left) > 0 && len(right) > 0 {
+		leftMost, rightMost := math.Inf(-1), math.Inf(1)
+		for _, r := range spans {
+			leftMost, rightMost = math.Max(leftMost, r.xmin+r.wl), math.Min(rightMost, r.xmax-r.wr)
+		}
+		if room := rightMost - leftMost; room < gap {
+			gap = math.Max(room, minGap)
+		}
+	}
+	leftX, rightX = mid-gap/2, mid+gap/2
+
+	// The shift that keeps every row inside the region, when there is one
+	lo, hi := math.Inf(-1), math.Inf(1)
+	for _, r := range spans {
+		if r.wl > 0 {
+			lo, hi = math.Max(lo, r.xmin-(leftX-r.wl)), math.Min(hi, r.xmax-leftX)
+		}
+		if r.wr > 0 {
+			lo, hi = math.Max(lo, r.xmin-rightX), math.Min(hi, r.xmax-(rightX+r.wr))
+		}
+	}
+	if lo <= hi {
+		limit := region.nudge * lineHeight
+		shift := math.Max(-limit, math.Min(math.Min(math.Max(0, lo), hi), limit))
+		leftX, rightX = leftX+shift, rightX+shift
+	}
+	return leftX, rightX
+}
+
+// labelBox returns the width and vertical extent of a placed label in the
+// current font, including its second line. The second line is measured in
+// the scaled-down current font, which is at least as wide as the regular
+// font it is drawn in.
+func labelBox(dc *gg.Context, l placedLabel, lineHeight float64) (w, top, bottom float64) {
+	w = l.entry.width(dc)
+	if l.comma {
+		commaW, _ := dc.MeasureString(",")
+		w += commaW
+	}
+	top, bottom = l.y-lineHeight/2, l.y+lineHeight/2
+	if l.entry.subLabel != "" {
+		subW, _ := dc.MeasureString(l.entry.subLabel)
+		w = math.Max(w, subW*subLabelScale)
+		bottom += lineHeight * subLabelScale
+	}
+	return w, top, bottom
+}
+
+// rowBlock is the horizontal and vertical extent of the labels of one row,
+// which move sideways together so the columns never run into each other
+type rowBlock struct {
+	labels      []int // Indexes into the layout's labels
+	left, right float64
+	top, bottom float64
+}
+
+// rowBlocks groups the labels of a layout into rows, measured in the current font
+func rowBlocks(dc *gg.Context, layout houseLayout, lineHeight float64) []rowBlock {
+	var blocks []rowBlock
+	byY := map[float64]int{}
+	for i, l := range layout.labels {
+		w, top, bottom := labelBox(dc, l, lineHeight)
+		left := l.x - l.ax*w
+		j, ok := byY[l.y]
+		if !ok {
+			byY[l.y] = len(blocks)
+			blocks = append(blocks, rowBlock{labels: []int{i}, left: left, right: left + w, top: top, bottom: bottom})
+			continue
+		}
+		b := &blocks[j]
+		b.labels = append(b.labels, i)
+		b.left, b.right = math.Min(b.left, left), math.Max(b.right, left+w)
+		b.top, b.bottom = math.Min(b.top, top), math.Max(b.bottom, bottom)
+	}
+	return blocks
+}
+
+// fitsRegion reports whether every row of a layout fits inside the region
+// without being nudged sideways further than the region allows
+func fitsRegion(dc *gg.Context, layout houseLayout, anchor houseAnchor, region labelRegion) bool {
+	lineHeight := planetLineHeight(layout.size)
+	for _, b := range rowBlocks(dc, layout, lineHeight) {
+		xmin, xmax, ok := region.boxSpan(b.top, b.bottom)
+		if !ok || b.right-b.left > xmax-xmin {
+			return false
+		}
+		if limit := region.nudge * lineHeight; xmin-b.left > limit || b.right-xmax > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// fitsHeight reports whether the rows of a layout stay between the top and
+// bottom of the region, however wide they are
+func fitsHeight(dc *gg.Context, layout houseLayout, anchor houseAnchor, region labelRegion) bool {
+	lineHeight := planetLineHeight(layout.size)
+	for _, b := range rowBlocks(dc, layout, lineHeight) {
+		if b.top < region.top || b.bottom > region.bottom {
+			return false
+		}
+	}
+	return true
+}
+
+// clampToRegion moves rows that stick out of the region sideways into it
+func clampToRegion(dc *gg.Context, layout houseLayout, anchor houseAnchor, region labelRegion) houseLayout {
+	lineHeight := planetLineHeight(layout.size)
+	for _, b := range rowBlocks(dc, layout, lineHeight) {
+		xmin, xmax, ok := region.boxSpan(b.top, b.bottom)
+		if !ok {
+			continue
+		}
+		shift := fitLabelX(b.right-b.left, b.left, 0, xmin, xmax) - b.left
+		for _, i := range b.labels {
+			layout.labels[i].x += shift
+		}
+	}
+	return layout
+}
+
+// ellipsizeRows shortens the widest label of every row that is wider than
+// the region, so that the row fits once nudged into place
+func ellipsizeRows(dc *gg.Context, layout houseLayout, anchor houseAnchor, region labelRegion) houseLayout {
+	lineHeight := planetLineHeight(layout.size)
+	for _, b := range rowBlocks(dc, layout, lineHeight) {
+		xmin, xmax, ok := region.boxSpan(b.top, b.bottom)
+		if !ok || b.right-b.left <= xmax-xmin {
+			continue
+		}
+		widest, widestW := b.labels[0], 0.0
+		for _, i := range b.labels {
+			if w := layout.labels[i].entry.width(dc); w > widestW {
+				widest, widestW = i, w
+			}
+		}
+		// The superscript is kept whole, the label before it is cut short
+		l := &layout.labels[widest]
+		labelW, _ := dc.MeasureString(l.entry.label)
+		l.entry.label = ellipsize(dc, l.entry.label, labelW-(b.right-b.left-(xmax-xmin)))
+	}
+	return layout
+}
+
+// stringMeasurer measures text in a font, as a gg.Context does in its
+// current one
+type stringMeasurer interface {
+	MeasureString(s string) (w, h float64)
+}
+
+// ellipsize returns s, or as much of it as fits in width followed by "…",
+// measured in the current font
+func ellipsize(dc stringMeasurer, s string, width float64) string {
+	if w, _ := dc.MeasureString(s); w <= width {
+		return s
+	}
+	runes := []rune(s)
+	shortened := func(n int) string { return strings.TrimRight(string(runes[:n]), " ") + ellipsis }
+	n := longestFitting(len(runes)-1, func(n int) bool {
+		w, _ := dc.MeasureString(shortened(n))
+		return w <= width
+	})
+	if n == 0 {
+		return ellipsis
+	}
+	return shortened(n)
+}
+
+// longestFitting returns the largest n up to limit for which fits(n) holds,
+// or 0 when none does, given that fits holds up to some n and not after it,
+// as text does as it grows. It doubles n while fits holds, then bisects, so
+// it asks about O(log n) prefixes, none much longer than the answer.
+func longestFitting(limit int, fits func(n int) bool) int {
+	lo, hi := 0, 1
+	for hi <= limit && fits(hi) {
+		lo, hi = hi, 2*hi
+	}
+	hi = min(hi, limit+1)
+	for hi-lo > 1 {
+		if mid := (lo + hi) / 2; fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// rowOffsets returns the vertical offset of each row of a house, pitch apart,
+// where row i holds left[i] and right[i] side by side. Rows with a second
+// line are taller.
+func rowOffsets(left, right []planetEntry, lineHeight, pitch float64) []float64 {
+	rows := len(left)
+	if len(right) > rows {
+		rows = len(right)
+	}
+	offsets := make([]float64, rows)
+	y := 0.0
+	for i := range offsets {
+		offsets[i] = y
+		y += pitch
+		if (i < len(left) && left[i].subLabel != "") || (i < len(right) && right[i].subLabel != "") {
+			y += lineHeight * subLabelScale
+		}
+	}
+	return offsets
+}
+
+// fitLabelX returns the x at which to draw a label of width w anchored at ax
+// so that it lies within [xmin, xmax]. A label that already fits keeps x, one
+// wider than the range is aligned to xmin.
+func fitLabelX(w, x, ax, xmin, xmax float64) float64 {
+	left := x - ax*w
+	if left+w > xmax {
+		left = xmax - w
+	}
+	if left < xmin {
+		left = xmin
+	}
+	return left + ax*w
+}
+
+// drawHouse draws the labels of a house in its layout font, each in the
+// color of its category (see categoryStyles and labelFormat.color)
+func (f labelFormat) drawHouse(dc *gg.Context, layout houseLayout) {
+	loadMatangiBold(dc, layout.size)
+	for _, l := range layout.labels {
+		c := f.color(l.entry.category)
+		dc.SetColor(c)
+		f.draw(dc, l.entry, l.x, l.y, l.ax, layout.size, c)
+		if l.comma {
+			drawText(dc, embeddedFace(matangiBold, layout.size), c, ",", l.x+l.entry.width(dc), boldMetrics(layout.size).baseline(l.y), 0)
+		}
+	}
+	dc.SetRGB(0, 0, 0) // Reset to black
+	f.drawConjunctions(dc, layout)
+}
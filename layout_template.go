@@ -0,0 +1,359 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/fogleman/gg"
+)
+
+// LayoutNumbering says which rashi each house position of a layout holds
+type LayoutNumbering string
+
+const (
+	// LayoutNumberingHouses puts the lagna's rashi in position 1 and the
+	// following rashis in order, as the North chart does (default)
+	LayoutNumberingHouses LayoutNumbering = "houses"
+	// LayoutNumberingRashis fixes rashi n in position n, as the South chart
+	// does
+	LayoutNumberingRashis LayoutNumbering = "rashis"
+)
+
+// LayoutTemplate describes a chart geometry: the twelve house regions and
+// where their labels go. Coordinates are pixels on a square canvas of Size
+// and scale with the chart.
+type LayoutTemplate struct {
+	Size      float64         `json:"size"`
+	Numbering LayoutNumbering `json:"numbering,omitempty"`
+	Houses    []HouseTemplate `json:"houses"` // Positions 1 to 12 in order
+}
+
+// HouseTemplate describes one house position of a LayoutTemplate
+type HouseTemplate struct {
+	// Polygon is the house region, a convex polygon
+	Polygon [][2]float64 `json:"polygon"`
+	// LabelAnchor is where the rashi label is centered
+	LabelAnchor [2]float64 `json:"label_anchor"`
+	// PlanetAnchor is the middle of the planet column
+	PlanetAnchor [2]float64 `json:"planet_anchor"`
+	// PlanetBox, when set, limits planets to the rectangle left, top, right,
+	// bottom instead of the whole polygon
+	PlanetBox *[4]float64 `json:"planet_box,omitempty"`
+}
+
+// layouts holds the registered layout templates by chart type
+var layouts = struct {
+	sync.RWMutex
+	m map[ChartType]*LayoutTemplate
+}{m: map[ChartType]*LayoutTemplate{}}
+
+// RegisterLayout registers a JSON LayoutTemplate under name, which then
+// works as a chart type. Registering a name again replaces its layout. The
+// built-in north and south layouts cannot be replaced; ExportLayout gives
+// their templates as a starting point.
+func RegisterLayout(name string, data []byte) error {
+	switch ChartType(name) {
+	case "":
+		return errors.New("layout name is required")
+	case ChartTypeNorth, ChartTypeSouth, ChartTypeSarvashtakavarga, ChartTypeSuryaKalanala:
+		return fmt.Errorf("layout %q is built in and cannot be replaced", name)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var t LayoutTemplate
+	if err := dec.Decode(&t); err != nil {
+		return fmt.Errorf("layout %q: %w", name, err)
+	}
+	if err := t.validate(); err != nil {
+		return fmt.Errorf("layout %q: %w", name, err)
+	}
+	layouts.Lock()
+	layouts.m[ChartType(name)] = &t
+	layouts.Unlock()
+	return nil
+}
+
+// registeredLayout returns the layout registered for a chart type, or nil
+func registeredLayout(chartType ChartType) *LayoutTemplate {
+	layouts.RLock()
+	defer layouts.RUnlock()
+	return layouts.m[chartType]
+}
+
+// ExportLayout returns the layout template of a chart type as JSON: the
+// geometry of the built-in north and south charts on a canvas of
+// defaultChartSize, or a registered layout
+func ExportLayout(chartType ChartType) ([]byte, error) {
+	var t *LayoutTemplate
+	switch chartType {
+	case ChartTypeNorth:
+		t = northTemplate()
+	case ChartTypeSouth:
+		t = southTemplate()
+	default:
+		if t = registeredLayout(chartType); t == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedChartType, chartType)
+		}
+	}
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// northTemplate returns the North chart's geometry as a template. Its
+// triangles keep planets in their inscribed rectangles, as the chart does.
+func northTemplate() *LayoutTemplate {
+	frame := newChartFrame(defaultChartSize, defaultChartSize, false)
+	padding := frame.px(40)
+	geo, _ := northChartGeometry(frame.x+frame.width/2, frame.y+frame.height/2, frame.width-2*padding)
+	t := &LayoutTemplate{Size: defaultChartSize, Numbering: LayoutNumberingHouses}
+	for position := 1; position <= 12; position++ {
+		poly := geo.housePolygon(position)
+		h := HouseTemplate{
+			Polygon:      templatePoints(poly),
+			LabelAnchor:  templatePoint(geo.labelAnchor(position)),
+			PlanetAnchor: templatePoint(geo.planetAnchor(position)),
+		}
+		if len(poly) == 3 {
+			lo, hi := inscribedRect(poly)
+			h.PlanetBox = &[4]float64{lo.X, lo.Y, hi.X, hi.Y}
+		}
+		t.Houses = append(t.Houses, h)
+	}
+	return t
+}
+
+// southTemplate returns the South chart's cells as a template, each with
+// its rashi label in the bottom-right corner and its planets above
+func southTemplate() *LayoutTemplate {
+	frame := newChartFrame(defaultChartSize, defaultChartSize, false)
+	padding := frame.px(40)
+	cell := (frame.width - 2*padding) / 4
+	rects := southHouseRects(frame.x+padding, frame.y+padding, cell, cell)
+	t := &LayoutTemplate{Size: defaultChartSize, Numbering: LayoutNumberingRashis}
+	for position := 1; position <= 12; position++ {
+		r := rects[position]
+		left, top, right, bottom := float64(r.Min.X), float64(r.Min.Y), float64(r.Max.X), float64(r.Max.Y)
+		t.Houses = append(t.Houses, HouseTemplate{
+			Polygon:      [][2]float64{{left, top}, {right, top}, {right, bottom}, {left, bottom}},
+			LabelAnchor:  [2]float64{right - frame.px(20), bottom - frame.px(16)},
+			PlanetAnchor: [2]float64{(left + right) / 2, (top + bottom) / 2},
+		})
+	}
+	return t
+}
+
+func templatePoint(p gg.Point) [2]float64 { return [2]float64{p.X, p.Y} }
+
+func templatePoints(poly []gg.Point) [][2]float64 {
+	points := make([][2]float64, len(poly))
+	for i, p := range poly {
+		points[i] = templatePoint(p)
+	}
+	return points
+}
+
+// validate checks that the template describes twelve convex houses on its
+// canvas, naming the offending field when it does not
+func (t *LayoutTemplate) validate() error {
+	if !(t.Size > 0) || math.IsInf(t.Size, 0) {
+		return fmt.Errorf("size: must be a positive number of pixels, got %v", t.Size)
+	}
+	switch t.Numbering {
+	case "", LayoutNumberingHouses, LayoutNumberingRashis:
+	default:
+		return fmt.Errorf("numbering: unsupported %q, use %q or %q", t.Numbering, LayoutNumberingHouses, LayoutNumberingRashis)
+	}
+	if len(t.Houses) != 12 {
+		return fmt.Errorf("houses: has %d houses, needs 12", len(t.Houses))
+	}
+	for i, h := range t.Houses {
+		if err := t.validateHouse(h); err != nil {
+			return fmt.Errorf("houses[%d].%w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateHouse checks one house of the template
+func (t *LayoutTemplate) validateHouse(h HouseTemplate) error {
+	if len(h.Polygon) < 3 {
+		return fmt.Errorf("polygon: has %d points, needs at least 3", len(h.Polygon))
+	}
+	for i, p := range h.Polygon {
+		if !t.onCanvas(p) {
+			return fmt.Errorf("polygon[%d]: %v is outside the %vpx canvas", i, p, t.Size)
+		}
+	}
+	poly := ggPoints(h.Polygon)
+	if !isConvex(poly) {
+		return errors.New("polygon: must be convex with its points in order")
+	}
+	if !insidePolygon(poly, ggPoint(h.LabelAnchor)) {
+		return fmt.Errorf("label_anchor: %v is outside the house polygon", h.LabelAnchor)
+	}
+	if !insidePolygon(poly, ggPoint(h.PlanetAnchor)) {
+		return fmt.Errorf("planet_anchor: %v is outside the house polygon", h.PlanetAnchor)
+	}
+	if b := h.PlanetBox; b != nil {
+		if !(b[0] < b[2] && b[1] < b[3]) || !t.onCanvas([2]float64{b[0], b[1]}) || !t.onCanvas([2]float64{b[2], b[3]}) {
+			return fmt.Errorf("planet_box: %v is not a rectangle left, top, right, bottom on the canvas", *b)
+		}
+	}
+	return nil
+}
+
+// onCanvas reports whether a point lies on the template's canvas
+func (t *LayoutTemplate) onCanvas(p [2]float64) bool {
+	return p[0] >= 0 && p[0] <= t.Size && p[1] >= 0 && p[1] <= t.Size
+}
+
+func ggPoint(p [2]float64) gg.Point { return gg.Point{X: p[0], Y: p[1]} }
+
+func ggPoints(points [][2]float64) []gg.Point {
+	poly := make([]gg.Point, len(points))
+	for i, p := range points {
+		poly[i] = ggPoint(p)
+	}
+	return poly
+}
+
+// isConvex reports whether a polygon turns the same way at every vertex and
+// encloses some area
+func isConvex(poly []gg.Point) bool {
+	sign, area := 0.0, 0.0
+	for i, a := range poly {
+		b, c := poly[(i+1)%len(poly)], poly[(i+2)%len(poly)]
+		cross := (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+		if cross == 0 {
+			continue
+		}
+		if sign != 0 && math.Signbit(cross) != math.Signbit(sign) {
+			return false
+		}
+		sign = cross
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area != 0
+}
+
+// insidePolygon reports whether p lies inside or on the edge of a convex
+// polygon
+func insidePolygon(poly []gg.Point, p gg.Point) bool {
+	xmin, xmax, ok := polygonSpanAt(poly, p.Y)
+	return ok && p.X >= xmin && p.X <= xmax
+}
+
+// templateGeometry is a LayoutTemplate scaled to a chart frame
+type templateGeometry struct {
+	t      *LayoutTemplate
+	frame  chartFrame
+	factor float64 // Frame pixels per template pixel
+}
+
+func newTemplateGeometry(t *LayoutTemplate, frame chartFrame) templateGeometry {
+	return templateGeometry{t: t, frame: frame, factor: frame.width / t.Size}
+}
+
+// point scales a template point to the frame
+func (g templateGeometry) point(p [2]float64) gg.Point {
+	return gg.Point{X: g.frame.x + p[0]*g.factor, Y: g.frame.y + p[1]*g.factor}
+}
+
+func (g templateGeometry) housePolygon(position int) []gg.Point {
+	points := g.t.Houses[position-1].Polygon
+	poly := make([]gg.Point, len(points))
+	for i, p := range points {
+		poly[i] = g.point(p)
+	}
+	return poly
+}
+
+func (g templateGeometry) labelAnchor(position int) gg.Point {
+	return g.point(g.t.Houses[position-1].LabelAnchor)
+}
+
+func (g templateGeometry) planetAnchor(position int) gg.Point {
+	return g.point(g.t.Houses[position-1].PlanetAnchor)
+}
+
+func (g templateGeometry) planetRegion(position int) labelRegion {
+	if b := g.t.Houses[position-1].PlanetBox; b != nil {
+		lo, hi := g.point([2]float64{b[0], b[1]}), g.point([2]float64{b[2], b[3]})
+		return rectRegion(lo.X, lo.Y, hi.X, hi.Y)
+	}
+	return polygonRegion(g.housePolygon(position))
+}
+
+// rashiAt returns the rashi in a house position of the template
+func (t *LayoutTemplate) rashiAt(lagnaRashi int) func(int) int {
+	if t.Numbering == LayoutNumberingRashis {
+		return func(position int) int { return position }
+	}
+	return func(position int) int { return (lagnaRashi+position-2)%12 + 1 }
+}
+
+// renderTemplateChart draws a chart with a registered layout on the
+// renderer's canvas, recording the box of every label it places in boxes
+// when that is not nil. Like the North chart it has no center text.
+func renderTemplateChart(r *renderer, input ChartInput, t *LayoutTemplate, boxes *chartBoxes) (image.Image, error) {
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, false)
+	geo := newTemplateGeometry(t, frame)
+
+	lagnaRashi := chartLagnaRashi(input)
+	rashiAt := t.rashiAt(lagnaRashi)
+
+	// Highlights are given by house number, which positions may not follow
+	positions := map[int]int{} // House number to position
+	for position := 1; position <= 12; position++ {
+		positions[HouseFromLagna(rashiAt(position), lagnaRashi)] = position
+	}
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, houseFills(input), func(house int) []gg.Point {
+		return geo.housePolygon(positions[house])
+	})
+
+	dc.SetRGB(0, 0, 0) // Black lines
+	dc.SetLineWidth(frame.px(2))
+	for position := 1; position <= 12; position++ {
+		for i, p := range geo.housePolygon(position) {
+			if i == 0 {
+				dc.MoveTo(p.X, p.Y)
+			} else {
+				dc.LineTo(p.X, p.Y)
+			}
+		}
+		dc.ClosePath()
+		dc.Stroke()
+	}
+
+	centroid := func(house int) gg.Point {
+		return polygonCentroid(geo.housePolygon(positions[house]))
+	}
+	drawNodalAxis(dc, input, frame, lagnaRashi, centroid)
+	drawAspectLines(dc, input, frame, lagnaRashi, centroid)
+
+	if err := drawRegionHouses(dc, input, geo, frame, lagnaRashi, rashiAt, boxes); err != nil {
+		return nil, err
+	}
+	return dc.Image(), nil
+}
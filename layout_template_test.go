@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// registerTestLayout registers a layout for the length of a test
+func registerTestLayout(t *testing.T, name string, data []byte) {
+	t.Helper()
+	if err := RegisterLayout(name, data); err != nil {
+		t.Fatalf("RegisterLayout(%q): %v", name, err)
+	}
+	t.Cleanup(func() {
+		layouts.Lock()
+		delete(layouts.m, ChartType(name))
+		layouts.Unlock()
+	})
+}
+
+// gridLayout returns a template of twelve square houses in a 4x3 grid, rashi
+// n in the nth square
+func gridLayout() LayoutTemplate {
+	t := LayoutTemplate{Size: 440, Numbering: LayoutNumberingRashis}
+	for i := range 12 {
+		x, y := float64(i%4*100+20), float64(i/4*100+70)
+		t.Houses = append(t.Houses, HouseTemplate{
+			Polygon:      [][2]float64{{x, y}, {x + 100, y}, {x + 100, y + 100}, {x, y + 100}},
+			LabelAnchor:  [2]float64{x + 85, y + 88},
+			PlanetAnchor: [2]float64{x + 40, y + 45},
+		})
+	}
+	return t
+}
+
+func TestExportLayout_NorthIsTheChartsGeometry(t *testing.T) {
+	data, err := ExportLayout(ChartTypeNorth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerTestLayout(t, "north_template", data)
+
+	// Drawn through its exported template, the North chart places every
+	// label exactly where the built-in chart does
+	for _, input := range []ChartInput{crowdedHouseInput(ChartTypeNorth), thumbnailInput(ChartTypeNorth)} {
+		input.Options.Thumbnail = false
+		input.Options.TiltRashiNumbers = true
+		want := renderWithBoxes(t, input)
+		got := &chartBoxes{}
+		if _, err := renderTemplateChart(nil, input, registeredLayout("north_template"), got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.boxes, want.boxes) {
+			t.Errorf("Template boxes differ from the North chart's:\ngot  %v\nwant %v", got.boxes, want.boxes)
+		}
+	}
+}
+
+func TestExportLayout_SouthIsValid(t *testing.T) {
+	data, err := ExportLayout(ChartTypeSouth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerTestLayout(t, "south_template", data)
+	input := crowdedHouseInput(ChartTypeSouth)
+	input.ChartType = "south_template"
+	if _, err := GenerateChart(input); err != nil {
+		t.Errorf("Error drawing the exported South layout: %v", err)
+	}
+	if _, err := ExportLayout("south_template"); err != nil {
+		t.Errorf("Error exporting a registered layout: %v", err)
+	}
+	if _, err := ExportLayout(ChartTypeEast); err == nil {
+		t.Error("Expected an error exporting an unknown chart type")
+	}
+}
+
+func TestRegisterLayout_Grid(t *testing.T) {
+	data, err := json.Marshal(gridLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerTestLayout(t, "grid", data)
+
+	input := ChartInput{
+		ChartType: "grid",
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo"},
+			"moon":    {Rashi: "scorpio"},
+			"mars":    {Rashi: "leo", IsRetrograde: true},
+			"jupiter": {Rashi: "pisces"},
+			"saturn":  {Rashi: "aries"},
+		},
+		Options: ChartOptions{LagnaHouseFill: "#ffe0b2"},
+	}
+	boxes := &chartBoxes{}
+	if _, err := renderTemplateChart(nil, input, registeredLayout("grid"), boxes); err != nil {
+		t.Fatal(err)
+	}
+	if pairs := boxes.overlapping(); len(pairs) > 0 {
+		t.Errorf("Overlapping labels: %v", pairs)
+	}
+	// Rashis are fixed, so the sun lies in the fifth square
+	geo := newTemplateGeometry(registeredLayout("grid"), newChartFrame(defaultChartSize, defaultChartSize, false))
+	for _, b := range boxes.boxes {
+		if strings.HasPrefix(b.text, "Su") {
+			poly := geo.housePolygon(5)
+			if !insidePolygon(poly, ggPoint([2]float64{b.left, b.top})) || !insidePolygon(poly, ggPoint([2]float64{b.right, b.bottom})) {
+				t.Errorf("Sun %+v is not in the fifth house %v", b, poly)
+			}
+		}
+	}
+
+	img, err := (&renderer{}).generate(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "layout_template_grid", img)
+}
+
+func TestRegisterLayout_Errors(t *testing.T) {
+	valid := func(edit func(*LayoutTemplate)) string {
+		l := gridLayout()
+		edit(&l)
+		data, _ := json.Marshal(l)
+		return string(data)
+	}
+	tests := []struct {
+		name, layout, data, want string
+	}{
+		{"reserved", "north", valid(func(*LayoutTemplate) {}), "built in"},
+		{"unnamed", "", valid(func(*LayoutTemplate) {}), "name is required"},
+		{"bad JSON", "grid", `{"size": `, "unexpected EOF"},
+		{"unknown field", "grid", `{"size": 400, "houses": [], "colour": "red"}`, `unknown field "colour"`},
+		{"size", "grid", valid(func(l *LayoutTemplate) { l.Size = 0 }), "size: must be a positive number"},
+		{"numbering", "grid", valid(func(l *LayoutTemplate) { l.Numbering = "clockwise" }), `numbering: unsupported "clockwise"`},
+		{"house count", "grid", valid(func(l *LayoutTemplate) { l.Houses = l.Houses[:11] }), "houses: has 11 houses, needs 12"},
+		{"short polygon", "grid", valid(func(l *LayoutTemplate) { l.Houses[3].Polygon = l.Houses[3].Polygon[:2] }), "houses[3].polygon: has 2 points"},
+		{"off canvas", "grid", valid(func(l *LayoutTemplate) { l.Houses[4].Polygon[1] = [2]float64{450, 150} }), "houses[4].polygon[1]: [450 150] is outside the 440px canvas"},
+		{"concave", "grid", valid(func(l *LayoutTemplate) { l.Houses[0].Polygon[2] = [2]float64{40, 90} }), "houses[0].polygon: must be convex"},
+		{"label anchor", "grid", valid(func(l *LayoutTemplate) { l.Houses[7].LabelAnchor = [2]float64{5, 5} }), "houses[7].label_anchor: [5 5] is outside"},
+		{"planet anchor", "grid", valid(func(l *LayoutTemplate) { l.Houses[11].PlanetAnchor = [2]float64{5, 5} }), "houses[11].planet_anchor"},
+		{"planet box", "grid", valid(func(l *LayoutTemplate) { l.Houses[2].PlanetBox = &[4]float64{250, 60, 210, 90} }), "houses[2].planet_box"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterLayout(tt.layout, []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RegisterLayout error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+	if registeredLayout("grid") != nil {
+		t.Error("An invalid layout was registered")
+	}
+}
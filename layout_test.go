@@ -0,0 +1,595 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+)
+
+// crowdedHouseInput returns a chart with twelve labels in Aries: the lagna,
+// the nine grahas (several with status suffixes) and two upagrahas
+func crowdedHouseInput(chartType ChartType) ChartInput {
+	return ChartInput{
+		ChartType: chartType,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "aries"},
+			"moon":    {Rashi: "aries"},
+			"mars":    {Rashi: "aries", IsRetrograde: true},
+			"mercury": {Rashi: "aries", IsRetrograde: true, IsCombust: true},
+			"jupiter": {Rashi: "aries", IsRetrograde: true},
+			"venus":   {Rashi: "aries", IsCombust: true},
+			"saturn":  {Rashi: "aries", IsRetrograde: true},
+			"rahu":    {Rashi: "aries"},
+			"ketu":    {Rashi: "aries"},
+			"mandi":   {Rashi: "aries", IsUpagraha: true},
+			"gulika":  {Rashi: "aries", IsUpagraha: true},
+		},
+	}
+}
+
+func TestGenerateChart_TwelveLabelsInOneHouse(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		base64Image, err := GenerateChart(crowdedHouseInput(chartType))
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_twelve_labels", imageData)
+	}
+}
+
+// twelveEntries returns twelve planet labels with status suffixes
+func twelveEntries() []planetEntry {
+	var entries []planetEntry
+	for i := 0; i < 12; i++ {
+		entries = append(entries, planetEntry{label: fmt.Sprintf("P%dRC", i)})
+	}
+	return entries
+}
+
+// assertInsideRegion checks that every placed label lies within the region
+func assertInsideRegion(t *testing.T, dc *gg.Context, layout houseLayout, region labelRegion) {
+	t.Helper()
+	loadMatangiBold(dc, layout.size)
+	lineHeight := planetLineHeight(layout.size)
+	for _, l := range layout.labels {
+		w, _ := dc.MeasureString(l.entry.label)
+		left := l.x - l.ax*w
+		xmin, xmax, ok := region.boxSpan(l.y-lineHeight/2, l.y+lineHeight/2)
+		if !ok || left < xmin-0.001 || left+w > xmax+0.001 {
+			t.Errorf("label %q at (%.1f, %.1f) size %v lies outside the region", l.entry.label, l.x, l.y, layout.size)
+		}
+	}
+}
+
+func TestLayoutHouse_WrapsThenShrinks(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 22}
+	region := rectRegion(220, 40, 400, 190)
+
+	// Three labels fit at the design size
+	layout := layoutHouse(dc, twelveEntries()[:3], nil, anchor, region, 22)
+	if layout.size != 22 {
+		t.Errorf("three labels: size = %v, want 22", layout.size)
+	}
+	if layout.labels[0].x != anchor.leftX || layout.labels[0].y != anchor.y {
+		t.Errorf("three labels: first label at (%v, %v), want the anchor", layout.labels[0].x, layout.labels[0].y)
+	}
+
+	// Seven labels are more than the house has rows, so they wrap into two
+	// balanced columns at the design size
+	layout = layoutHouse(dc, twelveEntries()[:7], nil, anchor, region, 22)
+	if layout.size != 22 {
+		t.Errorf("seven labels: size = %v, want 22", layout.size)
+	}
+	assertColumns(t, "seven labels", layout, 4, 3)
+	assertInsideRegion(t, dc, layout, region)
+
+	// Twelve labels wrap into two columns and need a smaller font as well
+	layout = layoutHouse(dc, twelveEntries(), nil, anchor, region, 22)
+	if want := 22 * planetFontSteps[1]; layout.size != want {
+		t.Errorf("twelve labels: size = %v, want %v", layout.size, want)
+	}
+	assertColumns(t, "twelve labels", layout, 6, 6)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+// assertColumns checks how many labels a layout puts in its right-aligned
+// left column and its left-aligned right column
+func assertColumns(t *testing.T, name string, layout houseLayout, left, right int) {
+	t.Helper()
+	columns := map[float64]int{}
+	for _, l := range layout.labels {
+		columns[l.ax]++
+	}
+	if columns[1] != left || columns[0] != right {
+		t.Errorf("%s: columns = %v, want %d on the left and %d on the right", name, columns, left, right)
+	}
+}
+
+// assertNoOverlap checks that no two placed labels share a row and any of its width
+func assertNoOverlap(t *testing.T, dc *gg.Context, layout houseLayout) {
+	t.Helper()
+	loadMatangiBold(dc, layout.size)
+	lineHeight := planetLineHeight(layout.size)
+	type box struct{ left, right, top, bottom float64 }
+	var boxes []box
+	for _, l := range layout.labels {
+		w, _ := dc.MeasureString(l.entry.label)
+		left := l.x - l.ax*w
+		boxes = append(boxes, box{left, left + w, l.y - lineHeight/2, l.y + lineHeight/2})
+	}
+	for i, a := range boxes {
+		for j, b := range boxes[:i] {
+			if a.left < b.right && b.left < a.right && a.top < b.bottom && b.top < a.bottom {
+				t.Errorf("labels %q and %q overlap", layout.labels[i].entry.label, layout.labels[j].entry.label)
+			}
+		}
+	}
+}
+
+func TestLayoutHouse_MeasuredColumnGap(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 22}
+	region := rectRegion(220, 40, 400, 190)
+	left := []planetEntry{{label: "Jupiter (R)"}, {label: "Saturn"}}
+	right := []planetEntry{{label: "HL", category: PointSpecialLagna}, {label: "GL", category: PointSpecialLagna}}
+
+	// The long name only fits beside the special lagnas with a narrower gap
+	layout := layoutHouse(dc, left, right, anchor, region, 22)
+	if layout.size != 22 {
+		t.Errorf("size = %v, want 22", layout.size)
+	}
+	assertNoOverlap(t, dc, layout)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestLayoutHouse_StacksSpecialLagnasWhenNarrow(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	anchor := houseAnchor{leftX: 275, rightX: 295, y: 65, size: 22}
+	region := rectRegion(220, 40, 340, 300)
+	left := []planetEntry{{label: "Jupiter (Guru)"}}
+	right := []planetEntry{{label: "Hora Lagna", category: PointSpecialLagna}}
+
+	// The two columns cannot sit side by side at any size, one above the other they fit
+	layout := layoutHouse(dc, left, right, anchor, region, 22)
+	for _, l := range layout.labels {
+		if l.ax != 1 {
+			t.Errorf("label %q is in the special lagna column, want one stacked column", l.entry.label)
+		}
+	}
+	if len(layout.labels) == 2 && layout.labels[1].entry.label != "Hora Lagna" {
+		t.Errorf("second row = %q, want the special lagna below the planets", layout.labels[1].entry.label)
+	}
+	assertNoOverlap(t, dc, layout)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestLayoutHouse_EllipsizesLongNames(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 22}
+	region := rectRegion(220, 40, 400, 190)
+	left := []planetEntry{{label: "Jupiter (Guru) Brihaspati Deva"}, {label: "Sa"}}
+
+	// Too wide even at the smallest size, the name is cut short
+	layout := layoutHouse(dc, left, nil, anchor, region, 22)
+	if want := 22 * planetFontSteps[len(planetFontSteps)-1]; layout.size != want {
+		t.Errorf("size = %v, want %v", layout.size, want)
+	}
+	for _, l := range layout.labels {
+		if l.entry.label == "Sa" {
+			continue
+		}
+		if !strings.HasPrefix(l.entry.label, "Jupiter") || !strings.HasSuffix(l.entry.label, "…") {
+			t.Errorf("label = %q, want the name cut short with an ellipsis", l.entry.label)
+		}
+	}
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestEllipsize(t *testing.T) {
+	dc := gg.NewContext(100, 100)
+	loadMatangiBold(dc, 18)
+	if got := ellipsize(dc, "Ju", 100); got != "Ju" {
+		t.Errorf("ellipsize(Ju, 100) = %q, want it unchanged", got)
+	}
+	got := ellipsize(dc, "Jupiter Guru", 60)
+	if w, _ := dc.MeasureString(got); w > 60 || !strings.HasSuffix(got, "…") {
+		t.Errorf("ellipsize(Jupiter Guru, 60) = %q (%vpx), want an ellipsized label within 60px", got, w)
+	}
+	if got := ellipsize(dc, "Jupiter", 1); got != "…" {
+		t.Errorf("ellipsize(Jupiter, 1) = %q, want just the ellipsis", got)
+	}
+}
+
+// countingMeasurer measures a string as one unit a rune, counting the runes
+// it measures
+type countingMeasurer struct{ runes int }
+
+func (m *countingMeasurer) MeasureString(s string) (float64, float64) {
+	n := utf8.RuneCountInString(s)
+	m.runes += n
+	return float64(n), 1
+}
+
+func TestEllipsize_LongText(t *testing.T) {
+	const n = 8000
+	var m countingMeasurer
+	got := ellipsize(&m, strings.Repeat("J", n), 10)
+	if want := strings.Repeat("J", 9) + ellipsis; got != want {
+		t.Errorf("ellipsize = %q, want %q", got, want)
+	}
+	// The whole text once, then a few prefixes no longer than the answer's
+	// double, not every prefix of the text
+	if m.runes > 2*n {
+		t.Errorf("ellipsize measured %d runes of an %d rune text", m.runes, n)
+	}
+}
+
+func TestLongestFitting(t *testing.T) {
+	for limit := range 40 {
+		for answer := range limit + 1 {
+			if got := longestFitting(limit, func(n int) bool { return n <= answer }); got != answer {
+				t.Errorf("longestFitting(%d) with answer %d = %d", limit, answer, got)
+			}
+		}
+	}
+}
+
+func TestGenerateChart_LongDisplayNames(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo"},
+			Planets: map[string]*Planet{
+				"jupiter": {Rashi: "gemini", Display: "Jupiter (Guru)"},
+				"mars":    {Rashi: "gemini", Display: "Mangala (Kuja) Bhauma", IsRetrograde: true},
+				"moon":    {Rashi: "virgo", Display: "Chandra (Soma)"},
+			},
+		}
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_long_display", imageData)
+	}
+}
+
+func TestLayoutHouse_NorthTriangle(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	geo := northGeometry{cx: 400, cy: 400, half: 361.5}
+	// Position 3 is the left triangle of the top-left corner
+	region := polygonRegion(geo.housePolygon(3))
+	anchor := houseAnchor{leftX: 65, rightX: 85, y: 150, size: 18}
+
+	layout := layoutHouse(dc, twelveEntries()[:6], nil, anchor, region, 18)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestGenerateNorthChart_LagnaHouse(t *testing.T) {
+	// The lagna and five planets share the lagna house
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "leo", Degrees: 12.5},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo"},
+			"moon":    {Rashi: "leo"},
+			"mars":    {Rashi: "leo", IsRetrograde: true},
+			"mercury": {Rashi: "leo", IsCombust: true},
+			"venus":   {Rashi: "leo"},
+			"saturn":  {Rashi: "capricorn"},
+		},
+	}
+	base64Image, err := GenerateChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	imageData, err := base64.StdEncoding.DecodeString(base64Image)
+	if err != nil {
+		t.Fatalf("Error decoding base64: %v", err)
+	}
+	assertGolden(t, "north_lagna_house", imageData)
+}
+
+func TestGenerateNorthChart_CornerHouse(t *testing.T) {
+	// Four planets in the 12th house from a Leo lagna, the top-right corner triangle
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "cancer"},
+			"mercury": {Rashi: "cancer", IsRetrograde: true, IsCombust: true},
+			"venus":   {Rashi: "cancer"},
+			"rahu":    {Rashi: "cancer"},
+		},
+	}
+	base64Image, err := GenerateChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	imageData, err := base64.StdEncoding.DecodeString(base64Image)
+	if err != nil {
+		t.Fatalf("Error decoding base64: %v", err)
+	}
+	assertGolden(t, "north_corner_house", imageData)
+}
+
+func TestLayoutHouse_NorthCornerTriangles(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	geo := northGeometry{cx: 400, cy: 400, half: 361.5}
+	for _, position := range []int{2, 3, 5, 6, 8, 9, 11, 12} {
+		region := geo.planetRegion(position)
+		center := geo.planetAnchor(position)
+		anchor := houseAnchor{leftX: center.X + 15, rightX: center.X + 35, y: center.Y, middle: true, size: 18}
+
+		// Four planets fit the inscribed rectangle, clear of the diagonals
+		layout := layoutHouse(dc, twelveEntries()[:4], nil, anchor, region, 18)
+		assertNoOverlap(t, dc, layout)
+		assertInsideRegion(t, dc, layout, region)
+		assertInsideRegion(t, dc, layout, polygonRegion(geo.housePolygon(position)))
+	}
+}
+
+func TestLayoutHouse_NorthLagnaHouseClearOfNumber(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	geo := northGeometry{cx: 400, cy: 400, half: 361.5}
+	region := polygonRegion(geo.housePolygon(1))
+	center := geo.planetAnchor(1)
+	anchor := houseAnchor{leftX: center.X + 15, rightX: center.X + 35, y: center.Y, middle: true, size: 18}
+
+	layout := layoutHouse(dc, twelveEntries()[:6], nil, anchor, region, 18)
+	assertInsideRegion(t, dc, layout, region)
+
+	// The column stays above the rashi number drawn at the label anchor
+	number := geo.labelAnchor(1)
+	loadMatangiBold(dc, layout.size)
+	for _, l := range layout.labels {
+		_, h := dc.MeasureString(l.entry.label)
+		if bottom := l.y + h/2; bottom > number.Y-10 {
+			t.Errorf("label %q ends at y=%.1f, overlapping the rashi number at y=%.1f", l.entry.label, bottom, number.Y)
+		}
+	}
+}
+
+func TestPolygonSpanAt(t *testing.T) {
+	geo := northGeometry{cx: 400, cy: 400, half: 200}
+	// The top diamond is widest halfway between the top edge and the center
+	xmin, xmax, ok := polygonSpanAt(geo.housePolygon(1), 300)
+	if !ok || xmin != 300 || xmax != 500 {
+		t.Errorf("polygonSpanAt(1, 300) = %v, %v, %v, want 300, 500, true", xmin, xmax, ok)
+	}
+	if _, _, ok := polygonSpanAt(geo.housePolygon(1), 450); ok {
+		t.Error("polygonSpanAt(1, 450) should miss the top diamond")
+	}
+}
+
+func TestGenerateChart_EightPlanetsThreeUpagrahas(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "aries"},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "cancer"},
+				"moon":    {Rashi: "cancer"},
+				"mars":    {Rashi: "cancer", IsRetrograde: true},
+				"mercury": {Rashi: "cancer", IsCombust: true},
+				"jupiter": {Rashi: "cancer"},
+				"venus":   {Rashi: "cancer"},
+				"saturn":  {Rashi: "cancer", IsRetrograde: true},
+				"rahu":    {Rashi: "cancer"},
+				"mandi":   {Rashi: "cancer", IsUpagraha: true},
+				"gulika":  {Rashi: "cancer", IsUpagraha: true},
+				"dhuma":   {Rashi: "cancer", IsUpagraha: true},
+			},
+		}
+
+		// The eleven labels share six rows of two balanced columns
+		boxes := renderWithBoxes(t, input)
+		rows := map[float64]int{}
+		for _, b := range boxes.boxes {
+			if b.house == 4 && b.text != "4" {
+				rows[b.top]++
+			}
+		}
+		if len(rows) != 6 {
+			t.Errorf("%s chart: labels in %d rows, want 6 rows of two columns", chartType, len(rows))
+		}
+		for _, pair := range boxes.overlapping() {
+			t.Errorf("%s chart: %q overlaps %q", chartType, pair[0].text, pair[1].text)
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_eight_planets_three_upagrahas", imageData)
+	}
+}
+
+// TestGenerateSouthChart_RowsClearRashiNumber stacks three and four planets
+// in one cell, the counts whose last row used to run into the rashi number
+// at the bottom of the cell
+func TestGenerateSouthChart_RowsClearRashiNumber(t *testing.T) {
+	planets := map[string]*Planet{
+		"sun":     {Rashi: "cancer"},
+		"mars":    {Rashi: "cancer", IsRetrograde: true},
+		"mercury": {Rashi: "cancer", IsCombust: true},
+		"saturn":  {Rashi: "cancer", IsRetrograde: true},
+	}
+	for _, n := range []int{3, 4} {
+		input := ChartInput{ChartType: ChartTypeSouth, Lagna: &Planet{Rashi: "aries"}, Planets: map[string]*Planet{}}
+		for _, name := range []string{"sun", "mars", "mercury", "saturn"}[:n] {
+			input.Planets[name] = planets[name]
+		}
+
+		boxes := renderWithBoxes(t, input)
+		var number textBox
+		var labels []textBox
+		for _, b := range boxes.boxes {
+			switch {
+			case b.house == 4 && b.kind == labelRashi:
+				number = b
+			case b.house == 4 && b.kind == labelPlanet:
+				labels = append(labels, b)
+			}
+		}
+		if len(labels) != n {
+			t.Fatalf("%d planets: %d labels in the cell", n, len(labels))
+		}
+		for _, l := range labels {
+			if l.bottom > number.top {
+				t.Errorf("%d planets: %q reaches %.1f, below the top of the rashi number at %.1f", n, l.text, l.bottom, number.top)
+			}
+		}
+		for _, pair := range boxes.overlapping() {
+			t.Errorf("%d planets: %q overlaps %q", n, pair[0].text, pair[1].text)
+		}
+
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating chart: %v", err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, fmt.Sprintf("south_%d_planets_one_cell", n), imageData)
+	}
+}
+
+func TestLayoutHouse_LineSpacing(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	region := rectRegion(220, 40, 400, 190)
+	for _, tt := range []struct {
+		spacing float64
+		want    float64
+	}{
+		{0, planetLineHeight(18)},
+		{1.8, 1.8 * 18},
+		{0.5, planetLineHeight(18)}, // Never closer than the font's lines
+	} {
+		anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 18, spacing: tt.spacing}
+		layout := layoutHouse(dc, twelveEntries()[:3], nil, anchor, region, 18)
+		if got := layout.labels[1].y - layout.labels[0].y; math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("spacing %v: rows %.2f apart, want %.2f", tt.spacing, got, tt.want)
+		}
+		assertNoOverlap(t, dc, layout)
+	}
+
+	// Looser rows leave room for fewer of them, so the labels wrap sooner
+	anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 22}
+	layout := layoutHouse(dc, twelveEntries()[:4], nil, anchor, region, 22)
+	assertColumns(t, "four labels", layout, 4, 0)
+	anchor.spacing = 2.5
+	layout = layoutHouse(dc, twelveEntries()[:4], nil, anchor, region, 22)
+	assertColumns(t, "four loose labels", layout, 2, 2)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestLayoutHouse_Inline(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	region := rectRegion(220, 40, 400, 190)
+	anchor := houseAnchor{leftX: 285, rightX: 335, y: 65, size: 22, inline: true}
+	layout := layoutHouse(dc, twelveEntries()[:5], twelveEntries()[5:6], anchor, region, 22)
+	if !layout.inline || len(layout.labels) != 6 {
+		t.Fatalf("Inline layout of %d labels, inline %v", len(layout.labels), layout.inline)
+	}
+
+	// The labels read left to right, row by row, special lagnas last, a
+	// comma after each but the last of its row
+	rows := map[float64]int{}
+	for i, l := range layout.labels {
+		rows[l.y]++
+		if i > 0 {
+			prev := layout.labels[i-1]
+			if l.y < prev.y || (l.y == prev.y && l.x <= prev.x) {
+				t.Errorf("%q at (%.1f, %.1f) comes before %q at (%.1f, %.1f)", l.entry.label, l.x, l.y, prev.entry.label, prev.x, prev.y)
+			}
+		}
+		lastOfRow := i == len(layout.labels)-1 || layout.labels[i+1].y != l.y
+		if l.comma == lastOfRow {
+			t.Errorf("%q: comma %v, last of its row %v", l.entry.label, l.comma, lastOfRow)
+		}
+	}
+	if len(rows) < 2 || len(rows) > 3 {
+		t.Errorf("Six labels in %d rows, want them to wrap into two or three", len(rows))
+	}
+	assertNoOverlap(t, dc, layout)
+	assertInsideRegion(t, dc, layout, region)
+}
+
+func TestGenerateChart_PlanetStacking(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		for _, opts := range []struct {
+			name    string
+			options ChartOptions
+		}{
+			{"inline", ChartOptions{PlanetStacking: PlanetStackingInline, ShowConjunctions: true}},
+			{"line_spacing", ChartOptions{PlanetLineSpacingPx: 30}},
+		} {
+			input := crowdedHouseInput(chartType)
+			// Conjunct in Cancer; the planets in Aries have no degrees
+			input.Planets["moon"] = &Planet{Rashi: "cancer", Degrees: 10}
+			input.Planets["venus"] = &Planet{Rashi: "cancer", Degrees: 11, IsCombust: true}
+			input.Planets["jupiter"] = &Planet{Rashi: "cancer", Degrees: 12, IsRetrograde: true}
+			input.Options = opts.options
+
+			boxes := renderWithBoxes(t, input)
+			for _, pair := range boxes.overlapping() {
+				t.Errorf("%s %s chart: %q overlaps %q", opts.name, chartType, pair[0].text, pair[1].text)
+			}
+			base64Image, err := GenerateChart(input)
+			if err != nil {
+				t.Fatalf("Error generating %s %s chart: %v", opts.name, chartType, err)
+			}
+			imageData, err := base64.StdEncoding.DecodeString(base64Image)
+			if err != nil {
+				t.Fatalf("Error decoding base64: %v", err)
+			}
+			assertGolden(t, string(chartType)+"_"+opts.name, imageData)
+		}
+	}
+
+	for _, options := range []ChartOptions{
+		{PlanetStacking: "diagonal"},
+		{PlanetLineSpacing: -1},
+		{PlanetLineSpacing: 5},
+		{PlanetLineSpacingPx: 120},
+	} {
+		if _, err := GenerateChart(ChartInput{ChartType: ChartTypeSouth, Options: options}); err == nil {
+			t.Errorf("Options %+v: expected an error", options)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Tags of the built-in locales
+const (
+	// LocaleEnglish labels planets as "Su", "Mo", …, the default
+	LocaleEnglish = "en"
+	// LocaleIAST labels the grahas with their Sanskrit names in IAST
+	// transliteration ("Sū", "Ca", "Maṅ", …) and names the rashis likewise
+	LocaleIAST = "en-IAST"
+)
+
+// Locale is a set of labels charts are drawn with. Names it leaves out are
+// labelled as in English, so a locale need only list what it changes.
+type Locale struct {
+	// Abbreviations maps lowercase planet and upagraha names, and "lagna",
+	// to the abbreviations charts label them with
+	Abbreviations map[string]string `json:"abbreviations,omitempty"`
+	// FullNames maps the same names to the full names the "full_name"
+	// display mode labels them with
+	FullNames map[string]string `json:"full_names,omitempty"`
+	// RashiNames are the rashi names the "name" rashi label mode draws,
+	// Aries first
+	RashiNames [12]string `json:"rashi_names,omitzero"`
+	// NativeLabels maps the fields of the native's details ("name", "date",
+	// "time", "place", "coordinates" and "ayanamsa") to their labels
+	NativeLabels map[string]string `json:"native_labels,omitempty"`
+}
+
+// chartLocale is a registered locale with English filling its gaps
+type chartLocale struct {
+	abbreviations map[string]string
+	fullNames     map[string]string
+	rashiNames    [12]string
+	nativeLabels  map[string]string
+}
+
+// englishLocale labels charts as they always were
+var englishLocale = &chartLocale{
+	abbreviations: planetAbbreviations,
+	fullNames:     planetFullNames,
+	rashiNames: [12]string{"Aries", "Taurus", "Gemini", "Cancer", "Leo", "Virgo",
+		"Libra", "Scorpio", "Sagittarius", "Capricorn", "Aquarius", "Pisces"},
+	nativeLabels: nativeLabels,
+}
+
+// iastLocale holds the labels of LocaleIAST, in precomposed (NFC) letters.
+// Matangi has none of the accented ones, so they are drawn from its fallback.
+var iastLocale = Locale{
+	Abbreviations: map[string]string{
+		"sun":     "Sū",  // Sūrya
+		"moon":    "Ca",  // Candra
+		"mars":    "Maṅ", // Maṅgala
+		"mercury": "Bu",  // Budha
+		"jupiter": "Gu",  // Guru
+		"venus":   "Śu",  // Śukra
+		"saturn":  "Śa",  // Śani
+		"rahu":    "Rā",  // Rāhu
+		"ketu":    "Ke",  // Ketu
+	},
+	FullNames: map[string]string{
+		"sun":     "Sūrya",
+		"moon":    "Candra",
+		"mars":    "Maṅgala",
+		"mercury": "Budha",
+		"jupiter": "Guru",
+		"venus":   "Śukra",
+		"saturn":  "Śani",
+		"rahu":    "Rāhu",
+		"ketu":    "Ketu",
+	},
+	RashiNames: [12]string{"Meṣa", "Vṛṣabha", "Mithuna", "Karka", "Siṃha", "Kanyā",
+		"Tulā", "Vṛścika", "Dhanus", "Makara", "Kumbha", "Mīna"},
+}
+
+// locales holds the registered locales by tag and the tag of the default
+var locales = struct {
+	sync.RWMutex
+	m   map[string]*chartLocale
+	def string
+}{m: map[string]*chartLocale{LocaleEnglish: englishLocale, LocaleIAST: newChartLocale(iastLocale)}, def: LocaleEnglish}
+
+// RegisterLocale registers l under tag, which charts then select with the
+// locale option. Registering a tag again replaces its locale, but the
+// built-in locales cannot be replaced. l is copied, so changing it
+// afterwards has no effect.
+func RegisterLocale(tag string, l Locale) error {
+	if tag == "" {
+		return fmt.Errorf("locale tag is required")
+	}
+	if tag == LocaleEnglish || tag == LocaleIAST {
+		return fmt.Errorf("locale %s is built in and cannot be replaced", tag)
+	}
+	for name, abbrev := range l.Abbreviations {
+		if abbrev == "" {
+			return fmt.Errorf("locale %s: empty abbreviation for %s", tag, name)
+		}
+	}
+	for name, full := range l.FullNames {
+		if full == "" {
+			return fmt.Errorf("locale %s: empty full name for %s", tag, name)
+		}
+	}
+	for field, label := range l.NativeLabels {
+		if _, ok := nativeLabels[field]; !ok {
+			return fmt.Errorf("locale %s: unknown native field %s", tag, field)
+		}
+		if label == "" {
+			return fmt.Errorf("locale %s: empty native label for %s", tag, field)
+		}
+	}
+	loc := newChartLocale(l)
+	locales.Lock()
+	defer locales.Unlock()
+	locales.m[tag] = loc
+	return nil
+}
+
+// newChartLocale fills the gaps of l with English
+func newChartLocale(l Locale) *chartLocale {
+	loc := &chartLocale{
+		abbreviations: maps.Clone(planetAbbreviations),
+		fullNames:     maps.Clone(planetFullNames),
+		rashiNames:    englishLocale.rashiNames,
+		nativeLabels:  maps.Clone(nativeLabels),
+	}
+	for name, abbrev := range l.Abbreviations {
+		loc.abbreviations[strings.ToLower(name)] = abbrev
+	}
+	for name, full := range l.FullNames {
+		loc.fullNames[strings.ToLower(name)] = full
+	}
+	for i, name := range l.RashiNames {
+		if name != "" {
+			loc.rashiNames[i] = name
+		}
+	}
+	maps.Copy(loc.nativeLabels, l.NativeLabels)
+	return loc
+}
+
+// Locales returns the tags of the registered locales in order
+func Locales() []string {
+	locales.RLock()
+	defer locales.RUnlock()
+	return slices.Sorted(maps.Keys(locales.m))
+}
+
+// SetDefaultLocale makes the registered locale tag the one charts without a
+// locale option, and GetPlanetAbbreviation, use
+func SetDefaultLocale(tag string) error {
+	locales.Lock()
+	defer locales.Unlock()
+	if locales.m[tag] == nil {
+		return fmt.Errorf("unsupported locale: %s", tag)
+	}
+	locales.def = tag
+	return nil
+}
+
+// DefaultLocale returns the tag of the default locale
+func DefaultLocale() string {
+	locales.RLock()
+	defer locales.RUnlock()
+	return locales.def
+}
+
+// lookupLocale returns the locale registered under tag, or the default
+// locale for an empty tag, and whether there is one
+func lookupLocale(tag string) (*chartLocale, bool) {
+	locales.RLock()
+	defer locales.RUnlock()
+	if tag == "" {
+		tag = locales.def
+	}
+	loc, ok := locales.m[tag]
+	return loc, ok
+}
+
+// localeFor returns the locale a chart with opts is labelled in, English
+// when its locale is not registered, which validation rejects
+func localeFor(opts ChartOptions) *chartLocale {
+	if loc, ok := lookupLocale(opts.Locale); ok {
+		return loc
+	}
+	return englishLocale
+}
+
+// GetPlanetAbbreviationIn returns the abbreviation for a planet or upagraha
+// in the locale registered under tag, the default locale when tag is empty,
+// or an empty string when either is unknown
+func GetPlanetAbbreviationIn(planetName, tag string) string {
+	loc, ok := lookupLocale(tag)
+	if !ok {
+		return ""
+	}
+	return loc.abbreviation(planetName)
+}
+
+// abbreviation returns the abbreviation for a planet or upagraha: the one
+// registered with RegisterPlanetAbbreviation, else the locale's
+func (l *chartLocale) abbreviation(planetName string) string {
+	name := strings.ToLower(planetName)
+	if abbrev, ok := customAbbreviation(name); ok {
+		return abbrev
+	}
+	return l.abbreviations[name]
+}
+
+// fullName returns the full name of a planet or upagraha, or its
+// abbreviation when the locale has no full name for it
+func (l *chartLocale) fullName(planetName string) string {
+	if full, ok := l.fullNames[strings.ToLower(planetName)]; ok {
+		return full
+	}
+	return l.abbreviation(planetName)
+}
+
+// displayName returns a planet's Display when set, else its name in mode
+func (l *chartLocale) displayName(planetName string, planet *Planet, mode DisplayMode) string {
+	if planet != nil && planet.Display != "" {
+		return planet.Display
+	}
+	switch mode {
+	case DisplayModeFullName:
+		return l.fullName(planetName)
+	case DisplayModeLetter:
+		if letter := PlanetLetter(planetName); letter != "" {
+			return letter
+		}
+	}
+	return l.abbreviation(planetName)
+}
+
+// rashiName returns the name of a rashi number, or an empty string when it
+// is out of range
+func (l *chartLocale) rashiName(rashiNum int) string {
+	if rashiNum < 1 || rashiNum > 12 {
+		return ""
+	}
+	return l.rashiNames[rashiNum-1]
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLocaleEnglish(t *testing.T) {
+	for name, want := range planetAbbreviations {
+		if got := GetPlanetAbbreviationIn(name, LocaleEnglish); got != want {
+			t.Errorf("GetPlanetAbbreviationIn(%q, en) = %q, want %q", name, got, want)
+		}
+		if got := GetPlanetAbbreviation(strings.ToUpper(name)); got != want {
+			t.Errorf("GetPlanetAbbreviation(%q) = %q, want %q", strings.ToUpper(name), got, want)
+		}
+	}
+	if got := GetPlanetAbbreviationIn("sun", "xx-unregistered"); got != "" {
+		t.Errorf("GetPlanetAbbreviationIn(sun, unregistered) = %q, want empty", got)
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	l := Locale{
+		Abbreviations: map[string]string{"Sun": "Sy", "moon": "Ch"},
+		RashiNames:    [12]string{0: "Mesha"},
+	}
+	if err := RegisterLocale("test-register", l); err != nil {
+		t.Fatal(err)
+	}
+	l.Abbreviations["sun"] = "changed" // The registry keeps its own copy
+	tests := []struct{ name, want string }{
+		{"sun", "Sy"},
+		{"MOON", "Ch"},
+		{"mars", "Ma"}, // Left out, so English
+		{"lagna", "Asc"},
+	}
+	for _, tt := range tests {
+		if got := GetPlanetAbbreviationIn(tt.name, "test-register"); got != tt.want {
+			t.Errorf("GetPlanetAbbreviationIn(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if !slices.Contains(Locales(), "test-register") {
+		t.Errorf("Locales() = %v, missing test-register", Locales())
+	}
+
+	for _, tt := range []struct {
+		tag  string
+		l    Locale
+		want string
+	}{
+		{"", l, "locale tag is required"},
+		{LocaleEnglish, l, "cannot be replaced"},
+		{"test-empty", Locale{Abbreviations: map[string]string{"sun": ""}}, "empty abbreviation for sun"},
+		{"test-empty", Locale{FullNames: map[string]string{"moon": ""}}, "empty full name for moon"},
+	} {
+		if err := RegisterLocale(tt.tag, tt.l); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("RegisterLocale(%q) error = %v, want %q", tt.tag, err, tt.want)
+		}
+	}
+}
+
+func TestSetDefaultLocale(t *testing.T) {
+	if err := RegisterLocale("test-default", Locale{Abbreviations: map[string]string{"jupiter": "Gu"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDefaultLocale("xx-unregistered"); err == nil {
+		t.Error("SetDefaultLocale(unregistered) succeeded")
+	}
+	if err := SetDefaultLocale("test-default"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetDefaultLocale(LocaleEnglish) })
+	if got := DefaultLocale(); got != "test-default" {
+		t.Errorf("DefaultLocale() = %q, want test-default", got)
+	}
+	if got := GetPlanetAbbreviation("jupiter"); got != "Gu" {
+		t.Errorf("GetPlanetAbbreviation(jupiter) = %q in the new default, want Gu", got)
+	}
+	// A chart's own locale wins over the default
+	input := houseScoresInput(ChartTypeSouth)
+	input.Options.Locale = LocaleEnglish
+	if labels := planetLabels(t, input); !slices.Contains(labels, "JuR") {
+		t.Errorf("labels = %v, want English JuR", labels)
+	}
+}
+
+// planetLabels returns the texts of the planet labels a chart draws
+func planetLabels(t *testing.T, input ChartInput) []string {
+	t.Helper()
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelPlanet) {
+			labels = append(labels, l.Text)
+		}
+	}
+	return labels
+}
+
+func TestGenerateChart_Locale(t *testing.T) {
+	if err := RegisterLocale("test-chart", Locale{
+		Abbreviations: map[string]string{"jupiter": "Gu", "sun": "Sy", "saturn": "Sh"},
+		RashiNames:    [12]string{"Mesha", "Vrishabha", "Mithuna", "Karka", "Simha", "Kanya", "Tula", "Vrishchika", "Dhanu", "Makara", "Kumbha", "Meena"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Options = ChartOptions{Locale: "test-chart", RashiLabelMode: RashiLabelName, ShowHouseLords: true}
+		_, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts := map[string][]string{}
+		for _, l := range layout.Labels {
+			texts[l.Kind] = append(texts[l.Kind], l.Text)
+		}
+		for _, want := range []string{"GuR", "Sy", "Sh", "Mo"} {
+			if !slices.Contains(texts[string(labelPlanet)], want) {
+				t.Errorf("%s: planet labels %v, want %s", chartType, texts[string(labelPlanet)], want)
+			}
+		}
+		if !slices.Contains(texts[string(labelRashi)], "Meena") {
+			t.Errorf("%s: rashi labels %v, want Meena", chartType, texts[string(labelRashi)])
+		}
+		// Jupiter rules Pisces and Sagittarius
+		if n := strings.Count(strings.Join(texts[string(labelHouseLord)], " "), "Gu"); n != 2 {
+			t.Errorf("%s: house lords %v, want Gu twice", chartType, texts[string(labelHouseLord)])
+		}
+	}
+
+	input := houseScoresInput(ChartTypeSouth)
+	input.Options.Locale = "xx-unregistered"
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "unsupported locale: xx-unregistered") {
+		t.Errorf("GenerateChart(unregistered locale) error = %v", err)
+	}
+}
+
+func TestLocaleRegistry_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tag := fmt.Sprintf("test-concurrent-%d", i)
+			if err := RegisterLocale(tag, Locale{Abbreviations: map[string]string{"sun": tag}}); err != nil {
+				t.Error(err)
+			}
+			if got := GetPlanetAbbreviationIn("sun", tag); got != tag {
+				t.Errorf("GetPlanetAbbreviationIn(sun, %s) = %q", tag, got)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			input := houseScoresInput(ChartTypeNorth)
+			input.Options.Locale = LocaleEnglish
+			if _, err := GenerateChart(input); err != nil {
+				t.Error(err)
+			}
+			Locales()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLocaleIAST(t *testing.T) {
+	want := map[string][]rune{
+		"sun":     {'S', 'ū'},
+		"moon":    {'C', 'a'},
+		"mars":    {'M', 'a', 'ṅ'},
+		"mercury": {'B', 'u'},
+		"jupiter": {'G', 'u'},
+		"venus":   {'Ś', 'u'},
+		"saturn":  {'Ś', 'a'},
+		"rahu":    {'R', 'ā'},
+		"ketu":    {'K', 'e'},
+	}
+	for name, runes := range want {
+		got := GetPlanetAbbreviationIn(name, LocaleIAST)
+		// Precomposed: one code point per letter, no combining marks
+		if !slices.Equal([]rune(got), runes) {
+			t.Errorf("GetPlanetAbbreviationIn(%q, en-IAST) = %q %U, want %U", name, got, []rune(got), runes)
+		}
+	}
+	if err := RegisterLocale(LocaleIAST, Locale{}); err == nil {
+		t.Error("RegisterLocale replaced the built-in en-IAST locale")
+	}
+}
+
+func TestGenerateChart_LocaleIAST(t *testing.T) {
+	// Every letter is drawn from Matangi or its fallback, never as a
+	// missing glyph
+	face := embeddedFace(matangiBold, 22)
+	for _, name := range grahas {
+		for _, r := range GetPlanetAbbreviationIn(name, LocaleIAST) {
+			if _, ok := face.GlyphAdvance(r); !ok {
+				t.Errorf("%s: no glyph for %q", name, r)
+			}
+		}
+	}
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Options = ChartOptions{Locale: LocaleIAST, RashiLabelMode: RashiLabelName}
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, string(chartType)+"_locale_iast", data)
+	}
+}
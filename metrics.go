@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// textMetrics are the vertical metrics of a font face in pixels. Placing text
+// by them rather than by offsets tuned for one font and size keeps labels
+// clear of borders and of each other at any size.
+type textMetrics struct {
+	ascent    float64 // Top of the tallest glyphs above the baseline
+	descent   float64 // Bottom of the descenders below the baseline
+	capHeight float64 // Top of capitals and digits above the baseline
+}
+
+// embeddedFace returns a face of an embedded font at a size, or the basic
+// fallback font when it cannot be loaded
+func embeddedFace(f *embeddedFont, size float64) font.Face {
+	if face, err := f.face(size); err == nil {
+		return face
+	}
+	return basicfont.Face7x13
+}
+
+// faceMetrics returns the metrics of an embedded font at a size
+func faceMetrics(f *embeddedFont, size float64) textMetrics {
+	m := embeddedFace(f, size).Metrics()
+	metrics := textMetrics{
+		ascent:    float64(m.Ascent) / 64,
+		descent:   float64(m.Descent) / 64,
+		capHeight: float64(m.CapHeight) / 64,
+	}
+	if metrics.capHeight == 0 {
+		metrics.capHeight = metrics.ascent // Faces without an OS/2 cap height
+	}
+	return metrics
+}
+
+// regularMetrics and boldMetrics return the metrics of Matangi at a size
+func regularMetrics(size float64) textMetrics { return faceMetrics(matangiRegular, size) }
+func boldMetrics(size float64) textMetrics    { return faceMetrics(matangiBold, size) }
+
+// lineHeight is the height of a line of mixed-case text, from the top of its
+// capitals to the bottom of its descenders
+func (m textMetrics) lineHeight() float64 {
+	return m.capHeight + m.descent
+}
+
+// baseline returns the baseline that centers a line of mixed-case text on y
+func (m textMetrics) baseline(y float64) float64 {
+	return y + (m.capHeight-m.descent)/2
+}
+
+// planetLineHeight is the row height of planet labels at a font size
+func planetLineHeight(size float64) float64 {
+	return boldMetrics(size).lineHeight()
+}
+
+// regularInk returns how far the ink of s in Matangi Regular at a size rises
+// above and drops below the baseline. Unlike the font-wide metrics it follows
+// the actual glyphs, such as the headline of Devanagari letters.
+func regularInk(size float64, s string) (above, below float64) {
+	bounds, _ := font.BoundString(embeddedFace(matangiRegular, size), s)
+	return -float64(bounds.Min.Y) / 64, float64(bounds.Max.Y) / 64
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// nakshatraSpan is the arc of each of the 27 nakshatras, 13°20'
+const nakshatraSpan = 360.0 / 27
+
+// nakshatraNames lists the 27 nakshatras in zodiacal order from 0° Aries
+var nakshatraNames = [27]string{
+	"Ashwini", "Bharani", "Krittika", "Rohini", "Mrigashira", "Ardra",
+	"Punarvasu", "Pushya", "Ashlesha", "Magha", "Purva Phalguni", "Uttara Phalguni",
+	"Hasta", "Chitra", "Swati", "Vishakha", "Anuradha", "Jyeshtha",
+	"Mula", "Purva Ashadha", "Uttara Ashadha", "Shravana", "Dhanishta", "Shatabhisha",
+	"Purva Bhadrapada", "Uttara Bhadrapada", "Revati",
+}
+
+// chakraNakshatras are the 28 nakshatras of the chakras in zodiacal order,
+// Abhijit between Uttara Ashadha and Shravana
+var chakraNakshatras = func() [28]string {
+	var names [28]string
+	copy(names[:21], nakshatraNames[:21])
+	names[21] = "Abhijit"
+	copy(names[22:], nakshatraNames[21:])
+	return names
+}()
+
+// NakshatraFromLongitude returns the nakshatra and pada (1-4) of a sidereal
+// longitude in degrees. Longitudes outside 0-360 are wrapped around the zodiac.
+// A NaN or infinite longitude has none, "" and 0.
+func NakshatraFromLongitude(longitude float64) (name string, pada int) {
+	if math.IsNaN(longitude) || math.IsInf(longitude, 0) {
+		return "", 0
+	}
+	lon := math.Mod(longitude, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	index := int(lon / nakshatraSpan)
+	if index > 26 { // Guard against rounding just below 360
+		index = 26
+	}
+	pada = int((lon-float64(index)*nakshatraSpan)/(nakshatraSpan/4)) + 1
+	if pada > 4 {
+		pada = 4
+	}
+	return nakshatraNames[index], pada
+}
+
+// nakshatraLabel returns the nakshatra line drawn under a planet ("Rohini-2"),
+// or an empty string when the planet has no nakshatra
+func nakshatraLabel(p *Planet) string {
+	if p == nil || p.Nakshatra == "" {
+		return ""
+	}
+	if p.Pada > 0 {
+		return p.Nakshatra + "-" + strconv.Itoa(p.Pada)
+	}
+	return p.Nakshatra
+}
+
+// chakraNakshatra returns the index in chakraNakshatras of a nakshatra name,
+// matched case-insensitively, or -1 for an unknown name
+func chakraNakshatra(name string) int {
+	name = strings.TrimSpace(name)
+	for i, nakshatra := range chakraNakshatras {
+		if strings.EqualFold(nakshatra, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nakshatraAbbreviation returns the short name of one of chakraNakshatras
+func nakshatraAbbreviation(nakshatra string) string {
+	for i, name := range nakshatraNames {
+		if name == nakshatra {
+			return nakshatraAbbreviations[i]
+		}
+	}
+	return "Abh"
+}
+
+// planetNakshatra returns the chakra nakshatra a planet sits in: its
+// Nakshatra when set, Abhijit included, otherwise the one at its Rashi and
+// Degrees, which is never Abhijit
+func planetNakshatra(name string, p *Planet) (string, error) {
+	if p.Nakshatra != "" {
+		i := chakraNakshatra(p.Nakshatra)
+		if i < 0 {
+			return "", fmt.Errorf("planet %s: unknown nakshatra %q", name, p.Nakshatra)
+		}
+		return chakraNakshatras[i], nil
+	}
+	rashi, err := ParseRashi(p.Rashi)
+	if err != nil {
+		return "", &ErrInvalidRashi{Planet: name, Rashi: p.Rashi}
+	}
+	if !validDegrees(p.Degrees) {
+		return "", fmt.Errorf("planet %s: degrees %v out of range [0, 30)", name, p.Degrees)
+	}
+	nakshatra, _ := NakshatraFromLongitude(float64(rashi-1)*30 + p.Degrees)
+	return nakshatra, nil
+}
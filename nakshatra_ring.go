@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// nakshatraAbbreviations are the short names of the nakshatras on the
+// nakshatra ring, in the order of nakshatraNames
+var nakshatraAbbreviations = [27]string{
+	"Ash", "Bha", "Kri", "Roh", "Mri", "Ard",
+	"Pun", "Pus", "Asl", "Mag", "PPh", "UPh",
+	"Has", "Chi", "Swa", "Vis", "Anu", "Jye",
+	"Mul", "PAs", "UAs", "Shr", "Dha", "Sha",
+	"PBh", "UBh", "Rev",
+}
+
+// ringShade fills every other nakshatra of the ring
+var ringShade = color.NRGBA{R: 242, G: 242, B: 242, A: 255}
+
+// Sizes of the nakshatra ring, for a chart of defaultChartSize
+const (
+	ringWidth    = 26 // The band, taken from the chart's size
+	ringGap      = 4  // Between the chart's outer square and the band
+	ringFontSize = 12
+)
+
+// ringPoint returns where a ray from the North chart's center at angle
+// (degrees counter-clockwise from the right, as on paper) meets the square
+// of a half size around the center
+func ringPoint(g northGeometry, angle, half float64) gg.Point {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	k := half / math.Max(math.Abs(cos), math.Abs(sin))
+	return gg.Point{X: g.cx + k*cos, Y: g.cy - k*sin}
+}
+
+// ringAngle returns the angle on the ring of a sidereal longitude. Each
+// house takes the 30° around its direction from the center, the first at
+// the top and the rest counter-clockwise as the houses run, or clockwise in
+// a clockwise chart, and its rashi spans them in order of longitude.
+func (g northGeometry) ringAngle(longitude float64, lagnaRashi int) float64 {
+	if g.clockwise {
+		return 105 - longitude + float64(lagnaRashi-1)*30
+	}
+	return 75 + longitude - float64(lagnaRashi-1)*30
+}
+
+// nakshatraStart returns the angle on the ring where nakshatra i (0-26)
+// starts counter-clockwise, which in a clockwise chart is where it ends
+func (g northGeometry) nakshatraStart(i, lagnaRashi int) float64 {
+	if g.clockwise {
+		return g.ringAngle(float64(i+1)*nakshatraSpan, lagnaRashi)
+	}
+	return g.ringAngle(float64(i)*nakshatraSpan, lagnaRashi)
+}
+
+// drawNakshatraRing draws the 27 nakshatras in a band around the North
+// chart, between the squares of half sizes inner and outer, so that each
+// house's rashi spans its 2¼ nakshatras beside it. Segments alternate in
+// shade and carry their abbreviations, horizontal along the top and bottom
+// and turned to read outwards along the sides, so none is upside down.
+func drawNakshatraRing(dc *gg.Context, g northGeometry, inner, outer float64, lagnaRashi int, frame chartFrame, boxes *chartBoxes) {
+	for i := range 27 {
+		from := g.nakshatraStart(i, lagnaRashi)
+		to := from + nakshatraSpan
+		// The segment between two rays, turning at any corner between them
+		var in, out []gg.Point
+		in = append(in, ringPoint(g, from, inner))
+		out = append(out, ringPoint(g, from, outer))
+		for corner := math.Floor((from-45)/90)*90 + 135; corner < to; corner += 90 {
+			in = append(in, ringPoint(g, corner, inner))
+			out = append(out, ringPoint(g, corner, outer))
+		}
+		in = append(in, ringPoint(g, to, inner))
+		out = append(out, ringPoint(g, to, outer))
+		dc.NewSubPath()
+		for _, p := range in {
+			dc.LineTo(p.X, p.Y)
+		}
+		for j := len(out) - 1; j >= 0; j-- {
+			dc.LineTo(out[j].X, out[j].Y)
+		}
+		dc.ClosePath()
+		if i%2 == 1 && !frame.contrast {
+			dc.SetColor(ringShade)
+			dc.FillPreserve()
+		}
+		dc.SetColor(frame.ink(houseNumberGray))
+		dc.SetLineWidth(frame.line(1))
+		dc.Stroke()
+	}
+
+	size := frame.px(ringFontSize)
+	face := embeddedFace(matangiRegular, size)
+	m := regularMetrics(size)
+	for i, name := range nakshatraAbbreviations {
+		// The label sits in the middle of the longest straight piece of its
+		// segment, clear of the corners
+		from := g.nakshatraStart(i, lagnaRashi)
+		to := from + nakshatraSpan
+		line := []gg.Point{ringPoint(g, from, (inner+outer)/2)}
+		for corner := math.Floor((from-45)/90)*90 + 135; corner < to; corner += 90 {
+			line = append(line, ringPoint(g, corner, (inner+outer)/2))
+		}
+		line = append(line, ringPoint(g, to, (inner+outer)/2))
+		var a, b gg.Point
+		for j := 1; j < len(line); j++ {
+			if line[j].Distance(line[j-1]) > b.Distance(a) {
+				a, b = line[j-1], line[j]
+			}
+		}
+		at := gg.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+		// Along the sides the text turns to run up the left and down the right
+		var turn float64
+		if math.Abs(a.X-b.X) < math.Abs(a.Y-b.Y) {
+			turn = math.Pi / 2
+			if at.X < g.cx {
+				turn = -math.Pi / 2
+			}
+		}
+		baseline := m.baseline(0)
+		dc.Push()
+		dc.Translate(at.X, at.Y)
+		dc.Rotate(turn)
+		drawText(dc, face, textBlack, name, 0, baseline, 0.5)
+		dc.Pop()
+		w := float64(font.MeasureString(face, name)) / 64
+		box := textBox{text: name, kind: labelNakshatra,
+			left: at.X - w/2, top: at.Y + baseline - m.capHeight, right: at.X + w/2, bottom: at.Y + baseline + m.descent}
+		boxes.add(box.rotated(turn, at.X, at.Y))
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import "testing"
+
+func TestGenerateChart_NakshatraRing(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "cancer"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "cancer"},
+			"moon":    {Rashi: "aries"},
+			"jupiter": {Rashi: "libra", IsRetrograde: true},
+			"saturn":  {Rashi: "capricorn"},
+		},
+		Options: ChartOptions{NakshatraRing: true},
+	}
+	data, err := GenerateNorthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "north_nakshatra_ring", data)
+
+	boxes := renderWithBoxes(t, input)
+	if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+		t.Errorf("overlapping labels: %v", overlaps)
+	}
+	ring := map[string]textBox{}
+	for _, b := range boxes.boxes {
+		if b.kind == labelNakshatra {
+			ring[b.text] = b
+			if b.left < 0 || b.top < 0 || b.right > defaultChartSize || b.bottom > defaultChartSize {
+				t.Errorf("nakshatra %q at %+v, off the canvas", b.text, b)
+			}
+		}
+	}
+	if len(ring) != 27 {
+		t.Fatalf("%d nakshatras on the ring, want 27", len(ring))
+	}
+
+	// With a Cancer lagna Aries is the 10th house, on the right, so Ashwini
+	// runs up the right side and Pushya, in Cancer, sits at the top
+	center := defaultChartSize / 2.0
+	if ash := ring["Ash"]; ash.left < center+300 || ash.bottom-ash.top <= ash.right-ash.left {
+		t.Errorf("Ashwini at %+v, want it upright along the right side", ash)
+	}
+	if pus := ring["Pus"]; pus.bottom > 100 || pus.right < center-60 || pus.left > center+60 {
+		t.Errorf("Pushya at %+v, want it at the top beside the lagna", pus)
+	}
+}
+
+func TestRingAngle(t *testing.T) {
+	// The lagna's rashi takes the 30° around the top, the 12th house's the
+	// 30° to its right
+	tests := []struct {
+		longitude  float64
+		lagnaRashi int
+		want       float64
+	}{
+		{0, 1, 75},
+		{30, 1, 105},
+		{0, 4, -15},
+		{105, 4, 90},
+	}
+	for _, tt := range tests {
+		if got := (northGeometry{}).ringAngle(tt.longitude, tt.lagnaRashi); got != tt.want {
+			t.Errorf("ringAngle(%v, %d) = %v, want %v", tt.longitude, tt.lagnaRashi, got, tt.want)
+		}
+	}
+}
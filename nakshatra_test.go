@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+)
+
+func TestNakshatraFromLongitude(t *testing.T) {
+	tests := []struct {
+		longitude float64
+		name      string
+		pada      int
+	}{
+		{0, "Ashwini", 1},
+		{3.5, "Ashwini", 2},
+		{13.3, "Ashwini", 4},
+		{13.34, "Bharani", 1},
+		{45, "Rohini", 2}, // 15° Taurus
+		{120, "Magha", 1}, // 0° Leo
+		{187, "Swati", 1}, // 7° Libra
+		{359.99, "Revati", 4},
+		{360, "Ashwini", 1}, // Wraps around
+		{-10, "Revati", 1},  // 350°
+		{400, "Rohini", 1},  // 40°
+		{math.NaN(), "", 0}, // No nakshatra, rather than a panic
+		{math.Inf(1), "", 0},
+		{math.Inf(-1), "", 0},
+	}
+	for _, tt := range tests {
+		name, pada := NakshatraFromLongitude(tt.longitude)
+		if name != tt.name || pada != tt.pada {
+			t.Errorf("NakshatraFromLongitude(%v) = %s-%d, want %s-%d", tt.longitude, name, pada, tt.name, tt.pada)
+		}
+	}
+
+	// Every nakshatra is reached and each has four padas
+	for i, want := range nakshatraNames {
+		for pada := 1; pada <= 4; pada++ {
+			lon := float64(i)*nakshatraSpan + (float64(pada)-0.5)*nakshatraSpan/4
+			name, got := NakshatraFromLongitude(lon)
+			if name != want || got != pada {
+				t.Errorf("NakshatraFromLongitude(%v) = %s-%d, want %s-%d", lon, name, got, want, pada)
+			}
+		}
+	}
+}
+
+func TestLabelFormat_Nakshatra(t *testing.T) {
+	f := newLabelFormat(ChartOptions{ShowNakshatra: true})
+	tests := []struct {
+		planet *Planet
+		want   string
+	}{
+		{&Planet{Rashi: "taurus", Nakshatra: "Rohini", Pada: 2}, "Rohini-2"},
+		{&Planet{Rashi: "taurus", Nakshatra: "Rohini"}, "Rohini"},
+		{&Planet{Rashi: "taurus"}, ""},
+	}
+	for _, tt := range tests {
+		e := f.entry("moon", tt.planet)
+		if e.label != "Mo" || e.subLabel != tt.want {
+			t.Errorf("entry(%+v) = %q / %q, want %q / %q", tt.planet, e.label, e.subLabel, "Mo", tt.want)
+		}
+	}
+	if e, _ := f.lagnaEntry(&Planet{Rashi: "leo", Nakshatra: "Magha", Pada: 1}); e.subLabel != "Magha-1" {
+		t.Errorf("lagnaEntry subLabel = %q, want %q", e.subLabel, "Magha-1")
+	}
+
+	// Without the option the nakshatra is not drawn
+	plain := newLabelFormat(ChartOptions{})
+	if e := plain.entry("moon", &Planet{Rashi: "taurus", Nakshatra: "Rohini", Pada: 2}); e.subLabel != "" {
+		t.Errorf("subLabel without ShowNakshatra = %q, want none", e.subLabel)
+	}
+}
+
+func TestRowOffsets(t *testing.T) {
+	left := []planetEntry{{label: "Su", subLabel: "Magha-1"}, {label: "Me"}, {label: "Ve"}}
+	right := []planetEntry{{label: "HL"}, {label: "GL", subLabel: "Pushya-3"}}
+	got := rowOffsets(left, right, 20, 20)
+	want := []float64{0, 20 + 20*subLabelScale, 40 + 40*subLabelScale}
+	if len(got) != len(want) {
+		t.Fatalf("rowOffsets returned %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d offset = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGenerateChart_PadaValidation(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Planets:   map[string]*Planet{"moon": {Rashi: "taurus", Nakshatra: "Rohini", Pada: 5}},
+	}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("expected an error for pada 5")
+	}
+}
+
+func TestGenerateChart_ShowNakshatra(t *testing.T) {
+	// Only some points carry nakshatra data, the Sun shares the lagna house
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo", Nakshatra: "Magha", Pada: 3},
+			Planets: map[string]*Planet{
+				"sun":     {Rashi: "leo"},
+				"moon":    {Rashi: "taurus", Nakshatra: "Rohini", Pada: 2},
+				"jupiter": {Rashi: "cancer", Nakshatra: "Pushya", IsRetrograde: true},
+				"saturn":  {Rashi: "libra"},
+			},
+			Options: ChartOptions{ShowNakshatra: true},
+		}
+		base64Image, err := GenerateChart(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		imageData, err := base64.StdEncoding.DecodeString(base64Image)
+		if err != nil {
+			t.Fatalf("Error decoding base64: %v", err)
+		}
+		assertGolden(t, string(chartType)+"_nakshatra", imageData)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// NativeInfo is the native's details a printed kundali carries. Fields left
+// empty are left out.
+type NativeInfo struct {
+	Name        string    `json:"name,omitempty"`
+	DateOfBirth time.Time `json:"date_of_birth,omitzero"`
+	// TimeOfBirth is printed as given, such as "14:35:20"; when empty the
+	// clock of DateOfBirth is printed unless it is midnight
+	TimeOfBirth string `json:"time_of_birth,omitempty"`
+	Place       string `json:"place,omitempty"`
+	// Latitude and Longitude are in degrees, north and east positive. Both
+	// zero leaves the coordinates out.
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	// TimeZone follows the time of birth, such as "IST" or "+05:30"
+	TimeZone string `json:"time_zone,omitempty"`
+	Ayanamsa string `json:"ayanamsa,omitempty"`
+}
+
+// Formats of the native's date and time of birth
+const (
+	nativeDateFormat = "2 Jan 2006"
+	nativeTimeFormat = "15:04"
+)
+
+// nativeLabels are the English labels of the native's details, by field
+var nativeLabels = map[string]string{
+	"name":        "Name",
+	"date":        "Date",
+	"time":        "Time",
+	"place":       "Place",
+	"coordinates": "Coordinates",
+	"ayanamsa":    "Ayanamsa",
+}
+
+// items returns the details of n that are set, labelled in loc, or nil for
+// a nil n
+func (n *NativeInfo) items(loc *chartLocale) []panchangaItem {
+	if n == nil {
+		return nil
+	}
+	clock := strings.TrimSpace(n.TimeOfBirth)
+	if clock == "" && !n.DateOfBirth.IsZero() && n.DateOfBirth.Format("15:04:05") != "00:00:00" {
+		clock = n.DateOfBirth.Format(nativeTimeFormat)
+	}
+	if zone := strings.TrimSpace(n.TimeZone); clock != "" && zone != "" {
+		clock += " " + zone
+	}
+	var date, coordinates string
+	if !n.DateOfBirth.IsZero() {
+		date = n.DateOfBirth.Format(nativeDateFormat)
+	}
+	if n.Latitude != 0 || n.Longitude != 0 {
+		coordinates = formatCoordinate(n.Latitude, "N", "S") + " " + formatCoordinate(n.Longitude, "E", "W")
+	}
+	var items []panchangaItem
+	for _, field := range []struct{ key, value string }{
+		{"name", n.Name},
+		{"date", date},
+		{"time", clock},
+		{"place", n.Place},
+		{"coordinates", coordinates},
+		{"ayanamsa", n.Ayanamsa},
+	} {
+		if value := strings.TrimSpace(field.value); value != "" {
+			items = append(items, panchangaItem{loc.nativeLabels[field.key], value})
+		}
+	}
+	return items
+}
+
+// formatCoordinate writes a latitude or longitude to two decimals with the
+// hemisphere letter for its sign, such as "28.61°N"
+func formatCoordinate(degrees float64, positive, negative string) string {
+	hemisphere := positive
+	if degrees < 0 {
+		hemisphere = negative
+	}
+	return fmt.Sprintf("%.2f°%s", math.Abs(degrees), hemisphere)
+}
+
+// validateNative checks the native's coordinates
+func validateNative(input ChartInput) error {
+	n := input.Native
+	if n == nil {
+		return nil
+	}
+	if math.IsNaN(n.Latitude) || n.Latitude < -90 || n.Latitude > 90 {
+		return fmt.Errorf("native: latitude %v out of range [-90, 90]", n.Latitude)
+	}
+	if math.IsNaN(n.Longitude) || n.Longitude < -180 || n.Longitude > 180 {
+		return fmt.Errorf("native: longitude %v out of range [-180, 180]", n.Longitude)
+	}
+	return nil
+}
+
+// nativeInCenter reports whether the native's details are drawn in the
+// center of a South chart, which is when it has no center text or lines.
+// Otherwise they are drawn as a strip beneath the chart.
+func nativeInCenter(input ChartInput) bool {
+	return input.Options.ShowNativeInfo && input.ChartType == ChartTypeSouth && input.CenterText == "" && len(input.CenterLines) == 0 && input.Native != nil
+}
+
+// newNativeStrip returns the strip of the native's details, or nil when
+// they are not shown or are drawn in the chart's center instead
+func newNativeStrip(input ChartInput) *itemStrip {
+	if !input.Options.ShowNativeInfo || nativeInCenter(input) {
+		return nil
+	}
+	return newItemStrip(input.Native.items(localeFor(input.Options)), labelNative)
+}
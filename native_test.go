@@ -0,0 +1,162 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// nativeInput returns the house scores chart with the native's details,
+// its place long enough to wrap and be cut short
+func nativeInput(chartType ChartType) ChartInput {
+	input := houseScoresInput(chartType)
+	input.HouseScores, input.SecondaryHouseScores = nil, nil
+	input.Native = &NativeInfo{
+		Name:        "Arjun Sharma",
+		DateOfBirth: time.Date(1990, 8, 15, 0, 0, 0, 0, time.UTC),
+		TimeOfBirth: "14:35",
+		Place:       "Sawai Madhopur, Rajasthan, India, near the Ranthambore fort and national park gates",
+		Latitude:    26.0173,
+		Longitude:   76.3565,
+		TimeZone:    "IST",
+		Ayanamsa:    "Lahiri",
+	}
+	input.Options = ChartOptions{ShowNativeInfo: true}
+	return input
+}
+
+func TestNativeInfo_Items(t *testing.T) {
+	items := func(n *NativeInfo, loc *chartLocale) string {
+		var got []string
+		for _, item := range n.items(loc) {
+			got = append(got, item.label+"="+item.value)
+		}
+		return strings.Join(got, "|")
+	}
+	english := localeFor(ChartOptions{})
+	n := nativeInput(ChartTypeSouth).Native
+	n.Place = "Jaipur"
+	want := "Name=Arjun Sharma|Date=15 Aug 1990|Time=14:35 IST|Place=Jaipur|Coordinates=26.02°N 76.36°E|Ayanamsa=Lahiri"
+	if got := items(n, english); got != want {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+
+	// Without a time of birth the clock of the date is printed, unless it
+	// is midnight; coordinates south and west get their own letters
+	n = &NativeInfo{DateOfBirth: time.Date(1990, 8, 15, 6, 5, 0, 0, time.UTC), Latitude: -33.8688, Longitude: -70.6693}
+	if got, want := items(n, english), "Date=15 Aug 1990|Time=06:05|Coordinates=33.87°S 70.67°W"; got != want {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+	n = &NativeInfo{DateOfBirth: time.Date(1990, 8, 15, 0, 0, 0, 0, time.UTC), TimeZone: "IST"}
+	if got, want := items(n, english), "Date=15 Aug 1990"; got != want {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+	if got := (*NativeInfo)(nil).items(english); got != nil {
+		t.Errorf("nil native items = %v", got)
+	}
+
+	// A locale's labels replace the English ones it lists
+	loc := newChartLocale(Locale{NativeLabels: map[string]string{"name": "Nāma", "place": "Sthāna"}})
+	if got, want := items(&NativeInfo{Name: "Arjun", Place: "Jaipur", Ayanamsa: "Lahiri"}, loc), "Nāma=Arjun|Sthāna=Jaipur|Ayanamsa=Lahiri"; got != want {
+		t.Errorf("localized items = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateChart_NativeInCenter(t *testing.T) {
+	input := nativeInput(ChartTypeSouth)
+	input.Panchanga = panchangaInput(ChartTypeSouth).Panchanga
+	input.Options.ShowPanchanga = true
+	data, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_native", data)
+
+	// The details take the center and push the panchanga beneath the chart
+	var place []string
+	panchanga := 0
+	for _, l := range layout.Labels {
+		switch l.Kind {
+		case string(labelNative):
+			if l.Left < 220 || l.Right > 580 || l.Top < 220 || l.Bottom > 580 {
+				t.Errorf("native %q at %+v, outside the center squares", l.Text, l)
+			}
+			if strings.HasPrefix(l.Text, "Sawai") || strings.HasSuffix(l.Text, "…") {
+				place = append(place, l.Text)
+			}
+		case string(labelPanchanga):
+			panchanga++
+			if l.Top < defaultChartSize {
+				t.Errorf("panchanga %q at %+v, not beneath the chart", l.Text, l)
+			}
+		}
+	}
+	if len(place) != 2 || !strings.HasSuffix(place[1], "…") {
+		t.Errorf("place lines = %q, want two, the second cut short", place)
+	}
+	if panchanga == 0 {
+		t.Error("panchanga not drawn beneath the chart")
+	}
+}
+
+func TestGenerateChart_NativeStrip(t *testing.T) {
+	data, layout, err := GenerateChartWithLayout(nativeInput(ChartTypeNorth))
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "north_native", data)
+	if layout.Height <= defaultChartSize {
+		t.Errorf("canvas height %d, want the strip beneath the chart", layout.Height)
+	}
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelNative) && l.Top < defaultChartSize {
+			t.Errorf("native %q at %+v, not beneath the chart", l.Text, l)
+		}
+	}
+
+	// Without the option the details are not drawn
+	input := nativeInput(ChartTypeNorth)
+	input.Options.ShowNativeInfo = false
+	_, layout, err = GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if layout.Height != defaultChartSize {
+		t.Errorf("canvas height %d without show_native_info, want %d", layout.Height, defaultChartSize)
+	}
+}
+
+func TestGenerateChart_NativeValidation(t *testing.T) {
+	input := nativeInput(ChartTypeSouth)
+	input.Native.Latitude = 91
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "native: latitude 91 out of range") {
+		t.Errorf("latitude error = %v", err)
+	}
+	input = nativeInput(ChartTypeSouth)
+	input.Native.Longitude = -181
+	if _, err := GenerateChart(input); err == nil || !strings.Contains(err.Error(), "native: longitude -181 out of range") {
+		t.Errorf("longitude error = %v", err)
+	}
+
+	if err := RegisterLocale("xx-native", Locale{NativeLabels: map[string]string{"birthday": "Born"}}); err == nil || !strings.Contains(err.Error(), "unknown native field birthday") {
+		t.Errorf("unknown native field error = %v", err)
+	}
+	if err := RegisterLocale("xx-native", Locale{NativeLabels: map[string]string{"name": ""}}); err == nil || !strings.Contains(err.Error(), "empty native label for name") {
+		t.Errorf("empty native label error = %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NodeRetrograde controls the retrograde marker of Rahu and Ketu, which
+// always move backwards and so are often left unmarked
+type NodeRetrograde string
+
+const (
+	// NodeRetrogradeFlagged marks the nodes only when they are flagged
+	// is_retrograde (the default)
+	NodeRetrogradeFlagged NodeRetrograde = "flagged"
+	// NodeRetrogradeAlways marks both nodes retrograde
+	NodeRetrogradeAlways NodeRetrograde = "always"
+	// NodeRetrogradeNever never marks the nodes retrograde, whatever their flags
+	NodeRetrogradeNever NodeRetrograde = "never"
+)
+
+// isNode reports whether a planet name is Rahu or Ketu
+func isNode(planetName string) bool {
+	name := strings.ToLower(planetName)
+	return name == "rahu" || name == "ketu"
+}
+
+// NormalizeChartInput returns the input with the flags that follow from its
+// other fields filled in. Generating a chart normalizes its input first, as
+// part of CanonicalizeChartInput.
+//
+// A planet with a negative SpeedDegPerDay is marked retrograde unless its
+// RetrogradeSet says IsRetrograde was given, so an explicit is_retrograde,
+// true or false, wins over the speed. The flag is only ever set from the
+// speed, never cleared, so a planet flagged is_retrograde stays retrograde
+// whatever its speed. Rahu and Ketu are not marked from their speed;
+// Options.NodeRetrograde decides their flags instead.
+//
+// Nil planets, which have nothing to draw, are dropped.
+//
+// The input's planets are left untouched: planets that change are copied,
+// along with the map holding them.
+func NormalizeChartInput(input ChartInput) ChartInput {
+	copied := false
+	copyPlanets := func() {
+		if !copied {
+			planets := make(map[string]*Planet, len(input.Planets))
+			for k, v := range input.Planets {
+				planets[k] = v
+			}
+			input.Planets, copied = planets, true
+		}
+	}
+	for name, p := range input.Planets {
+		if p == nil {
+			copyPlanets()
+			delete(input.Planets, name)
+			continue
+		}
+		retrograde := p.IsRetrograde
+		if isNode(name) {
+			switch input.Options.NodeRetrograde {
+			case NodeRetrogradeAlways:
+				retrograde = true
+			case NodeRetrogradeNever:
+				retrograde = false
+			}
+		} else if p.SpeedDegPerDay < 0 && !p.RetrogradeSet {
+			retrograde = true
+		}
+		if retrograde == p.IsRetrograde {
+			continue
+		}
+		copyPlanets()
+		changed := *p
+		changed.IsRetrograde = retrograde
+		input.Planets[name] = &changed
+	}
+	return input
+}
+
+// CanonicalizeChartInput rewrites an input in place into one canonical form
+// and returns a description of every change made, in a fixed order:
+//
+//   - planet names are lowercased, an error when two of them then clash
+//   - nil planets are dropped
+//   - the rashis and navamsa rashis of the lagna and planets are resolved
+//     with ParseRashi to the English names NumberToRashi returns, an unknown
+//     one being an error
+//   - the flags NormalizeChartInput fills in are set
+//
+// Changed planets are copied rather than changed, as are the map holding
+// them, so inputs sharing planets with this one are left alone, and on an
+// error the input is not changed at all.
+//
+// GenerateChart and the generators built on it canonicalize a copy of their
+// input after validating it, so a chart is drawn the same whatever the case
+// of its planet names, and two planets whose names differ only in case are
+// an error rather than two planets.
+func CanonicalizeChartInput(input *ChartInput) ([]string, error) {
+	var changes []string
+	c := *input
+
+	lagna, err := canonicalPlanet("lagna", c.Lagna, &changes)
+	if err != nil {
+		return nil, err
+	}
+	c.Lagna = lagna
+
+	if c.Planets != nil {
+		c.Planets = make(map[string]*Planet, len(input.Planets))
+		for _, name := range sortedPlanetNames(input.Planets) {
+			p := input.Planets[name]
+			key := strings.ToLower(name)
+			if p == nil {
+				changes = append(changes, fmt.Sprintf("planet %q dropped: nil", name))
+				continue
+			}
+			if key != name {
+				changes = append(changes, fmt.Sprintf("planet %q renamed %q", name, key))
+			}
+			if _, ok := c.Planets[key]; ok {
+				return nil, fmt.Errorf("planet %q: another planet is also named %q", name, key)
+			}
+			if c.Planets[key], err = canonicalPlanet(key, p, &changes); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	normalized := NormalizeChartInput(c)
+	for _, name := range sortedPlanetNames(normalized.Planets) {
+		if was, now := c.Planets[name].IsRetrograde, normalized.Planets[name].IsRetrograde; was != now {
+			changes = append(changes, fmt.Sprintf("planet %s: is_retrograde set to %v", name, now))
+		}
+	}
+	*input = normalized
+	return changes, nil
+}
+
+// canonicalPlanet returns p with its rashi and navamsa rashi resolved to
+// their English names, copied when either changes, and appends the changes
+// to changes. name is the planet's canonical name, "lagna" for the lagna.
+func canonicalPlanet(name string, p *Planet, changes *[]string) (*Planet, error) {
+	if p == nil {
+		return nil, nil
+	}
+	// Named as ErrInvalidRashi names them
+	subject := "planet " + name
+	if name == "lagna" {
+		subject = name
+	}
+	num, err := ParseRashi(p.Rashi)
+	if err != nil {
+		return nil, &ErrInvalidRashi{Planet: name, Rashi: p.Rashi}
+	}
+	navamsa := p.NavamsaRashi
+	if navamsa != "" {
+		n, err := ParseRashi(navamsa)
+		if err != nil {
+			return nil, fmt.Errorf("%s: unknown navamsa_rashi %q", subject, navamsa)
+		}
+		navamsa = NumberToRashi(n)
+	}
+	rashi := NumberToRashi(num)
+	if rashi == p.Rashi && navamsa == p.NavamsaRashi {
+		return p, nil
+	}
+	if rashi != p.Rashi {
+		*changes = append(*changes, fmt.Sprintf("%s: rashi %q resolved to %q", subject, p.Rashi, rashi))
+	}
+	if navamsa != p.NavamsaRashi {
+		*changes = append(*changes, fmt.Sprintf("%s: navamsa_rashi %q resolved to %q", subject, p.NavamsaRashi, navamsa))
+	}
+	changed := *p
+	changed.Rashi, changed.NavamsaRashi = rashi, navamsa
+	return &changed, nil
+}
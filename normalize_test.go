@@ -0,0 +1,289 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeChartInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		planet string
+		in     Planet
+		mode   NodeRetrograde
+		want   bool
+	}{
+		{"negative speed", "mars", Planet{SpeedDegPerDay: -0.2}, "", true},
+		{"positive speed", "mars", Planet{SpeedDegPerDay: 0.5}, "", false},
+		{"unknown speed", "mars", Planet{}, "", false},
+		{"explicit flag wins over positive speed", "saturn", Planet{IsRetrograde: true, SpeedDegPerDay: 0.03}, "", true},
+		{"explicit flag agrees with speed", "jupiter", Planet{IsRetrograde: true, SpeedDegPerDay: -0.1}, "", true},
+		{"explicit false wins over negative speed", "mercury", Planet{RetrogradeSet: true, SpeedDegPerDay: -0.4}, "", false},
+		{"node speed is not used", "rahu", Planet{SpeedDegPerDay: -0.05}, "", false},
+		{"node flag kept", "ketu", Planet{IsRetrograde: true}, NodeRetrogradeFlagged, true},
+		{"nodes always", "rahu", Planet{}, NodeRetrogradeAlways, true},
+		{"nodes never", "Ketu", Planet{IsRetrograde: true}, NodeRetrogradeNever, false},
+		{"node mode leaves planets alone", "venus", Planet{IsRetrograde: true}, NodeRetrogradeNever, true},
+	}
+	for _, tt := range tests {
+		planet := tt.in
+		planet.Rashi = "aries"
+		input := ChartInput{
+			Planets: map[string]*Planet{tt.planet: &planet},
+			Options: ChartOptions{NodeRetrograde: tt.mode},
+		}
+		got := NormalizeChartInput(input)
+		if r := got.Planets[tt.planet].IsRetrograde; r != tt.want {
+			t.Errorf("%s: IsRetrograde = %v, want %v", tt.name, r, tt.want)
+		}
+		if planet.IsRetrograde != tt.in.IsRetrograde {
+			t.Errorf("%s: the caller's planet was modified", tt.name)
+		}
+	}
+}
+
+func TestNormalizeChartInput_RetrogradeFromJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want bool
+	}{
+		{"explicit false", `{"rashi": "aries", "is_retrograde": false, "speed_deg_per_day": -0.4}`, false},
+		{"explicit true", `{"rashi": "aries", "is_retrograde": true, "speed_deg_per_day": 0.4}`, true},
+		{"left out", `{"rashi": "aries", "speed_deg_per_day": -0.4}`, true},
+	}
+	for _, tt := range tests {
+		var p Planet
+		if err := json.Unmarshal([]byte(tt.json), &p); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := NormalizeChartInput(ChartInput{Planets: map[string]*Planet{"mercury": &p}})
+		if r := got.Planets["mercury"].IsRetrograde; r != tt.want {
+			t.Errorf("%s: IsRetrograde = %v, want %v", tt.name, r, tt.want)
+		}
+
+		// Encoding keeps whether the flag was given
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Planet
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded != p {
+			t.Errorf("%s: %s decodes to %+v, want %+v", tt.name, data, decoded, p)
+		}
+	}
+}
+
+func TestPlanet_UnmarshalJSONUnknownField(t *testing.T) {
+	var input ChartInput
+	err := json.Unmarshal([]byte(`{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "retrograde": true}}}`), &input)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "retrograde"`) {
+		t.Errorf("decoding a planet with an unknown field: %v", err)
+	}
+}
+
+func TestNormalizeChartInput_SharesUnchangedInput(t *testing.T) {
+	planets := map[string]*Planet{"sun": {Rashi: "aries"}, "moon": {Rashi: "leo", SpeedDegPerDay: 13}}
+	got := NormalizeChartInput(ChartInput{Planets: planets})
+	if got.Planets["sun"] != planets["sun"] || got.Planets["moon"] != planets["moon"] {
+		t.Error("NormalizeChartInput copied planets that did not change")
+	}
+
+	planets["mars"] = &Planet{Rashi: "aries", SpeedDegPerDay: -0.3}
+	got = NormalizeChartInput(ChartInput{Planets: planets})
+	if got.Planets["sun"] != planets["sun"] || got.Planets["mars"] == planets["mars"] {
+		t.Error("NormalizeChartInput should copy only the planets it changes")
+	}
+	if planets["mars"].IsRetrograde {
+		t.Error("NormalizeChartInput modified the caller's map")
+	}
+}
+
+func TestNormalizeChartInput_DropsNilPlanets(t *testing.T) {
+	planets := map[string]*Planet{"sun": {Rashi: "aries"}, "moon": nil}
+	got := NormalizeChartInput(ChartInput{Planets: planets})
+	if _, ok := got.Planets["moon"]; ok || got.Planets["sun"] != planets["sun"] {
+		t.Errorf("normalized planets = %v, want the sun alone", got.Planets)
+	}
+	if _, ok := planets["moon"]; !ok {
+		t.Error("NormalizeChartInput modified the caller's map")
+	}
+}
+
+func TestGenerateChart_RetrogradeFromSpeed(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		flagged := ChartInput{
+			ChartType: chartType,
+			Planets: map[string]*Planet{
+				"mars": {Rashi: "aries", IsRetrograde: true},
+				"rahu": {Rashi: "leo"},
+			},
+		}
+		fromSpeed := ChartInput{
+			ChartType: chartType,
+			Planets: map[string]*Planet{
+				"mars": {Rashi: "aries", SpeedDegPerDay: -0.2},
+				"rahu": {Rashi: "leo", IsRetrograde: true},
+			},
+			Options: ChartOptions{NodeRetrograde: NodeRetrogradeNever},
+		}
+		want, err := GenerateChartPNGs([]ChartInput{flagged})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := GenerateChartPNGs([]ChartInput{fromSpeed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got[0], want[0]) {
+			t.Errorf("%s: retrograde from speed differs from the flagged chart", chartType)
+		}
+		direct, err := generateFor(chartType)(fromSpeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(direct, want[0]) {
+			t.Errorf("%s: the chart style's generator does not normalize its input", chartType)
+		}
+	}
+}
+
+func TestGenerateChart_NodeRetrogradeInvalid(t *testing.T) {
+	input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{NodeRetrograde: "sometimes"}}
+	if _, err := GenerateChart(input); err == nil {
+		t.Error("GenerateChart accepted node_retrograde \"sometimes\"")
+	}
+}
+
+func TestCanonicalizeChartInput(t *testing.T) {
+	sun := &Planet{Rashi: "Simha", NavamsaRashi: "Mesha"}
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "LEO"},
+		Planets: map[string]*Planet{
+			"Sun":   sun,
+			"mars":  {Rashi: "aries", SpeedDegPerDay: -0.2},
+			"moon":  {Rashi: "taurus"},
+			"Ghost": nil,
+		},
+	}
+	original := input.Clone()
+	want, err := GenerateChartPNGs([]ChartInput{input})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := CanonicalizeChartInput(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChanges := []string{
+		`lagna: rashi "LEO" resolved to "leo"`,
+		`planet "Ghost" dropped: nil`,
+		`planet "Sun" renamed "sun"`,
+		`planet sun: rashi "Simha" resolved to "leo"`,
+		`planet sun: navamsa_rashi "Mesha" resolved to "aries"`,
+		"planet mars: is_retrograde set to true",
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %q, want %q", changes, wantChanges)
+	}
+	if got := input.Planets["sun"]; got == nil || got.Rashi != "leo" || got.NavamsaRashi != "aries" {
+		t.Errorf("sun = %+v", got)
+	}
+	if len(input.Planets) != 3 || input.Lagna.Rashi != "leo" {
+		t.Errorf("canonical input = %+v", input)
+	}
+	if sun.Rashi != "Simha" || !reflect.DeepEqual(original.Planets["Sun"], sun) {
+		t.Error("CanonicalizeChartInput changed a planet it shares with another input")
+	}
+
+	// The chart is drawn as before
+	got, err := GenerateChartPNGs([]ChartInput{input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got[0], want[0]) {
+		t.Error("the canonical input draws another chart")
+	}
+
+	// Canonical input has nothing left to change
+	if changes, err := CanonicalizeChartInput(&input); err != nil || len(changes) != 0 {
+		t.Errorf("second pass changes = %q, %v", changes, err)
+	}
+}
+
+func TestCanonicalizeChartInput_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input ChartInput
+		want  string
+	}{
+		{"clashing names", ChartInput{Planets: map[string]*Planet{"Sun": {Rashi: "leo"}, "SUN": {Rashi: "leo"}}}, `planet "Sun": another planet is also named "sun"`},
+		{"unknown rashi", ChartInput{Planets: map[string]*Planet{"Mars": {Rashi: "Ares"}}}, `planet mars: unknown rashi "Ares"`},
+		{"unknown lagna rashi", ChartInput{Lagna: &Planet{Rashi: "Leon"}}, `lagna: unknown rashi "Leon"`},
+		{"unknown navamsa", ChartInput{Planets: map[string]*Planet{"moon": {Rashi: "leo", NavamsaRashi: "x"}}}, `planet moon: unknown navamsa_rashi "x"`},
+	}
+	for _, tt := range tests {
+		input := tt.input.Clone()
+		_, err := CanonicalizeChartInput(&input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error %v, want %s", tt.name, err, tt.want)
+		}
+		if !reflect.DeepEqual(input, tt.input) {
+			t.Errorf("%s: the input changed on error", tt.name)
+		}
+	}
+}
+
+func TestGenerateChart_CanonicalizesPlanetNames(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		lower := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo"},
+			Planets:   map[string]*Planet{"sun": {Rashi: "leo"}, "mars": {Rashi: "aries"}},
+		}
+		mixed := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "Simha"},
+			Planets:   map[string]*Planet{"Sun": {Rashi: "LEO"}, "MARS": {Rashi: "mesha"}},
+		}
+		want, err := GenerateChart(lower)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := GenerateChart(mixed); err != nil || got != want {
+			t.Errorf("%s: names and rashis in other cases draw another chart (error %v)", chartType, err)
+		}
+
+		// "Sun" and "sun" are one planet twice
+		mixed.Planets["sun"] = &Planet{Rashi: "virgo"}
+		if _, err := GenerateChart(mixed); err == nil || !strings.Contains(err.Error(), `another planet is also named "sun"`) {
+			t.Errorf("%s: GenerateChart with \"Sun\" and \"sun\" returned error %v", chartType, err)
+		}
+		if _, _, err := GenerateBothCharts(mixed); err == nil {
+			t.Errorf("%s: GenerateBothCharts accepted \"Sun\" and \"sun\"", chartType)
+		}
+	}
+}
@@ -16,8 +16,9 @@
 package parashari
 
 import (
+	"bytes"
 	"encoding/base64"
-	"os"
+	"math"
 	"testing"
 )
 
@@ -93,10 +94,7 @@ func TestNorthChart_AllPlanets(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_north_all_planets.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "north_all_planets", imageData)
 
 	t.Logf("Test 1 passed: All planets chart generated successfully (%d bytes)", len(imageData))
 }
@@ -169,10 +167,7 @@ func TestNorthChart_AllPlanetsWithLagna(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_north_all_planets_with_lagna.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "north_all_planets_with_lagna", imageData)
 
 	t.Logf("Test 2 passed: All planets with Lagna chart generated successfully (%d bytes)", len(imageData))
 }
@@ -265,10 +260,7 @@ func TestNorthChart_AllPlanetsWithUpagrahas(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_north_all_planets_with_upagrahas.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "north_all_planets_with_upagrahas", imageData)
 
 	t.Logf("Test 3 passed: All planets with upagrahas chart generated successfully (%d bytes)", len(imageData))
 }
@@ -341,10 +333,7 @@ func TestNorthChart_AllPlanetsUpagrahasLagnaSameRashi(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_north_all_same_rashi.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "north_all_same_rashi", imageData)
 
 	t.Logf("Test 4 passed: All planets, upagrahas, and lagna in same rashi chart generated successfully (%d bytes)", len(imageData))
 }
@@ -417,10 +406,129 @@ func TestNorthChart_WithLagnaInLeo(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_north_lagna_leo.png", imageData, 0644)
+	assertGolden(t, "north_lagna_leo", imageData)
+
+	t.Logf("Test 5 passed: Lagna in Leo chart generated successfully (%d bytes)", len(imageData))
+}
+
+func TestNorthChart_DignityMarkers(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets: map[string]*Planet{
+			"sun":    {Rashi: "aries", IsExalted: true},
+			"saturn": {Rashi: "libra", IsExalted: true, IsRetrograde: true},
+			"moon":   {Rashi: "scorpio", IsDebilitated: true},
+		},
+	}
+
+	imageData, err := GenerateNorthChart(input)
 	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
+		t.Fatalf("Error generating chart: %v", err)
 	}
+	assertGolden(t, "north_dignity_markers", imageData)
+}
 
-	t.Logf("Test 5 passed: Lagna in Leo chart generated successfully (%d bytes)", len(imageData))
+func TestNorthChart_RashiNumbersAtLabelAnchors(t *testing.T) {
+	geo := northGeometry{cx: 400, cy: 400, half: 361.5}
+	// Without planets or a lagna the only labels are the rashi numbers
+	input := ChartInput{ChartType: ChartTypeNorth}
+
+	// Every number is centered on its region's label anchor, towards the chart center
+	boxes := renderWithBoxes(t, input)
+	if len(boxes.boxes) != 12 {
+		t.Fatalf("Recorded %d boxes, want the 12 rashi numbers", len(boxes.boxes))
+	}
+	for _, b := range boxes.boxes {
+		want := geo.labelAnchor(b.house)
+		if x, y := (b.left+b.right)/2, (b.top+b.bottom)/2; math.Abs(x-want.X) > 1 || math.Abs(y-want.Y) > 1 {
+			t.Errorf("position %d: number %q centered at (%.1f, %.1f), want (%.1f, %.1f)", b.house, b.text, x, y, want.X, want.Y)
+		}
+	}
+}
+
+func TestNorthChart_TiltRashiNumbers(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets:   map[string]*Planet{"sun": {Rashi: "leo"}},
+	}
+	upright, err := GenerateNorthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	input.Options.TiltRashiNumbers = true
+	tilted, err := GenerateNorthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if bytes.Equal(upright, tilted) {
+		t.Error("Tilted rashi numbers render the same as upright ones")
+	}
+	assertGolden(t, "north_tilt_rashi_numbers", tilted)
+}
+
+func TestNorthChart_Clockwise(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo"},
+			"moon":    {Rashi: "virgo"},
+			"mars":    {Rashi: "cancer"},
+			"jupiter": {Rashi: "scorpio"},
+			"saturn":  {Rashi: "aquarius"},
+		},
+		Options: ChartOptions{HighlightHouses: []HouseHighlight{{Houses: []int{2}, Color: "#ffe0b2"}}},
+	}
+	labelsOf := func(direction Direction) map[string]LabelLayout {
+		input.Options.Direction = direction
+		_, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatalf("%s: error generating chart: %v", direction, err)
+		}
+		labels := map[string]LabelLayout{}
+		for _, l := range layout.Labels {
+			labels[l.Kind+" "+l.Text] = l
+		}
+		return labels
+	}
+	counter, clockwise := labelsOf(""), labelsOf(DirectionClockwise)
+
+	// The houses keep their rashis and planets, drawn in the mirror image of
+	// their counter-clockwise regions: Virgo, the 2nd house, at the top right
+	const center = 400
+	for key, want := range counter {
+		got, ok := clockwise[key]
+		if !ok {
+			t.Errorf("%s missing from the clockwise chart", key)
+			continue
+		}
+		if got.House != want.House || got.Rashi != want.Rashi {
+			t.Errorf("%s in house %d rashi %d, want house %d rashi %d", key, got.House, got.Rashi, want.House, want.Rashi)
+		}
+		gotX, wantX := (got.Left+got.Right)/2, 2*center-(want.Left+want.Right)/2
+		if math.Abs(gotX-wantX) > 25 || math.Abs(got.Top-want.Top) > 25 {
+			t.Errorf("%s at (%.0f, %.0f), want near the mirror image (%.0f, %.0f)", key, gotX, got.Top, wantX, want.Top)
+		}
+	}
+	if virgo := clockwise["rashi 6"]; virgo.Left < center || virgo.Top > center {
+		t.Errorf("Virgo at %+v, want it in the top right", virgo)
+	}
+	if leo := clockwise["rashi 5"]; leo.House != 1 || leo.Left > center || leo.Right < center {
+		t.Errorf("Leo at %+v, want it in the top diamond", leo)
+	}
+
+	input.Options.Direction = DirectionClockwise
+	input.Options.NakshatraRing = true
+	imageData, err := GenerateNorthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "north_clockwise", imageData)
+
+	input.Options.Direction = "sunwise"
+	if _, err := GenerateChart(input); err == nil || err.Error() != "unsupported direction: sunwise" {
+		t.Errorf("unsupported direction: err = %v", err)
+	}
 }
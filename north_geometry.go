@@ -0,0 +1,170 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// northGeometry describes the 12 house regions of a North Indian chart.
+// The chart is an outer square centered on (cx, cy), a diamond joining the
+// midpoints of its edges and the two diagonals of the outer square.
+type northGeometry struct {
+	cx, cy    float64
+	half      float64 // Half the side of the outer square
+	clockwise bool    // Houses run clockwise from the top diamond
+}
+
+// vertices returns the named points the house regions are built from
+func (g northGeometry) vertices() (c, t, r, b, l, tl, tr, br, bl, mtl, mtr, mbr, mbl gg.Point) {
+	h := g.half
+	c = gg.Point{X: g.cx, Y: g.cy}
+	// Diamond vertices (midpoints of the outer square's edges)
+	t = gg.Point{X: g.cx, Y: g.cy - h}
+	r = gg.Point{X: g.cx + h, Y: g.cy}
+	b = gg.Point{X: g.cx, Y: g.cy + h}
+	l = gg.Point{X: g.cx - h, Y: g.cy}
+	// Outer square corners
+	tl = gg.Point{X: g.cx - h, Y: g.cy - h}
+	tr = gg.Point{X: g.cx + h, Y: g.cy - h}
+	br = gg.Point{X: g.cx + h, Y: g.cy + h}
+	bl = gg.Point{X: g.cx - h, Y: g.cy + h}
+	// Where the diagonals cross the diamond's edges
+	mtl = gg.Point{X: g.cx - h/2, Y: g.cy - h/2}
+	mtr = gg.Point{X: g.cx + h/2, Y: g.cy - h/2}
+	mbr = gg.Point{X: g.cx + h/2, Y: g.cy + h/2}
+	mbl = gg.Point{X: g.cx - h/2, Y: g.cy + h/2}
+	return
+}
+
+// housePolygon returns the region of a house position (1-12). Position 1 is the
+// top diamond (the lagna house) and positions proceed counter-clockwise, or
+// clockwise in a clockwise chart, where a position takes the region of its
+// mirror image. Positions 1, 4, 7 and 10 are squares, the rest are triangles.
+func (g northGeometry) housePolygon(position int) []gg.Point {
+	c, t, r, b, l, tl, tr, br, bl, mtl, mtr, mbr, mbl := g.vertices()
+	if g.clockwise {
+		position = (13-position)%12 + 1
+	}
+	switch position {
+	case 1:
+		return []gg.Point{t, mtr, c, mtl}
+	case 2:
+		return []gg.Point{tl, t, mtl}
+	case 3:
+		return []gg.Point{tl, mtl, l}
+	case 4:
+		return []gg.Point{l, mtl, c, mbl}
+	case 5:
+		return []gg.Point{l, mbl, bl}
+	case 6:
+		return []gg.Point{bl, mbl, b}
+	case 7:
+		return []gg.Point{b, mbl, c, mbr}
+	case 8:
+		return []gg.Point{b, mbr, br}
+	case 9:
+		return []gg.Point{br, mbr, r}
+	case 10:
+		return []gg.Point{r, mbr, c, mtr}
+	case 11:
+		return []gg.Point{r, mtr, tr}
+	case 12:
+		return []gg.Point{tr, mtr, t}
+	}
+	return nil
+}
+
+// labelAnchor returns where a house's rashi label sits: the region's centroid
+// pulled towards the vertex nearest the chart center, where the region is widest
+func (g northGeometry) labelAnchor(position int) gg.Point {
+	return g.towardsInner(position, 0.35)
+}
+
+// planetAnchor returns the middle of a house's planet column: the center of
+// a triangle's inscribed rectangle, or a square's centroid pushed away from
+// the chart center, clear of the rashi label
+func (g northGeometry) planetAnchor(position int) gg.Point {
+	if poly := g.housePolygon(position); len(poly) == 3 {
+		lo, hi := inscribedRect(poly)
+		return gg.Point{X: (lo.X + hi.X) / 2, Y: (lo.Y + hi.Y) / 2}
+	}
+	return g.towardsInner(position, -0.3)
+}
+
+// planetRegion returns the part of a house that its planet labels may use.
+// Labels following a triangle's slanted sides would run into the diagonals,
+// so triangles offer their inscribed rectangle, squares their whole region.
+func (g northGeometry) planetRegion(position int) labelRegion {
+	poly := g.housePolygon(position)
+	if len(poly) == 3 {
+		lo, hi := inscribedRect(poly)
+		return rectRegion(lo.X, lo.Y, hi.X, hi.Y)
+	}
+	return polygonRegion(poly)
+}
+
+// inscribedRect returns the largest axis-aligned rectangle inside an
+// isosceles triangle with an axis-aligned base: the middle half of the base,
+// reaching halfway to the apex
+func inscribedRect(tri []gg.Point) (lo, hi gg.Point) {
+	for i := range tri {
+		a, b, apex := tri[i], tri[(i+1)%3], tri[(i+2)%3]
+		switch {
+		case a.Y == b.Y:
+			x1, x2 := a.X+(b.X-a.X)/4, a.X+3*(b.X-a.X)/4
+			y1, y2 := a.Y, (a.Y+apex.Y)/2
+			return gg.Point{X: math.Min(x1, x2), Y: math.Min(y1, y2)}, gg.Point{X: math.Max(x1, x2), Y: math.Max(y1, y2)}
+		case a.X == b.X:
+			y1, y2 := a.Y+(b.Y-a.Y)/4, a.Y+3*(b.Y-a.Y)/4
+			x1, x2 := a.X, (a.X+apex.X)/2
+			return gg.Point{X: math.Min(x1, x2), Y: math.Min(y1, y2)}, gg.Point{X: math.Max(x1, x2), Y: math.Max(y1, y2)}
+		}
+	}
+	return polygonCentroid(tri), polygonCentroid(tri)
+}
+
+// towardsInner returns the point a fraction t of the way from a region's
+// centroid to its vertex nearest the chart center. Negative t moves away
+// from the center.
+func (g northGeometry) towardsInner(position int, t float64) gg.Point {
+	poly := g.housePolygon(position)
+	centroid := polygonCentroid(poly)
+	inner := poly[0]
+	for _, p := range poly[1:] {
+		if math.Hypot(p.X-g.cx, p.Y-g.cy) < math.Hypot(inner.X-g.cx, inner.Y-g.cy) {
+			inner = p
+		}
+	}
+	return gg.Point{
+		X: centroid.X + t*(inner.X-centroid.X),
+		Y: centroid.Y + t*(inner.Y-centroid.Y),
+	}
+}
+
+// polygonCentroid returns the average of a polygon's vertices, which is the
+// true centroid for the triangles and squares of the chart layouts
+func polygonCentroid(poly []gg.Point) gg.Point {
+	var c gg.Point
+	for _, p := range poly {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float64(len(poly))
+	return gg.Point{X: c.X / n, Y: c.Y / n}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fogleman/gg"
+)
+
+func TestNorthGeometry_AnchorsInsideRegions(t *testing.T) {
+	// Anchors follow the chart at any size and position
+	for _, geo := range []northGeometry{
+		{cx: 400, cy: 400, half: 361.5},
+		{cx: 150, cy: 120, half: 100},
+	} {
+		for position := 1; position <= 12; position++ {
+			poly := geo.housePolygon(position)
+			if a := geo.labelAnchor(position); !insidePolygon(poly, a) {
+				t.Errorf("%+v: label anchor %v of position %d lies outside its region", geo, a, position)
+			}
+			if a := geo.planetAnchor(position); !insidePolygon(poly, a) {
+				t.Errorf("%+v: planet anchor %v of position %d lies outside its region", geo, a, position)
+			}
+		}
+	}
+}
+
+func TestNorthGeometry_AnchorsSymmetric(t *testing.T) {
+	geo := northGeometry{cx: 400, cy: 400, half: 361.5}
+	// Positions 2-12 mirror 12-2 across the vertical axis, 1 and 7 mirror themselves
+	for position := 1; position <= 12; position++ {
+		mirror := (14-position-1)%12 + 1
+		for name, anchor := range map[string]func(int) gg.Point{
+			"label":  geo.labelAnchor,
+			"planet": geo.planetAnchor,
+		} {
+			a, b := anchor(position), anchor(mirror)
+			if math.Abs((a.X-geo.cx)+(b.X-geo.cx)) > 1e-9 || math.Abs(a.Y-b.Y) > 1e-9 {
+				t.Errorf("%s anchors of positions %d %v and %d %v are not mirrored", name, position, a, mirror, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"time"
+)
+
+// RashiLabelMode controls how the rashi of each house is labelled
+type RashiLabelMode string
+
+const (
+	// RashiLabelNumber draws the rashi number (1-12), the default
+	RashiLabelNumber RashiLabelMode = "number"
+	// RashiLabelGlyph draws the zodiac glyph (♈ ♉ ♊ …) instead of the number
+	RashiLabelGlyph RashiLabelMode = "glyph"
+	// RashiLabelName draws the English rashi name ("Aries", "Taurus", …)
+	RashiLabelName RashiLabelMode = "name"
+	// RashiLabelSanskritName draws the Sanskrit rashi name ("Mesha", "Vrishabha", …)
+	RashiLabelSanskritName RashiLabelMode = "sanskrit_name"
+)
+
+// isName reports whether the mode draws full rashi names, which need
+// smaller text and more room than a number or glyph
+func (m RashiLabelMode) isName() bool {
+	return m == RashiLabelName || m == RashiLabelSanskritName
+}
+
+// DisplayMode picks how planets are named in their labels
+type DisplayMode string
+
+const (
+	// DisplayModeAbbreviation names planets by their abbreviations ("Ju"),
+	// the default
+	DisplayModeAbbreviation DisplayMode = "abbreviation"
+	// DisplayModeFullName names planets in full ("Jupiter"), for readers new
+	// to the abbreviations
+	DisplayModeFullName DisplayMode = "full_name"
+	// DisplayModeLetter names the grahas by a single letter ("J"), for
+	// very small charts. Thumbnails use it unless another mode is set.
+	DisplayModeLetter DisplayMode = "letter"
+)
+
+// Direction is the way the North chart's houses run from the lagna
+type Direction string
+
+const (
+	// DirectionCounterClockwise runs the houses counter-clockwise from the
+	// top diamond, the usual North chart and the default
+	DirectionCounterClockwise Direction = "counter_clockwise"
+	// DirectionClockwise runs them clockwise, as a minority tradition draws
+	// the North chart
+	DirectionClockwise Direction = "clockwise"
+)
+
+// PlanetStacking is how the planets of a house are listed
+type PlanetStacking string
+
+const (
+	// PlanetStackingVertical lists them one per row, the default
+	PlanetStackingVertical PlanetStacking = "vertical"
+	// PlanetStackingInline lists them left to right separated by commas,
+	// wrapping to the width of the house, as some traditional charts print
+	// them
+	PlanetStackingInline PlanetStacking = "inline"
+)
+
+// NodeStyle is how the labels of Rahu and Ketu stand apart from the other
+// planets, the two nodes always sitting opposite each other
+type NodeStyle string
+
+const (
+	// NodeStylePlain draws the nodes like the grahas, the default
+	NodeStylePlain NodeStyle = "plain"
+	// NodeStyleItalic slants the nodes' labels
+	NodeStyleItalic NodeStyle = "italic"
+	// NodeStyleShaded draws the nodes' labels in a slate blue
+	NodeStyleShaded NodeStyle = "shaded"
+)
+
+// maxPlanetLineSpacing bounds PlanetLineSpacing, in font sizes, and
+// maxPlanetLineSpacingPx bounds PlanetLineSpacingPx
+const (
+	maxPlanetLineSpacing   = 4.0
+	maxPlanetLineSpacingPx = 100.0
+)
+
+// ChartOptions holds optional rendering settings shared by all chart types.
+// The zero value renders the classic chart.
+type ChartOptions struct {
+	RashiLabelMode RashiLabelMode `json:"rashi_label_mode,omitempty"`
+	// ShowHouseNumbers prints the house number counted from lagna in each
+	// South chart cell, in addition to the fixed rashi number
+	ShowHouseNumbers bool `json:"show_house_numbers,omitempty"`
+	// ShowHouseLords prints the abbreviated lord of each house's rashi (see
+	// SignLord) in small gray text, in a corner clear of the rashi label
+	ShowHouseLords bool `json:"show_house_lords,omitempty"`
+	// HighlightHouses tints groups of houses with a background fill
+	HighlightHouses []HouseHighlight `json:"highlight_houses,omitempty"`
+	// LagnaHouseFill tints the house containing the lagna ("#RRGGBB"); ignored when Lagna is nil
+	LagnaHouseFill string `json:"lagna_house_fill,omitempty"`
+	// HighlightBadhaka tints the badhaka house of the lagna (the 11th for a
+	// movable lagna, the 9th for a fixed and the 7th for a dual one, see
+	// BadhakaHouse) with BadhakaFill, DefaultBadhakaFill when empty.
+	// Highlight groups listing the house win over it. Without a lagna the
+	// chart fails with ErrMissingLagna.
+	HighlightBadhaka bool   `json:"highlight_badhaka,omitempty"`
+	BadhakaFill      string `json:"badhaka_fill,omitempty"`
+	// MarkBadhakesh follows the name of the badhaka house's lord (see
+	// BadhakaLord) with BadhakeshMarker, "×" by default, wherever it sits.
+	// Like HighlightBadhaka it needs a lagna.
+	MarkBadhakesh   bool   `json:"mark_badhakesh,omitempty"`
+	BadhakeshMarker string `json:"badhakesh_marker,omitempty"`
+	// RetrogradeMarker is appended to retrograde planets, "R" by default.
+	// Markers the planet font cannot draw (such as "℞") fall back to "(R)".
+	RetrogradeMarker string `json:"retrograde_marker,omitempty"`
+	// CombustMarker is appended to combust planets, "C" by default
+	CombustMarker string `json:"combust_marker,omitempty"`
+	// ExaltedMarker and DebilitatedMarker follow the names of planets flagged
+	// IsExalted or IsDebilitated, "↑" and "↓" by default
+	ExaltedMarker     string `json:"exalted_marker,omitempty"`
+	DebilitatedMarker string `json:"debilitated_marker,omitempty"`
+	// AutoDignity computes each planet's dignity from its rashi with GetDignity
+	// and draws the exalted/debilitated markers without the flags being set
+	AutoDignity bool `json:"auto_dignity,omitempty"`
+	// VargottamaStyle distinguishes planets whose NavamsaRashi matches their
+	// rashi: "box" (default), "underline" or "marker" (appends VargottamaMarker, "v" by default)
+	VargottamaStyle  VargottamaStyle `json:"vargottama_style,omitempty"`
+	VargottamaMarker string          `json:"vargottama_marker,omitempty"`
+	// ShowDigbala appends DigbalaMarker ("•" by default) to planets with
+	// directional strength in their house from lagna (see HasDigbala)
+	ShowDigbala   bool   `json:"show_digbala,omitempty"`
+	DigbalaMarker string `json:"digbala_marker,omitempty"`
+	// ShowOwnSign puts OwnSignMarker ("·" by default) before planets in a
+	// rashi they rule and MoolatrikonaMarker ("˚" by default) before those
+	// in their moolatrikona portion (see IsMoolatrikona). The portion needs
+	// the planet's degrees, so without them only the own sign is marked.
+	ShowOwnSign        bool   `json:"show_own_sign,omitempty"`
+	OwnSignMarker      string `json:"own_sign_marker,omitempty"`
+	MoolatrikonaMarker string `json:"moolatrikona_marker,omitempty"`
+	// ShowDegrees prints each planet's (and the lagna's) degrees after its
+	// label, in DegreeFormat: "degree" ("Ju 17°", default) or "degree_minute" ("Ju 17°32'")
+	ShowDegrees  bool         `json:"show_degrees,omitempty"`
+	DegreeFormat DegreeFormat `json:"degree_format,omitempty"`
+	// ShowNakshatra prints each planet's nakshatra and pada ("Rohini-2") on a
+	// smaller second line under its label; planets without a nakshatra get none
+	ShowNakshatra bool `json:"show_nakshatra,omitempty"`
+	// ShowKPLords prints each planet's KP star and sub lords ("Sa-Me") on
+	// the smaller second line under its label, after the nakshatra when
+	// that is shown too
+	ShowKPLords bool `json:"show_kp_lords,omitempty"`
+	// ShowCharaKarakas raises each planet's Jaimini chara karaka ("AK",
+	// "AmK", …) after its label, ranked by degrees within the sign as
+	// ComputeCharaKarakas ranks them, so planets need their Degrees.
+	// CharaKarakasWithRahu counts Rahu for the eight-karaka scheme.
+	ShowCharaKarakas     bool `json:"show_chara_karakas,omitempty"`
+	CharaKarakasWithRahu bool `json:"chara_karakas_with_rahu,omitempty"`
+	// ShowBaladiAvastha raises the letter of each graha's baladi avastha
+	// (see ComputeBaladiAvastha) after its label: "B" bala, "K" kumara,
+	// "Y" yuva, "V" vriddha or "M" mrita. Planets without Degrees get none.
+	ShowBaladiAvastha bool `json:"show_baladi_avastha,omitempty"`
+	// ShowStationary adds StationaryMarker ("S" by default) before the status
+	// markers of planets whose |SpeedDegPerDay| is below StationaryThreshold
+	// (DefaultStationaryThreshold when zero): "MaSR", "Ma (S,R)"
+	ShowStationary      bool    `json:"show_stationary,omitempty"`
+	StationaryMarker    string  `json:"stationary_marker,omitempty"`
+	StationaryThreshold float64 `json:"stationary_threshold,omitempty"`
+	// StatusStyle controls how the markers combine: "MeRC" (suffix) or "Me (R,C)" (parenthesized)
+	StatusStyle StatusStyle `json:"status_style,omitempty"`
+	// LagnaMarkerStyle picks how the South chart marks the lagna's cell:
+	// "double_slash" (default), "single_slash", "full_diagonal" or "letter"
+	LagnaMarkerStyle LagnaMarkerStyle `json:"lagna_marker_style,omitempty"`
+	// RotateToLagna turns the South chart so the lagna's rashi always takes
+	// the cell that holds Aries in the classic layout, the other rashis
+	// following it in order. The North chart ignores it.
+	RotateToLagna bool `json:"rotate_to_lagna,omitempty"`
+	// TiltRashiNumbers tilts the North chart's rashi numbers slightly, the
+	// lagna's by 5° and the others by -1°, so the lagna's stands out.
+	// Numbers are upright by default; names are never tilted.
+	TiltRashiNumbers bool `json:"tilt_rashi_numbers,omitempty"`
+	// Direction runs the North chart's houses from the lagna's top diamond
+	// "counter_clockwise" (default) or "clockwise". The regions stay put,
+	// only the house, and so the rashi, each holds changes.
+	Direction Direction `json:"direction,omitempty"`
+	// Width and Height set the canvas size in pixels, each 800 when unset
+	// and at most MaxCanvasSize. The chart is centered in the largest square
+	// that fits the canvas.
+	// Canvases too small for the chart's text to stay legible are rejected.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// NakshatraRing draws the 27 nakshatras in a band around the North
+	// chart, which shrinks to make room, each house's rashi beside its
+	// 2¼ nakshatras. The South chart and thumbnails leave it out.
+	NakshatraRing bool `json:"nakshatra_ring,omitempty"`
+	// AllowStretch lets the South chart's grid fill a non-square canvas, its
+	// cells becoming rectangles. The North chart always stays square.
+	AllowStretch bool `json:"allow_stretch,omitempty"`
+	// Thumbnail draws a quick preview: the grid, rashi numbers and bare
+	// planet names in one small font, single letters unless DisplayMode is
+	// set, without status suffixes, markers or center text. The canvas
+	// defaults to 200px a side.
+	Thumbnail bool `json:"thumbnail,omitempty"`
+	// HighContrast draws the chart for low vision: strokes twice as thick,
+	// text about 30% larger, and everything in pure black on white, house
+	// highlights and category colors included, with bolder planet labels.
+	// The layout is unchanged, so crowded houses shrink their labels to fit
+	// as usual. Thumbnails leave it out.
+	HighContrast bool `json:"high_contrast,omitempty"`
+	// Grayscale draws the points of each category so they stay apart when
+	// printed in black and white: the lagna underlined, upagrahas in dark
+	// gray, special lagnas in [brackets] and arudha padas in (parentheses),
+	// the rest in black. The color key shows the same treatments.
+	Grayscale bool `json:"grayscale,omitempty"`
+	// AspectLines draws graha drishti arrows from the house of each named
+	// planet ("mars", "jupiter", …) to the houses it aspects, beneath the
+	// labels: the 7th for every planet, and the 4th and 8th for Mars, the
+	// 5th and 9th for Jupiter and the 3rd and 10th for Saturn. Thumbnails
+	// leave them out.
+	AspectLines []string `json:"aspect_lines,omitempty"`
+	// NodeAspects gives Rahu and Ketu the 5th and 9th aspects besides the
+	// 7th, in ComputeAspects and AspectLines
+	NodeAspects bool `json:"node_aspects,omitempty"`
+	// ShowConjunctions brackets together the planets of each tight
+	// conjunction: planets in one rashi within ConjunctionOrb degrees
+	// (DefaultConjunctionOrb when zero) of each other, as ComputeConjunctions
+	// finds them. Their labels move next to each other in order of degrees.
+	ShowConjunctions bool    `json:"show_conjunctions,omitempty"`
+	ConjunctionOrb   float64 `json:"conjunction_orb,omitempty"`
+	// ShowHouseScores prints the input's HouseScores, and beneath them its
+	// SecondaryHouseScores, small and muted in each house: at the bottom
+	// center of South chart cells and by the rashi label of North chart
+	// houses. Thumbnails leave them out.
+	ShowHouseScores bool `json:"show_house_scores,omitempty"`
+	// HighlightSAV tints the cells of a sarvashtakavarga chart whose total
+	// is below 25 (weak) light red and above 30 (strong) light green
+	HighlightSAV bool `json:"highlight_sav,omitempty"`
+	// JanmaNakshatra names the birth nakshatra ("Abhijit" included), which
+	// a Surya Kalanala chart highlights the spoke of and the Sapta Shalaka
+	// Chakra checks for vedha
+	JanmaNakshatra string `json:"janma_nakshatra,omitempty"`
+	// NodeRetrograde decides whether Rahu and Ketu get the retrograde
+	// marker: "flagged" (default, when their is_retrograde is set),
+	// "always" or "never"
+	NodeRetrograde NodeRetrograde `json:"node_retrograde,omitempty"`
+	// DashaTable tabulates dasha periods right of the chart, widening the
+	// canvas. The periods running at DashaReference, when it is set, are
+	// highlighted. Dates are written with the time layout DashaDateFormat,
+	// DefaultDashaDateFormat when empty. Thumbnails leave the table out.
+	DashaTable      []DashaPeriod `json:"dasha_table,omitempty"`
+	DashaReference  time.Time     `json:"dasha_reference,omitzero"`
+	DashaDateFormat string        `json:"dasha_date_format,omitempty"`
+	// ShowPanchanga prints the input's Panchanga as a block of labelled
+	// values: in the center of a South chart without center text, else in a
+	// strip beneath the chart. Long values wrap or are cut short.
+	ShowPanchanga bool `json:"show_panchanga,omitempty"`
+	// ShowNativeInfo prints the input's Native details as a block of
+	// labelled values, labelled in the locale: in the center of a South
+	// chart without center text, ahead of the panchanga, else in a strip
+	// beneath the chart. Long values such as places wrap or are cut short.
+	ShowNativeInfo bool `json:"show_native_info,omitempty"`
+	// Debug draws over the chart, in translucent magenta, the region of
+	// every house numbered with its house, and outlines in blue the box of
+	// every piece of text, as GenerateChartWithLayout reports them. It is
+	// meant for working on layouts, not for charts shown to anyone.
+	// GenerateSouthChart and GenerateNorthChart, which record nothing,
+	// leave it out.
+	Debug bool `json:"debug,omitempty"`
+	// AlignmentGrid draws faint lines every 50 pixels beneath the chart,
+	// with their coordinates along the top and left edges, for lining up
+	// custom layouts and reporting where things land
+	AlignmentGrid bool `json:"alignment_grid,omitempty"`
+	// ShowVersionStamp writes "go-vedic-astro-charts" and the Version in
+	// small gray text in the padding beneath the chart, flush with its right
+	// edge. Thumbnails leave it out.
+	ShowVersionStamp bool `json:"show_version_stamp,omitempty"`
+	// Locale picks the registered locale (see RegisterLocale) planets and
+	// rashi names are labelled in, the default locale when empty
+	Locale string `json:"locale,omitempty"`
+	// DisplayMode picks how planets are named: by their abbreviations
+	// ("abbreviation", default), in full ("full_name", from the locale's
+	// FullNames) or by a single letter ("letter", see PlanetLetter).
+	// Full names are wide, so crowded houses stack them in one column,
+	// shrinking them before they wrap. A planet's Display always wins, and
+	// the house lords and KP lords stay abbreviated.
+	DisplayMode DisplayMode `json:"display_mode,omitempty"`
+	// LagnaLabel, when set, labels the lagna in place of its name in the
+	// locale ("Asc" in English), such as "La" or "Lg". An empty label
+	// leaves the lagna's house without one, the South chart's corner
+	// marker still showing the lagna. The lagna's Display wins over it.
+	LagnaLabel *string `json:"lagna_label,omitempty"`
+	// PlanetLineSpacing sets the distance between the rows of planet labels
+	// as a multiple of their font size, up to 4, such as 1.6 for airy
+	// rows. PlanetLineSpacingPx sets it in pixels of an 800px chart
+	// instead, up to 100, scaling with the canvas, and wins over it. Either
+	// shrinks with the labels of crowded houses. Rows never come closer
+	// than the font's own lines, about 1.3 times its size, which is the
+	// spacing when both are zero. Thumbnails leave them out.
+	PlanetLineSpacing   float64 `json:"planet_line_spacing,omitempty"`
+	PlanetLineSpacingPx float64 `json:"planet_line_spacing_px,omitempty"`
+	// PlanetStacking lists the planets of each house "vertical" (default),
+	// one per row, or "inline", left to right separated by commas and
+	// wrapping to the width of the house, special lagnas last. Inline rows
+	// shrink like stacked ones when a house is crowded; conjunct planets
+	// still sit together but lose their brackets. Thumbnails stack them.
+	PlanetStacking PlanetStacking `json:"planet_stacking,omitempty"`
+	// NodeStyle sets Rahu and Ketu, and any point of the node category,
+	// apart from the other planets: "plain" (default), "italic" or
+	// "shaded". High contrast charts keep shaded nodes black, grayscale
+	// ones print them black. Thumbnails draw them plain.
+	NodeStyle NodeStyle `json:"node_style,omitempty"`
+	// DrawNodalAxis draws a faint dashed line, beneath the labels, between
+	// the houses of Rahu and Ketu. Charts with only one of the nodes leave
+	// it out, with a warning in the result.
+	DrawNodalAxis bool `json:"draw_nodal_axis,omitempty"`
+	// ShowColorKey adds a strip beneath the chart with a swatch and the name
+	// of each category (see PointCategory) the chart draws in a color of its
+	// own. Charts whose points are all black get none.
+	ShowColorKey bool `json:"show_color_key,omitempty"`
+	// StrictLabels rejects charts where planets in one house share a label
+	// (before status markers), such as a Display of "Mn" beside Mandi.
+	// Otherwise they are told apart by a subscript number in alphabetical
+	// order of the planets, "Mn₁" and "Mn₂", with a warning in the result.
+	StrictLabels bool `json:"strict_labels,omitempty"`
+	// SVGStyle is CSS that GenerateChartSVG adds to the style block of the
+	// SVG after its defaults, such as ".house:hover { fill: #ffd70040; }",
+	// up to 64 KiB. PNG charts ignore it.
+	SVGStyle string `json:"svg_style,omitempty"`
+}
+
+// validate checks that every option holds a supported value
+func (o ChartOptions) validate() error {
+	switch o.RashiLabelMode {
+	case "", RashiLabelNumber, RashiLabelGlyph, RashiLabelName, RashiLabelSanskritName:
+	default:
+		return fmt.Errorf("unsupported rashi_label_mode: %s", o.RashiLabelMode)
+	}
+	switch o.DisplayMode {
+	case "", DisplayModeAbbreviation, DisplayModeFullName, DisplayModeLetter:
+	default:
+		return fmt.Errorf("unsupported display_mode: %s", o.DisplayMode)
+	}
+	switch o.StatusStyle {
+	case "", StatusStyleSuffix, StatusStyleParenthesized:
+	default:
+		return fmt.Errorf("unsupported status_style: %s", o.StatusStyle)
+	}
+	switch o.VargottamaStyle {
+	case "", VargottamaStyleBox, VargottamaStyleUnderline, VargottamaStyleMarker:
+	default:
+		return fmt.Errorf("unsupported vargottama_style: %s", o.VargottamaStyle)
+	}
+	switch o.LagnaMarkerStyle {
+	case "", LagnaMarkerDoubleSlash, LagnaMarkerSingleSlash, LagnaMarkerFullDiagonal, LagnaMarkerLetter:
+	default:
+		return fmt.Errorf("unsupported lagna_marker_style: %s", o.LagnaMarkerStyle)
+	}
+	switch o.NodeRetrograde {
+	case "", NodeRetrogradeFlagged, NodeRetrogradeAlways, NodeRetrogradeNever:
+	default:
+		return fmt.Errorf("unsupported node_retrograde: %s", o.NodeRetrograde)
+	}
+	switch o.Direction {
+	case "", DirectionCounterClockwise, DirectionClockwise:
+	default:
+		return fmt.Errorf("unsupported direction: %s", o.Direction)
+	}
+	switch o.PlanetStacking {
+	case "", PlanetStackingVertical, PlanetStackingInline:
+	default:
+		return fmt.Errorf("unsupported planet_stacking: %s", o.PlanetStacking)
+	}
+	switch o.NodeStyle {
+	case "", NodeStylePlain, NodeStyleItalic, NodeStyleShaded:
+	default:
+		return fmt.Errorf("unsupported node_style: %s", o.NodeStyle)
+	}
+	switch o.DegreeFormat {
+	case "", DegreeFormatDegree, DegreeFormatDegreeMinute:
+	default:
+		return fmt.Errorf("unsupported degree_format: %s", o.DegreeFormat)
+	}
+	if _, ok := lookupLocale(o.Locale); !ok {
+		return fmt.Errorf("unsupported locale: %s", o.Locale)
+	}
+	if err := o.checkCanvasSize(); err != nil {
+		return err
+	}
+	if len(o.SVGStyle) > maxSVGStyleBytes {
+		return fmt.Errorf("svg_style must be at most %d bytes: %d", maxSVGStyleBytes, len(o.SVGStyle))
+	}
+	if o.ConjunctionOrb < 0 {
+		return fmt.Errorf("conjunction_orb must not be negative: %v", o.ConjunctionOrb)
+	}
+	if o.PlanetLineSpacing < 0 || o.PlanetLineSpacing > maxPlanetLineSpacing {
+		return fmt.Errorf("planet_line_spacing %v out of range 0-%v", o.PlanetLineSpacing, maxPlanetLineSpacing)
+	}
+	if o.PlanetLineSpacingPx < 0 || o.PlanetLineSpacingPx > maxPlanetLineSpacingPx {
+		return fmt.Errorf("planet_line_spacing_px %v out of range 0-%v", o.PlanetLineSpacingPx, maxPlanetLineSpacingPx)
+	}
+	if o.StationaryThreshold < 0 {
+		return fmt.Errorf("stationary_threshold must not be negative: %v", o.StationaryThreshold)
+	}
+	if o.LagnaHouseFill != "" {
+		if _, err := parseHexColor(o.LagnaHouseFill); err != nil {
+			return fmt.Errorf("lagna_house_fill: %w", err)
+		}
+	}
+	if o.BadhakaFill != "" {
+		if _, err := parseHexColor(o.BadhakaFill); err != nil {
+			return fmt.Errorf("badhaka_fill: %w", err)
+		}
+	}
+	if o.JanmaNakshatra != "" && chakraNakshatra(o.JanmaNakshatra) < 0 {
+		return fmt.Errorf("janma_nakshatra: unknown nakshatra %q", o.JanmaNakshatra)
+	}
+	if err := validateDashaTable(o.DashaTable); err != nil {
+		return err
+	}
+	return validateHighlights(o.HighlightHouses)
+}
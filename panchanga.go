@@ -0,0 +1,247 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// Panchanga is the panchanga at birth: its five limbs named as the caller
+// prefers, such as "Shukla Panchami" or "Ravivara", and optionally the
+// sunrise and sunset of the day, printed in their own time zones.
+type Panchanga struct {
+	Tithi     string    `json:"tithi,omitempty"`
+	Vara      string    `json:"vara,omitempty"`
+	Nakshatra string    `json:"nakshatra,omitempty"`
+	Yoga      string    `json:"yoga,omitempty"`
+	Karana    string    `json:"karana,omitempty"`
+	Sunrise   time.Time `json:"sunrise,omitzero"`
+	Sunset    time.Time `json:"sunset,omitzero"`
+}
+
+// panchangaTimeFormat is how the sunrise and sunset are printed
+const panchangaTimeFormat = "15:04"
+
+// panchangaMaxLines is the most lines a panchanga value wraps onto before
+// the rest is cut short with "…"
+const panchangaMaxLines = 2
+
+// maxItemValueRunes is as much of a panchanga or native value as is wrapped,
+// far more than panchangaMaxLines lines hold in a chart's center or strip.
+// The rest is cut off with "…" before wrapping, so that a long value costs
+// no more to draw than one that fills the lines.
+const maxItemValueRunes = 1000
+
+// panchangaItem is a labelled row of the panchanga block, or of the
+// native's details
+type panchangaItem struct {
+	label, value string
+}
+
+// items returns the limbs and times of p that are set, in the traditional
+// order, or nil for a nil p
+func (p *Panchanga) items() []panchangaItem {
+	if p == nil {
+		return nil
+	}
+	var items []panchangaItem
+	for _, item := range []panchangaItem{
+		{"Tithi", p.Tithi},
+		{"Vara", p.Vara},
+		{"Nakshatra", p.Nakshatra},
+		{"Yoga", p.Yoga},
+		{"Karana", p.Karana},
+	} {
+		if item.value = strings.TrimSpace(item.value); item.value != "" {
+			items = append(items, item)
+		}
+	}
+	if !p.Sunrise.IsZero() {
+		items = append(items, panchangaItem{"Sunrise", p.Sunrise.Format(panchangaTimeFormat)})
+	}
+	if !p.Sunset.IsZero() {
+		items = append(items, panchangaItem{"Sunset", p.Sunset.Format(panchangaTimeFormat)})
+	}
+	return items
+}
+
+// panchangaInCenter reports whether the panchanga of input is drawn in the
+// center of a South chart, which is when it has no center text or lines
+// and the native's details are not there. Otherwise it is drawn as a strip
+// beneath the chart.
+func panchangaInCenter(input ChartInput) bool {
+	return input.Options.ShowPanchanga && input.ChartType == ChartTypeSouth && input.CenterText == "" && len(input.CenterLines) == 0 && !nativeInCenter(input)
+}
+
+// wrapValue wraps a panchanga or native value to width in the current font, onto at
+// most panchangaMaxLines lines, the last cut short with "…" when the value
+// needs more
+func wrapValue(dc textWrapper, value string, width float64) []string {
+	if runes := []rune(value); len(runes) > maxItemValueRunes {
+		value = string(runes[:maxItemValueRunes]) + ellipsis
+	}
+	lines := wrapLine(dc, value, width)
+	if len(lines) > panchangaMaxLines {
+		rest := strings.Join(lines[panchangaMaxLines-1:], " ")
+		lines = append(lines[:panchangaMaxLines-1], ellipsize(dc, rest, width))
+	}
+	return lines
+}
+
+// drawSouthItems draws the panchanga, or the native's details, in the 4
+// empty squares in the middle of a South grid, as bold labels and the values
+// aligned in a column beside them. The block is centered and shrunk, like the
+// center text, until it fits. kind is the kind of its text boxes, and names
+// the block in the error when it does not fit.
+func drawSouthItems(dc *gg.Context, kind labelKind, items []panchangaItem, gridLeft, gridTop, cellW, cellH float64, frame chartFrame, boxes *chartBoxes) error {
+	if len(items) == 0 {
+		return nil
+	}
+	margin := frame.px(10)
+	left := gridLeft + cellW + margin
+	top := gridTop + cellH + margin
+	width := 2*cellW - 2*margin
+	height := 2*cellH - 2*margin
+
+	for step := centerTextSize; step >= minCenterTextSize; step-- {
+		size := step * frame.scale
+		labelFace, valueFace := embeddedFace(matangiBold, size), embeddedFace(matangiRegular, size)
+		labelW := 0.0
+		for _, item := range items {
+			labelW = max(labelW, float64(font.MeasureString(labelFace, item.label))/64)
+		}
+		gap := size * 0.6
+		valueW := width - labelW - gap
+		if valueW <= 0 {
+			continue
+		}
+
+		loadMatangiRegular(dc, size)
+		values := make([][]string, len(items))
+		lines, widest := 0, 0.0
+		for i, item := range items {
+			values[i] = wrapValue(dc, item.value, valueW)
+			lines += len(values[i])
+			for _, line := range values[i] {
+				widest = max(widest, float64(font.MeasureString(valueFace, line))/64)
+			}
+		}
+		m := regularMetrics(size)
+		lineHeight := m.lineHeight()
+		if float64(lines)*lineHeight > height {
+			continue
+		}
+
+		// Center the block, its labels flush left and values flush left
+		// in the column after the widest label
+		labelX := left + (width-(labelW+gap+widest))/2
+		valueX := labelX + labelW + gap
+		y := top + (height-float64(lines)*lineHeight)/2 + lineHeight/2
+		for i, item := range items {
+			baseline := m.baseline(y)
+			drawText(dc, labelFace, textBlack, item.label, labelX, baseline, 0)
+			w := float64(font.MeasureString(labelFace, item.label)) / 64
+			boxes.add(textBox{text: item.label, kind: kind, left: labelX, top: baseline - m.capHeight, right: labelX + w, bottom: baseline + m.descent})
+			for _, line := range values[i] {
+				baseline := m.baseline(y)
+				drawText(dc, valueFace, textBlack, line, valueX, baseline, 0)
+				w := float64(font.MeasureString(valueFace, line)) / 64
+				boxes.add(textBox{text: line, kind: kind, left: valueX, top: baseline - m.capHeight, right: valueX + w, bottom: baseline + m.descent})
+				y += lineHeight
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s does not fit the chart center at %vpx", kind, minCenterTextSize*frame.scale)
+}
+
+// itemStrip is a panel beneath the chart printing the panchanga, or the
+// native's details, as a row of columns, each a small gray label over its
+// value, its text boxes of kind
+type itemStrip struct {
+	items []panchangaItem
+	kind  labelKind
+}
+
+// newPanchangaStrip returns the strip of the input's panchanga, or nil when
+// it is not shown or is drawn in the chart's center instead
+func newPanchangaStrip(input ChartInput) *itemStrip {
+	if !input.Options.ShowPanchanga || panchangaInCenter(input) {
+		return nil
+	}
+	return newItemStrip(input.Panchanga.items(), labelPanchanga)
+}
+
+// newItemStrip returns the strip of items, or nil when there are none
+func newItemStrip(items []panchangaItem, kind labelKind) *itemStrip {
+	if len(items) == 0 {
+		return nil
+	}
+	return &itemStrip{items: items, kind: kind}
+}
+
+// Sizes of the panchanga and native strips, for a chart of defaultChartSize
+const (
+	panchangaLabelSize = 12
+	panchangaValueSize = 15
+	panchangaMargin    = 12 // Above and below the columns
+	panchangaColumnGap = 8
+)
+
+func (s itemStrip) side() panelSide { return panelBelow }
+
+func (s itemStrip) extent(frame chartFrame) float64 {
+	label := regularMetrics(frame.px(panchangaLabelSize)).lineHeight()
+	value := regularMetrics(frame.px(panchangaValueSize)).lineHeight()
+	return 2*frame.px(panchangaMargin) + label + panchangaMaxLines*value
+}
+
+// draw draws the items in equal columns across the chart's grid, values
+// wrapping onto a second line or cut short when even that is too narrow
+func (s itemStrip) draw(dc *gg.Context, frame chartFrame, _, top, _, _ float64, boxes *chartBoxes) {
+	padding := frame.px(40) // Aligned with the chart's grid
+	left, right := frame.x+padding, frame.x+frame.width-padding
+	columnW := (right - left) / float64(len(s.items))
+	gap := frame.px(panchangaColumnGap)
+
+	labelSize, valueSize := frame.px(panchangaLabelSize), frame.px(panchangaValueSize)
+	labelFace, valueFace := embeddedFace(matangiRegular, labelSize), embeddedFace(matangiRegular, valueSize)
+	lm, vm := regularMetrics(labelSize), regularMetrics(valueSize)
+	loadMatangiRegular(dc, valueSize)
+
+	labelTop := top + frame.px(panchangaMargin)
+	for i, item := range s.items {
+		x := left + float64(i)*columnW
+		baseline := labelTop + lm.capHeight
+		drawText(dc, labelFace, frame.ink(houseNumberGray), item.label, x, baseline, 0)
+		w := float64(font.MeasureString(labelFace, item.label)) / 64
+		boxes.add(textBox{text: item.label, kind: s.kind, left: x, top: baseline - lm.capHeight, right: x + w, bottom: baseline + lm.descent})
+
+		y := labelTop + lm.lineHeight() + vm.lineHeight()/2
+		for _, line := range wrapValue(dc, item.value, columnW-gap) {
+			baseline := vm.baseline(y)
+			drawText(dc, valueFace, textBlack, line, x, baseline, 0)
+			w := float64(font.MeasureString(valueFace, line)) / 64
+			boxes.add(textBox{text: line, kind: s.kind, left: x, top: baseline - vm.capHeight, right: x + w, bottom: baseline + vm.descent})
+			y += vm.lineHeight()
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// panchangaInput returns the house scores chart with a full panchanga,
+// its nakshatra and yoga long enough to wrap
+func panchangaInput(chartType ChartType) ChartInput {
+	input := houseScoresInput(chartType)
+	input.HouseScores, input.SecondaryHouseScores = nil, nil
+	ist := time.FixedZone("IST", 5*3600+1800)
+	input.Panchanga = &Panchanga{
+		Tithi:     "Shukla Panchami",
+		Vara:      "Guruvara",
+		Nakshatra: "Uttara Bhadrapada",
+		Yoga:      "Vishkambha",
+		Karana:    "Balava",
+		Sunrise:   time.Date(2024, 3, 14, 6, 31, 0, 0, ist),
+		Sunset:    time.Date(2024, 3, 14, 18, 29, 0, 0, ist),
+	}
+	input.Options = ChartOptions{ShowPanchanga: true}
+	return input
+}
+
+func TestPanchanga_Items(t *testing.T) {
+	p := panchangaInput(ChartTypeSouth).Panchanga
+	p.Yoga = " "
+	var got []string
+	for _, item := range p.items() {
+		got = append(got, item.label+"="+item.value)
+	}
+	want := "Tithi=Shukla Panchami Vara=Guruvara Nakshatra=Uttara Bhadrapada Karana=Balava Sunrise=06:31 Sunset=18:29"
+	if strings.Join(got, " ") != want {
+		t.Errorf("items = %q, want %q", strings.Join(got, " "), want)
+	}
+	if items := (*Panchanga)(nil).items(); items != nil {
+		t.Errorf("nil panchanga items = %v", items)
+	}
+}
+
+func TestGenerateChart_PanchangaInCenter(t *testing.T) {
+	input := panchangaInput(ChartTypeSouth)
+	data, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_panchanga", data)
+
+	if layout.Height != defaultChartSize {
+		t.Errorf("canvas height %d, want %d with the panchanga in the center", layout.Height, defaultChartSize)
+	}
+	var values []string
+	for _, l := range layout.Labels {
+		if l.Kind != string(labelPanchanga) {
+			continue
+		}
+		values = append(values, l.Text)
+		if l.Left < 220 || l.Right > 580 || l.Top < 220 || l.Bottom > 580 {
+			t.Errorf("panchanga %q at %+v, outside the center squares", l.Text, l)
+		}
+	}
+	if len(values) != 14 {
+		t.Errorf("%d panchanga labels and values, want 14: %q", len(values), values)
+	}
+	if overlaps := renderWithBoxes(t, input).overlapping(); len(overlaps) > 0 {
+		t.Errorf("overlapping labels: %v", overlaps)
+	}
+}
+
+func TestGenerateChart_PanchangaStrip(t *testing.T) {
+	withCenterText := panchangaInput(ChartTypeSouth)
+	withCenterText.CenterText = "Rasi"
+	for _, input := range []ChartInput{panchangaInput(ChartTypeNorth), withCenterText} {
+		data, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", input.ChartType, err)
+		}
+		if input.ChartType == ChartTypeNorth {
+			assertGolden(t, "north_panchanga", data)
+		}
+
+		if layout.Height <= defaultChartSize {
+			t.Errorf("%s: canvas height %d, want the strip beneath the chart", input.ChartType, layout.Height)
+		}
+		var texts []string
+		for _, l := range layout.Labels {
+			if l.Kind != string(labelPanchanga) {
+				continue
+			}
+			texts = append(texts, l.Text)
+			if l.Top < defaultChartSize || l.Right > defaultChartSize {
+				t.Errorf("%s: panchanga %q at %+v, off the strip", input.ChartType, l.Text, l)
+			}
+		}
+		// Every column has a label and a value, wrapped onto two lines
+		// where the column is too narrow
+		if len(texts) < 14 || len(texts) > 21 {
+			t.Errorf("%s: panchanga texts %q", input.ChartType, texts)
+		}
+	}
+
+	// Without a panchanga there is nothing to show
+	input := panchangaInput(ChartTypeNorth)
+	input.Panchanga = nil
+	if _, layout, err := GenerateChartWithLayout(input); err != nil || layout.Height != defaultChartSize {
+		t.Errorf("without a panchanga: height %d, error %v", layout.Height, err)
+	}
+}
+
+func TestWrapValue_LongValue(t *testing.T) {
+	var m wordMeasurer
+	value := strings.Repeat("word ", 4000)
+	lines := wrapValue(&m, value, 40)
+	if len(lines) != panchangaMaxLines || !strings.HasSuffix(lines[1], ellipsis) {
+		t.Errorf("wrapValue = %q, want %d lines, the last cut short", lines, panchangaMaxLines)
+	}
+	// Only the first maxItemValueRunes runes are wrapped
+	if m.runes > 20*maxItemValueRunes {
+		t.Errorf("wrapValue measured %d runes of a %d rune value", m.runes, len(value))
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/fogleman/gg"
+)
+
+// panelSide is the side of the chart a panel extends the canvas on
+type panelSide int
+
+const (
+	panelBelow panelSide = iota // Beneath the chart, as wide as its canvas
+	panelRight                  // Right of the chart, as tall as its canvas
+)
+
+// chartPanel is a strip drawn beside the chart, such as the color key, the
+// strength bars, the dasha table, the native's details or the panchanga, which extends the canvas by its extent. Panels scale
+// with the chart's frame.
+type chartPanel interface {
+	side() panelSide
+	// extent returns the panel's height beneath the chart, or its width
+	// beside it, in a frame
+	extent(frame chartFrame) float64
+	// draw draws the panel in the area left, top, right, bottom
+	draw(dc *gg.Context, frame chartFrame, left, top, right, bottom float64, boxes *chartBoxes)
+}
+
+// chartPanels returns the panels to draw beside a chart of input, each side's
+// in order away from the chart. Thumbnails have none.
+func chartPanels(input ChartInput) []chartPanel {
+	if input.Options.Thumbnail {
+		return nil
+	}
+	var panels []chartPanel
+	if key := newColorKey(input); key != nil {
+		panels = append(panels, key)
+	}
+	if bars := newStrengthBars(input); bars != nil {
+		panels = append(panels, bars)
+	}
+	if table := newDashaTable(input.Options); table != nil {
+		panels = append(panels, table)
+	}
+	if strip := newNativeStrip(input); strip != nil {
+		panels = append(panels, strip)
+	}
+	if strip := newPanchangaStrip(input); strip != nil {
+		panels = append(panels, strip)
+	}
+	return panels
+}
+
+// addPanels returns the chart img with the input's panels drawn beside it,
+// or img itself when there are none. The chart keeps the frame it was drawn
+// in, at the top left of the larger canvas.
+func addPanels(img image.Image, input ChartInput, boxes *chartBoxes) image.Image {
+	panels := chartPanels(input)
+	if len(panels) == 0 {
+		return img
+	}
+	canvasW, canvasH := input.Options.canvasSize()
+	frame, w, h := panelCanvas(input, panels)
+	dc := gg.NewContext(w, h)
+	drawBackground(dc, input.Options, nil, nil)
+	dc.DrawImage(img, 0, 0)
+	x, y := float64(canvasW), float64(canvasH)
+	for _, p := range panels {
+		extent := p.extent(frame)
+		if p.side() == panelRight {
+			p.draw(dc, frame, x, 0, x+extent, float64(canvasH), boxes)
+			x += extent
+		} else {
+			p.draw(dc, frame, 0, y, float64(canvasW), y+extent, boxes)
+			y += extent
+		}
+	}
+	return dc.Image()
+}
+
+// panelCanvas returns the frame the panels of a chart of input are drawn in
+// and the size of its canvas extended by them
+func panelCanvas(input ChartInput, panels []chartPanel) (frame chartFrame, w, h int) {
+	canvasW, canvasH := input.Options.canvasSize()
+	frame = newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	frame.contrast = input.Options.HighContrast
+	var below, right float64
+	for _, p := range panels {
+		if p.side() == panelRight {
+			right += p.extent(frame)
+		} else {
+			below += p.extent(frame)
+		}
+	}
+	return frame, canvasW + int(right+0.5), canvasH + int(below+0.5)
+}
+
+// ChartImageSize returns the width and height of the image GenerateChart
+// draws for input, its panels such as the dasha table included, without
+// drawing it, so that a service can refuse charts too large to draw. The
+// input is checked as GenerateChart checks it before drawing.
+func ChartImageSize(input ChartInput) (width, height int, err error) {
+	if err := validateInput(input); err != nil {
+		return 0, 0, err
+	}
+	switch input.ChartType {
+	case ChartTypeSouth, ChartTypeNorth, ChartTypeSarvashtakavarga, ChartTypeSuryaKalanala:
+	default:
+		if registeredLayout(input.ChartType) == nil {
+			return 0, 0, fmt.Errorf("%w: %s", ErrUnsupportedChartType, input.ChartType)
+		}
+	}
+	if _, err := CanonicalizeChartInput(&input); err != nil {
+		return 0, 0, err
+	}
+	_, width, height = panelCanvas(input, chartPanels(input))
+	return width, height, nil
+}
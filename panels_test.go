@@ -0,0 +1,52 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package parashari
+
+import "testing"
+
+func TestChartImageSize(t *testing.T) {
+	withPanels := strengthsInput(ChartTypeNorth)
+	withPanels.Panchanga = panchangaInput(ChartTypeNorth).Panchanga
+	withPanels.Options = ChartOptions{DashaTable: dashaPeriods(), ShowPanchanga: true, ShowColorKey: true, Width: 600, Height: 500}
+	thumbnail := withPanels.Clone()
+	thumbnail.Options.Thumbnail, thumbnail.Options.Width, thumbnail.Options.Height = true, 0, 0
+	tests := []struct {
+		name  string
+		input ChartInput
+	}{
+		{"plain", ChartInput{ChartType: ChartTypeSouth}},
+		{"dasha table", ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{DashaTable: dashaPeriods()}}},
+		{"strength bars", strengthsInput(ChartTypeSouth)},
+		{"panels on both sides", withPanels},
+		{"thumbnail", thumbnail},
+	}
+	for _, tt := range tests {
+		w, h, err := ChartImageSize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result, err := GenerateChartResult(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != result.Width || h != result.Height {
+			t.Errorf("%s: ChartImageSize = %dx%d, drawn %dx%d", tt.name, w, h, result.Width, result.Height)
+		}
+	}
+
+	if _, _, err := ChartImageSize(ChartInput{ChartType: "east"}); err == nil {
+		t.Error("ChartImageSize accepted an unsupported chart type")
+	}
+}
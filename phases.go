@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/fogleman/gg"
+)
+
+// Every chart is drawn in three phases, each over the one before:
+//
+//   - background: the white canvas, the alignment grid and the house fills
+//     (drawBackground)
+//   - content: the chart's lines and text, drawn by each generator, then
+//     the version stamp and the panels
+//   - overlays: the debug overlay, over the chart and its panels
+//     (drawOverlays)
+
+// alignmentGridSpacing is the distance, in pixels, between the lines of the
+// alignment grid
+const alignmentGridSpacing = 50
+
+// Colors of the alignment grid's lines and coordinates
+var (
+	alignmentGridLine  = color.NRGBA{R: 0, G: 110, B: 220, A: 36}
+	alignmentGridLabel = color.NRGBA{R: 0, G: 90, B: 190, A: 150}
+)
+
+// drawBackground clears dc to white, draws the alignment grid when the
+// options ask for it and fills each region of fills, in order of its key,
+// with the polygon polygon returns for the key. High-contrast charts keep
+// the background white.
+func drawBackground(dc *gg.Context, opts ChartOptions, fills map[int]color.Color, polygon func(key int) []gg.Point) {
+	dc.SetRGB(1, 1, 1)
+	dc.Clear()
+	if opts.AlignmentGrid {
+		drawAlignmentGrid(dc)
+	}
+	// Fills go before any lines so borders stay crisp
+	if opts.HighContrast {
+		return
+	}
+	for _, key := range sortedFills(fills) {
+		fillPolygon(dc, polygon(key), fills[key])
+	}
+}
+
+// drawAlignmentGrid draws faint lines across dc every alignmentGridSpacing
+// pixels, with their coordinates along the top and left edges
+func drawAlignmentGrid(dc *gg.Context) {
+	w, h := float64(dc.Width()), float64(dc.Height())
+	dc.SetColor(alignmentGridLine)
+	dc.SetLineWidth(1)
+	for x := float64(alignmentGridSpacing); x < w; x += alignmentGridSpacing {
+		dc.DrawLine(x+0.5, 0, x+0.5, h)
+		dc.Stroke()
+	}
+	for y := float64(alignmentGridSpacing); y < h; y += alignmentGridSpacing {
+		dc.DrawLine(0, y+0.5, w, y+0.5)
+		dc.Stroke()
+	}
+	loadMatangiRegular(dc, 9)
+	dc.SetColor(alignmentGridLabel)
+	for x := alignmentGridSpacing; float64(x) < w; x += alignmentGridSpacing {
+		dc.DrawStringAnchored(strconv.Itoa(x), float64(x)+3, 2, 0, 1)
+	}
+	for y := alignmentGridSpacing; float64(y) < h; y += alignmentGridSpacing {
+		dc.DrawStringAnchored(strconv.Itoa(y), 3, float64(y)+3, 0, 1)
+	}
+}
+
+// drawOverlays draws over a finished chart, panels and all, what the options
+// ask to see on top of it
+func drawOverlays(img image.Image, input ChartInput, boxes *chartBoxes) image.Image {
+	if input.Options.Debug {
+		img = drawDebugOverlay(img, input, boxes)
+	}
+	return img
+}
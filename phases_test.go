@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateChart_AlignmentGrid(t *testing.T) {
+	decode := func(data []byte) image.Image {
+		t.Helper()
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	black := color.RGBAModel.Convert(textBlack)
+	white := color.RGBAModel.Convert(color.White)
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Strengths = map[string]float64{"sun": 6, "moon": 4}
+		plainData, plainLayout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input.Options.AlignmentGrid = true
+		gridData, gridLayout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gridLayout, plainLayout) {
+			t.Errorf("%s: the alignment grid changed the layout", chartType)
+		}
+		plain, grid := decode(plainData), decode(gridData)
+
+		// The grid shows in the margin, at x = 50, and below the chart
+		// beside the strength bars
+		if got := color.RGBAModel.Convert(grid.At(50, 20)); got == white {
+			t.Errorf("%s: no grid line at (50, 20)", chartType)
+		}
+		if got := color.RGBAModel.Convert(grid.At(50, plain.Bounds().Dy()-5)); got == white {
+			t.Errorf("%s: no grid line beneath the strength bars", chartType)
+		}
+		// The grid lies beneath the chart, so nothing drawn solid changes
+		covered := 0
+		b := plain.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if color.RGBAModel.Convert(plain.At(x, y)) != black {
+					continue
+				}
+				if got := color.RGBAModel.Convert(grid.At(x, y)); got != black {
+					t.Fatalf("%s: pixel (%d, %d) of the chart is %v over the grid, want black", chartType, x, y, got)
+				}
+				covered++
+			}
+		}
+		if covered == 0 {
+			t.Fatalf("%s: no black pixels to check", chartType)
+		}
+	}
+}
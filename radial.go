@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// Radial layout, shared by the circular chakras. Angles are in degrees
+// clockwise from the top, the way a chakra is read.
+
+// radialPoint returns the point radius from the center (cx, cy) at angle
+func radialPoint(cx, cy, radius, angle float64) gg.Point {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	return gg.Point{X: cx + radius*sin, Y: cy - radius*cos}
+}
+
+// radialTurn returns the rotation, in radians, of text running along the
+// ray at angle: outwards on the right half and inwards on the left, so that
+// it is never upside down
+func radialTurn(angle float64) float64 {
+	angle = math.Mod(math.Mod(angle, 360)+360, 360)
+	if angle > 180 {
+		return (angle - 270) * math.Pi / 180
+	}
+	return (angle - 90) * math.Pi / 180
+}
+
+// tangentTurn returns the rotation, in radians, of text running across the
+// ray at angle along the circle: clockwise over the top half and
+// counter-clockwise under the bottom, so that it is never upside down
+func tangentTurn(angle float64) float64 {
+	angle = math.Mod(math.Mod(angle, 360)+360, 360)
+	switch {
+	case angle > 90 && angle < 270:
+		angle -= 180
+	case angle >= 270:
+		angle -= 360
+	}
+	return angle * math.Pi / 180
+}
+
+// drawTurnedText draws s centered on at, its capitals centered vertically,
+// turned by turn radians, and returns its box
+func drawTurnedText(dc *gg.Context, face font.Face, m textMetrics, c color.Color, s string, at gg.Point, turn float64) textBox {
+	baseline := m.capHeight / 2
+	dc.Push()
+	dc.Translate(at.X, at.Y)
+	dc.Rotate(turn)
+	drawText(dc, face, c, s, 0, baseline, 0.5)
+	dc.Pop()
+	w := float64(font.MeasureString(face, s)) / 64
+	box := textBox{text: s, left: at.X - w/2, top: at.Y + baseline - m.capHeight, right: at.X + w/2, bottom: at.Y + baseline + m.descent}
+	return box.rotated(turn, at.X, at.Y)
+}
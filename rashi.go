@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseRashi returns the number (1-12) of a rashi, given by its English name
+// ("aries"), a common abbreviation of it ("ari", "sag"), its Sanskrit name
+// ("mesha", "vrishabha", also in IAST, "meṣa") or its number ("1"). Case and
+// surrounding spaces are ignored. Anything else is an error.
+func ParseRashi(rashi string) (int, error) {
+	r := strings.ToLower(strings.TrimSpace(rashi))
+	if num, ok := rashiNumbers[r]; ok {
+		return num, nil
+	}
+	if num, ok := rashiAliases[r]; ok {
+		return num, nil
+	}
+	if num, err := strconv.Atoi(r); err == nil && num >= 1 && num <= 12 && r == strconv.Itoa(num) {
+		return num, nil
+	}
+	return 0, fmt.Errorf("unknown rashi %q", rashi)
+}
+
+// rashiAliases maps the other lowercase names ParseRashi accepts to rashi
+// numbers: the English abbreviations, then the Sanskrit names and their
+// common spellings, then the IAST names of LocaleIAST
+var rashiAliases = func() map[string]int {
+	aliases := map[string]int{
+		"ari": 1, "tau": 2, "gem": 3, "can": 4, "vir": 6, "lib": 7,
+		"sco": 8, "sag": 9, "cap": 10, "aqu": 11, "aq": 11, "pis": 12,
+
+		"mesh": 1, "vrishabh": 2, "vrisha": 2, "rishabha": 2, "vrushabha": 2,
+		"mithun": 3, "karkata": 4, "karkataka": 4, "kataka": 4, "simh": 5,
+		"kanyā": 6, "tulā": 7, "vrischika": 8, "vrishchik": 8, "vrushchika": 8,
+		"dhanus": 9, "dhanush": 9, "makar": 10, "kumbh": 11, "mina": 12, "meen": 12,
+	}
+	for num, name := range sanskritRashiNames {
+		aliases[strings.ToLower(name)] = num
+	}
+	for i, name := range iastLocale.RashiNames {
+		aliases[strings.ToLower(name)] = i + 1
+	}
+	return aliases
+}()
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strconv"
+
+	"github.com/fogleman/gg"
+)
+
+// nameLabelScale shrinks the font for full rashi names, which are much wider than two digits
+const nameLabelScale = 0.7
+
+// rashiLabelText returns the text drawn for a rashi number in the given mode,
+// with names from the chart's locale. Glyphs are drawn as strokes, so the
+// glyph mode returns the Unicode symbol only for reference.
+func rashiLabelText(opts ChartOptions, rashiNum int) string {
+	switch opts.RashiLabelMode {
+	case RashiLabelGlyph:
+		return RashiGlyph(rashiNum)
+	case RashiLabelName:
+		return localeFor(opts).rashiName(rashiNum)
+	case RashiLabelSanskritName:
+		return NumberToSanskritRashi(rashiNum)
+	default:
+		return strconv.Itoa(rashiNum)
+	}
+}
+
+// drawRashiLabel draws the label for a rashi number, honouring the rashi label
+// mode. The label's ink, from the baseline to the top of its capitals or the
+// glyph box, is anchored at (x, y) like DrawStringAnchored: ay=0 puts it above
+// y and ay=1 below. fontSize is the size of the current number font and is
+// used to scale glyphs and names.
+func drawRashiLabel(dc *gg.Context, opts ChartOptions, rashiNum int, x, y, ax, ay, fontSize float64) {
+	switch {
+	case opts.RashiLabelMode == RashiLabelGlyph:
+		size := fontSize * glyphScale
+		// Match DrawStringAnchored, where ay=0 puts the label above y and ay=1 below it
+		drawZodiacGlyph(dc, rashiNum, x-ax*size, y-(1-ay)*size, size)
+	case opts.RashiLabelMode.isName():
+		size := fontSize * nameLabelScale
+		drawText(dc, embeddedFace(matangiRegular, size), textBlack, rashiLabelText(opts, rashiNum), x, y+ay*regularMetrics(size).capHeight, ax)
+	default:
+		drawText(dc, embeddedFace(matangiRegular, fontSize), textBlack, rashiLabelText(opts, rashiNum), x, y+ay*regularMetrics(fontSize).capHeight, ax)
+	}
+}
+
+// rashiLabelBox returns the ink box of the label drawRashiLabel draws with
+// the same arguments, measured like it in the current number font
+func rashiLabelBox(dc *gg.Context, opts ChartOptions, rashiNum int, x, y, ax, ay, fontSize float64) textBox {
+	text := rashiLabelText(opts, rashiNum)
+	switch {
+	case opts.RashiLabelMode == RashiLabelGlyph:
+		size := fontSize * glyphScale
+		left, top := x-ax*size, y-(1-ay)*size
+		return textBox{text: text, left: left, top: top, right: left + size, bottom: top + size}
+	case opts.RashiLabelMode.isName():
+		size := fontSize * nameLabelScale
+		loadMatangiRegular(dc, size)
+		w, _ := dc.MeasureString(text)
+		loadMatangiRegular(dc, fontSize)
+		m := regularMetrics(size)
+		// Names have lowercase letters, so their ink may reach below the baseline
+		baseline := y + ay*m.capHeight
+		return textBox{text: text, left: x - ax*w, top: baseline - m.capHeight, right: x - ax*w + w, bottom: baseline + m.descent}
+	default:
+		w, _ := dc.MeasureString(text)
+		m := regularMetrics(fontSize)
+		baseline := y + ay*m.capHeight
+		return textBox{text: text, left: x - ax*w, top: baseline - m.capHeight, right: x - ax*w + w, bottom: baseline}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseRashi(t *testing.T) {
+	tests := []struct {
+		rashi string
+		want  int
+	}{
+		{"aries", 1},
+		{"  Scorpio\t", 8},
+		{"SAG", 9},
+		{"ari", 1},
+		{"aq", 11},
+		{"mesha", 1},
+		{"Vrishabha", 2},
+		{"mithuna", 3},
+		{"karkataka", 4},
+		{"vrischika", 8},
+		{"Mina", 12},
+		{"Meṣa", 1},
+		{"VṚŚCIKA", 8},
+		{"kanyā", 6},
+		{"1", 1},
+		{" 12 ", 12},
+	}
+	for _, tt := range tests {
+		got, err := ParseRashi(tt.rashi)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRashi(%q) = %d, %v, want %d", tt.rashi, got, err, tt.want)
+		}
+		if got := RashiToNumber(tt.rashi); got != tt.want {
+			t.Errorf("RashiToNumber(%q) = %d, want %d", tt.rashi, got, tt.want)
+		}
+	}
+
+	for _, rashi := range []string{"", " ", "atlantis", "0", "13", "-1", "01", "+1", "1.0", "ar", "aries aries"} {
+		if got, err := ParseRashi(rashi); err == nil || got != 0 {
+			t.Errorf("ParseRashi(%q) = %d, %v, want an error", rashi, got, err)
+		}
+	}
+	if _, err := ParseRashi("atlantis"); err == nil || err.Error() != `unknown rashi "atlantis"` {
+		t.Errorf("ParseRashi error = %v", err)
+	}
+
+	// Every rashi's names parse back to its number
+	for num := 1; num <= 12; num++ {
+		for _, name := range []string{NumberToRashi(num), NumberToSanskritRashi(num), iastLocale.RashiNames[num-1], strconv.Itoa(num)} {
+			if got, _ := ParseRashi(name); got != num {
+				t.Errorf("ParseRashi(%q) = %d, want %d", name, got, num)
+			}
+		}
+	}
+}
+
+func TestGenerateChart_RashiAliases(t *testing.T) {
+	input := houseScoresInput(ChartTypeSouth)
+	want, err := GenerateChart(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.Lagna.Rashi = "Kanya"
+	for _, p := range input.Planets {
+		num := RashiToNumber(p.Rashi)
+		p.Rashi = " " + strings.ToUpper(NumberToSanskritRashi(num)) + " "
+	}
+	got, err := GenerateChart(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Error("Chart with Sanskrit rashi names differs from one with English names")
+	}
+
+	input.Planets["moon"].Rashi = "moonland"
+	var invalid *ErrInvalidRashi
+	if _, err := GenerateChart(input); !errors.As(err, &invalid) || invalid.Planet != "moon" {
+		t.Errorf("unknown planet rashi: err = %v, want the moon's *ErrInvalidRashi", err)
+	}
+	input.Planets["moon"].Rashi = "aries"
+	input.Lagna.Rashi = ""
+	if _, err := GenerateChart(input); !errors.As(err, &invalid) || invalid.Planet != "lagna" {
+		t.Errorf("empty lagna rashi: err = %v, want the lagna's *ErrInvalidRashi", err)
+	}
+}
+
+func FuzzParseRashi(f *testing.F) {
+	for _, seed := range []string{"aries", " Sag ", "mesha", "Vṛścika", "7", "13", "", "\xff", "٣"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, rashi string) {
+		num, err := ParseRashi(rashi)
+		if err != nil {
+			if num != 0 {
+				t.Errorf("ParseRashi(%q) = %d with an error", rashi, num)
+			}
+			if RashiToNumber(rashi) != 0 {
+				t.Errorf("RashiToNumber(%q) = %d, ParseRashi failed", rashi, RashiToNumber(rashi))
+			}
+			return
+		}
+		if num < 1 || num > 12 {
+			t.Fatalf("ParseRashi(%q) = %d, out of range 1-12", rashi, num)
+		}
+		// Whatever parsed names the same rashi as its English name
+		if got, _ := ParseRashi(NumberToRashi(num)); got != num {
+			t.Errorf("ParseRashi(%q) = %d, its name %q parses to %d", rashi, num, NumberToRashi(num), got)
+		}
+		if got, _ := ParseRashi(strings.ToUpper(" " + rashi + "\n")); got != num && strings.ToLower(strings.ToUpper(rashi)) == strings.ToLower(rashi) {
+			t.Errorf("ParseRashi(%q) = %d, differs in upper case: %d", rashi, num, got)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// houseGeometry places the houses of a chart drawn as twelve regions, such
+// as the North chart or a layout template. Positions run from 1 to 12.
+type houseGeometry interface {
+	// housePolygon returns the region of a house position
+	housePolygon(position int) []gg.Point
+	// labelAnchor returns where the position's rashi label is centered
+	labelAnchor(position int) gg.Point
+	// planetAnchor returns the middle of the position's planet column
+	planetAnchor(position int) gg.Point
+	// planetRegion returns the part of the position its planets may use
+	planetRegion(position int) labelRegion
+}
+
+// drawRegionHouses draws the rashi label and planets of every house position
+// of geo, where position p holds rashi rashiAt(p), recording their boxes in
+// boxes when that is not nil. It fails when the text would be too small to
+// read.
+func drawRegionHouses(dc *gg.Context, input ChartInput, geo houseGeometry, frame chartFrame, lagnaRashiNum int, rashiAt func(int) int, boxes *chartBoxes) error {
+	canvasW, canvasH := dc.Width(), dc.Height()
+
+	// Rashi numbers sit upright at the label anchor of their house region,
+	// towards the chart center. The tilt option turns the lagna's slightly one
+	// way and the others slightly the other, which sets the lagna apart.
+	const lagnaNumberAngle = 5.0
+	const rashiNumberAngle = -1.0
+
+	// Set up font for rashi numbers
+	dc.SetRGB(0, 0, 0)
+	// Load Matangi font from embedded data
+	numberSize := frame.font(20)
+	loadMatangiRegular(dc, numberSize)
+	scoreSize, lordSize := frame.font(13), frame.font(12)
+	var legible legibility
+	legible.use(numberSize)
+	if input.Options.ShowHouseScores {
+		legible.use(scoreSize)
+	}
+	if input.Options.ShowHouseLords {
+		legible.use(lordSize)
+	}
+
+	// Draw rashi numbers in positions 1-12, with the house scores by them
+	// Their boxes are kept so the planets can stay clear of them.
+	var fixed [13][]textBox
+	for positionNum := 1; positionNum <= 12; positionNum++ {
+		anchor := geo.labelAnchor(positionNum)
+		// Names are too wide to tilt without running into the region's sides
+		angle := 0.0
+		if input.Options.TiltRashiNumbers && !input.Options.RashiLabelMode.isName() {
+			angle = rashiNumberAngle
+			if rashiAt(positionNum) == lagnaRashiNum {
+				angle = lagnaNumberAngle
+			}
+		}
+
+		box := rashiLabelBox(dc, input.Options, rashiAt(positionNum), anchor.X, anchor.Y, 0.5, 0.5, numberSize)
+		box = box.rotated(angle*math.Pi/180, anchor.X, anchor.Y)
+		// Keep long rashi names on the canvas
+		dx, dy := box.shiftInto(canvasW, canvasH)
+		anchor.X, anchor.Y = anchor.X+dx, anchor.Y+dy
+		box.left, box.right, box.top, box.bottom = box.left+dx, box.right+dx, box.top+dy, box.bottom+dy
+		box.house, box.kind = positionNum, labelRashi
+		fixed[positionNum] = append(fixed[positionNum], box)
+		boxes.add(box)
+		boxes.addHouse(positionNum, rashiAt(positionNum), geo.housePolygon(positionNum))
+
+		dc.Push()
+		dc.Translate(anchor.X, anchor.Y)
+		dc.Rotate(angle * math.Pi / 180)
+		drawRashiLabel(dc, input.Options, rashiAt(positionNum), 0, 0, 0.5, 0.5, numberSize) // Center-aligned
+		dc.Pop()
+
+		if rows := houseScoreRows(input, rashiAt(positionNum)); len(rows) > 0 {
+			top := regionScoreTop(geo.housePolygon(positionNum), box, rows, scoreSize)
+			scores := drawHouseScores(dc, rows, positionNum, (box.left+box.right)/2, top, scoreSize, frame.ink(scoreColor))
+			fixed[positionNum] = append(fixed[positionNum], scores...)
+			boxes.add(scores...)
+		}
+		if input.Options.ShowHouseLords {
+			lord := regionLordBox(localeFor(input.Options), geo.housePolygon(positionNum), box, fixed[positionNum], rashiAt(positionNum), positionNum, lordSize)
+			drawHouseLord(dc, lord, lordSize, frame.ink(houseNumberGray))
+			fixed[positionNum] = append(fixed[positionNum], lord)
+			boxes.add(lord)
+		}
+	}
+
+	// Resolve the status markers once, they are checked against the planet font
+	labels := newLabelFormat(input.Options).withConjunctions(input).withKarakas(input).withDistinctNames(input)
+	if input.Lagna != nil {
+		labels = labels.withLagna(lagnaRashiNum)
+	}
+
+	// Now draw planets near each rashi number position
+	// Load larger font for planets from embedded data
+	planetSize := frame.font(18)
+	loadMatangiBold(dc, planetSize)
+
+	// Draw planets for positions 1-12, reusing the house slices
+	occupants := planetsByHouse(input)
+	var entries, mainLane, sideLane []planetEntry
+	for positionNum := 1; positionNum <= 12; positionNum++ {
+		rashiNum := rashiAt(positionNum)
+
+		// Collect the lagna if it's in this rashi and the points in it, then
+		// list each in its category's lane
+		entries = entries[:0]
+		if input.Lagna != nil && rashiNum == lagnaRashiNum {
+			// Lagna is never retrograde or combust (it's a point, not a planet)
+			if entry, ok := labels.lagnaEntry(input.Lagna); ok {
+				entries = append(entries, entry)
+			}
+		}
+		for _, planetName := range occupants[HouseFromLagna(rashiNum, lagnaRashiNum)] {
+			entries = append(entries, labels.entry(planetName, input.Planets[planetName]))
+		}
+		mainLane, sideLane = splitLanes(entries, mainLane[:0], sideLane[:0])
+
+		// Draw planets near this rashi number
+		if len(entries) > 0 {
+			// Center the column on the house's planet anchor, planets end just
+			// right of it and special lagnas start 20px further right. The
+			// layout shrinks or wraps the column to fit the house region.
+			center := geo.planetAnchor(positionNum)
+			anchor := newHouseAnchor(input.Options, frame, center.X+frame.px(15), center.X+frame.px(35), center.Y, planetSize)
+			anchor.middle = true
+			// Full names are too wide to sit side by side
+			anchor.oneColumn = labels.mode == DisplayModeFullName
+			region := geo.planetRegion(positionNum).avoiding(fixed[positionNum]...)
+			mainLane = labels.groupConjunctions(mainLane)
+			layout := layoutHouse(dc, mainLane, sideLane, anchor, region, labels.fontSize(planetSize, len(entries)))
+			layout = clampLayout(dc, layout, canvasW, canvasH)
+			legible.useLayout(layout)
+			boxes.add(houseBoxes(dc, layout, positionNum)...)
+			labels.drawHouse(dc, layout)
+		}
+	}
+	return legible.check(canvasW, canvasH)
+}
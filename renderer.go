@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/fogleman/gg"
+)
+
+// renderer holds the canvas a chart is drawn on and the buffer it is encoded
+// into, so that charts drawn one after another reuse them rather than each
+// allocating a few megabytes. A renderer draws one chart at a time and is not
+// safe for concurrent use; charts drawn concurrently each take their own from
+// rendererPool. A nil renderer allocates afresh for every chart.
+type renderer struct {
+	canvas *image.RGBA
+	buf    bytes.Buffer
+	// base64Len is the length of the last base64 chart, which the next
+	// one is likely to be close to
+	base64Len int
+}
+
+// rendererPool hands out renderers to the public chart functions
+var rendererPool = sync.Pool{New: func() any { return &renderer{} }}
+
+// pngEncoder shares the encoder's scratch buffers between charts
+var pngEncoder = png.Encoder{BufferPool: &pngBufferPool{}}
+
+type pngBufferPool struct{ pool sync.Pool }
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	b, _ := p.pool.Get().(*png.EncoderBuffer)
+	return b
+}
+
+func (p *pngBufferPool) Put(b *png.EncoderBuffer) { p.pool.Put(b) }
+
+// context returns a context drawing on a w x h canvas, reusing the
+// renderer's canvas when it has that size. The canvas is not cleared.
+func (r *renderer) context(w, h int) *gg.Context {
+	if r == nil {
+		return gg.NewContext(w, h)
+	}
+	if r.canvas == nil || r.canvas.Rect.Dx() != w || r.canvas.Rect.Dy() != h {
+		r.canvas = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+	return gg.NewContextForRGBA(r.canvas)
+}
+
+// encode returns img as PNG bytes, which stay valid after the renderer is reused
+func (r *renderer) encode(img image.Image) ([]byte, error) {
+	if r == nil {
+		return encodePNG(img)
+	}
+	r.buf.Reset()
+	if err := pngEncoder.Encode(&r.buf, img); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(r.buf.Bytes()), nil
+}
+
+// encodeBase64 returns img as a base64-encoded PNG. The PNG streams through
+// the base64 encoder into the string as it is written, so it is never held
+// in full next to its encoding.
+func (r *renderer) encodeBase64(img image.Image) (string, error) {
+	var b strings.Builder
+	b.Grow(r.base64Len)
+	enc := base64.NewEncoder(base64.StdEncoding, &b)
+	if err := pngEncoder.Encode(enc, img); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	r.base64Len = b.Len()
+	return b.String(), nil
+}
+
+// write draws a chart and writes it to w as PNG
+func (r *renderer) write(w io.Writer, input ChartInput) error {
+	img, err := r.draw(input, nil)
+	if err != nil {
+		return err
+	}
+	return pngEncoder.Encode(w, img)
+}
+
+// generate validates the input, then draws and encodes a chart of its type
+func (r *renderer) generate(input ChartInput) ([]byte, error) {
+	img, err := r.draw(input, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.encode(img)
+}
+
+// generateBase64 draws a chart as its result and returns just the image, as
+// a base64-encoded PNG
+func (r *renderer) generateBase64(input ChartInput) (string, error) {
+	img, _, err := r.result(input)
+	if err != nil {
+		return "", err
+	}
+	return r.encodeBase64(img)
+}
+
+// draw validates the input and draws a chart of its type, recording its
+// houses and text in boxes when that is not nil
+func (r *renderer) draw(input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	if err := validateInput(input); err != nil {
+		return nil, err
+	}
+	if _, err := CanonicalizeChartInput(&input); err != nil {
+		return nil, err
+	}
+	return r.drawNormalized(input, boxes)
+}
+
+// drawNormalized draws a chart of the type of an input already validated
+// and canonicalized, as draw does
+func (r *renderer) drawNormalized(input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	if input.Options.Debug && boxes == nil {
+		boxes = &chartBoxes{}
+	}
+	var img image.Image
+	var err error
+	switch input.ChartType {
+	case ChartTypeSouth:
+		img, err = renderSouthChart(r, input, boxes)
+	case ChartTypeNorth:
+		img, err = renderNorthChart(r, input, boxes)
+	case ChartTypeSarvashtakavarga:
+		img, err = renderSAVChart(r, input, boxes)
+	case ChartTypeSuryaKalanala:
+		img, err = renderSuryaKalanalaChart(r, input, boxes)
+	default:
+		layout := registeredLayout(input.ChartType)
+		if layout == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedChartType, input.ChartType)
+		}
+		img, err = renderTemplateChart(r, input, layout, boxes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate chart: %w", err)
+	}
+	if input.Options.ShowVersionStamp && !input.Options.Thumbnail {
+		img = drawVersionStamp(img, input, boxes)
+	}
+	return drawOverlays(addPanels(img, input, boxes), input, boxes), nil
+}
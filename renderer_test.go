@@ -0,0 +1,213 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRenderer_ReusesCanvas(t *testing.T) {
+	r := &renderer{}
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth, ChartTypeSouth} {
+		input := crowdedHouseInput(chartType)
+		got, err := r.generate(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		canvas := r.canvas
+
+		// A reused canvas is cleared, so the chart matches one drawn afresh
+		want, err := (*renderer)(nil).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s chart drawn on a reused canvas differs from one drawn afresh", chartType)
+		}
+		if _, err := r.generate(input); err != nil || r.canvas != canvas {
+			t.Errorf("Expected the renderer to reuse its canvas for a chart of the same size")
+		}
+	}
+
+	input := crowdedHouseInput(ChartTypeNorth)
+	input.Options.Width = 1000
+	canvas := r.canvas
+	if _, err := r.generate(input); err != nil {
+		t.Fatalf("Error generating wide chart: %v", err)
+	}
+	if r.canvas == canvas || r.canvas.Rect.Dx() != 1000 {
+		t.Errorf("Expected a new 1000px canvas, got %v", r.canvas.Rect)
+	}
+}
+
+func TestRenderer_EncodedBytesOutliveReuse(t *testing.T) {
+	r := &renderer{}
+	first, err := r.generate(crowdedHouseInput(ChartTypeSouth))
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	kept := bytes.Clone(first)
+	if _, err := r.generate(crowdedHouseInput(ChartTypeNorth)); err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if !bytes.Equal(first, kept) {
+		t.Error("Expected the PNG bytes of a chart to stay intact when the renderer draws the next one")
+	}
+}
+
+// TestGenerateChart_ConcurrentChartsDoNotShareCanvas draws different charts
+// at the same time; were a canvas shared, one would bleed into another
+func TestGenerateChart_ConcurrentChartsDoNotShareCanvas(t *testing.T) {
+	inputs := []ChartInput{crowdedHouseInput(ChartTypeSouth), crowdedHouseInput(ChartTypeNorth)}
+	inputs[0].CenterText = "South"
+	want := make([]string, len(inputs))
+	for i, input := range inputs {
+		var err error
+		if want[i], err = GenerateChart(input); err != nil {
+			t.Fatalf("Error generating chart: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	got := make([]string, 16)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i], _ = GenerateChart(inputs[i%len(inputs)])
+		}()
+	}
+	wg.Wait()
+	for i, g := range got {
+		if g != want[i%len(inputs)] {
+			t.Errorf("Chart #%d drawn concurrently differs from the same chart drawn alone", i)
+		}
+	}
+}
+
+func TestGenerateChartPNGs(t *testing.T) {
+	var inputs []ChartInput
+	for i := range 6 {
+		input := crowdedHouseInput(ChartTypeSouth)
+		if i%2 == 1 {
+			input.ChartType = ChartTypeNorth
+		}
+		input.CenterText = fmt.Sprintf("Chart %d", i)
+		inputs = append(inputs, input)
+	}
+
+	pngs, err := GenerateChartPNGs(inputs)
+	if err != nil {
+		t.Fatalf("Error generating charts: %v", err)
+	}
+	if len(pngs) != len(inputs) {
+		t.Fatalf("Expected %d charts, got %d", len(inputs), len(pngs))
+	}
+	for i, input := range inputs {
+		want, err := (*renderer)(nil).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating chart %d: %v", i, err)
+		}
+		if !bytes.Equal(pngs[i], want) {
+			t.Errorf("Chart %d of the batch differs from the same chart drawn alone", i)
+		}
+	}
+
+	inputs[3].ChartType = "east"
+	if _, err := GenerateChartPNGs(inputs); err == nil || !strings.HasPrefix(err.Error(), "chart 3:") {
+		t.Errorf("Expected the error to name chart 3, got %v", err)
+	}
+}
+
+func TestGenerateChart_Base64MatchesPNG(t *testing.T) {
+	r := &renderer{}
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth, ChartTypeSouth} {
+		input := crowdedHouseInput(chartType)
+		want, err := (*renderer)(nil).generate(input)
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		// Twice on one renderer, whose size hint then comes from another chart
+		for range 2 {
+			got, err := r.generateBase64(input)
+			if err != nil {
+				t.Fatalf("Error generating %s chart: %v", chartType, err)
+			}
+			if got != base64.StdEncoding.EncodeToString(want) {
+				t.Errorf("Base64 %s chart does not decode to its PNG", chartType)
+			}
+		}
+	}
+}
+
+func TestWriteChart(t *testing.T) {
+	input := crowdedHouseInput(ChartTypeNorth)
+	var buf bytes.Buffer
+	if err := WriteChart(&buf, input); err != nil {
+		t.Fatalf("Error writing chart: %v", err)
+	}
+	want, err := GenerateNorthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("Written chart differs from the generated one")
+	}
+
+	input.ChartType = ""
+	if err := WriteChart(&buf, input); err == nil {
+		t.Error("Expected an error for a chart without a type")
+	}
+}
+
+func TestGenerateChart_Deterministic(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := crowdedHouseInput(chartType)
+		// Neighbouring fills share their antialiased edges, and names not
+		// in lowercase are found by scanning the planets
+		input.Options.HighlightHouses = []HouseHighlight{
+			{Houses: []int{1, 2, 3, 4, 5, 6}, Color: "#fde2e2"},
+			{Houses: []int{7, 8, 9, 10, 11, 12}, Color: "#e2f0fd"},
+		}
+		input.Options.ShowConjunctions = true
+		input.Options.AspectLines = []string{"Mars"}
+		for _, name := range []string{"mars", "sun", "mandi", "gulika"} {
+			delete(input.Planets, name)
+		}
+		input.Planets["Mars"] = &Planet{Rashi: "leo", Degrees: 10}
+		input.Planets["SUN"] = &Planet{Rashi: "leo", Degrees: 11}
+		input.Planets["Mandi"] = &Planet{Rashi: "virgo", Degrees: 12, IsUpagraha: true}
+		input.Planets["GULIKA"] = &Planet{Rashi: "virgo", Degrees: 12, IsUpagraha: true}
+
+		var first string
+		for i := range 20 {
+			got, err := GenerateChart(input)
+			if err != nil {
+				t.Fatalf("Error generating %s chart: %v", chartType, err)
+			}
+			if i == 0 {
+				first = got
+			} else if got != first {
+				t.Fatalf("%s: render %d differs from the first", chartType, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+)
+
+// A4 in points, the unit of PDF pages
+const (
+	a4Width  = 595.28
+	a4Height = 841.89
+)
+
+// writePDF writes pages, images of w x h pixels, as a PDF of A4 pages, each
+// image filling its page. It writes only what that takes: a catalog, the
+// page tree, and for each page its content stream and its image, deflated.
+func writePDF(pages []image.Image, w, h int) ([]byte, error) {
+	var buf bytes.Buffer
+	var offsets []int // Of each object, numbered from 1
+	object := func(body func()) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		body()
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object(func() { buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>") })
+	// Each page takes three objects after the catalog and the page tree:
+	// the page, its content stream and its image
+	kids := make([]byte, 0, len(pages)*8)
+	for i := range pages {
+		kids = fmt.Appendf(kids, "%d 0 R ", 3+3*i)
+	}
+	object(func() {
+		fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids), len(pages))
+	})
+
+	for i, page := range pages {
+		pageObj := 3 + 3*i
+		object(func() {
+			fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+				a4Width, a4Height, pageObj+2, pageObj+1)
+		})
+		content := fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", a4Width, a4Height)
+		object(func() { fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n%s\nendstream", len(content), content) })
+
+		pixels, err := deflateRGB(page, w, h)
+		if err != nil {
+			return nil, err
+		}
+		object(func() {
+			fmt.Fprintf(&buf, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n",
+				w, h, len(pixels))
+			buf.Write(pixels)
+			buf.WriteString("\nendstream")
+		})
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes(), nil
+}
+
+// deflateRGB returns the w x h pixels of img, row by row as 8-bit RGB
+// over white, zlib-compressed as the FlateDecode filter expects
+func deflateRGB(img image.Image, w, h int) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 3*w)
+	b := img.Bounds()
+	for y := range h {
+		for x := range w {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			// Blend onto white, the page beneath
+			white := 0xffff - a
+			row[3*x] = byte((r + white) >> 8)
+			row[3*x+1] = byte((g + white) >> 8)
+			row[3*x+2] = byte((bl + white) >> 8)
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
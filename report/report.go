@@ -0,0 +1,394 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package report lays out the charts of several people, such as a family
+// or the two sides of a match, in one document.
+//
+// Build takes named chart inputs and draws each person's name over their
+// chart, and optionally their navamsa (D9) chart beneath it, into a single
+// long PNG or a paginated PDF:
+//
+//	data, err := report.Build([]report.ReportEntry{
+//		{Name: "Arjun", Chart: arjun},
+//		{Name: "Meera", Chart: meera},
+//	}, report.ReportOptions{Format: report.FormatPDF, IncludeD9: true})
+//
+// Entries keep their order. An entry that fails is reported in a
+// *BuildError and drawn as its name and the error, and the rest of the
+// report is still built.
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+// Format is the kind of document Build produces
+type Format string
+
+const (
+	// FormatPNG stacks the entries one under another in a single PNG
+	FormatPNG Format = "png"
+	// FormatPDF lays the entries out on A4 pages, starting a new page
+	// when the next entry does not fit the rest of the current one
+	FormatPDF Format = "pdf"
+)
+
+// ReportEntry is one person of a report
+type ReportEntry struct {
+	Name  string
+	Chart parashari.ChartInput
+	// D9 is the person's navamsa chart, drawn beneath Chart when set.
+	// With ReportOptions.IncludeD9 an entry without one gets it from the
+	// NavamsaRashi of its lagna and planets.
+	D9 *parashari.ChartInput
+}
+
+// ReportOptions are the options of Build
+type ReportOptions struct {
+	Format Format // FormatPNG when empty
+	// IncludeD9 draws a navamsa chart beneath every entry's chart
+	IncludeD9 bool
+}
+
+// EntryError is the failure of one entry of a report
+type EntryError struct {
+	Index int // Position of the entry in the report
+	Name  string
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("entry %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// BuildError lists the entries of a report that failed, in report order
+type BuildError struct {
+	Errors []*EntryError
+}
+
+func (e *BuildError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d report entries failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed entries, for errors.Is and errors.As
+func (e *BuildError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Sizes of the report, in pixels of its PNG or of its PDF pages
+const (
+	margin       = 40
+	headerHeight = 64
+	headerSize   = 30
+	errorSize    = 18
+	entryGap     = 32 // Between one entry and the next
+	pageWidth    = 1240
+	pageHeight   = 1754 // A4 at 150 dpi
+)
+
+// errorColor is the color the error of a failed entry is written in
+var errorColor = color.NRGBA{R: 200, G: 30, B: 30, A: 255}
+
+// block is an entry as drawn: its name over its charts, or over its error
+type block struct {
+	name   string
+	charts []image.Image
+	err    error
+}
+
+// size returns the width and height of the block, unscaled
+func (b block) size() (w, h int) {
+	w, h = 0, headerHeight
+	if b.err != nil {
+		return 0, headerHeight + errorSize*2
+	}
+	for _, img := range b.charts {
+		w = max(w, img.Bounds().Dx())
+		h += img.Bounds().Dy()
+	}
+	return w, h
+}
+
+// Build draws the entries in order into a report of opts.Format. When
+// some entries fail the report is still returned, each failed entry drawn
+// as its name and error, with a *BuildError naming them.
+func Build(entries []ReportEntry, opts ReportOptions) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("report: no entries")
+	}
+	switch opts.Format {
+	case "", FormatPNG, FormatPDF:
+	default:
+		return nil, fmt.Errorf("report: unsupported format: %s", opts.Format)
+	}
+
+	// Every chart of the report is generated in one batch, entry by entry,
+	// each entry's chart followed by its D9 when it has one
+	var inputs []parashari.ChartInput
+	first := make([]int, len(entries)) // Index in inputs of each entry's chart
+	blocks := make([]block, len(entries))
+	for i, e := range entries {
+		blocks[i].name = e.Name
+		first[i] = len(inputs)
+		inputs = append(inputs, e.Chart)
+		d9 := e.D9
+		if d9 == nil && opts.IncludeD9 {
+			derived, err := navamsaInput(e.Chart)
+			if err != nil {
+				blocks[i].err = err
+				continue
+			}
+			d9 = &derived
+		}
+		if d9 != nil {
+			inputs = append(inputs, *d9)
+		}
+	}
+	pngs, err := parashari.GenerateCharts(context.Background(), inputs, 0)
+	var batch *parashari.BatchError
+	if err != nil && !errors.As(err, &batch) {
+		return nil, err
+	}
+	var failed []*EntryError
+	for i := range entries {
+		end := len(inputs)
+		if i+1 < len(entries) {
+			end = first[i+1]
+		}
+		for j := first[i]; j < end && blocks[i].err == nil; j++ {
+			if pngs[j] == nil {
+				blocks[i].err = chartError(batch, j)
+				break
+			}
+			img, err := png.Decode(bytes.NewReader(pngs[j]))
+			if err != nil {
+				blocks[i].err = err
+				break
+			}
+			blocks[i].charts = append(blocks[i].charts, img)
+		}
+		if blocks[i].err != nil {
+			blocks[i].charts = nil
+			failed = append(failed, &EntryError{Index: i, Name: entries[i].Name, Err: blocks[i].err})
+		}
+	}
+
+	faces, err := newFaces()
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if opts.Format == FormatPDF {
+		data, err = writePDF(paginate(blocks, faces), pageWidth, pageHeight)
+	} else {
+		data, err = encodePNG(stack(blocks, faces))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return data, &BuildError{Errors: failed}
+	}
+	return data, nil
+}
+
+// chartError returns the error of chart i of a batch
+func chartError(batch *parashari.BatchError, i int) error {
+	if batch != nil {
+		for _, err := range batch.Errors {
+			if err.Index == i {
+				return err.Err
+			}
+		}
+	}
+	return errors.New("chart not generated")
+}
+
+// navamsaInput returns the D9 chart of input: its chart type, with the
+// lagna and planets in their NavamsaRashi and the options that name things
+// and size the canvas
+func navamsaInput(input parashari.ChartInput) (parashari.ChartInput, error) {
+	opts := input.Options
+	d9 := parashari.ChartInput{
+		ChartType: input.ChartType,
+		Planets:   make(map[string]*parashari.Planet, len(input.Planets)),
+		Options: parashari.ChartOptions{
+			Width: opts.Width, Height: opts.Height,
+			Locale: opts.Locale, DisplayMode: opts.DisplayMode,
+			RashiLabelMode: opts.RashiLabelMode, Direction: opts.Direction,
+			LagnaMarkerStyle: opts.LagnaMarkerStyle, LagnaLabel: opts.LagnaLabel,
+		},
+		CenterText: "Navamsa (D9)",
+	}
+	if input.ChartType != parashari.ChartTypeSouth {
+		d9.CenterText = ""
+	}
+	if input.Lagna != nil {
+		if input.Lagna.NavamsaRashi == "" {
+			return d9, errors.New("d9: lagna has no navamsa_rashi")
+		}
+		d9.Lagna = &parashari.Planet{Rashi: input.Lagna.NavamsaRashi, Display: input.Lagna.Display}
+	}
+	for name, p := range input.Planets {
+		if p == nil {
+			continue
+		}
+		if p.NavamsaRashi == "" {
+			return d9, fmt.Errorf("d9: planet %s has no navamsa_rashi", name)
+		}
+		d9.Planets[name] = &parashari.Planet{Rashi: p.NavamsaRashi, IsRetrograde: p.IsRetrograde, Display: p.Display, Category: p.Category}
+	}
+	return d9, nil
+}
+
+// faces are the fonts of the report's headers and errors
+type faces struct {
+	header, err font.Face
+}
+
+func newFaces() (faces, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return faces{}, err
+	}
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return faces{}, err
+	}
+	header, err := opentype.NewFace(bold, &opentype.FaceOptions{Size: headerSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return faces{}, err
+	}
+	errFace, err := opentype.NewFace(regular, &opentype.FaceOptions{Size: errorSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return faces{}, err
+	}
+	return faces{header: header, err: errFace}, nil
+}
+
+// draw draws b with its top left at x, y scaled by scale, its header and
+// error text at full size
+func (b block) draw(dc *gg.Context, f faces, x, y, scale float64) {
+	dc.SetFontFace(f.header)
+	dc.SetRGB(0, 0, 0)
+	dc.DrawStringAnchored(b.name, x, y+headerHeight/2, 0, 0.35)
+	y += headerHeight
+	if b.err != nil {
+		dc.SetFontFace(f.err)
+		dc.SetColor(errorColor)
+		dc.DrawStringAnchored(b.err.Error(), x, y+errorSize, 0, 0.35)
+		return
+	}
+	for _, img := range b.charts {
+		dc.Push()
+		dc.Translate(x, y)
+		dc.Scale(scale, scale)
+		dc.DrawImage(img, 0, 0)
+		dc.Pop()
+		y += float64(img.Bounds().Dy()) * scale
+	}
+}
+
+// stack draws the blocks one under another on a canvas as wide as the
+// widest of them
+func stack(blocks []block, f faces) image.Image {
+	width, height := 0, 2*margin-entryGap
+	for _, b := range blocks {
+		w, h := b.size()
+		width = max(width, w)
+		height += h + entryGap
+	}
+	dc := gg.NewContext(width+2*margin, height)
+	dc.SetRGB(1, 1, 1)
+	dc.Clear()
+	y := float64(margin)
+	for _, b := range blocks {
+		b.draw(dc, f, margin, y, 1)
+		_, h := b.size()
+		y += float64(h + entryGap)
+	}
+	return dc.Image()
+}
+
+// paginate lays the blocks out on pages, each block shrunk to the page's
+// width and height when it is larger, starting a new page whenever the
+// next block does not fit the rest of the current one
+func paginate(blocks []block, f faces) []image.Image {
+	var pages []image.Image
+	var dc *gg.Context
+	y := 0.0
+	for _, b := range blocks {
+		w, h := b.size()
+		scale := 1.0
+		if w > 0 {
+			scale = math.Min(scale, float64(pageWidth-2*margin)/float64(w))
+		}
+		scale = math.Min(scale, float64(pageHeight-2*margin-headerHeight)/float64(h-headerHeight))
+		height := headerHeight + float64(h-headerHeight)*scale
+		if dc == nil || y+height > pageHeight-margin {
+			if dc != nil {
+				pages = append(pages, dc.Image())
+			}
+			dc = gg.NewContext(pageWidth, pageHeight)
+			dc.SetRGB(1, 1, 1)
+			dc.Clear()
+			y = margin
+		}
+		// Charts are centered across the page
+		x := float64(pageWidth)/2 - float64(w)*scale/2
+		if w == 0 {
+			x = margin
+		}
+		b.draw(dc, f, x, y, scale)
+		y += height + entryGap
+	}
+	return append(pages, dc.Image())
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
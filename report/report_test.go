@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package report
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+
+	parashari "github.com/tejzpr/go-vedic-astro-charts"
+)
+
+// person returns a small chart with navamsa rashis for its D9
+func person(chartType parashari.ChartType) parashari.ChartInput {
+	return parashari.ChartInput{
+		ChartType: chartType,
+		Lagna:     &parashari.Planet{Rashi: "leo", NavamsaRashi: "aries"},
+		Planets: map[string]*parashari.Planet{
+			"sun":    {Rashi: "leo", NavamsaRashi: "leo"},
+			"moon":   {Rashi: "taurus", NavamsaRashi: "capricorn"},
+			"saturn": {Rashi: "libra", NavamsaRashi: "gemini", IsRetrograde: true},
+		},
+	}
+}
+
+func TestBuild_PNG(t *testing.T) {
+	entries := []ReportEntry{
+		{Name: "Arjun", Chart: person(parashari.ChartTypeNorth)},
+		{Name: "Meera", Chart: person(parashari.ChartTypeSouth)},
+	}
+	data, err := Build(entries, ReportOptions{IncludeD9: true})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error decoding report: %v", err)
+	}
+	// Two entries of a header and two 800px charts each
+	wantW, wantH := 800+2*margin, 2*margin+2*(headerHeight+2*800)+entryGap
+	if got := img.Bounds(); got.Dx() != wantW || got.Dy() != wantH {
+		t.Errorf("report is %dx%d, want %dx%d", got.Dx(), got.Dy(), wantW, wantH)
+	}
+
+	// The same entries give the same report every time
+	again, err := Build(entries, ReportOptions{IncludeD9: true})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Error("report differs between two builds of the same entries")
+	}
+}
+
+func TestBuild_PDF(t *testing.T) {
+	// An entry with its D9 takes a page of its own, so three need three
+	var entries []ReportEntry
+	for _, name := range []string{"Arjun", "Meera", "Kabir"} {
+		entries = append(entries, ReportEntry{Name: name, Chart: person(parashari.ChartTypeNorth)})
+	}
+	data, err := Build(entries, ReportOptions{Format: FormatPDF, IncludeD9: true})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("report is not a PDF")
+	}
+	pages := regexp.MustCompile(`/Type /Page\b`).FindAll(data, -1)
+	if len(pages) != 3 {
+		t.Errorf("%d pages, want 3", len(pages))
+	}
+
+	// Smaller charts without D9s fit two to a page
+	for i := range entries {
+		entries[i].Chart.Options = parashari.ChartOptions{Width: 600, Height: 600}
+	}
+	data, err = Build(entries, ReportOptions{Format: FormatPDF})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if pages := regexp.MustCompile(`/Type /Page\b`).FindAll(data, -1); len(pages) != 2 {
+		t.Errorf("%d pages of small charts, want 2", len(pages))
+	}
+}
+
+func TestBuild_EntryErrors(t *testing.T) {
+	bad := person(parashari.ChartTypeSouth)
+	bad.Planets["mars"] = &parashari.Planet{Rashi: "nowhere", NavamsaRashi: "aries"}
+	noNavamsa := person(parashari.ChartTypeSouth)
+	noNavamsa.Planets["moon"].NavamsaRashi = ""
+	entries := []ReportEntry{
+		{Name: "Arjun", Chart: person(parashari.ChartTypeSouth)},
+		{Name: "Meera", Chart: bad},
+		{Name: "Kabir", Chart: noNavamsa},
+	}
+	data, err := Build(entries, ReportOptions{IncludeD9: true})
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("error = %v, want a *BuildError", err)
+	}
+	if len(buildErr.Errors) != 2 || buildErr.Errors[0].Index != 1 || buildErr.Errors[1].Index != 2 {
+		t.Fatalf("failed entries = %v, want 1 and 2", buildErr)
+	}
+	var rashiErr *parashari.ErrInvalidRashi
+	if !errors.As(buildErr.Errors[0], &rashiErr) {
+		t.Errorf("entry 1 error = %v, want ErrInvalidRashi", buildErr.Errors[0])
+	}
+	if msg := buildErr.Errors[1].Error(); !strings.Contains(msg, "entry 2 (Kabir): d9: planet moon has no navamsa_rashi") {
+		t.Errorf("entry 2 error = %q", msg)
+	}
+
+	// The report is still built, the failed entries drawn as their errors
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error decoding report: %v", err)
+	}
+	wantH := 2*margin + (headerHeight + 2*800) + 2*(headerHeight+2*errorSize) + 2*entryGap
+	if got := img.Bounds().Dy(); got != wantH {
+		t.Errorf("report height %d, want %d", got, wantH)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	if _, err := Build(nil, ReportOptions{}); err == nil {
+		t.Error("Build of no entries succeeded")
+	}
+	entries := []ReportEntry{{Name: "Arjun", Chart: person(parashari.ChartTypeNorth)}}
+	if _, err := Build(entries, ReportOptions{Format: "gif"}); err == nil || !strings.Contains(err.Error(), "unsupported format: gif") {
+		t.Errorf("unsupported format error = %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// ChartResult is a generated chart together with what was placed on it, so
+// that callers can write alt text or check the placement without decoding
+// the image
+type ChartResult struct {
+	PNG    []byte `json:"png"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// LagnaRashi is the rashi number of the lagna, zero when the chart has
+	// none, in which case houses are counted from Aries
+	LagnaRashi int `json:"lagna_rashi,omitempty"`
+	// Houses lists the planets drawn in each house counted from the lagna,
+	// in the order they were placed. The lagna itself is left out, as are
+	// planets that were not drawn, such as those a thumbnail has no room for.
+	Houses map[int][]string `json:"houses"`
+	// Warnings describe what the chart drew differently from the input
+	// without failing, such as planets skipped or labels cut short
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GenerateChartResult generates a chart like GenerateChart and returns its
+// PNG bytes with the houses its planets were placed in and any warnings
+func GenerateChartResult(input ChartInput) (*ChartResult, error) {
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	img, result, err := r.result(input)
+	if err != nil {
+		return nil, err
+	}
+	if result.PNG, err = r.encode(img); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// result draws a chart and describes it, leaving the image to be encoded
+func (r *renderer) result(input ChartInput) (image.Image, *ChartResult, error) {
+	var boxes chartBoxes
+	img, err := r.draw(input, &boxes)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := img.Bounds()
+	layout := boxes.layout(input, bounds.Dx(), bounds.Dy())
+	result := &ChartResult{
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Houses:   map[int][]string{},
+		Warnings: inputWarnings(input),
+	}
+	if input.Lagna != nil {
+		result.LagnaRashi = RashiToNumber(input.Lagna.Rashi)
+	}
+	for _, l := range layout.Labels {
+		switch {
+		case l.Kind == string(labelPlanet) && l.Planet != "lagna" && l.House > 0:
+			result.Houses[l.House] = append(result.Houses[l.House], l.Planet)
+			if strings.HasSuffix(l.Text, ellipsis) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("planet %s: label truncated to %q", l.Planet, l.Text))
+			}
+		case l.Kind == string(labelHidden):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("house %d: %s planets not shown", l.House, strings.TrimPrefix(l.Text, "+")))
+		}
+	}
+	return img, result, nil
+}
+
+// inputWarnings returns the warnings about parts of input the charts skip
+// or cannot label, in the order of the planet names, then about a nodal
+// axis missing a node and planets left out of the chara karakas
+func inputWarnings(input ChartInput) []string {
+	var warnings []string
+	loc := localeFor(input.Options)
+	renamed := distinctNames(input, loc, input.Options.DisplayMode)
+	for _, name := range sortedPlanetNames(input.Planets) {
+		p := input.Planets[name]
+		switch {
+		case p == nil:
+			warnings = append(warnings, fmt.Sprintf("planet %s: nil, skipped", name))
+		case loc.displayName(name, p, input.Options.DisplayMode) == "":
+			warnings = append(warnings, fmt.Sprintf("planet %s: unknown planet without a display name, drawn unlabelled (set its display or RegisterPlanetAbbreviation)", name))
+		case renamed[name] != "":
+			warnings = append(warnings, fmt.Sprintf("planet %s: label %q shared in its house, drawn as %q", name, loc.displayName(name, p, input.Options.DisplayMode), renamed[name]))
+		}
+	}
+	if input.Options.DrawNodalAxis {
+		var present []string
+		for _, node := range []string{"rahu", "ketu"} {
+			if _, p := findPlanet(input.Planets, node); p != nil && RashiToNumber(p.Rashi) != 0 {
+				present = append(present, node)
+			}
+		}
+		if len(present) == 1 {
+			warnings = append(warnings, fmt.Sprintf("draw_nodal_axis: only %s is in the chart, axis skipped", present[0]))
+		}
+	}
+	if input.Options.ShowCharaKarakas {
+		candidates := charaKarakaGrahas
+		if input.Options.CharaKarakasWithRahu {
+			candidates = append(append([]string{}, charaKarakaGrahas...), "rahu")
+		}
+		for _, graha := range candidates {
+			if _, p := findPlanet(input.Planets, graha); p != nil && p.Degrees <= 0 {
+				warnings = append(warnings, fmt.Sprintf("show_chara_karakas: %s has no degrees, left out of the karakas", graha))
+			}
+		}
+	}
+	return warnings
+}
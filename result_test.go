@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/base64"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateChartResult(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeNorth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo"},
+			"mercury": {Rashi: "leo", IsRetrograde: true},
+			"moon":    {Rashi: "aries"},
+			"saturn":  {Rashi: " Kumbha "},
+			"venus":   nil,
+			"pluto":   {Rashi: "virgo"},
+		},
+	}
+	result, err := GenerateChartResult(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if result.Width != defaultChartSize || result.Height != defaultChartSize || result.LagnaRashi != 5 {
+		t.Errorf("result %dx%d lagna %d, want %dx%[4]d lagna 5", result.Width, result.Height, result.LagnaRashi, defaultChartSize)
+	}
+	want := map[int][]string{1: {"mercury", "sun"}, 2: {"pluto"}, 7: {"saturn"}, 9: {"moon"}}
+	if !reflect.DeepEqual(result.Houses, want) {
+		t.Errorf("houses = %v, want %v", result.Houses, want)
+	}
+	wantWarnings := []string{
+		`planet pluto: unknown planet without a display name, drawn unlabelled (set its display or RegisterPlanetAbbreviation)`,
+		`planet venus: nil, skipped`,
+	}
+	if !slices.Equal(result.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q, want %q", result.Warnings, wantWarnings)
+	}
+
+	// The string API draws the same chart
+	encoded, err := GenerateChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	if encoded != base64.StdEncoding.EncodeToString(result.PNG) {
+		t.Error("GenerateChart differs from the result's PNG")
+	}
+}
+
+func TestGenerateChartResult_Warnings(t *testing.T) {
+	thumbnail := crowdedHouseInput(ChartTypeSouth)
+	thumbnail.Options.Thumbnail = true
+	long := houseScoresInput(ChartTypeNorth)
+	long.Planets["jupiter"] = &Planet{Rashi: "pisces", Display: "Jupiter the great benefic and guru of the devas"}
+	oneNode := aspectLinesInput(ChartTypeSouth)
+	oneNode.Planets["rahu"] = &Planet{Rashi: "gemini"}
+	oneNode.Options.DrawNodalAxis = true
+
+	tests := []struct {
+		name  string
+		input ChartInput
+		want  string
+	}{
+		{"thumbnail", thumbnail, "house 1: "},
+		{"long display", long, "planet jupiter: label truncated to "},
+		{"one node", oneNode, "draw_nodal_axis: only rahu is in the chart"},
+	}
+	for _, tt := range tests {
+		result, err := GenerateChartResult(tt.input)
+		if err != nil {
+			t.Fatalf("%s: error generating chart: %v", tt.name, err)
+		}
+		if !slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.HasPrefix(w, tt.want) }) {
+			t.Errorf("%s: warnings %q, want one starting %q", tt.name, result.Warnings, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// shalakaEnds lists the nakshatras at the ends of the seven rods of the
+// Sapta Shalaka Chakra, clockwise from the top of the leftmost upright rod:
+// the tops of the upright rods, the right ends of the level rods from the
+// top, the bottoms of the upright rods from the right and the left ends of
+// the level rods from the bottom. The two ends of a rod pierce each other.
+var shalakaEnds = [28]string{
+	"Krittika", "Rohini", "Mrigashira", "Ardra", "Punarvasu", "Pushya", "Ashlesha",
+	"Magha", "Purva Phalguni", "Uttara Phalguni", "Hasta", "Chitra", "Swati", "Vishakha",
+	"Anuradha", "Jyeshtha", "Mula", "Purva Ashadha", "Uttara Ashadha", "Abhijit", "Shravana",
+	"Dhanishta", "Shatabhisha", "Purva Bhadrapada", "Uttara Bhadrapada", "Revati", "Ashwini", "Bharani",
+}
+
+// shalakaEnd returns the position in shalakaEnds of a nakshatra
+func shalakaEnd(nakshatra string) int {
+	for i, name := range shalakaEnds {
+		if name == nakshatra {
+			return i
+		}
+	}
+	return -1
+}
+
+// shalakaPartner returns the end of the same rod as end: the top of an
+// upright rod faces its bottom and the left end of a level rod its right
+func shalakaPartner(end int) int {
+	side, i := end/7, end%7
+	return ((side+2)%4)*7 + 6 - i
+}
+
+// Colors of the janma nakshatra's end and of the rods piercing it
+var (
+	shalakaJanma = color.NRGBA{R: 200, G: 130, B: 0, A: 255}
+	shalakaVedha = color.NRGBA{R: 200, G: 30, B: 30, A: 255}
+)
+
+// GenerateSaptaShalakaChakra draws the Sapta Shalaka Chakra: seven upright
+// and seven level rods whose 28 ends carry the nakshatras, Krittika at the
+// top left and the rest clockwise. planets, transits as a rule, are written
+// at their nakshatras' ends. With opts.JanmaNakshatra set, the natal Moon's
+// nakshatra, its end is marked and every rod with a planet at its other end
+// is drawn in red, the vedha the planet casts on the birth star.
+func GenerateSaptaShalakaChakra(planets map[string]*Planet, opts ChartOptions) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	occupants := map[int][]string{}
+	for _, name := range sortedPlanetNames(planets) {
+		p := planets[name]
+		if p == nil {
+			continue
+		}
+		nakshatra, err := planetNakshatra(name, p)
+		if err != nil {
+			return nil, err
+		}
+		end := shalakaEnd(nakshatra)
+		occupants[end] = append(occupants[end], name)
+	}
+	janma := -1
+	if opts.JanmaNakshatra != "" {
+		janma = shalakaEnd(chakraNakshatras[chakraNakshatra(opts.JanmaNakshatra)])
+	}
+
+	canvasW, canvasH := opts.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, false)
+	band := frame.px(110) // Around the lattice, for the labels at the ends
+	left, top := frame.x+band, frame.y+band
+	side := frame.width - 2*band
+	spacing := side / 8
+	// endPoint returns where the rod of an end meets the lattice's edge
+	endPoint := func(end int) gg.Point {
+		i := float64(end%7 + 1)
+		switch end / 7 {
+		case 0:
+			return gg.Point{X: left + i*spacing, Y: top}
+		case 1:
+			return gg.Point{X: left + side, Y: top + i*spacing}
+		case 2:
+			return gg.Point{X: left + side - i*spacing, Y: top + side}
+		}
+		return gg.Point{X: left, Y: top + side - i*spacing}
+	}
+
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, opts, nil, nil)
+
+	// Rods piercing the janma nakshatra go last, over the rods they cross
+	var vedha []int
+	for end := range 14 {
+		a, b := endPoint(end), endPoint(shalakaPartner(end))
+		if janma >= 0 && (end == janma || shalakaPartner(end) == janma) && len(occupants[shalakaPartner(janma)]) > 0 {
+			vedha = append(vedha, end)
+			continue
+		}
+		dc.SetRGB(0, 0, 0)
+		dc.SetLineWidth(frame.px(1.5))
+		dc.DrawLine(a.X, a.Y, b.X, b.Y)
+		dc.Stroke()
+	}
+	for _, end := range vedha {
+		a, b := endPoint(end), endPoint(shalakaPartner(end))
+		dc.SetColor(shalakaVedha)
+		dc.SetLineWidth(frame.px(3.5))
+		dc.DrawLine(a.X, a.Y, b.X, b.Y)
+		dc.Stroke()
+	}
+
+	nameSize, planetSize := frame.px(15), frame.px(14)
+	var legible legibility
+	legible.use(planetSize)
+	nameFace, nameMetrics := embeddedFace(matangiBold, nameSize), boldMetrics(nameSize)
+	planetFace, planetMetrics := embeddedFace(matangiBold, planetSize), boldMetrics(planetSize)
+	loc := localeFor(opts)
+	gap := frame.px(8)
+	for end, nakshatra := range shalakaEnds {
+		at := endPoint(end)
+		dot := color.Color(textBlack)
+		switch {
+		case end == janma:
+			dot = shalakaJanma
+		case janma >= 0 && shalakaPartner(end) == janma && len(occupants[end]) > 0:
+			dot = shalakaVedha
+		}
+		dc.SetColor(dot)
+		dc.DrawCircle(at.X, at.Y, frame.px(4))
+		if end == janma {
+			dc.DrawCircle(at.X, at.Y, frame.px(7))
+		}
+		dc.Fill()
+
+		// The nakshatra's name sits beyond the end, and its planets beyond
+		// that in rows as wide as the space between two rods
+		labels := make([]string, len(occupants[end]))
+		for i, name := range occupants[end] {
+			labels[i] = loc.displayName(name, planets[name], opts.DisplayMode)
+		}
+		rows := labelRows(planetFace, labels, spacing-frame.px(4))
+		height := nameMetrics.lineHeight() + float64(len(rows))*planetMetrics.lineHeight()
+		// x and y are the middle of the block's top edge, ax its alignment
+		var x, y, ax float64
+		switch end / 7 {
+		case 0:
+			x, y, ax = at.X, at.Y-gap-height, 0.5
+		case 1:
+			x, y, ax = at.X+gap, at.Y-height/2, 0
+		case 2:
+			x, y, ax = at.X, at.Y+gap, 0.5
+		default:
+			x, y, ax = at.X-gap, at.Y-height/2, 1
+		}
+		nameColor := textBlack
+		if end == janma {
+			nameColor = shalakaJanma
+		}
+		drawText(dc, nameFace, nameColor, nakshatraAbbreviation(nakshatra), x, y+nameMetrics.capHeight, ax)
+		y += nameMetrics.lineHeight()
+		i := 0
+		for _, row := range rows {
+			// Each label keeps its planet's category color
+			rowWidth := float64(font.MeasureString(planetFace, strings.Join(labels[i:i+row], " "))) / 64
+			cursor := x - ax*rowWidth
+			for range row {
+				name := occupants[end][i]
+				drawText(dc, planetFace, PlanetCategory(name, planets[name]).color(), labels[i], cursor, y+planetMetrics.capHeight, 0)
+				cursor += float64(font.MeasureString(planetFace, labels[i]+" ")) / 64
+				i++
+			}
+			y += planetMetrics.lineHeight()
+		}
+	}
+	if err := legible.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+	return r.encode(dc.Image())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSaptaShalakaChakra(t *testing.T) {
+	data, err := GenerateSaptaShalakaChakra(nil, ChartOptions{})
+	if err != nil {
+		t.Fatalf("Error generating the empty chakra: %v", err)
+	}
+	assertGolden(t, "sapta_shalaka_empty", data)
+
+	// Born under Shravana, with transiting Saturn in Krittika at the other
+	// end of its rod, and Rahu and Ketu elsewhere
+	transits := map[string]*Planet{
+		"saturn":  {Rashi: "aries", Degrees: 28},
+		"mars":    {Rashi: "taurus", Degrees: 2},
+		"jupiter": {Nakshatra: "Rohini"},
+		"rahu":    {Rashi: "pisces", Degrees: 20},
+		"ketu":    {Rashi: "virgo", Degrees: 20},
+		"sun":     {Rashi: "leo", Degrees: 3},
+		"moon":    {Rashi: "capricorn", Degrees: 5},
+	}
+	data, err = GenerateSaptaShalakaChakra(transits, ChartOptions{JanmaNakshatra: "Shravana"})
+	if err != nil {
+		t.Fatalf("Error generating the chakra with a vedha: %v", err)
+	}
+	assertGolden(t, "sapta_shalaka_vedha", data)
+}
+
+func TestShalakaPartner(t *testing.T) {
+	for _, tc := range []struct{ a, b string }{
+		{"Krittika", "Shravana"},
+		{"Rohini", "Abhijit"},
+		{"Ashlesha", "Anuradha"},
+		{"Magha", "Bharani"},
+		{"Vishakha", "Dhanishta"},
+	} {
+		a, b := shalakaEnd(tc.a), shalakaEnd(tc.b)
+		if got := shalakaEnds[shalakaPartner(a)]; got != tc.b {
+			t.Errorf("%s pierces %s, want %s", tc.a, got, tc.b)
+		}
+		if got := shalakaEnds[shalakaPartner(b)]; got != tc.a {
+			t.Errorf("%s pierces %s, want %s", tc.b, got, tc.a)
+		}
+	}
+	for _, name := range chakraNakshatras {
+		if shalakaEnd(name) < 0 {
+			t.Errorf("nakshatra %s has no end", name)
+		}
+	}
+}
+
+func TestGenerateSaptaShalakaChakra_Errors(t *testing.T) {
+	_, err := GenerateSaptaShalakaChakra(nil, ChartOptions{JanmaNakshatra: "Shravan"})
+	if err == nil || !strings.Contains(err.Error(), `janma_nakshatra: unknown nakshatra "Shravan"`) {
+		t.Errorf("unknown janma nakshatra error = %v", err)
+	}
+	_, err = GenerateSaptaShalakaChakra(map[string]*Planet{"mars": {Rashi: "aries", Degrees: 30}}, ChartOptions{})
+	if err == nil || !strings.Contains(err.Error(), "planet mars: degrees 30 out of range") {
+		t.Errorf("degrees error = %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// SarvatobhadraInput is the input of GenerateSarvatobhadraChakra
+type SarvatobhadraInput struct {
+	// Planets are placed in the cells of their nakshatras: Nakshatra when
+	// set ("Abhijit" included), otherwise the one at Rashi and Degrees
+	Planets map[string]*Planet `json:"planets,omitempty"`
+	// Vedha names planets whose vedha lines are highlighted
+	Vedha   []string     `json:"vedha,omitempty"`
+	Options ChartOptions `json:"options,omitempty"`
+}
+
+// sbcKind is what a cell of the Sarvatobhadra Chakra holds
+type sbcKind int
+
+const (
+	sbcVowel sbcKind = iota
+	sbcNakshatra
+	sbcConsonant
+	sbcRashi
+	sbcTithi
+)
+
+// sbcCell is one of the 81 cells of the chakra. name is the letter,
+// nakshatra or tithi group; rashi is set for rashi cells.
+type sbcCell struct {
+	kind  sbcKind
+	name  string
+	rashi int
+}
+
+// sbcPos is a cell's row and column, both 0-8 with east at the top
+type sbcPos struct{ row, col int }
+
+// sbcRing is the content of one square ring of the chakra, from the border
+// inward: the vowels in its corners from the top left clockwise, and what
+// lies between them along each side in the same clockwise walk
+type sbcRing struct {
+	corners [4]string
+	kind    sbcKind
+	sides   [4][]string
+}
+
+// sbcRings is the classical arrangement: the 28 nakshatras on the border
+// from Krittika in the east, then the consonants, the rashis from Vrishabha
+// and the tithi groups, with Purna in the center
+var sbcRings = [4]sbcRing{
+	{
+		corners: [4]string{"अ", "आ", "इ", "ई"},
+		kind:    sbcNakshatra,
+		sides: [4][]string{
+			{"Krittika", "Rohini", "Mrigashira", "Ardra", "Punarvasu", "Pushya", "Ashlesha"},
+			{"Magha", "Purva Phalguni", "Uttara Phalguni", "Hasta", "Chitra", "Swati", "Vishakha"},
+			{"Anuradha", "Jyeshtha", "Mula", "Purva Ashadha", "Uttara Ashadha", "Abhijit", "Shravana"},
+			{"Dhanishta", "Shatabhisha", "Purva Bhadrapada", "Uttara Bhadrapada", "Revati", "Ashwini", "Bharani"},
+		},
+	},
+	{
+		corners: [4]string{"उ", "ऊ", "ऋ", "ॠ"},
+		kind:    sbcConsonant,
+		sides: [4][]string{
+			{"अ", "व", "क", "ह", "ड"},
+			{"म", "ट", "प", "र", "त"},
+			{"न", "य", "भ", "ज", "ख"},
+			{"ग", "स", "द", "च", "ल"},
+		},
+	},
+	{
+		corners: [4]string{"ऌ", "ॡ", "ए", "ऐ"},
+		kind:    sbcRashi,
+		sides:   [4][]string{{"2", "3", "4"}, {"5", "6", "7"}, {"8", "9", "10"}, {"11", "12", "1"}},
+	},
+	{
+		corners: [4]string{"ओ", "औ", "अं", "अः"},
+		kind:    sbcTithi,
+		sides:   [4][]string{{"Nanda"}, {"Bhadra"}, {"Jaya"}, {"Rikta"}},
+	},
+}
+
+// sbcTithiGroup is a tithi group's tithis of each paksha and its weekdays
+type sbcTithiGroup struct {
+	tithis, weekdays string
+}
+
+var sbcTithiGroups = map[string]sbcTithiGroup{
+	"Nanda":  {"1 6 11", "Sun Tue"},
+	"Bhadra": {"2 7 12", "Mon Wed"},
+	"Jaya":   {"3 8 13", "Thu"},
+	"Rikta":  {"4 9 14", "Fri"},
+	"Purna":  {"5 10 15", "Sat"},
+}
+
+// sbcGrid holds the chakra's cells by row and column, and sbcNakshatraCells
+// the border cell of each nakshatra
+var sbcGrid, sbcNakshatraCells = buildSarvatobhadraGrid()
+
+func buildSarvatobhadraGrid() ([9][9]sbcCell, map[string]sbcPos) {
+	var grid [9][9]sbcCell
+	nakshatras := map[string]sbcPos{}
+	for ring, content := range sbcRings {
+		lo, hi := ring, 8-ring
+		corners := [4]sbcPos{{lo, lo}, {lo, hi}, {hi, hi}, {hi, lo}}
+		for i, pos := range corners {
+			grid[pos.row][pos.col] = sbcCell{kind: sbcVowel, name: content.corners[i]}
+			// Walk the side from this corner to the next one clockwise
+			next := corners[(i+1)%4]
+			dr, dc := (next.row-pos.row)/(hi-lo), (next.col-pos.col)/(hi-lo)
+			for j, name := range content.sides[i] {
+				at := sbcPos{pos.row + dr*(j+1), pos.col + dc*(j+1)}
+				cell := sbcCell{kind: content.kind, name: name}
+				if content.kind == sbcRashi {
+					cell.rashi = RashiToNumber(name)
+					cell.name = NumberToSanskritRashi(cell.rashi)
+				}
+				grid[at.row][at.col] = cell
+				if content.kind == sbcNakshatra {
+					nakshatras[name] = at
+				}
+			}
+		}
+	}
+	grid[4][4] = sbcCell{kind: sbcTithi, name: "Purna"}
+	return grid, nakshatras
+}
+
+// sbcVedhaLines returns the three vedha lines from a nakshatra's border
+// cell: straight across the chakra and along both inward diagonals, each
+// until it leaves the grid. The origin is not part of a line.
+func sbcVedhaLines(from sbcPos) [][]sbcPos {
+	var in sbcPos // Inward step from the side holding the nakshatra
+	switch {
+	case from.row == 0:
+		in = sbcPos{1, 0}
+	case from.col == 8:
+		in = sbcPos{0, -1}
+	case from.row == 8:
+		in = sbcPos{-1, 0}
+	default:
+		in = sbcPos{0, 1}
+	}
+	across := sbcPos{in.col, in.row}
+	steps := []sbcPos{in, {in.row + across.row, in.col + across.col}, {in.row - across.row, in.col - across.col}}
+	lines := make([][]sbcPos, 0, len(steps))
+	for _, step := range steps {
+		var line []sbcPos
+		for at := (sbcPos{from.row + step.row, from.col + step.col}); at.row >= 0 && at.row <= 8 && at.col >= 0 && at.col <= 8; at = (sbcPos{at.row + step.row, at.col + step.col}) {
+			line = append(line, at)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Colors of the cells and lines a vedha reaches
+var (
+	sbcVedhaFill = color.NRGBA{R: 250, G: 214, B: 214, A: 255}
+	sbcVedhaLine = color.NRGBA{R: 200, G: 30, B: 30, A: 160}
+	sbcBorder    = color.NRGBA{R: 242, G: 242, B: 242, A: 255}
+)
+
+// GenerateSarvatobhadraChakra draws the Sarvatobhadra Chakra: the 9x9 grid
+// with the 28 nakshatras around its border, the vowels, consonants, rashis
+// and tithi groups inside, and east at the top. Planets are written in
+// their nakshatras' cells, and the vedha lines of the planets named in
+// Vedha are drawn across the chakra with the cells they reach tinted.
+func GenerateSarvatobhadraChakra(input SarvatobhadraInput) ([]byte, error) {
+	if err := input.Options.validate(); err != nil {
+		return nil, err
+	}
+	occupants := map[sbcPos][]string{}
+	placed := map[string]sbcPos{}
+	for _, name := range sortedPlanetNames(input.Planets) {
+		p := input.Planets[name]
+		if p == nil {
+			continue
+		}
+		nakshatra, err := planetNakshatra(name, p)
+		if err != nil {
+			return nil, err
+		}
+		pos := sbcNakshatraCells[nakshatra]
+		occupants[pos] = append(occupants[pos], name)
+		placed[name] = pos
+	}
+	var vedhaFrom []sbcPos
+	for _, name := range input.Vedha {
+		pos, ok := placed[name]
+		if !ok {
+			return nil, fmt.Errorf("vedha: %w", &ErrUnknownPlanet{Name: name})
+		}
+		vedhaFrom = append(vedhaFrom, pos)
+	}
+
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	padding := frame.px(40)
+	gridLeft, gridTop := frame.x+padding, frame.y+padding
+	cellW := (frame.width - 2*padding) / 9
+	cellH := (frame.height - 2*padding) / 9
+	cellRect := func(pos sbcPos) (x, y float64) {
+		return gridLeft + float64(pos.col)*cellW, gridTop + float64(pos.row)*cellH
+	}
+
+	// Shade the nakshatra border, then tint what the vedhas reach, before
+	// the grid so borders stay crisp
+	fills := map[int]color.Color{}
+	for _, pos := range sbcNakshatraCells {
+		fills[pos.row*9+pos.col] = sbcBorder
+	}
+	var lineEnds [][2]sbcPos
+	for _, from := range vedhaFrom {
+		fills[from.row*9+from.col] = sbcVedhaFill
+		for _, line := range sbcVedhaLines(from) {
+			for _, pos := range line {
+				fills[pos.row*9+pos.col] = sbcVedhaFill
+			}
+			if len(line) > 0 {
+				lineEnds = append(lineEnds, [2]sbcPos{from, line[len(line)-1]})
+			}
+		}
+	}
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, fills, func(key int) []gg.Point {
+		x, y := cellRect(sbcPos{key / 9, key % 9})
+		return []gg.Point{{X: x, Y: y}, {X: x + cellW, Y: y}, {X: x + cellW, Y: y + cellH}, {X: x, Y: y + cellH}}
+	})
+	dc.SetRGB(0, 0, 0)
+	dc.SetLineWidth(frame.px(1))
+	for i := 0; i <= 9; i++ {
+		dc.DrawLine(gridLeft+float64(i)*cellW, gridTop, gridLeft+float64(i)*cellW, gridTop+9*cellH)
+		dc.DrawLine(gridLeft, gridTop+float64(i)*cellH, gridLeft+9*cellW, gridTop+float64(i)*cellH)
+	}
+	dc.Stroke()
+	dc.SetLineWidth(frame.px(2.5))
+	dc.DrawRectangle(gridLeft, gridTop, 9*cellW, 9*cellH)
+	dc.Stroke()
+
+	// Vedha lines run from the center of the planet's cell to the far end
+	// of each line, under the text so it stays legible
+	dc.SetColor(sbcVedhaLine)
+	dc.SetLineWidth(frame.px(3))
+	for _, ends := range lineEnds {
+		x1, y1 := cellRect(ends[0])
+		x2, y2 := cellRect(ends[1])
+		dc.DrawLine(x1+cellW/2, y1+cellH/2, x2+cellW/2, y2+cellH/2)
+		dc.Stroke()
+	}
+	letterSize, nameSize, smallSize := frame.px(26), frame.px(15), frame.px(11)
+	var legible legibility
+	legible.use(smallSize)
+	letterFace := embeddedFace(matangiRegular, letterSize)
+	nameFace := embeddedFace(matangiBold, nameSize)
+	smallFace := embeddedFace(matangiRegular, smallSize)
+	letterMetrics, nameMetrics, smallMetrics := regularMetrics(letterSize), boldMetrics(nameSize), regularMetrics(smallSize)
+	inset := frame.px(4)
+	for row := range sbcGrid {
+		for col, cell := range sbcGrid[row] {
+			x, y := cellRect(sbcPos{row, col})
+			centerX, centerY := x+cellW/2, y+cellH/2
+			switch cell.kind {
+			case sbcVowel, sbcConsonant:
+				drawText(dc, letterFace, textBlack, cell.name, centerX, letterMetrics.baseline(centerY), 0.5)
+			case sbcRashi:
+				// Long rashi names shrink to the cell's width
+				size := nameSize
+				if w := float64(font.MeasureString(nameFace, cell.name)) / 64; w > cellW-2*inset {
+					size *= (cellW - 2*inset) / w
+				}
+				legible.use(size)
+				drawText(dc, embeddedFace(matangiBold, size), textBlack, cell.name, centerX, boldMetrics(size).baseline(centerY), 0.5)
+			case sbcTithi:
+				group := sbcTithiGroups[cell.name]
+				gap := smallMetrics.lineHeight() * 0.4
+				top := centerY - (nameMetrics.lineHeight()+2*smallMetrics.lineHeight()+2*gap)/2
+				drawText(dc, nameFace, textBlack, cell.name, centerX, top+nameMetrics.capHeight, 0.5)
+				top += nameMetrics.lineHeight() + gap
+				drawText(dc, smallFace, textBlack, group.tithis, centerX, top+smallMetrics.capHeight, 0.5)
+				top += smallMetrics.lineHeight() + gap
+				drawText(dc, smallFace, textBlack, group.weekdays, centerX, top+smallMetrics.capHeight, 0.5)
+			case sbcNakshatra:
+				drawSBCNakshatra(dc, input, cell.name, occupants[sbcPos{row, col}], x, y, cellW, cellH, frame, &legible)
+			}
+		}
+	}
+	if err := legible.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+
+	return r.encode(dc.Image())
+}
+
+// drawSBCNakshatra draws a nakshatra's border cell: its short name at the
+// top and the planets in it below, as many to a line as fit, shrinking
+// them when the lines overflow the cell
+func drawSBCNakshatra(dc *gg.Context, input SarvatobhadraInput, nakshatra string, planets []string, x, y, cellW, cellH float64, frame chartFrame, legible *legibility) {
+	nameSize := frame.px(15)
+	nameMetrics := boldMetrics(nameSize)
+	top := y + frame.px(5)
+	drawText(dc, embeddedFace(matangiBold, nameSize), textBlack, nakshatraAbbreviation(nakshatra), x+cellW/2, top+nameMetrics.capHeight, 0.5)
+	if len(planets) == 0 {
+		return
+	}
+	top += nameMetrics.lineHeight() + frame.px(3)
+	loc := localeFor(input.Options)
+	labels := make([]string, len(planets))
+	for i, name := range planets {
+		labels[i] = loc.displayName(name, input.Planets[name], input.Options.DisplayMode)
+	}
+	width, height := cellW-frame.px(6), y+cellH-frame.px(3)-top
+	for size := frame.px(14); ; size *= 0.9 {
+		face := embeddedFace(matangiBold, size)
+		metrics := boldMetrics(size)
+		rows := labelRows(face, labels, width)
+		if float64(len(rows))*metrics.lineHeight() > height && size > minFontSize {
+			continue
+		}
+		legible.use(size)
+		i := 0
+		for _, row := range rows {
+			// Each label keeps its planet's category color
+			rowWidth := float64(font.MeasureString(face, strings.Join(labels[i:i+row], " "))) / 64
+			left := x + (cellW-rowWidth)/2
+			for _, label := range labels[i : i+row] {
+				c := PlanetCategory(planets[i], input.Planets[planets[i]]).color()
+				drawText(dc, face, c, label, left, top+metrics.capHeight, 0)
+				left += float64(font.MeasureString(face, label+" ")) / 64
+				i++
+			}
+			top += metrics.lineHeight()
+		}
+		return
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func sarvatobhadraPlanets() map[string]*Planet {
+	return map[string]*Planet{
+		"sun":     {Rashi: "libra", Degrees: 2},
+		"moon":    {Nakshatra: "Rohini"},
+		"mars":    {Rashi: "capricorn", Degrees: 12},
+		"mercury": {Rashi: "virgo", Degrees: 20},
+		"jupiter": {Nakshatra: "abhijit"},
+		"venus":   {Rashi: "leo", Degrees: 28},
+		"saturn":  {Rashi: "aries", Degrees: 28},
+		"rahu":    {Rashi: "taurus", Degrees: 5},
+		"ketu":    {Rashi: "scorpio", Degrees: 5},
+	}
+}
+
+func TestGenerateSarvatobhadraChakra(t *testing.T) {
+	data, err := GenerateSarvatobhadraChakra(SarvatobhadraInput{})
+	if err != nil {
+		t.Fatalf("Error generating the empty chakra: %v", err)
+	}
+	assertGolden(t, "sarvatobhadra_empty", data)
+
+	// Saturn at 28° Aries is in Krittika, as is Rahu at 5° Taurus
+	data, err = GenerateSarvatobhadraChakra(SarvatobhadraInput{Planets: sarvatobhadraPlanets(), Vedha: []string{"saturn"}})
+	if err != nil {
+		t.Fatalf("Error generating the chakra with Saturn's vedha: %v", err)
+	}
+	assertGolden(t, "sarvatobhadra_saturn_vedha", data)
+}
+
+func TestSarvatobhadraGrid(t *testing.T) {
+	if len(sbcNakshatraCells) != 28 {
+		t.Errorf("%d nakshatra cells, want 28", len(sbcNakshatraCells))
+	}
+	for _, name := range nakshatraNames {
+		if _, ok := sbcNakshatraCells[name]; !ok {
+			t.Errorf("nakshatra %s missing from the border", name)
+		}
+	}
+	for _, tc := range []struct {
+		pos  sbcPos
+		want sbcCell
+	}{
+		{sbcPos{0, 0}, sbcCell{kind: sbcVowel, name: "अ"}},
+		{sbcPos{0, 1}, sbcCell{kind: sbcNakshatra, name: "Krittika"}},
+		{sbcPos{8, 2}, sbcCell{kind: sbcNakshatra, name: "Abhijit"}},
+		{sbcPos{1, 2}, sbcCell{kind: sbcConsonant, name: "अ"}},
+		{sbcPos{2, 3}, sbcCell{kind: sbcRashi, name: "Vrishabha", rashi: 2}},
+		{sbcPos{3, 2}, sbcCell{kind: sbcRashi, name: "Mesha", rashi: 1}},
+		{sbcPos{3, 4}, sbcCell{kind: sbcTithi, name: "Nanda"}},
+		{sbcPos{4, 4}, sbcCell{kind: sbcTithi, name: "Purna"}},
+	} {
+		if got := sbcGrid[tc.pos.row][tc.pos.col]; got != tc.want {
+			t.Errorf("cell %v = %+v, want %+v", tc.pos, got, tc.want)
+		}
+	}
+}
+
+func TestSarvatobhadraVedha(t *testing.T) {
+	// The three lines from a nakshatra end on the nakshatras it pierces
+	for _, tc := range []struct {
+		nakshatra string
+		want      []string
+	}{
+		{"Krittika", []string{"Shravana", "Vishakha", "Bharani"}},
+		{"Ashlesha", []string{"Anuradha", "Magha", "Dhanishta"}},
+		{"Hasta", []string{"Uttara Bhadrapada", "Ardra", "Purva Ashadha"}},
+	} {
+		var got []string
+		for _, line := range sbcVedhaLines(sbcNakshatraCells[tc.nakshatra]) {
+			end := line[len(line)-1]
+			got = append(got, sbcGrid[end.row][end.col].name)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("vedha of %s ends on %v, want %v", tc.nakshatra, got, tc.want)
+		}
+	}
+
+	// From the second cell of a side, the straight line runs down the
+	// consonant ring
+	var across []string
+	for _, pos := range sbcVedhaLines(sbcNakshatraCells["Krittika"])[0] {
+		across = append(across, sbcGrid[pos.row][pos.col].name)
+	}
+	if got, want := strings.Join(across, ","), "उ,ल,च,द,स,ग,ॠ,Shravana"; got != want {
+		t.Errorf("Krittika's straight vedha = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateSarvatobhadraChakra_Errors(t *testing.T) {
+	_, err := GenerateSarvatobhadraChakra(SarvatobhadraInput{Planets: map[string]*Planet{"sun": {Nakshatra: "Rohinii"}}})
+	if err == nil || !strings.Contains(err.Error(), `planet sun: unknown nakshatra "Rohinii"`) {
+		t.Errorf("unknown nakshatra error = %v", err)
+	}
+
+	var rashiErr *ErrInvalidRashi
+	_, err = GenerateSarvatobhadraChakra(SarvatobhadraInput{Planets: map[string]*Planet{"sun": {Rashi: "nowhere"}}})
+	if !errors.As(err, &rashiErr) {
+		t.Errorf("unknown rashi error = %v, want ErrInvalidRashi", err)
+	}
+
+	var planetErr *ErrUnknownPlanet
+	_, err = GenerateSarvatobhadraChakra(SarvatobhadraInput{Planets: sarvatobhadraPlanets(), Vedha: []string{"pluto"}})
+	if !errors.As(err, &planetErr) || planetErr.Name != "pluto" {
+		t.Errorf("unknown vedha planet error = %v, want ErrUnknownPlanet", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// Sarvashtakavarga totals below savLowScore mark a weak rashi and those
+// above savHighScore a strong one, the usual thresholds around the average
+// of 28 bindus
+const (
+	savLowScore  = 25
+	savHighScore = 30
+)
+
+// Cell tints of weak and strong rashis with Options.HighlightSAV
+var (
+	savLowFill  = color.NRGBA{R: 250, G: 214, B: 214, A: 255}
+	savHighFill = color.NRGBA{R: 214, G: 240, B: 214, A: 255}
+)
+
+// GenerateSarvashtakavargaChart draws a sarvashtakavarga chart of twelve
+// rashi totals, Aries first, on the South Indian grid. lagnaRashi, which may
+// be empty, marks the lagna's cell as in a South chart.
+func GenerateSarvashtakavargaChart(scores [12]int, lagnaRashi string, opts ChartOptions) ([]byte, error) {
+	input := ChartInput{ChartType: ChartTypeSarvashtakavarga, HouseScores: map[int]int{}, Options: opts}
+	for i, score := range scores {
+		input.HouseScores[i+1] = score
+	}
+	if lagnaRashi != "" {
+		input.Lagna = &Planet{Rashi: lagnaRashi}
+	}
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	img, err := r.draw(input, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.encode(img)
+}
+
+// renderSAVChart draws the input's HouseScores as a sarvashtakavarga chart:
+// the South grid with each rashi's total large in its cell and no planets
+func renderSAVChart(r *renderer, input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	if err := validateSAVScores(input); err != nil {
+		return nil, err
+	}
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	padding := frame.px(40)
+	gridLeft, gridTop := frame.x+padding, frame.y+padding
+	cellW := (frame.width - 2*padding) / 4
+	cellH := (frame.height - 2*padding) / 4
+
+	lagnaRashi := 1
+	if input.Lagna != nil {
+		if n := RashiToNumber(input.Lagna.Rashi); n > 0 {
+			lagnaRashi = n
+		}
+	}
+	houseRects := southHouseRects(gridLeft, gridTop, cellW, cellH)
+
+	// Tint weak and strong rashis, then any highlighted houses, before the
+	// grid so borders stay crisp
+	fills := map[int]color.Color{}
+	if input.Options.HighlightSAV {
+		for rashiNum, score := range input.HouseScores {
+			switch {
+			case score < savLowScore:
+				fills[rashiNum] = savLowFill
+			case score > savHighScore:
+				fills[rashiNum] = savHighFill
+			}
+		}
+	}
+	for house, c := range houseFills(input) {
+		fills[(lagnaRashi+house-2)%12+1] = c
+	}
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, fills, func(rashiNum int) []gg.Point {
+		return rectPolygon(houseRects[southRashiCell(rashiNum, lagnaRashi, input.Options.RotateToLagna)])
+	})
+	drawSouthGrid(dc, gridLeft, gridTop, cellW, cellH, frame)
+
+	numberSize, scoreSize := frame.px(16), frame.px(48)
+	numberMetrics, scoreMetrics := regularMetrics(numberSize), boldMetrics(scoreSize)
+	var legible legibility
+	legible.use(numberSize)
+	scoreFace := embeddedFace(matangiBold, scoreSize)
+	for cell := 1; cell <= 12; cell++ {
+		rect := houseRects[cell]
+		rashiNum := southCellRashi(cell, lagnaRashi, input.Options.RotateToLagna)
+
+		// The rashi label sits at the bottom right, as in the South chart
+		textX := float64(rect.Max.X) - frame.px(10)
+		textY := float64(rect.Max.Y) - numberMetrics.descent
+		numberBox := rashiLabelBox(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		dx, dy := numberBox.shiftInto(canvasW, canvasH)
+		textX, textY = textX+dx, textY+dy
+		numberBox = rashiLabelBox(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		numberBox.house, numberBox.kind = cell, labelRashi
+		dc.SetRGB(0, 0, 0)
+		drawRashiLabel(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		boxes.add(numberBox)
+		boxes.addHouse(cell, rashiNum, rectPolygon(rect))
+
+		if input.Lagna != nil && rashiNum == lagnaRashi {
+			marker := drawLagnaMarker(dc, rect, input.Options.LagnaMarkerStyle, numberSize)
+			for i := range marker {
+				marker[i].house, marker[i].kind = cell, labelMarker
+			}
+			boxes.add(marker...)
+		}
+
+		// The total is centered in the cell
+		score := strconv.Itoa(input.HouseScores[rashiNum])
+		centerX := float64(rect.Min.X+rect.Max.X) / 2
+		baseline := scoreMetrics.baseline(float64(rect.Min.Y+rect.Max.Y) / 2)
+		drawText(dc, scoreFace, textBlack, score, centerX, baseline, 0.5)
+		w := float64(font.MeasureString(scoreFace, score)) / 64
+		boxes.add(textBox{text: score, house: cell, kind: labelScore, left: centerX - w/2, top: baseline - scoreMetrics.capHeight, right: centerX + w/2, bottom: baseline})
+	}
+	if err := legible.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+
+	if err := drawSouthCenterText(dc, input, gridLeft, gridTop, cellW, cellH, frame, boxes); err != nil {
+		return nil, err
+	}
+	return dc.Image(), nil
+}
+
+// validateSAVScores reports a sarvashtakavarga chart input missing a rashi
+func validateSAVScores(input ChartInput) error {
+	for rashiNum := 1; rashiNum <= 12; rashiNum++ {
+		if _, ok := input.HouseScores[rashiNum]; !ok {
+			return fmt.Errorf("house_scores: rashi %d missing for the sarvashtakavarga chart", rashiNum)
+		}
+	}
+	return nil
+}
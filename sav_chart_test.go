@@ -0,0 +1,65 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+)
+
+// savArray is savScores, Aries first
+var savArray = [12]int{28, 31, 25, 33, 29, 22, 30, 26, 34, 27, 32, 20}
+
+func TestGenerateSarvashtakavargaChart(t *testing.T) {
+	data, err := GenerateSarvashtakavargaChart(savArray, "virgo", ChartOptions{HighlightSAV: true})
+	if err != nil {
+		t.Fatalf("Error generating sarvashtakavarga chart: %v", err)
+	}
+	assertGolden(t, "sarvashtakavarga", data)
+
+	// The chart type draws the same chart from HouseScores, ignoring planets
+	input := houseScoresInput(ChartTypeSarvashtakavarga)
+	input.SecondaryHouseScores = nil
+	input.Options = ChartOptions{HighlightSAV: true}
+	boxes := renderWithBoxes(t, input)
+	got := map[int]string{}
+	for _, b := range boxes.boxes {
+		switch b.kind {
+		case labelPlanet:
+			t.Errorf("planet %q drawn on the sarvashtakavarga chart", b.text)
+		case labelScore:
+			got[b.house] = b.text
+		}
+	}
+	if len(got) != 12 || got[4] != "33" || got[12] != "20" {
+		t.Errorf("scores by cell = %v, want all 12 with 33 in cell 4 and 20 in cell 12", got)
+	}
+	if overlaps := boxes.overlapping(); len(overlaps) > 0 {
+		t.Errorf("overlapping labels: %v", overlaps)
+	}
+}
+
+func TestGenerateChart_SarvashtakavargaMissingScores(t *testing.T) {
+	input := ChartInput{ChartType: ChartTypeSarvashtakavarga, HouseScores: map[int]int{1: 28, 2: 31}}
+	_, err := GenerateChart(input)
+	if err == nil || !strings.Contains(err.Error(), "house_scores: rashi 3 missing") {
+		t.Errorf("error = %v, want rashi 3 missing", err)
+	}
+
+	if _, err := GenerateSarvashtakavargaChart([12]int{1: 57}, "", ChartOptions{}); err == nil {
+		t.Error("score above 56 accepted")
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Schema returns a JSON Schema (draft 2020-12) of the JSON a ChartInput is
+// read from. It is built from the ChartInput struct and the types it holds,
+// so it always lists their fields, with the values the charts accept for
+// their enums: chart types, rashi spellings, locales, categories and the
+// option modes. Registered layouts and locales are included, as of the call.
+//
+// Rashis are matched case-insensitively by the charts but enumerated in
+// lowercase and title case here. ValidateChartJSON checks JSON against it.
+func Schema() []byte {
+	data, err := json.MarshalIndent(chartInputSchema(), "", "  ")
+	if err != nil {
+		panic(err) // Built of plain maps, slices and strings
+	}
+	return data
+}
+
+// schemaRequired lists the fields each struct must have
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeFor[ChartInput]():     {"chart_type"},
+	reflect.TypeFor[Planet]():         {"rashi"},
+	reflect.TypeFor[HouseHighlight](): {"houses", "color"},
+	reflect.TypeFor[DashaPeriod]():    {"lord", "start", "end"},
+}
+
+// schemaColor matches the colors parseHexColor reads, and schemaOptionalColor
+// those of colors left empty for a default
+func schemaColor() map[string]any {
+	return map[string]any{"pattern": "^#?([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6}|[0-9A-Fa-f]{8})$"}
+}
+
+func schemaOptionalColor() map[string]any {
+	return map[string]any{"pattern": "^(#?([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6}|[0-9A-Fa-f]{8}))?$"}
+}
+
+// schemaFields adds the constraints of single fields, by struct and field
+// name, that their types do not carry
+var schemaFields = map[string]func() map[string]any{
+	"ChartInput.Planets": func() map[string]any {
+		known := map[string]any{}
+		for _, p := range SupportedPlanets() {
+			if p.Name != "lagna" {
+				known[p.Name] = map[string]any{"$ref": "#/$defs/Planet"}
+			}
+		}
+		return map[string]any{"properties": known}
+	},
+	"ChartInput.HouseScores":          schemaHouseScores,
+	"ChartInput.SecondaryHouseScores": schemaHouseScores,
+	"Planet.Rashi":                    schemaRashi,
+	"Planet.NavamsaRashi":             schemaRashi,
+	"Planet.Degrees":                  func() map[string]any { return map[string]any{"minimum": 0, "exclusiveMaximum": 30} },
+	"Planet.Pada":                     func() map[string]any { return map[string]any{"minimum": 0, "maximum": 4} },
+	"ChartOptions.Locale": func() map[string]any {
+		return map[string]any{"enum": append([]string{""}, Locales()...)}
+	},
+	"ChartOptions.PlanetLineSpacing": func() map[string]any {
+		return map[string]any{"minimum": 0, "maximum": maxPlanetLineSpacing}
+	},
+	"ChartOptions.PlanetLineSpacingPx": func() map[string]any {
+		return map[string]any{"minimum": 0, "maximum": maxPlanetLineSpacingPx}
+	},
+	"ChartOptions.Width":          schemaCanvasSide,
+	"ChartOptions.Height":         schemaCanvasSide,
+	"ChartOptions.LagnaHouseFill": schemaOptionalColor,
+	"ChartOptions.BadhakaFill":    schemaOptionalColor,
+	"HouseHighlight.Color":        schemaColor,
+	"HouseHighlight.Houses": func() map[string]any {
+		return map[string]any{"items": map[string]any{"type": "integer", "minimum": 1, "maximum": 12}}
+	},
+	"CenterLine.Color":     schemaOptionalColor,
+	"CenterLine.Size":      func() map[string]any { return map[string]any{"minimum": 0, "maximum": maxCenterLineSize} },
+	"NativeInfo.Latitude":  func() map[string]any { return map[string]any{"minimum": -90, "maximum": 90} },
+	"NativeInfo.Longitude": func() map[string]any { return map[string]any{"minimum": -180, "maximum": 180} },
+}
+
+func schemaCanvasSide() map[string]any { return map[string]any{"minimum": 0, "maximum": MaxCanvasSize} }
+
+// schemaHouseScores keys scores by rashi number
+func schemaHouseScores() map[string]any {
+	return map[string]any{
+		"propertyNames":        map[string]any{"pattern": "^([1-9]|1[0-2])$"},
+		"additionalProperties": map[string]any{"type": "integer", "minimum": 0, "maximum": maxHouseScore},
+	}
+}
+
+// schemaRashi enumerates the rashi spellings ParseRashi accepts
+func schemaRashi() map[string]any {
+	names := map[string]bool{}
+	for name := range rashiNumbers {
+		names[name] = true
+	}
+	for name := range rashiAliases {
+		names[name] = true
+	}
+	for name := range names {
+		r, size := utf8.DecodeRuneInString(name)
+		names[string(unicode.ToUpper(r))+name[size:]] = true
+	}
+	for num := 1; num <= 12; num++ {
+		names[strconv.Itoa(num)] = true
+	}
+	return map[string]any{"enum": slices.Sorted(maps.Keys(names))}
+}
+
+// schemaEnums returns the values of the enum types, each with the empty
+// string their fields may be left at, except chart types, which are required
+func schemaEnums() map[reflect.Type][]string {
+	chartTypes := []string{string(ChartTypeNorth), string(ChartTypeSouth), string(ChartTypeSarvashtakavarga), string(ChartTypeSuryaKalanala)}
+	layouts.RLock()
+	for name := range layouts.m {
+		chartTypes = append(chartTypes, string(name))
+	}
+	layouts.RUnlock()
+	slices.Sort(chartTypes[4:])
+
+	var categories []string
+	for _, c := range pointCategories {
+		if validCategory(c) {
+			categories = append(categories, string(c))
+		}
+	}
+	values := func(vs ...string) []string { return append([]string{""}, vs...) }
+	return map[reflect.Type][]string{
+		reflect.TypeFor[ChartType]():        chartTypes,
+		reflect.TypeFor[PointCategory]():    values(categories...),
+		reflect.TypeFor[RashiLabelMode]():   values(string(RashiLabelNumber), string(RashiLabelGlyph), string(RashiLabelName), string(RashiLabelSanskritName)),
+		reflect.TypeFor[DisplayMode]():      values(string(DisplayModeAbbreviation), string(DisplayModeFullName), string(DisplayModeLetter)),
+		reflect.TypeFor[StatusStyle]():      values(string(StatusStyleSuffix), string(StatusStyleParenthesized)),
+		reflect.TypeFor[VargottamaStyle]():  values(string(VargottamaStyleBox), string(VargottamaStyleUnderline), string(VargottamaStyleMarker)),
+		reflect.TypeFor[LagnaMarkerStyle](): values(string(LagnaMarkerDoubleSlash), string(LagnaMarkerSingleSlash), string(LagnaMarkerFullDiagonal), string(LagnaMarkerLetter)),
+		reflect.TypeFor[NodeRetrograde]():   values(string(NodeRetrogradeFlagged), string(NodeRetrogradeAlways), string(NodeRetrogradeNever)),
+		reflect.TypeFor[Direction]():        values(string(DirectionCounterClockwise), string(DirectionClockwise)),
+		reflect.TypeFor[PlanetStacking]():   values(string(PlanetStackingVertical), string(PlanetStackingInline)),
+		reflect.TypeFor[NodeStyle]():        values(string(NodeStylePlain), string(NodeStyleItalic), string(NodeStyleShaded)),
+		reflect.TypeFor[DegreeFormat]():     values(string(DegreeFormatDegree), string(DegreeFormatDegreeMinute)),
+	}
+}
+
+// schemaBuilder turns Go types into schemas, the structs into definitions
+type schemaBuilder struct {
+	defs  map[string]any
+	enums map[reflect.Type][]string
+}
+
+// chartInputSchema returns the schema Schema encodes
+func chartInputSchema() map[string]any {
+	b := &schemaBuilder{defs: map[string]any{}, enums: schemaEnums()}
+	root := b.object(reflect.TypeFor[ChartInput]())
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "ChartInput"
+	root["$defs"] = b.defs
+	return root
+}
+
+// object returns the schema of a struct's JSON object
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := b.schema(f.Type)
+		if extra, ok := schemaFields[t.Name()+"."+f.Name]; ok {
+			maps.Copy(s, extra())
+		}
+		properties[name] = s
+	}
+	s := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if required, ok := schemaRequired[t]; ok {
+		s["required"] = required
+	}
+	return s
+}
+
+// schema returns the schema of a value of type t, a reference for a struct
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	if values, ok := b.enums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.Struct:
+		if t == reflect.TypeFor[time.Time]() {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		if _, ok := b.defs[t.Name()]; !ok {
+			b.defs[t.Name()] = nil // Taken before recursing
+			b.defs[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		s := map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+		if t.Key().Kind() != reflect.String {
+			s["propertyNames"] = map[string]any{"pattern": "^-?[0-9]+$"}
+		}
+		return s
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	panic("parashari: no schema for " + t.String())
+}
+
+// FieldError is a value of chart JSON that does not match Schema
+type FieldError struct {
+	// Field is the path of the value, such as "planets.sun.rashi" or
+	// "options.highlight_houses[0].color", empty for the whole document
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// SchemaError lists the values of chart JSON that do not match Schema, in
+// the order of their fields
+type SchemaError struct {
+	Errors []*FieldError
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the fields, for errors.As
+func (e *SchemaError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ValidateChartJSON checks chart JSON against Schema and returns a
+// *SchemaError naming every value that does not match it, or an error when
+// data is not JSON at all. JSON that passes may still fail to draw, such as
+// a planet named in aspect_lines that the chart does not have.
+func ValidateChartJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid chart JSON: %w", err)
+	}
+	// The schema is read back from JSON, so it holds the types doc does
+	var root map[string]any
+	if err := json.Unmarshal(Schema(), &root); err != nil {
+		return err
+	}
+	v := schemaValidator{defs: root["$defs"].(map[string]any)}
+	v.check(root, doc, "")
+	if len(v.errs) > 0 {
+		return &SchemaError{Errors: v.errs}
+	}
+	return nil
+}
+
+// schemaValidator checks values against the keywords Schema uses
+type schemaValidator struct {
+	defs map[string]any
+	errs []*FieldError
+}
+
+func (v *schemaValidator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check checks value, at path, against schema s
+func (v *schemaValidator) check(s map[string]any, value any, path string) {
+	if ref, ok := s["$ref"].(string); ok {
+		v.check(v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), value, path)
+	}
+	if t, ok := s["type"].(string); ok && !schemaTypeMatches(t, value) {
+		v.fail(path, "must be %s %s, not %s", article(t), t, jsonType(value))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, value) {
+		if len(enum) <= 12 {
+			v.fail(path, "%s is not one of %s", jsonText(value), enumText(enum))
+		} else {
+			v.fail(path, "%s is not a known value", jsonText(value))
+		}
+		return
+	}
+	switch value := value.(type) {
+	case string:
+		if pattern, ok := s["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(value) {
+			v.fail(path, "%q does not match %s", value, pattern)
+		}
+		if s["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				v.fail(path, "%q is not an RFC 3339 date-time", value)
+			}
+		}
+	case json.Number:
+		n, _ := value.Float64()
+		if min, ok := s["minimum"].(float64); ok && n < min {
+			v.fail(path, "%s is less than %v", value, min)
+		}
+		if max, ok := s["maximum"].(float64); ok && n > max {
+			v.fail(path, "%s is more than %v", value, max)
+		}
+		if max, ok := s["exclusiveMaximum"].(float64); ok && n >= max {
+			v.fail(path, "%s is not less than %v", value, max)
+		}
+	case []any:
+		if n, ok := s["minItems"].(float64); ok && float64(len(value)) < n {
+			v.fail(path, "has %d items, want at least %v", len(value), n)
+		}
+		if n, ok := s["maxItems"].(float64); ok && float64(len(value)) > n {
+			v.fail(path, "has %d items, want at most %v", len(value), n)
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.check(items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case map[string]any:
+		v.checkObject(s, value, path)
+	}
+}
+
+// checkObject checks the fields of an object, in order of their names
+func (v *schemaValidator) checkObject(s map[string]any, value map[string]any, path string) {
+	field := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+	if required, ok := s["required"].([]any); ok {
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				v.fail(field(name.(string)), "is required")
+			}
+		}
+	}
+	properties, _ := s["properties"].(map[string]any)
+	names, _ := s["propertyNames"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(value)) {
+		if names != nil {
+			v.check(names, name, field(name))
+		}
+		if p, ok := properties[name].(map[string]any); ok {
+			v.check(p, value[name], field(name))
+			continue
+		}
+		switch extra := s["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(field(name), "is not a known field")
+			}
+		case map[string]any:
+			v.check(extra, value[name], field(name))
+		}
+	}
+}
+
+// schemaTypeMatches reports whether a decoded JSON value is of a schema type
+func schemaTypeMatches(t string, value any) bool {
+	switch value := value.(type) {
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case json.Number:
+		if t == "integer" {
+			n, err := value.Float64()
+			return err == nil && n == math.Trunc(n)
+		}
+		return t == "number"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+// jsonType names the type of a decoded JSON value
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	}
+	return "null"
+}
+
+func article(t string) string {
+	if t == "integer" || t == "object" || t == "array" {
+		return "an"
+	}
+	return "a"
+}
+
+// jsonText quotes a decoded JSON string, and prints other values as they are
+func jsonText(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// enumText lists the values of an enum, the empty string left out
+func enumText(enum []any) string {
+	var values []string
+	for _, e := range enum {
+		if e != "" {
+			values = append(values, jsonText(e))
+		}
+	}
+	return strings.Join(values, ", ")
+}
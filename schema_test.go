@@ -0,0 +1,153 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSchema_AcceptsCharts(t *testing.T) {
+	inputs := append(batchInputs(4), categoriesInput(ChartTypeNorth), houseScoresInput(ChartTypeSarvashtakavarga), crowdedHouseInput(ChartTypeSouth))
+	for i, input := range inputs {
+		data, err := json.Marshal(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateChartJSON(data); err != nil {
+			t.Errorf("Input %d: %v", i, err)
+		}
+	}
+}
+
+// TestSchema_EnumsValidate draws on every value the schema enumerates, so
+// the schema offers nothing the charts reject
+func TestSchema_EnumsValidate(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct{ Enum []string }
+		Defs       map[string]struct {
+			Properties map[string]struct{ Enum []string }
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("Schema is not JSON: %v", err)
+	}
+	docs := map[string]string{
+		"":             `{"chart_type": %s, "lagna": {"rashi": "leo"}}`,
+		"ChartOptions": `{"chart_type": "south", "options": {%q: %s}}`,
+		"Planet":       `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", %q: %s}}}`,
+	}
+	check := func(def, field, value string) {
+		v, _ := json.Marshal(value)
+		var doc string
+		if def == "" {
+			doc = fmt.Sprintf(docs[def], v)
+		} else {
+			doc = fmt.Sprintf(docs[def], field, v)
+		}
+		if err := ValidateChartJSON([]byte(doc)); err != nil {
+			t.Errorf("%s.%s %q does not match the schema: %v", def, field, value, err)
+		}
+		var input ChartInput
+		if err := json.Unmarshal([]byte(doc), &input); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateInput(input); err != nil {
+			t.Errorf("%s.%s %q is rejected: %v", def, field, value, err)
+		}
+	}
+	for _, value := range schema.Properties["chart_type"].Enum {
+		check("", "chart_type", value)
+	}
+	for def, s := range schema.Defs {
+		for field, p := range s.Properties {
+			if len(p.Enum) == 0 {
+				continue
+			}
+			if _, ok := docs[def]; !ok {
+				t.Errorf("No test of the enum %s.%s", def, field)
+				continue
+			}
+			for _, value := range p.Enum {
+				check(def, field, value)
+			}
+		}
+	}
+	if rashis := schema.Defs["Planet"].Properties["rashi"].Enum; !slices.Contains(rashis, "Mesha") || !slices.Contains(rashis, "12") {
+		t.Errorf("Rashi spellings %q, want Sanskrit names and numbers", rashis)
+	}
+}
+
+func TestValidateChartJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		field string
+		want  string
+	}{
+		{"no chart type", `{}`, "chart_type", "is required"},
+		{"unknown chart type", `{"chart_type": "east"}`, "chart_type", `"east" is not one of "north", "south"`},
+		{"unknown field", `{"chart_type": "south", "planet": {}}`, "planet", "is not a known field"},
+		{"bad rashi", `{"chart_type": "south", "planets": {"sun": {"rashi": "leon"}}}`, "planets.sun.rashi", `"leon" is not a known value`},
+		{"no rashi", `{"chart_type": "south", "lagna": {"degrees": 4}}`, "lagna.rashi", "is required"},
+		{"degrees", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "degrees": 30}}}`, "planets.sun.degrees", "30 is not less than 30"},
+		{"flag", `{"chart_type": "south", "planets": {"sun": {"rashi": "leo", "is_retrograde": "yes"}}}`, "planets.sun.is_retrograde", "must be a boolean, not a string"},
+		{"color", `{"chart_type": "south", "options": {"highlight_houses": [{"houses": [1], "color": "red"}]}}`, "options.highlight_houses[0].color", `"red" does not match`},
+		{"house", `{"chart_type": "south", "options": {"highlight_houses": [{"houses": [1, 13], "color": "#fff"}]}}`, "options.highlight_houses[0].houses[1]", "13 is more than 12"},
+		{"score house", `{"chart_type": "sarvashtakavarga", "house_scores": {"0": 20}}`, "house_scores.0", "does not match"},
+		{"width", `{"chart_type": "south", "options": {"width": 2.5}}`, "options.width", "must be an integer, not a number"},
+		{"date", `{"chart_type": "south", "native": {"date_of_birth": "yesterday"}}`, "native.date_of_birth", "is not an RFC 3339 date-time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChartJSON([]byte(tt.json))
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("ValidateChartJSON = %v, want a *SchemaError", err)
+			}
+			if len(schemaErr.Errors) != 1 || schemaErr.Errors[0].Field != tt.field || !strings.Contains(schemaErr.Errors[0].Message, tt.want) {
+				t.Errorf("Errors %v, want one for %s mentioning %q", schemaErr.Errors, tt.field, tt.want)
+			}
+		})
+	}
+
+	if err := ValidateChartJSON([]byte(`{"chart_type": `)); err == nil || errors.As(err, new(*SchemaError)) {
+		t.Errorf("ValidateChartJSON of malformed JSON = %v, want a syntax error", err)
+	}
+}
+
+func TestValidateChartJSON_ListsEveryField(t *testing.T) {
+	err := ValidateChartJSON([]byte(`{"chart_type": "south", "planets": {"sun": {"rashi": "leon", "pada": 5}, "moon": {}}}`))
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("ValidateChartJSON = %v, want a *SchemaError", err)
+	}
+	var fields []string
+	for _, e := range schemaErr.Errors {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"planets.moon.rashi", "planets.sun.pada", "planets.sun.rashi"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Errors for %q, want %q", fields, want)
+	}
+	if !strings.Contains(err.Error(), "planets.sun.pada: 5 is more than 4") {
+		t.Errorf("Error %q does not name the field", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// requiredShadbala is the least shadbala, in rupas, each planet needs to be
+// strong, after BPHS. Rahu and Ketu have no shadbala.
+var requiredShadbala = map[string]float64{
+	"sun":     6.5,
+	"moon":    6,
+	"mars":    5,
+	"mercury": 7,
+	"jupiter": 6.5,
+	"venus":   5.5,
+	"saturn":  5,
+}
+
+// RequiredShadbala returns the least shadbala, in rupas, a planet needs to
+// be strong, and false for planets without shadbala such as Rahu and Ketu
+func RequiredShadbala(planet string) (float64, bool) {
+	rupas, ok := requiredShadbala[planet]
+	return rupas, ok
+}
+
+// Bar colors of planets with sufficient and deficient shadbala
+var (
+	strengthGreen = color.NRGBA{R: 76, G: 175, B: 80, A: 255}
+	strengthRed   = color.NRGBA{R: 229, G: 57, B: 53, A: 255}
+)
+
+// validateStrengths checks that strengths are given for planets with a
+// shadbala, as finite, non-negative rupas
+func validateStrengths(input ChartInput) error {
+	for name, rupas := range input.Strengths {
+		if _, ok := requiredShadbala[name]; !ok {
+			if !IsKnownPlanet(name) {
+				return fmt.Errorf("strengths: %w", &ErrUnknownPlanet{Name: name})
+			}
+			return fmt.Errorf("strengths: %q has no shadbala", name)
+		}
+		if rupas < 0 || math.IsNaN(rupas) || math.IsInf(rupas, 0) {
+			return fmt.Errorf("strengths: %v rupas for %s out of range", rupas, name)
+		}
+	}
+	return nil
+}
+
+// strengthBar is one planet's row of the strength bars
+type strengthBar struct {
+	planet   string
+	label    string
+	rupas    float64
+	required float64
+}
+
+// strengthBars is the panel of shadbala bars, one row per planet with a
+// strength, in the order of the grahas
+type strengthBars []strengthBar
+
+// newStrengthBars returns the bars of the input's strengths, or nil when it
+// has none
+func newStrengthBars(input ChartInput) strengthBars {
+	var bars strengthBars
+	loc := localeFor(input.Options)
+	for _, name := range grahas {
+		rupas, ok := input.Strengths[name]
+		required, hasShadbala := requiredShadbala[name]
+		if !ok || !hasShadbala {
+			continue
+		}
+		bars = append(bars, strengthBar{
+			planet:   name,
+			label:    loc.displayName(name, input.Planets[name], input.Options.DisplayMode),
+			rupas:    rupas,
+			required: required,
+		})
+	}
+	return bars
+}
+
+// Sizes of the strength bars, for a chart of defaultChartSize
+const (
+	strengthRowHeight = 26
+	strengthBarHeight = 16
+	strengthFontSize  = 16
+	strengthMargin    = 16 // Above and below the rows
+)
+
+func (s strengthBars) side() panelSide { return panelBelow }
+
+func (s strengthBars) extent(frame chartFrame) float64 {
+	return frame.px(float64(len(s))*strengthRowHeight + 2*strengthMargin)
+}
+
+// draw draws a bar per planet from a label column on the left, its length
+// the planet's shadbala relative to its required minimum, which a gray line
+// marks across all rows. Each row ends with its rupas and the minimum.
+func (s strengthBars) draw(dc *gg.Context, frame chartFrame, _, top, _, _ float64, boxes *chartBoxes) {
+	padding := frame.px(40) // Aligned with the chart's grid
+	size := frame.px(strengthFontSize)
+	face := embeddedFace(matangiBold, size)
+	valueFace := embeddedFace(matangiRegular, size)
+	m := boldMetrics(size)
+	left, right := frame.x+padding, frame.x+frame.width-padding
+	barLeft, barRight := left+frame.px(56), right-frame.px(96)
+
+	// Minimums line up at two thirds of the bar width, unless a planet is
+	// stronger than 1.5 times its minimum
+	span := 1.5
+	for _, b := range s {
+		span = math.Max(span, b.rupas/b.required)
+	}
+	unit := (barRight - barLeft) / span
+
+	rowTop := top + frame.px(strengthMargin)
+	for _, b := range s {
+		centerY := rowTop + frame.px(strengthRowHeight)/2
+		baseline := m.baseline(centerY)
+
+		drawText(dc, face, textBlack, b.label, left, baseline, 0)
+		w := float64(font.MeasureString(face, b.label)) / 64
+		boxes.add(textBox{text: b.label, kind: labelStrength, planet: b.planet,
+			left: left, top: baseline - m.capHeight, right: left + w, bottom: baseline + m.descent})
+
+		barColor := strengthGreen
+		if b.rupas < b.required {
+			barColor = strengthRed
+		}
+		dc.DrawRectangle(barLeft, centerY-frame.px(strengthBarHeight)/2, b.rupas/b.required*unit, frame.px(strengthBarHeight))
+		dc.SetColor(barColor)
+		dc.Fill()
+
+		value := strconv.FormatFloat(b.rupas, 'f', 2, 64) + " / " + strconv.FormatFloat(b.required, 'f', 1, 64)
+		drawText(dc, valueFace, houseNumberGray, value, right, baseline, 1)
+		w = float64(font.MeasureString(valueFace, value)) / 64
+		boxes.add(textBox{text: value, kind: labelStrength, planet: b.planet,
+			left: right - w, top: baseline - m.capHeight, right: right, bottom: baseline + m.descent})
+		rowTop += frame.px(strengthRowHeight)
+	}
+
+	// The required minimum, the same for every row relative to its planet
+	dc.SetRGB(0.4, 0.4, 0.4)
+	dc.SetLineWidth(frame.px(1))
+	dc.DrawLine(barLeft+unit, top+frame.px(strengthMargin)/2, barLeft+unit, rowTop+frame.px(strengthMargin)/2)
+	dc.Stroke()
+}
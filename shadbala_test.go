@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"strings"
+	"testing"
+)
+
+// strengthsInput returns the house scores chart with shadbala for every
+// planet but Mercury, Mars and Saturn short of their minimums
+func strengthsInput(chartType ChartType) ChartInput {
+	input := houseScoresInput(chartType)
+	input.HouseScores, input.SecondaryHouseScores = nil, nil
+	input.Options = ChartOptions{}
+	input.Strengths = map[string]float64{
+		"sun": 7.12, "moon": 6.4, "mars": 4.3, "jupiter": 8.05, "venus": 5.9, "saturn": 4.81,
+	}
+	return input
+}
+
+func TestGenerateChart_Strengths(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		data, layout, err := GenerateChartWithLayout(strengthsInput(chartType))
+		if err != nil {
+			t.Fatalf("Error generating %s chart: %v", chartType, err)
+		}
+		assertGolden(t, string(chartType)+"_strengths", data)
+
+		if layout.Width != defaultChartSize || layout.Height <= defaultChartSize {
+			t.Errorf("%s: canvas %dx%d, want %d wide and taller than the chart", chartType, layout.Width, layout.Height, defaultChartSize)
+		}
+		var planets []string
+		for _, l := range layout.Labels {
+			if l.Kind == string(labelStrength) && l.Top < defaultChartSize {
+				t.Errorf("%s: strength label %q drawn over the chart", chartType, l.Text)
+			}
+			if l.Kind == string(labelStrength) && !strings.Contains(l.Text, "/") {
+				planets = append(planets, l.Text)
+			}
+		}
+		// Mercury has no strength and is skipped, the rest follow the graha order
+		if got := strings.Join(planets, " "); got != "Su Mo Ma Ju Ve Sa" {
+			t.Errorf("%s: bar labels %q, want %q", chartType, got, "Su Mo Ma Ju Ve Sa")
+		}
+	}
+}
+
+func TestGenerateChart_StrengthsThumbnail(t *testing.T) {
+	input := strengthsInput(ChartTypeSouth)
+	input.Options.Thumbnail = true
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating thumbnail: %v", err)
+	}
+	if layout.Height != thumbnailSize {
+		t.Errorf("thumbnail height %d, want %d without strength bars", layout.Height, thumbnailSize)
+	}
+}
+
+func TestGenerateChart_StrengthsInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		strengths map[string]float64
+		want      string
+	}{
+		{"node", map[string]float64{"rahu": 5}, `strengths: "rahu" has no shadbala`},
+		{"unknown", map[string]float64{"pluto": 5}, `strengths: unknown planet "pluto"`},
+		{"negative", map[string]float64{"sun": -1}, "strengths: -1 rupas for sun out of range"},
+	}
+	for _, tt := range tests {
+		input := ChartInput{ChartType: ChartTypeSouth, Strengths: tt.strengths}
+		_, err := GenerateChart(input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRequiredShadbala(t *testing.T) {
+	if rupas, ok := RequiredShadbala("mercury"); !ok || rupas != 7 {
+		t.Errorf("RequiredShadbala(mercury) = %v, %v, want 7, true", rupas, ok)
+	}
+	if _, ok := RequiredShadbala("ketu"); ok {
+		t.Error("RequiredShadbala(ketu) found a minimum")
+	}
+}
@@ -18,119 +18,418 @@ package parashari
 import (
 	"fmt"
 	"image"
-	"strings"
+	"math"
+	"strconv"
 
 	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
 )
 
 // GenerateSouthChart generates a South Indian style chart
 // Houses are fixed, rashis rotate based on Lagna
 func GenerateSouthChart(input ChartInput) ([]byte, error) {
-	const size = 800
-	const padding = 40
-	const gridSize = size - 2*padding
+	if err := input.Options.checkCanvasSize(); err != nil {
+		return nil, err
+	}
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	img, err := renderSouthChart(r, NormalizeChartInput(input), nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.encode(img)
+}
+
+// renderSouthChart draws a South Indian style chart on the renderer's
+// canvas, recording the box of every label it places in boxes when that is
+// not nil
+func renderSouthChart(r *renderer, input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	if input.Options.Thumbnail {
+		return renderSouthThumbnail(r, input, boxes)
+	}
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	frame.contrast = input.Options.HighContrast
+	padding := frame.px(40)
+	gridLeft, gridTop := frame.x+padding, frame.y+padding
+
+	// Calculate cell size (4x4 grid = 16 cells, but we use 12 houses around perimeter)
+	// Cells are square unless the grid is stretched to the canvas
+	cellW := (frame.width - 2*padding) / 4
+	cellH := (frame.height - 2*padding) / 4
+
+	// Find Lagna rashi
+	// For South Indian charts, rashi numbers are FIXED positions:
+	// 1=Aries, 2=Taurus, 3=Gemini, ..., 8=Scorpio, ..., 12=Pisces
+	// These numbers don't change - they're always in the same positions
+	// If lagna not provided, default to Aries
+	lagnaRashi := chartLagnaRashi(input)
+
+	// House positions as rectangles (arranged around perimeter)
+	houseRects := southHouseRects(gridLeft, gridTop, cellW, cellH)
+
+	// Background: highlighted houses, whose numbers count from lagna, so
+	// find the rashi (and fixed cell) of each house
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, houseFills(input), func(house int) []gg.Point {
+		rashiNum := (lagnaRashi+house-2)%12 + 1
+		return rectPolygon(houseRects[southRashiCell(rashiNum, lagnaRashi, input.Options.RotateToLagna)])
+	})
+
+	// Content: the grid, nodal axis, aspect lines, labels and center text
+	drawSouthGrid(dc, gridLeft, gridTop, cellW, cellH, frame)
+	centroid := func(house int) gg.Point {
+		rashiNum := (lagnaRashi+house-2)%12 + 1
+		rect := houseRects[southRashiCell(rashiNum, lagnaRashi, input.Options.RotateToLagna)]
+		return gg.Point{X: float64(rect.Min.X+rect.Max.X) / 2, Y: float64(rect.Min.Y+rect.Max.Y) / 2}
+	}
+	drawNodalAxis(dc, input, frame, lagnaRashi, centroid)
+	drawAspectLines(dc, input, frame, lagnaRashi, centroid)
+
+	// Resolve the status markers once, they are checked against the planet font
+	labels := newLabelFormat(input.Options).withConjunctions(input).withKarakas(input).withDistinctNames(input)
+	if input.Lagna != nil {
+		labels = labels.withLagna(lagnaRashi)
+	}
+
+	// Draw rashi numbers and planets in each house
+	dc.SetRGB(0, 0, 0)
+	// Load Matangi font for rashi numbers from embedded data
+	numberSize, planetSize, houseNumberSize, scoreSize := frame.font(16), frame.font(22), frame.font(12), frame.font(13)
+	loadMatangiRegular(dc, numberSize)
+	numberMetrics := regularMetrics(numberSize)
+	planetMetrics := boldMetrics(planetSize)
+	var legible legibility
+	legible.use(numberSize)
+	if input.Options.ShowHouseNumbers || input.Options.ShowHouseLords {
+		legible.use(houseNumberSize)
+	}
+	if input.Options.ShowHouseScores {
+		legible.use(scoreSize)
+	}
+
+	// STEP 1-12: Draw all 12 Houses
+	// In South Indian charts, rashi numbers are FIXED positions:
+	// Position 1 = Aries (1), Position 2 = Taurus (2), ..., Position 8 = Scorpio (8), etc.
+	// These numbers never change - they're always in the same positions,
+	// unless the chart is rotated to put the lagna in position 1
+	// The house slices are reused from house to house.
+	occupants := planetsByHouse(input)
+	var entries, mainLane, sideLane []planetEntry
+	for houseNum := 1; houseNum <= 12; houseNum++ {
+		rect := houseRects[houseNum]
+		rashiNum := southCellRashi(houseNum, lagnaRashi, input.Options.RotateToLagna)
+
+		// Position text in bottom-right of the rectangle, sitting on a baseline
+		// that leaves the font's descent clear of the bottom border
+		textX := float64(rect.Max.X) - frame.px(10)
+		textY := float64(rect.Max.Y) - numberMetrics.descent
+
+		// Keep long rashi names on the canvas
+		numberBox := rashiLabelBox(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		dx, dy := numberBox.shiftInto(canvasW, canvasH)
+		textX, textY = textX+dx, textY+dy
+		numberBox = rashiLabelBox(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		numberBox.house, numberBox.kind = houseNum, labelRashi
+
+		// Ensure rashi number is drawn in black
+		dc.SetRGB(0, 0, 0)
+		// Draw rashi label (anchored to bottom-right)
+		drawRashiLabel(dc, input.Options, rashiNum, textX, textY, 1.0, 0.0, numberSize)
+		fixed := []textBox{numberBox}
+
+		// Draw house number (counted from lagna) in small gray text at top-left
+		if input.Options.ShowHouseNumbers {
+			loadMatangiRegular(dc, houseNumberSize)
+			houseStr := strconv.Itoa(HouseFromLagna(rashiNum, lagnaRashi))
+			houseX, houseTop := float64(rect.Min.X)+frame.px(6), float64(rect.Min.Y)+frame.px(6)
+			houseCap := regularMetrics(houseNumberSize).capHeight
+			drawText(dc, embeddedFace(matangiRegular, houseNumberSize), frame.ink(houseNumberGray), houseStr, houseX, houseTop+houseCap, 0)
+			w, _ := dc.MeasureString(houseStr)
+			fixed = append(fixed, textBox{text: houseStr, house: houseNum, kind: labelHouseNumber, left: houseX, top: houseTop, right: houseX + w, bottom: houseTop + houseCap})
+			loadMatangiRegular(dc, numberSize)
+		}
+		// The rashi's lord sits in small gray text at top-right
+		if input.Options.ShowHouseLords {
+			lord := houseLordBox(labels.locale, rashiNum, houseNum, float64(rect.Max.X)-frame.px(6), float64(rect.Min.Y)+frame.px(6), 1, houseNumberSize)
+			drawHouseLord(dc, lord, houseNumberSize, frame.ink(houseNumberGray))
+			fixed = append(fixed, lord)
+		}
+		// Scores sit at the bottom center, their last row level with the rashi label
+		if rows := houseScoreRows(input, rashiNum); len(rows) > 0 {
+			centerX := float64(rect.Min.X+rect.Max.X) / 2
+			fixed = append(fixed, drawHouseScores(dc, rows, houseNum, centerX, textY-scoreRowsHeight(len(rows), scoreSize), scoreSize, frame.ink(scoreColor))...)
+		}
+		boxes.add(fixed...)
+		boxes.addHouse(houseNum, rashiNum, rectPolygon(rect))
+
+		// Mark the lagna rashi position with diagonals across its bottom-left corner
+		if input.Lagna != nil && lagnaRashi > 0 && rashiNum == lagnaRashi {
+			marker := drawLagnaMarker(dc, rect, input.Options.LagnaMarkerStyle, numberSize)
+			for i := range marker {
+				marker[i].house, marker[i].kind = houseNum, labelMarker
+			}
+			fixed = append(fixed, marker...)
+			boxes.add(marker...)
+		}
+
+		// Collect the lagna, planets, upagrahas and other points in this
+		// house based on their Rashi, then list each in its category's lane
+		entries = entries[:0]
+		if input.Lagna != nil && lagnaRashi > 0 && rashiNum == lagnaRashi {
+			// Lagna is never retrograde or combust (it's a point, not a planet)
+			if entry, ok := labels.lagnaEntry(input.Lagna); ok {
+				entries = append(entries, entry)
+			}
+		}
+		for _, planetName := range occupants[HouseFromLagna(rashiNum, lagnaRashi)] {
+			entries = append(entries, labels.entry(planetName, input.Planets[planetName]))
+		}
+		mainLane, sideLane = splitLanes(entries, mainLane[:0], sideLane[:0])
+
+		// Draw planets in top center of the box, planets on the left and special
+		// lagnas on the right, shrinking or wrapping them to fit above the rashi number
+		centerX := float64(rect.Min.X+rect.Max.X) / 2 // Center horizontally
+		// The first row's capitals start half a cap height below the top
+		firstRowY := float64(rect.Min.Y) + planetMetrics.capHeight/2 + planetMetrics.lineHeight()/2
+		// Regular planets end left of center, special lagnas start right of it
+		anchor := newHouseAnchor(input.Options, frame, centerX-frame.px(25), centerX+frame.px(25), firstRowY, planetSize)
+		// Full names are too wide to sit side by side
+		anchor.oneColumn = labels.mode == DisplayModeFullName
+		// Planets stay above the rashi number, with a descent's gap between them
+		numberTop := textY - numberMetrics.capHeight
+		region := rectRegion(float64(rect.Min.X), float64(rect.Min.Y), float64(rect.Max.X), numberTop-numberMetrics.descent).avoiding(fixed...)
+		mainLane = labels.groupConjunctions(mainLane)
+		layout := layoutHouse(dc, mainLane, sideLane, anchor, region, labels.fontSize(planetSize, len(entries)))
+		layout = clampLayout(dc, layout, canvasW, canvasH)
+		legible.useLayout(layout)
+		boxes.add(houseBoxes(dc, layout, houseNum)...)
+		labels.drawHouse(dc, layout)
+
+		// Reset font back to smaller size for rashi numbers
+		loadMatangiRegular(dc, numberSize)
+	}
+	if err := legible.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+
+	// Draw center text if provided, wrapped and shrunk to fit the 4 empty
+	// squares in the middle, or else the native's details or the panchanga
+	// there
+	switch {
+	case nativeInCenter(input):
+		if err := drawSouthItems(dc, labelNative, input.Native.items(localeFor(input.Options)), gridLeft, gridTop, cellW, cellH, frame, boxes); err != nil {
+			return nil, err
+		}
+	case panchangaInCenter(input):
+		if err := drawSouthItems(dc, labelPanchanga, input.Panchanga.items(), gridLeft, gridTop, cellW, cellH, frame, boxes); err != nil {
+			return nil, err
+		}
+	default:
+		if err := drawSouthCenterText(dc, input, gridLeft, gridTop, cellW, cellH, frame, boxes); err != nil {
+			return nil, err
+		}
+	}
+
+	return dc.Image(), nil
+}
+
+// drawSouthCenterText draws the center text of input in the 4 empty squares
+// in the middle of a South grid, wrapped and shrunk to fit them with a small
+// margin
+func drawSouthCenterText(dc *gg.Context, input ChartInput, gridLeft, gridTop, cellW, cellH float64, frame chartFrame, boxes *chartBoxes) error {
+	lines, field := centerLines(input)
+	if len(lines) == 0 {
+		return nil
+	}
+	margin := frame.px(10)
+	left := gridLeft + cellW + margin
+	top := gridTop + cellH + margin
+	width := 2*cellW - 2*margin
+	height := 2*cellH - 2*margin
+
+	layout, err := layoutCenterText(dc, lines, width, height, frame.scale)
+	if err != nil {
+		return fmt.Errorf("%s %w", field, err)
+	}
+
+	// Center the block of rows vertically, each row horizontally
+	y := top + (height-layout.height())/2
+	for _, row := range layout.rows {
+		m := row.metrics()
+		if row.text != "" { // Skip empty lines
+			face := embeddedFace(row.face, row.size)
+			x, baseline := left+width/2, m.baseline(y+m.lineHeight()/2)
+			drawText(dc, face, row.color, row.text, x, baseline, 0.5)
+			w := float64(font.MeasureString(face, row.text)) / 64
+			boxes.add(textBox{text: row.text, kind: labelCenterText, left: x - w/2, top: baseline - m.capHeight, right: x + w/2, bottom: baseline + m.descent})
+		}
+		y += m.lineHeight()
+	}
+	return nil
+}
+
+// rectPolygon returns the corners of a house rectangle, clockwise from the
+// top left
+func rectPolygon(rect image.Rectangle) []gg.Point {
+	l, t, r, b := float64(rect.Min.X), float64(rect.Min.Y), float64(rect.Max.X), float64(rect.Max.Y)
+	return []gg.Point{{X: l, Y: t}, {X: r, Y: t}, {X: r, Y: b}, {X: l, Y: b}}
+}
 
-	dc := gg.NewContext(size, size)
-	dc.SetRGB(1, 1, 1) // White background
-	dc.Clear()
+// southCellRashi returns the rashi drawn in a South chart cell, where cells
+// are numbered by the rashi they hold in the classic layout. Rotated to the
+// lagna, the lagna's rashi takes cell 1 and the others follow it in order.
+func southCellRashi(cell, lagnaRashi int, rotateToLagna bool) int {
+	if !rotateToLagna {
+		return cell
+	}
+	return (cell-1+lagnaRashi-1)%12 + 1
+}
+
+// southRashiCell returns the South chart cell a rashi is drawn in, the
+// inverse of southCellRashi
+func southRashiCell(rashiNum, lagnaRashi int, rotateToLagna bool) int {
+	if !rotateToLagna {
+		return rashiNum
+	}
+	return (rashiNum-lagnaRashi+12)%12 + 1
+}
 
+// southHouseRects returns the cell of every house position on a grid whose
+// top-left corner is at gridLeft, gridTop
+// Top row: 12 (left), 1 (left-center), 2 (right-center), 3 (right corner)
+// Right side: 3 (corner), 4 (top), 5 (middle), 6 (bottom corner)
+// Bottom row: 6 (corner), 7 (right-center), 8 (left-center), 9 (left corner)
+// Left side: 9 (corner), 10 (bottom), 11 (middle), 12 (top corner)
+func southHouseRects(gridLeft, gridTop, cellW, cellH float64) map[int]image.Rectangle {
+	cell := func(col, row float64) image.Rectangle {
+		return image.Rect(
+			int(math.Round(gridLeft+col*cellW)), int(math.Round(gridTop+row*cellH)),
+			int(math.Round(gridLeft+(col+1)*cellW)), int(math.Round(gridTop+(row+1)*cellH)),
+		)
+	}
+	return map[int]image.Rectangle{
+		// Top row (left to right)
+		12: cell(0, 0), // Top-left corner
+		1:  cell(1, 0), // Top left-center
+		2:  cell(2, 0), // Top right-center
+		3:  cell(3, 0), // Top-right corner
+
+		// Right side (top to bottom, excluding corners)
+		4: cell(3, 1), // Right top
+		5: cell(3, 2), // Right middle
+		// House 6 is bottom-right corner (shared with bottom row)
+
+		// Bottom row (right to left)
+		6: cell(3, 3), // Bottom-right corner
+		7: cell(2, 3), // Bottom right-center
+		8: cell(1, 3), // Bottom left-center
+		9: cell(0, 3), // Bottom-left corner
+
+		// Left side (bottom to top, excluding corners)
+		10: cell(0, 2), // Left bottom
+		11: cell(0, 1), // Left middle
+		// House 12 is top-left corner (already defined above)
+	}
+}
+
+// drawSouthGrid strokes the outer square and the lines between the twelve
+// houses, leaving the four center cells open
+func drawSouthGrid(dc *gg.Context, gridLeft, gridTop, cellW, cellH float64, frame chartFrame) {
 	// Draw outer square
 	dc.SetRGB(0, 0, 0) // Black lines
-	dc.SetLineWidth(2)
-	dc.DrawRectangle(float64(padding), float64(padding), float64(gridSize), float64(gridSize))
+	dc.SetLineWidth(frame.line(2))
+	dc.DrawRectangle(gridLeft, gridTop, 4*cellW, 4*cellH)
 	dc.Stroke()
 
-	// Calculate cell size (4x4 grid = 16 cells, but we use 12 houses around perimeter)
-	cellSize := float64(gridSize) / 4
-
 	// STEP 1, 2, 3 & 4: Draw Houses 1-4
 	// Top row: House 1 (Aries), House 2 (Taurus), House 3 (Gemini)
 	// Right side: House 3 (corner), House 4 (Cancer) below House 3
 
-	dc.SetLineWidth(1)
+	dc.SetLineWidth(frame.line(1))
 
 	// Draw the boundaries for top row houses
 	// Left edge: vertical line at x = padding + cellSize (from top to first horizontal line)
-	x1 := float64(padding) + cellSize
-	dc.DrawLine(x1, float64(padding), x1, float64(padding)+cellSize)
+	x1 := gridLeft + cellW
+	dc.DrawLine(x1, gridTop, x1, gridTop+cellH)
 	dc.Stroke()
 
 	// Right edge of House 1 (also left edge of House 2): vertical line at x = padding + 2*cellSize
-	x2 := float64(padding) + 2*cellSize
-	dc.DrawLine(x2, float64(padding), x2, float64(padding)+cellSize)
+	x2 := gridLeft + 2*cellW
+	dc.DrawLine(x2, gridTop, x2, gridTop+cellH)
 	dc.Stroke()
 
 	// Right edge of House 2 (also left edge of House 3): vertical line at x = padding + 3*cellSize
-	x3 := float64(padding) + 3*cellSize
+	x3 := gridLeft + 3*cellW
 	// Top part: from top to first horizontal line (House 3)
-	dc.DrawLine(x3, float64(padding), x3, float64(padding)+cellSize)
+	dc.DrawLine(x3, gridTop, x3, gridTop+cellH)
 	dc.Stroke()
 	// Bottom part: from first horizontal line to second horizontal line (left edge of House 4)
-	dc.DrawLine(x3, float64(padding)+cellSize, x3, float64(padding)+2*cellSize)
+	dc.DrawLine(x3, gridTop+cellH, x3, gridTop+2*cellH)
 	dc.Stroke()
 
 	// Right edge of House 3 (also right edge of Houses 4, 5, and 6): vertical line at x = padding + 4*cellSize (outer edge)
 	// This is the right side of the chart, so draw from top to bottom
-	x4 := float64(padding) + 4*cellSize
+	x4 := gridLeft + 4*cellW
 	// Top part: from top to first horizontal line (House 3)
-	dc.DrawLine(x4, float64(padding), x4, float64(padding)+cellSize)
+	dc.DrawLine(x4, gridTop, x4, gridTop+cellH)
 	dc.Stroke()
 	// Middle part: from first horizontal line to bottom (Houses 4, 5, and 6)
-	dc.DrawLine(x4, float64(padding)+cellSize, x4, float64(padding)+4*cellSize)
+	dc.DrawLine(x4, gridTop+cellH, x4, gridTop+4*cellH)
 	dc.Stroke()
 
 	// Left edge of House 5: extend x3 line down to third horizontal line
-	dc.DrawLine(x3, float64(padding)+2*cellSize, x3, float64(padding)+3*cellSize)
+	dc.DrawLine(x3, gridTop+2*cellH, x3, gridTop+3*cellH)
 	dc.Stroke()
 
 	// Left edge of House 6: extend x3 line down to bottom
-	dc.DrawLine(x3, float64(padding)+3*cellSize, x3, float64(padding)+4*cellSize)
+	dc.DrawLine(x3, gridTop+3*cellH, x3, gridTop+4*cellH)
 	dc.Stroke()
 
 	// Left edge of House 7 (also right edge of House 8): vertical line at x = padding + 2*cellSize (from third horizontal line to bottom)
-	x2Bottom := float64(padding) + 2*cellSize
-	dc.DrawLine(x2Bottom, float64(padding)+3*cellSize, x2Bottom, float64(padding)+4*cellSize)
+	x2Bottom := gridLeft + 2*cellW
+	dc.DrawLine(x2Bottom, gridTop+3*cellH, x2Bottom, gridTop+4*cellH)
 	dc.Stroke()
 
 	// Left edge of House 8 (also right edge of House 9): vertical line at x = padding + cellSize (from third horizontal line to bottom)
-	x1Bottom := float64(padding) + cellSize
-	dc.DrawLine(x1Bottom, float64(padding)+3*cellSize, x1Bottom, float64(padding)+4*cellSize)
+	x1Bottom := gridLeft + cellW
+	dc.DrawLine(x1Bottom, gridTop+3*cellH, x1Bottom, gridTop+4*cellH)
 	dc.Stroke()
 
 	// Left edge of House 9: vertical line at x = padding (from third horizontal line to bottom)
 	// This is the left edge of the chart, already part of outer square, but we need the bottom part
-	x0Bottom := float64(padding)
-	dc.DrawLine(x0Bottom, float64(padding)+3*cellSize, x0Bottom, float64(padding)+4*cellSize)
+	x0Bottom := gridLeft
+	dc.DrawLine(x0Bottom, gridTop+3*cellH, x0Bottom, gridTop+4*cellH)
 	dc.Stroke()
 
 	// Left edge of House 10: vertical line at x = padding (from second horizontal line to third horizontal line)
 	// This is the left edge of the chart, extend upward
-	dc.DrawLine(x0Bottom, float64(padding)+2*cellSize, x0Bottom, float64(padding)+3*cellSize)
+	dc.DrawLine(x0Bottom, gridTop+2*cellH, x0Bottom, gridTop+3*cellH)
 	dc.Stroke()
 
 	// Left edge of House 11: vertical line at x = padding (from first horizontal line to second horizontal line)
 	// This is the left edge of the chart, extend further upward
-	dc.DrawLine(x0Bottom, float64(padding)+cellSize, x0Bottom, float64(padding)+2*cellSize)
+	dc.DrawLine(x0Bottom, gridTop+cellH, x0Bottom, gridTop+2*cellH)
 	dc.Stroke()
 
 	// Left edge of House 12: vertical line at x = padding (from top to first horizontal line)
 	// This is the left edge of the chart, top-left corner
-	dc.DrawLine(x0Bottom, float64(padding), x0Bottom, float64(padding)+cellSize)
+	dc.DrawLine(x0Bottom, gridTop, x0Bottom, gridTop+cellH)
 	dc.Stroke()
 
 	// Right edge of House 10 (also right edge of House 11): vertical line at x = padding + cellSize (from first horizontal line to third horizontal line)
 	// This is also the left edge of House 12 and right edge of House 1
-	dc.DrawLine(x1Bottom, float64(padding)+cellSize, x1Bottom, float64(padding)+3*cellSize)
+	dc.DrawLine(x1Bottom, gridTop+cellH, x1Bottom, gridTop+3*cellH)
 	dc.Stroke()
 
 	// Right edge of House 12: vertical line at x = padding + cellSize (from top to first horizontal line)
 	// This is also the left edge of House 1
-	dc.DrawLine(x1Bottom, float64(padding), x1Bottom, float64(padding)+cellSize)
+	dc.DrawLine(x1Bottom, gridTop, x1Bottom, gridTop+cellH)
 	dc.Stroke()
 
 	// Top edge: already part of outer square
 	// Bottom edge of top row: horizontal line at y = padding + cellSize (from left edge of House 1 to right edge)
-	y1 := float64(padding) + cellSize
+	y1 := gridTop + cellH
 	// Right part: from x1 to x4 (bottom edge of top row houses 1, 2, 3)
 	dc.DrawLine(x1, y1, x4, y1)
 	dc.Stroke()
@@ -139,7 +438,7 @@ func GenerateSouthChart(input ChartInput) ([]byte, error) {
 	dc.Stroke()
 
 	// Bottom edge of House 4: horizontal line at y = padding + 2*cellSize (from left edge to right edge of House 4)
-	y2 := float64(padding) + 2*cellSize
+	y2 := gridTop + 2*cellH
 	// Right part: from x3 to x4 (bottom edge of House 4)
 	dc.DrawLine(x3, y2, x4, y2)
 	dc.Stroke()
@@ -149,7 +448,7 @@ func GenerateSouthChart(input ChartInput) ([]byte, error) {
 
 	// Top edge of Houses 7, 8, and 9 (also bottom edge of House 5 and House 10): horizontal line at y = padding + 3*cellSize
 	// This line goes from left edge of House 9 to right edge (separating House 5 from Houses 7, 8, and 9, and House 10 from House 9)
-	y3 := float64(padding) + 3*cellSize
+	y3 := gridTop + 3*cellH
 	// Left part: from x0Bottom to x1Bottom (top edge of House 9, bottom edge of House 10)
 	dc.DrawLine(x0Bottom, y3, x1Bottom, y3)
 	dc.Stroke()
@@ -165,216 +464,7 @@ func GenerateSouthChart(input ChartInput) ([]byte, error) {
 
 	// Bottom edge of Houses 6, 7, 8, and 9: horizontal line at y = padding + 4*cellSize (from left edge of House 9 to right edge of House 6)
 	// This is the bottom of the chart, already part of outer square
-	y4 := float64(padding) + 4*cellSize
+	y4 := gridTop + 4*cellH
 	dc.DrawLine(x0Bottom, y4, x4, y4)
 	dc.Stroke()
-
-	// Find Lagna rashi
-	// For South Indian charts, rashi numbers are FIXED positions:
-	// 1=Aries, 2=Taurus, 3=Gemini, ..., 8=Scorpio, ..., 12=Pisces
-	// These numbers don't change - they're always in the same positions
-	var lagnaRashi int
-
-	// Get lagna rashi from input parameter
-	if input.Lagna != nil {
-		lagnaRashi = RashiToNumber(input.Lagna.Rashi)
-	}
-
-	// If lagna not provided or invalid, default to Aries
-	if lagnaRashi == 0 {
-		lagnaRashi = 1
-	}
-
-	// House positions as rectangles (arranged around perimeter)
-	// Top row: 12 (left), 1 (left-center), 2 (right-center), 3 (right corner)
-	// Right side: 3 (corner), 4 (top), 5 (middle), 6 (bottom corner)
-	// Bottom row: 6 (corner), 7 (right-center), 8 (left-center), 9 (left corner)
-	// Left side: 9 (corner), 10 (bottom), 11 (middle), 12 (top corner)
-	houseRects := map[int]image.Rectangle{
-		// Top row (left to right)
-		12: image.Rect(int(padding), int(padding), int(padding+cellSize), int(padding+cellSize)),                   // Top-left corner
-		1:  image.Rect(int(padding)+int(cellSize), int(padding), int(padding+2*cellSize), int(padding+cellSize)),   // Top left-center
-		2:  image.Rect(int(padding)+int(2*cellSize), int(padding), int(padding+3*cellSize), int(padding+cellSize)), // Top right-center
-		3:  image.Rect(int(padding)+int(3*cellSize), int(padding), int(padding+4*cellSize), int(padding+cellSize)), // Top-right corner
-
-		// Right side (top to bottom, excluding corners)
-		4: image.Rect(int(padding)+int(3*cellSize), int(padding)+int(cellSize), int(padding+4*cellSize), int(padding+2*cellSize)),   // Right top
-		5: image.Rect(int(padding)+int(3*cellSize), int(padding)+int(2*cellSize), int(padding+4*cellSize), int(padding+3*cellSize)), // Right middle
-		// House 6 is bottom-right corner (shared with bottom row)
-
-		// Bottom row (right to left)
-		6: image.Rect(int(padding)+int(3*cellSize), int(padding)+int(3*cellSize), int(padding+4*cellSize), int(padding+4*cellSize)), // Bottom-right corner
-		7: image.Rect(int(padding)+int(2*cellSize), int(padding)+int(3*cellSize), int(padding+3*cellSize), int(padding+4*cellSize)), // Bottom right-center
-		8: image.Rect(int(padding)+int(cellSize), int(padding)+int(3*cellSize), int(padding+2*cellSize), int(padding+4*cellSize)),   // Bottom left-center
-		9: image.Rect(int(padding), int(padding)+int(3*cellSize), int(padding+cellSize), int(padding+4*cellSize)),                   // Bottom-left corner
-
-		// Left side (bottom to top, excluding corners)
-		10: image.Rect(int(padding), int(padding)+int(2*cellSize), int(padding+cellSize), int(padding+3*cellSize)), // Left bottom
-		11: image.Rect(int(padding), int(padding)+int(cellSize), int(padding+cellSize), int(padding+2*cellSize)),   // Left middle
-		// House 12 is top-left corner (already defined above)
-	}
-
-	// Draw rashi numbers and planets in each house
-	dc.SetRGB(0, 0, 0)
-	// Load Matangi font for rashi numbers from embedded data
-	loadMatangiRegular(dc, 16)
-
-	// STEP 1-12: Draw all 12 Houses
-	// In South Indian charts, rashi numbers are FIXED positions:
-	// Position 1 = Aries (1), Position 2 = Taurus (2), ..., Position 8 = Scorpio (8), etc.
-	// These numbers never change - they're always in the same positions
-	for houseNum := 1; houseNum <= 12; houseNum++ {
-		rect := houseRects[houseNum]
-		// Rashi number is always the same as the position (1-12)
-		// Position 1 = Aries (1), Position 2 = Taurus (2), etc.
-		rashiNum := houseNum
-
-		// Draw rashi number (no L marker) - always display the rashi number
-		rashiStr := fmt.Sprintf("%d", rashiNum)
-
-		// Position text in bottom-right of the rectangle
-		// Use bottom-right anchor with some padding from edges
-		// Move text up more to avoid being crossed by bottom border
-		textX := float64(rect.Max.X) - 10
-		textY := float64(rect.Max.Y) - 29 // Moved up by another 2px (was 27, now 29)
-
-		// Ensure rashi number is drawn in black
-		dc.SetRGB(0, 0, 0)
-		// Draw rashi number (anchored to bottom-right)
-		dc.DrawStringAnchored(rashiStr, textX, textY, 1.0, 1.0)
-
-		// Draw two parallel diagonal lines at bottom-left corner if this is the lagna rashi position
-		// These form parallel diagonal lines (like //) at the corner
-		if input.Lagna != nil && lagnaRashi > 0 && rashiNum == lagnaRashi {
-			cornerX := float64(rect.Min.X) + 15 // Left border + 15px offset
-			cornerY := float64(rect.Max.Y)      // Bottom border
-			lineLength := 15.0                  // Length of each diagonal line
-			offset := 3.0                       // Offset between the two parallel lines
-
-			// Rotate by 270 degrees total (90 + 90 + 90): use rotation matrix
-			// Original direction is -45 degrees, rotate by 270 degrees total
-			cos90 := 0.0 // cos(90°)
-			sin90 := 1.0 // sin(90°)
-
-			// Original direction vector (lineLength, -lineLength)
-			// First rotate by 90 degrees
-			dx1 := lineLength*cos90 - (-lineLength)*sin90
-			dy1 := lineLength*sin90 + (-lineLength)*cos90
-			// Then rotate by another 90 degrees
-			dx2 := dx1*cos90 - dy1*sin90
-			dy2 := dx1*sin90 + dy1*cos90
-			// Then rotate by another 90 degrees
-			dx := dx2*cos90 - dy2*sin90
-			dy := dx2*sin90 + dy2*cos90
-
-			dc.SetLineWidth(2)
-			// First diagonal: rotated line from bottom-left corner
-			dc.DrawLine(cornerX, cornerY, cornerX+dx, cornerY+dy)
-			dc.Stroke()
-			// Second diagonal: parallel line, slightly offset
-			dc.DrawLine(cornerX+offset, cornerY-offset, cornerX+dx+offset, cornerY+dy-offset)
-			dc.Stroke()
-			dc.SetLineWidth(1) // Reset line width
-		}
-
-		// Collect planets, grahas, and upagrahas in this house based on their Rashi
-		// Planets should be placed in the house that contains their rashi
-		var regularPlanets []string
-		var specialLagnas []string
-
-		// Add planets and lagna - treat lagna just like any other planet
-		// First add lagna if this is the lagna rashi position
-		if input.Lagna != nil && lagnaRashi > 0 && rashiNum == lagnaRashi {
-			abbrev := GetPlanetDisplayName("lagna", input.Lagna)
-			// Lagna is never retrograde or combust (it's a point, not a planet)
-			regularPlanets = append(regularPlanets, abbrev)
-		}
-
-		// Add regular planets and separate special lagnas
-		for planetName, planet := range input.Planets {
-			planetRashiNum := RashiToNumber(planet.Rashi)
-			// Check if this planet's rashi matches the rashi number of this position
-			if planetRashiNum > 0 && planetRashiNum == rashiNum {
-				abbrev := GetPlanetDisplayName(planetName, planet)
-
-				if planet.IsRetrograde {
-					abbrev += "R"
-				}
-				if planet.IsCombust {
-					abbrev += "C"
-				}
-
-				// Separate special lagnas from regular planets
-				if IsSpecialLagnaAbbrev(abbrev, input) {
-					specialLagnas = append(specialLagnas, abbrev)
-				} else {
-					regularPlanets = append(regularPlanets, abbrev)
-				}
-			}
-		}
-
-		// Draw planets in top center of the box with larger font
-		// Load larger Matangi font for planets from embedded data
-		loadMatangiBold(dc, 22)
-		centerX := float64(rect.Min.X+rect.Max.X) / 2 // Center horizontally
-		planetY := float64(rect.Min.Y) + 25           // Top with padding
-
-		// Calculate spacing: planets on left, special lagnas on right
-		leftX := centerX - 25  // Left side for regular planets
-		rightX := centerX + 25 // Right side for special lagnas
-
-		// Draw regular planets on the left
-		for i, planetAbbrev := range regularPlanets {
-			// Check if this is Ascendant and set color to saffron
-			if strings.Contains(planetAbbrev, "Asc") {
-				dc.SetRGB(1.0, 0.6, 0.2) // Saffron
-			} else {
-				dc.SetRGB(0, 0, 0) // Black
-			}
-			dc.DrawStringAnchored(planetAbbrev, leftX, planetY+float64(i*25), 1.0, 0.5)
-		}
-
-		// Draw special lagnas on the right, matching up with planets by index
-		maxItems := len(regularPlanets)
-		if len(specialLagnas) > maxItems {
-			maxItems = len(specialLagnas)
-		}
-
-		for i := 0; i < maxItems; i++ {
-			// Draw special lagna if available at this index
-			if i < len(specialLagnas) {
-				dc.SetRGB(1.0, 0.85, 0.0) // Yellow for special lagnas
-				dc.DrawStringAnchored(specialLagnas[i], rightX, planetY+float64(i*25), 0.0, 0.5)
-			}
-		}
-		// Reset color back to black after drawing planets
-		dc.SetRGB(0, 0, 0)
-		// Reset font back to smaller size for rashi numbers
-		loadMatangiRegular(dc, 16)
-	}
-
-	// Draw center text if provided
-	if input.CenterText != "" {
-		// Center of the chart (the 4 empty squares in the middle)
-		centerX := float64(padding) + 2*cellSize
-		centerY := float64(padding) + 2*cellSize
-
-		// Load font for center text from embedded data
-		loadMatangiRegular(dc, 18)
-
-		dc.SetRGB(0, 0, 0) // Black text
-
-		// Split text by newlines and draw each line
-		lines := strings.Split(input.CenterText, "\n")
-		lineHeight := 25.0                                     // Height between lines
-		startY := centerY - float64(len(lines)-1)*lineHeight/2 // Center vertically
-
-		for i, line := range lines {
-			if line != "" { // Skip empty lines
-				dc.DrawStringAnchored(line, centerX, startY+float64(i)*lineHeight, 0.5, 0.5)
-			}
-		}
-	}
-
-	return encodePNG(dc.Image())
 }
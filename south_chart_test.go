@@ -16,9 +16,16 @@
 package parashari
 
 import (
+	"bytes"
 	"encoding/base64"
-	"os"
+	"image"
+	"image/png"
+	"math"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/fogleman/gg"
 )
 
 func TestSouthChart_AllPlanets(t *testing.T) {
@@ -93,10 +100,7 @@ func TestSouthChart_AllPlanets(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_south_all_planets.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "south_all_planets", imageData)
 
 	t.Logf("Test 1 passed: All planets chart generated successfully (%d bytes)", len(imageData))
 }
@@ -169,10 +173,7 @@ func TestSouthChart_AllPlanetsWithLagna(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_south_all_planets_with_lagna.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "south_all_planets_with_lagna", imageData)
 
 	t.Logf("Test 2 passed: All planets with Lagna chart generated successfully (%d bytes)", len(imageData))
 }
@@ -265,10 +266,7 @@ func TestSouthChart_AllPlanetsWithUpagrahas(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_south_all_planets_with_upagrahas.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "south_all_planets_with_upagrahas", imageData)
 
 	t.Logf("Test 3 passed: All planets with upagrahas chart generated successfully (%d bytes)", len(imageData))
 }
@@ -341,10 +339,7 @@ func TestSouthChart_AllPlanetsUpagrahasLagnaSameRashi(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_south_all_same_rashi.png", imageData, 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
-	}
+	assertGolden(t, "south_all_same_rashi", imageData)
 
 	t.Logf("Test 4 passed: All planets, upagrahas, and lagna in same rashi chart generated successfully (%d bytes)", len(imageData))
 }
@@ -388,10 +383,378 @@ func TestSouthChart_WithCenterText(t *testing.T) {
 		t.Fatalf("Error decoding base64: %v", err)
 	}
 
-	err = os.WriteFile("test_south_with_center_text.png", imageData, 0644)
+	assertGolden(t, "south_with_center_text", imageData)
+
+	t.Logf("Test with center text passed: Chart generated successfully (%d bytes)", len(imageData))
+}
+
+// renderSouthCenterText renders a South chart with the given center text and
+// returns it decoded alongside the same chart without center text
+func renderSouthCenterText(t *testing.T, text string) (withText, without image.Image) {
+	t.Helper()
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "scorpio"},
+		Planets: map[string]*Planet{
+			"sun":  {Rashi: "aries"},
+			"moon": {Rashi: "taurus"},
+			"mars": {Rashi: "scorpio"},
+		},
+	}
+	decode := func(input ChartInput) image.Image {
+		data, err := GenerateSouthChart(input)
+		if err != nil {
+			t.Fatalf("Error generating chart: %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Error decoding chart: %v", err)
+		}
+		return img
+	}
+	without = decode(input)
+	input.CenterText = text
+	return decode(input), without
+}
+
+// assertCenterTextContained checks that center text only changed pixels
+// inside the 2x2 center area of a South chart
+func assertCenterTextContained(t *testing.T, withText, without image.Image) {
+	t.Helper()
+	center := image.Rect(220, 220, 580, 580)
+	changed := 0
+	bounds := withText.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if withText.At(x, y) == without.At(x, y) {
+				continue
+			}
+			changed++
+			if !image.Pt(x, y).In(center) {
+				t.Fatalf("Center text drew at (%d, %d), outside the center area %v", x, y, center)
+			}
+		}
+	}
+	if changed == 0 {
+		t.Fatal("Center text was not drawn")
+	}
+}
+
+func TestSouthChart_CenterTextTenLines(t *testing.T) {
+	// Each line wraps in two at the full font size, so the block must also shrink
+	text := strings.Repeat("Jupiter Mahadasha, Saturn Antardasha and Mercury Pratyantardasha\n", 9) + "Until 2031"
+	withText, without := renderSouthCenterText(t, text)
+	assertCenterTextContained(t, withText, without)
+
+	dc := gg.NewContext(800, 800)
+	lines, _ := centerLines(ChartInput{CenterText: text})
+	layout, err := layoutCenterText(dc, lines, 340, 340, 1)
 	if err != nil {
-		t.Fatalf("Error writing file: %v", err)
+		t.Fatalf("Error laying out center text: %v", err)
 	}
+	if layout.size >= centerTextSize {
+		t.Errorf("Ten lines should shrink the font below %v, got %v", centerTextSize, layout.size)
+	}
+}
 
-	t.Logf("Test with center text passed: Chart generated successfully (%d bytes)", len(imageData))
+func TestSouthChart_CenterTextWrapsLongLine(t *testing.T) {
+	text := "Born on a Thursday in the Shukla Paksha of Margashirsha with the Moon in Rohini, " +
+		"Supercalifragilisticexpialidociouslyunbreakablewordthatiswiderthanthecenter"
+	withText, without := renderSouthCenterText(t, text)
+	assertCenterTextContained(t, withText, without)
+
+	dc := gg.NewContext(800, 800)
+	lines, _ := centerLines(ChartInput{CenterText: text})
+	layout, err := layoutCenterText(dc, lines, 340, 340, 1)
+	if err != nil {
+		t.Fatalf("Error laying out center text: %v", err)
+	}
+	if len(layout.rows) < 3 {
+		t.Errorf("Long line should wrap onto several lines, got %d", len(layout.rows))
+	}
+	for _, row := range layout.rows {
+		if w, _ := dc.MeasureString(row.text); w > 340 {
+			t.Errorf("Wrapped line %q is %.0fpx wide, wider than the 340px area", row.text, w)
+		}
+	}
+}
+
+// wordMeasurer is a countingMeasurer that wraps nothing at spaces, as for
+// a single word
+type wordMeasurer struct{ countingMeasurer }
+
+func (m *wordMeasurer) WordWrap(s string, _ float64) []string { return []string{s} }
+
+func TestWrapLine_LongWord(t *testing.T) {
+	const n = 2000
+	var m wordMeasurer
+	lines := wrapLine(&m, strings.Repeat("x", n), 20)
+	if len(lines) != n/20 {
+		t.Fatalf("Got %d lines, want %d", len(lines), n/20)
+	}
+	for _, line := range lines {
+		if len(line) != 20 {
+			t.Errorf("Line %q is not 20 wide", line)
+		}
+	}
+	// Each line's break is found from a few prefixes no longer than twice
+	// the line, rather than every prefix of the rest of the word
+	if m.runes > 10*n {
+		t.Errorf("wrapLine measured %d runes of a %d rune word", m.runes, n)
+	}
+}
+
+func TestSouthChart_CenterTextTooLong(t *testing.T) {
+	input := ChartInput{
+		ChartType:  ChartTypeSouth,
+		CenterText: strings.Repeat("Line\n", 100),
+	}
+	if _, err := GenerateSouthChart(input); err == nil {
+		t.Error("Expected an error for center text that cannot fit even at the smallest font")
+	}
+}
+
+func TestCenterTextFromDasha(t *testing.T) {
+	got := CenterTextFromDasha("Venus", "Saturn", "", "3y 2m 10d")
+	if want := "Dasa: Venus\nBhukti: Saturn\nBalance: 3y 2m 10d"; got != want {
+		t.Errorf("CenterTextFromDasha = %q, want %q", got, want)
+	}
+	if got := CenterTextFromDasha("", " ", "", ""); got != "" {
+		t.Errorf("CenterTextFromDasha of empty parts = %q, want empty", got)
+	}
+}
+
+func TestCenterTextFromDasha_LongLords(t *testing.T) {
+	long := strings.Repeat("Brihaspati-Shani-", 4) + "Budha"
+	text := CenterTextFromDasha(long, long, long, "12 years, 11 months and 29 days remaining at birth")
+	lines := strings.Split(text, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4: %q", len(lines), text)
+	}
+	for i, prefix := range []string{"Dasa: Brihaspati", "Bhukti: Brihaspati", "Antara: Brihaspati", "Balance: 12 years"} {
+		if !strings.HasPrefix(lines[i], prefix) || !strings.HasSuffix(lines[i], ellipsis) {
+			t.Errorf("line %d = %q, want it to start %q and be cut short with %q", i, lines[i], prefix, ellipsis)
+		}
+	}
+
+	// The block fits the center at full size, one chart line per line
+	dc := gg.NewContext(800, 800)
+	centered, _ := centerLines(ChartInput{CenterText: text})
+	layout, err := layoutCenterText(dc, centered, centerTextWidth, centerTextWidth, 1)
+	if err != nil {
+		t.Fatalf("Error laying out center text: %v", err)
+	}
+	if layout.size != centerTextSize || len(layout.rows) != 4 {
+		t.Errorf("laid out %d lines at %v, want 4 at %v", len(layout.rows), layout.size, centerTextSize)
+	}
+	withText, without := renderSouthCenterText(t, text)
+	assertCenterTextContained(t, withText, without)
+}
+
+// birthCenterLines are a bold name, plain lines and a small gray date and
+// place of birth
+var birthCenterLines = []CenterLine{
+	{Text: "Rama Krishna Sharma", Bold: true, Size: 26},
+	{Text: "Rashi Chart"},
+	{Text: "Vimshottari: Venus-Saturn"},
+	{Text: "14 March 1990, 06:12, Varanasi", Size: 12, Color: "#666666"},
+}
+
+func TestSouthChart_CenterLines(t *testing.T) {
+	_, without := renderSouthCenterText(t, "")
+	input := ChartInput{
+		ChartType:   ChartTypeSouth,
+		Lagna:       &Planet{Rashi: "scorpio"},
+		Planets:     map[string]*Planet{"sun": {Rashi: "aries"}, "moon": {Rashi: "taurus"}, "mars": {Rashi: "scorpio"}},
+		CenterLines: birthCenterLines,
+	}
+	data, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	withText, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error decoding chart: %v", err)
+	}
+	assertCenterTextContained(t, withText, without)
+	assertGolden(t, "south_center_lines", data)
+
+	// Each line is as tall as its own font, and they do not overlap
+	var lines []LabelLayout
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelCenterText) {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) != len(birthCenterLines) {
+		t.Fatalf("got %d center lines, want %d", len(lines), len(birthCenterLines))
+	}
+	height := func(l LabelLayout) float64 { return l.Bottom - l.Top }
+	if !(height(lines[0]) > height(lines[1]) && height(lines[1]) > height(lines[3])) {
+		t.Errorf("line heights %v, %v, %v, want the name tallest and the date smallest", height(lines[0]), height(lines[1]), height(lines[3]))
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i].Top < lines[i-1].Bottom {
+			t.Errorf("line %q overlaps the line above it", lines[i].Text)
+		}
+	}
+}
+
+func TestSouthChart_CenterLinesShrink(t *testing.T) {
+	// Sixteen lines of mixed sizes overflow the center at full size, and
+	// shrink together until they fit
+	var lines []CenterLine
+	for range 4 {
+		lines = append(lines, birthCenterLines...)
+	}
+	dc := gg.NewContext(800, 800)
+	layout, err := layoutCenterText(dc, lines, 340, 340, 1)
+	if err != nil {
+		t.Fatalf("Error laying out center lines: %v", err)
+	}
+	if layout.size >= centerTextSize || layout.height() > 340 {
+		t.Errorf("laid out at %v, %.0fpx tall, want smaller than %v and at most 340px", layout.size, layout.height(), centerTextSize)
+	}
+	if want := 26 * layout.size / centerTextSize; layout.rows[0].size != want {
+		t.Errorf("name drawn at %v, want %v in proportion to the plain lines", layout.rows[0].size, want)
+	}
+
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeSarvashtakavarga} {
+		input := ChartInput{ChartType: chartType, CenterLines: lines}
+		if chartType == ChartTypeSarvashtakavarga {
+			input.HouseScores = map[int]int{1: 28, 2: 25, 3: 30, 4: 29, 5: 27, 6: 31, 7: 26, 8: 24, 9: 33, 10: 32, 11: 35, 12: 17}
+		}
+		_, chartLayout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatalf("%s: error generating chart: %v", chartType, err)
+		}
+		center := image.Rect(220, 220, 580, 580)
+		for _, l := range chartLayout.Labels {
+			if l.Kind != string(labelCenterText) {
+				continue
+			}
+			box := image.Rect(int(l.Left), int(l.Top), int(math.Ceil(l.Right)), int(math.Ceil(l.Bottom)))
+			if !box.In(center) {
+				t.Errorf("%s: line %q at %v escapes the center area %v", chartType, l.Text, box, center)
+			}
+		}
+	}
+}
+
+func TestValidateCenterLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		input ChartInput
+		want  string
+	}{
+		{"both", ChartInput{CenterText: "Rasi", CenterLines: birthCenterLines}, "center_text and center_lines cannot both be set"},
+		{"negative size", ChartInput{CenterLines: []CenterLine{{Text: "Rasi", Size: -1}}}, "center_lines[0]: size -1 out of range 0-36"},
+		{"huge size", ChartInput{CenterLines: []CenterLine{{Text: "Rasi"}, {Text: "D1", Size: 40}}}, "center_lines[1]: size 40 out of range 0-36"},
+		{"color", ChartInput{CenterLines: []CenterLine{{Text: "Rasi", Color: "gray"}}}, `center_lines[0]: invalid color "gray"`},
+		{"long text", ChartInput{CenterText: strings.Repeat("x", 2001)}, "center_text: 2001 characters, at most 2000"},
+		{"long lines", ChartInput{CenterLines: slices.Repeat([]CenterLine{{Text: strings.Repeat("x", 500)}}, 5)}, "center_lines: 2500 characters, at most 2000"},
+	}
+	for _, tt := range tests {
+		tt.input.ChartType = ChartTypeSouth
+		_, err := GenerateChart(tt.input)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	tooLong := ChartInput{ChartType: ChartTypeSouth, CenterLines: slices.Repeat([]CenterLine{{Text: "Line"}}, 100)}
+	if _, err := GenerateChart(tooLong); err == nil || !strings.Contains(err.Error(), "center_lines does not fit") {
+		t.Errorf("too long: err = %v", err)
+	}
+}
+
+func TestSouthChart_ShowHouseNumbers(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":  {Rashi: "leo"},
+			"moon": {Rashi: "scorpio"},
+		},
+		Options: ChartOptions{ShowHouseNumbers: true},
+	}
+
+	imageData, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_house_numbers", imageData)
+}
+
+func TestSouthChart_DignityMarkers(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "aries"},
+		Planets: map[string]*Planet{
+			"sun":    {Rashi: "aries", IsExalted: true},
+			"saturn": {Rashi: "libra", IsExalted: true, IsRetrograde: true},
+			"moon":   {Rashi: "scorpio", IsDebilitated: true},
+		},
+	}
+
+	imageData, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_dignity_markers", imageData)
+}
+
+func TestSouthCellRashi_RotateToLagna(t *testing.T) {
+	for lagna := 1; lagna <= 12; lagna++ {
+		// The lagna takes cell 1 and the rashis follow it cell by cell
+		for cell := 1; cell <= 12; cell++ {
+			want := (cell+lagna-2)%12 + 1
+			if got := southCellRashi(cell, lagna, true); got != want {
+				t.Errorf("lagna %d: cell %d holds rashi %d, want %d", lagna, cell, got, want)
+			}
+			if got := southRashiCell(southCellRashi(cell, lagna, true), lagna, true); got != cell {
+				t.Errorf("lagna %d: rashi of cell %d maps back to cell %d", lagna, cell, got)
+			}
+			if got := southCellRashi(cell, lagna, false); got != cell {
+				t.Errorf("lagna %d: unrotated cell %d holds rashi %d, want %d", lagna, cell, got, cell)
+			}
+		}
+		if got := southCellRashi(1, lagna, true); got != lagna {
+			t.Errorf("lagna %d: cell 1 holds rashi %d, want the lagna", lagna, got)
+		}
+		if got := southRashiCell(lagna, lagna, true); got != 1 {
+			t.Errorf("lagna %d: lagna drawn in cell %d, want 1", lagna, got)
+		}
+	}
+}
+
+func TestSouthChart_RotateToLagna(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Lagna:     &Planet{Rashi: "leo"},
+		Planets: map[string]*Planet{
+			"sun":  {Rashi: "leo"},
+			"moon": {Rashi: "scorpio"},
+			"mars": {Rashi: "cancer"},
+		},
+		Options: ChartOptions{RotateToLagna: true, ShowHouseNumbers: true, HighlightHouses: []HouseHighlight{{Houses: []int{4}, Color: "#fde8c8"}}},
+	}
+
+	// Leo takes the Aries cell, with the lagna and the Sun in it
+	boxes := renderWithBoxes(t, input)
+	cells := map[string]int{}
+	for _, b := range boxes.boxes {
+		cells[b.text] = b.house
+	}
+	for text, cell := range map[string]int{"Asc": 1, "Su": 1, "Mo": 4, "Ma": 12} {
+		if cells[text] != cell {
+			t.Errorf("%s drawn in cell %d, want %d", text, cells[text], cell)
+		}
+	}
+
+	imageData, err := GenerateSouthChart(input)
+	if err != nil {
+		t.Fatalf("Error generating chart: %v", err)
+	}
+	assertGolden(t, "south_rotate_to_lagna", imageData)
 }
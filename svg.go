@@ -0,0 +1,199 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package parashari
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgDefaultStyle is the look of the elements GenerateChartSVG lays over
+// the chart: see-through, so the chart reads as its PNG, and tinted under
+// the pointer
+const svgDefaultStyle = `
+.house, .planet rect { fill: transparent; stroke: none; }
+.house:hover { fill: rgba(255, 200, 0, 0.15); }
+.planet:hover rect { fill: rgba(0, 120, 255, 0.15); }
+`
+
+// maxSVGStyleBytes bounds ChartOptions.SVGStyle
+const maxSVGStyleBytes = 64 << 10
+
+// GenerateChartSVG generates a chart like GenerateChart and returns it as an
+// SVG document. The chart is drawn as for PNG and embedded as an image of
+// class "chart-image", beneath elements that browsers and stylesheets can
+// pick out:
+//
+//   - a polygon over each house, id "house-5" and class "house", titled
+//     with the house and its rashi
+//   - a group over each planet label, id "planet-jupiter", class "planet"
+//     followed by the planet's statuses ("planet retrograde combust"),
+//     holding a rect over the label and a title naming the planet in full
+//     with its statuses, which browsers show as a tooltip
+//
+// The style block, id "chart-style", sets the elements' default look, then
+// holds Options.SVGStyle, so that CSS can tint houses or planets, or filter
+// the chart image, without drawing the chart again.
+func GenerateChartSVG(input ChartInput) ([]byte, error) {
+	if err := validateInput(input); err != nil {
+		return nil, err
+	}
+	if _, err := CanonicalizeChartInput(&input); err != nil {
+		return nil, err
+	}
+	r := rendererPool.Get().(*renderer)
+	defer rendererPool.Put(r)
+	var boxes chartBoxes
+	img, err := r.drawNormalized(input, &boxes)
+	if err != nil {
+		return nil, err
+	}
+	png, err := r.encodeBase64(img)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	return chartSVG(input, png, boxes.layout(input, bounds.Dx(), bounds.Dy())), nil
+}
+
+// chartSVG writes the SVG document of a chart of the canonical input, from
+// its base64 PNG and layout
+func chartSVG(input ChartInput, png string, layout *ChartLayout) []byte {
+	var b bytes.Buffer
+	w, h := strconv.Itoa(layout.Width), strconv.Itoa(layout.Height)
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="chart" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n", w, h, w, h)
+	b.WriteString(`<style id="chart-style"><![CDATA[`)
+	b.WriteString(svgDefaultStyle)
+	if input.Options.SVGStyle != "" {
+		// "]]>" would end the CDATA section, so it is split across two
+		b.WriteString(strings.ReplaceAll(input.Options.SVGStyle, "]]>", "]]]]><![CDATA[>"))
+		b.WriteByte('\n')
+	}
+	b.WriteString("]]></style>\n")
+	fmt.Fprintf(&b, `<image class="chart-image" width="%s" height="%s" href="data:image/png;base64,%s"/>`+"\n", w, h, png)
+
+	loc := localeFor(input.Options)
+	b.WriteString(`<g class="houses">` + "\n")
+	for _, house := range layout.Houses {
+		points := make([]string, len(house.Polygon))
+		for i, p := range house.Polygon {
+			points[i] = svgNumber(p[0]) + "," + svgNumber(p[1])
+		}
+		fmt.Fprintf(&b, `<polygon id="house-%d" class="house" data-rashi="%d" points="%s"><title>`, house.House, house.Rashi, strings.Join(points, " "))
+		writeSVGText(&b, fmt.Sprintf("House %d, %s", house.House, loc.rashiName(house.Rashi)))
+		b.WriteString("</title></polygon>\n")
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g class="planets">` + "\n")
+	ids := map[string]bool{}
+	for _, l := range layout.Labels {
+		if l.Kind != string(labelPlanet) || l.Planet == "" {
+			continue
+		}
+		p := input.Lagna
+		if l.Planet != "lagna" {
+			p = input.Planets[l.Planet]
+		}
+		statuses := planetStatuses(input.Options, l.Planet, p)
+		id := "planet-" + svgID(l.Planet)
+		for n := 2; ids[id]; n++ {
+			id = fmt.Sprintf("planet-%s-%d", svgID(l.Planet), n)
+		}
+		ids[id] = true
+		b.WriteString(`<g id="` + id + `" class="`)
+		writeSVGText(&b, strings.Join(append([]string{"planet"}, statuses...), " "))
+		b.WriteString(`"><title>`)
+		writeSVGText(&b, strings.Join(append([]string{planetTitle(loc, l.Planet, p)}, statuses...), ", "))
+		fmt.Fprintf(&b, `</title><rect x="%s" y="%s" width="%s" height="%s"/></g>`+"\n",
+			svgNumber(l.Left), svgNumber(l.Top), svgNumber(l.Right-l.Left), svgNumber(l.Bottom-l.Top))
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes()
+}
+
+// planetStatuses returns the statuses of a planet its label marks, as the
+// words SVG classes and titles use, in a fixed order
+func planetStatuses(opts ChartOptions, planetName string, p *Planet) []string {
+	if p == nil || planetName == "lagna" {
+		return nil
+	}
+	var statuses []string
+	if p.IsRetrograde {
+		statuses = append(statuses, "retrograde")
+	}
+	if p.IsCombust {
+		statuses = append(statuses, "combust")
+	}
+	dignity := DignityNeutral
+	if opts.AutoDignity {
+		dignity = GetDignity(planetName, p.Rashi)
+	}
+	switch {
+	case p.IsExalted || (!p.IsDebilitated && dignity == DignityExalted):
+		statuses = append(statuses, "exalted")
+	case p.IsDebilitated || dignity == DignityDebilitated:
+		statuses = append(statuses, "debilitated")
+	}
+	if IsVargottama(p) {
+		statuses = append(statuses, "vargottama")
+	}
+	threshold := opts.StationaryThreshold
+	if threshold == 0 {
+		threshold = DefaultStationaryThreshold
+	}
+	if opts.ShowStationary && isStationary(p, threshold) {
+		statuses = append(statuses, "stationary")
+	}
+	return statuses
+}
+
+// planetTitle returns the name a planet's SVG title starts with: its full
+// name in the locale, else its display or its name
+func planetTitle(loc *chartLocale, planetName string, p *Planet) string {
+	if full := loc.fullName(planetName); full != "" {
+		return full
+	}
+	if p != nil && p.Display != "" {
+		return p.Display
+	}
+	return planetName
+}
+
+// svgID returns a planet name fit for an SVG id and CSS selectors, its
+// letters, digits, "_" and "-" kept and anything else turned into "-"
+func svgID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// svgNumber formats a coordinate to a tenth of a pixel
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// writeSVGText writes s escaped for SVG text and attribute values
+func writeSVGText(b *bytes.Buffer, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
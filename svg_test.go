@@ -0,0 +1,208 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package parashari
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// svgElement is an element of a parsed SVG document
+type svgElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Children []svgElement `xml:",any"`
+	Text     string       `xml:",chardata"`
+}
+
+// attr returns the value of the element's attribute name
+func (e svgElement) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// walk calls visit for the element and every element inside it
+func (e svgElement) walk(visit func(svgElement)) {
+	visit(e)
+	for _, c := range e.Children {
+		c.walk(visit)
+	}
+}
+
+// child returns the first child element named name
+func (e svgElement) child(name string) (svgElement, bool) {
+	for _, c := range e.Children {
+		if c.XMLName.Local == name {
+			return c, true
+		}
+	}
+	return svgElement{}, false
+}
+
+// parseSVG parses an SVG document and indexes its elements by id
+func parseSVG(t *testing.T, data []byte) (svgElement, map[string]svgElement) {
+	t.Helper()
+	var root svgElement
+	if err := xml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("SVG does not parse: %v", err)
+	}
+	byID := map[string]svgElement{}
+	root.walk(func(e svgElement) {
+		if id := e.attr("id"); id != "" {
+			if _, ok := byID[id]; ok {
+				t.Errorf("id %q used twice", id)
+			}
+			byID[id] = e
+		}
+	})
+	return root, byID
+}
+
+func TestGenerateChartSVG(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := ChartInput{
+			ChartType: chartType,
+			Lagna:     &Planet{Rashi: "leo"},
+			Planets: map[string]*Planet{
+				"Jupiter": {Rashi: "cancer", IsRetrograde: true, IsExalted: true},
+				"saturn":  {Rashi: "aries", IsCombust: true},
+				"moon":    {Rashi: "taurus"},
+			},
+			Options: ChartOptions{SVGStyle: ".planet.retrograde rect { fill: #f003; }"},
+		}
+		data, err := GenerateChartSVG(input)
+		if err != nil {
+			t.Fatalf("%s: %v", chartType, err)
+		}
+		root, byID := parseSVG(t, data)
+		if root.XMLName.Local != "svg" || root.XMLName.Space != "http://www.w3.org/2000/svg" {
+			t.Fatalf("%s: root element %v, want svg", chartType, root.XMLName)
+		}
+
+		for house := 1; house <= 12; house++ {
+			id := "house-" + strconv.Itoa(house)
+			e, ok := byID[id]
+			if !ok {
+				t.Errorf("%s: no %s", chartType, id)
+				continue
+			}
+			if e.XMLName.Local != "polygon" || e.attr("class") != "house" || e.attr("points") == "" {
+				t.Errorf("%s: %s is <%s class=%q>, want a house polygon", chartType, id, e.XMLName.Local, e.attr("class"))
+			}
+		}
+		if title, _ := byID["house-1"].child("title"); title.Text != "House 1, Leo" {
+			t.Errorf("%s: house-1 title %q", chartType, title.Text)
+		}
+
+		planets := []struct{ id, class, title string }{
+			{"planet-jupiter", "planet retrograde exalted", "Jupiter, retrograde, exalted"},
+			{"planet-saturn", "planet combust", "Saturn, combust"},
+			{"planet-moon", "planet", "Moon"},
+			{"planet-lagna", "planet", "Ascendant"},
+		}
+		for _, want := range planets {
+			e, ok := byID[want.id]
+			if !ok {
+				t.Errorf("%s: no %s", chartType, want.id)
+				continue
+			}
+			if got := e.attr("class"); got != want.class {
+				t.Errorf("%s: %s class %q, want %q", chartType, want.id, got, want.class)
+			}
+			if title, _ := e.child("title"); title.Text != want.title {
+				t.Errorf("%s: %s title %q, want %q", chartType, want.id, title.Text, want.title)
+			}
+			if rect, ok := e.child("rect"); !ok || rect.attr("width") == "" || rect.attr("height") == "" {
+				t.Errorf("%s: %s has no rect over its label", chartType, want.id)
+			}
+		}
+
+		style, ok := byID["chart-style"]
+		if !ok || !strings.Contains(style.Text, ".house:hover") || !strings.Contains(style.Text, input.Options.SVGStyle) {
+			t.Errorf("%s: style block %q lacks the defaults or svg_style", chartType, style.Text)
+		}
+
+		var image svgElement
+		root.walk(func(e svgElement) {
+			if e.XMLName.Local == "image" {
+				image = e
+			}
+		})
+		encoded, ok := strings.CutPrefix(image.attr("href"), "data:image/png;base64,")
+		if !ok || image.attr("class") != "chart-image" {
+			t.Fatalf("%s: chart image %v", chartType, image.Attrs)
+		}
+		pngData, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(pngData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); root.attr("width") != "800" || b.Dx() != 800 || b.Dy() != 800 {
+			t.Errorf("%s: svg width %s, image %v, want 800", chartType, root.attr("width"), b)
+		}
+	}
+}
+
+func TestGenerateChartSVG_Escapes(t *testing.T) {
+	input := ChartInput{
+		ChartType: ChartTypeSouth,
+		Planets: map[string]*Planet{
+			"a<b> & c": {Rashi: "aries", Display: `"X"`},
+		},
+		Options: ChartOptions{SVGStyle: `/* ]]> <script> */ .house { stroke: red; }`},
+	}
+	data, err := GenerateChartSVG(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, byID := parseSVG(t, data)
+	e, ok := byID["planet-a-b----c"]
+	if !ok {
+		t.Fatalf("no planet-a-b----c in %s", data)
+	}
+	if title, _ := e.child("title"); title.Text != `"X"` {
+		t.Errorf("title %q, want %q", title.Text, `"X"`)
+	}
+	if style := byID["chart-style"].Text; !strings.Contains(style, input.Options.SVGStyle) {
+		t.Errorf("style block %q lacks %q", style, input.Options.SVGStyle)
+	}
+	root.walk(func(e svgElement) {
+		if e.XMLName.Local == "script" {
+			t.Error("svg_style escaped its style block")
+		}
+	})
+}
+
+func TestGenerateChartSVG_Invalid(t *testing.T) {
+	if _, err := GenerateChartSVG(ChartInput{ChartType: "east"}); err == nil {
+		t.Error("GenerateChartSVG accepted an unsupported chart type")
+	}
+	input := ChartInput{ChartType: ChartTypeSouth, Options: ChartOptions{SVGStyle: strings.Repeat("a", maxSVGStyleBytes+1)}}
+	if _, err := GenerateChartSVG(input); err == nil {
+		t.Error("GenerateChartSVG accepted an oversized svg_style")
+	}
+}
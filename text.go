@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// drawText draws s in a face and color like dc.DrawStringAnchored(s, x, y,
+// ax, 0). gg composites every glyph through a general image transform that
+// allocates for each pixel it touches; while the context is untransformed
+// the glyph masks are composited straight onto the canvas instead, with the
+// same arithmetic and so the same pixels. Text is never drawn under a clip,
+// which this does not honour. Unlike DrawStringAnchored it leaves the
+// context's font and color alone.
+func drawText(dc *gg.Context, face font.Face, c color.Color, s string, x, y, ax float64) {
+	dst, ok := dc.Image().(*image.RGBA)
+	if !ok || !untransformed(dc) {
+		dc.Push()
+		dc.SetFontFace(face)
+		dc.SetColor(c)
+		dc.DrawStringAnchored(s, x, y, ax, 0)
+		dc.Pop()
+		return
+	}
+
+	// Measure and place the text exactly as gg does
+	w := float64(font.MeasureString(face, s) >> 6)
+	dot := fixed.Point26_6{X: fixed.Int26_6((x - ax*w) * 64), Y: fixed.Int26_6(y * 64)}
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			dot.X += face.Kern(prev, r)
+		}
+		dr, mask, maskp, advance, ok := face.Glyph(dot, r)
+		if !ok {
+			continue
+		}
+		drawGlyph(dst, dr, mask, maskp, c)
+		dot.X += advance
+		prev = r
+	}
+}
+
+// drawGlyph composites a glyph mask in color c over dst at dr. The sums are
+// those golang.org/x/image/draw's bilinear Over transform makes at a whole
+// pixel offset, which is how gg draws text.
+func drawGlyph(dst *image.RGBA, dr image.Rectangle, mask image.Image, maskp image.Point, c color.Color) {
+	sr, sg, sb, sa := c.RGBA()
+	alpha, isAlpha := mask.(*image.Alpha)
+	clipped := dr.Intersect(dst.Rect)
+	for y := clipped.Min.Y; y < clipped.Max.Y; y++ {
+		for x := clipped.Min.X; x < clipped.Max.X; x++ {
+			mx, my := maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y
+			var ma uint32
+			if isAlpha {
+				ma = uint32(alpha.AlphaAt(mx, my).A) * 0x101
+			} else {
+				_, _, _, ma = mask.At(mx, my).RGBA()
+			}
+			if ma == 0 {
+				continue
+			}
+			pa := sa * ma / 0xffff
+			pa1 := 0xffff - pa
+			i := dst.PixOffset(x, y)
+			d := dst.Pix[i : i+4 : i+4]
+			d[0] = uint8((uint32(d[0])*0x101*pa1/0xffff + sr*ma/0xffff) >> 8)
+			d[1] = uint8((uint32(d[1])*0x101*pa1/0xffff + sg*ma/0xffff) >> 8)
+			d[2] = uint8((uint32(d[2])*0x101*pa1/0xffff + sb*ma/0xffff) >> 8)
+			d[3] = uint8((uint32(d[3])*0x101*pa1/0xffff + pa) >> 8)
+		}
+	}
+}
+
+// untransformed reports whether the context maps points to themselves
+func untransformed(dc *gg.Context) bool {
+	for _, p := range []gg.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+		if x, y := dc.TransformPoint(p.X, p.Y); x != p.X || y != p.Y {
+			return false
+		}
+	}
+	return true
+}
+
+// labelRows splits labels into rows no wider than width, returning the
+// number of labels on each row. A label wider than width gets a row alone.
+func labelRows(face font.Face, labels []string, width float64) []int {
+	var rows []int
+	line := ""
+	for _, label := range labels {
+		if line != "" && float64(font.MeasureString(face, line+" "+label))/64 <= width {
+			line += " " + label
+			rows[len(rows)-1]++
+			continue
+		}
+		line = label
+		rows = append(rows, 1)
+	}
+	return rows
+}
+
+// faceMeasurer measures strings in a face, as a gg.Context does in its
+// current one
+type faceMeasurer struct{ face font.Face }
+
+func (m faceMeasurer) MeasureString(s string) (w, h float64) {
+	return float64(font.MeasureString(m.face, s)) / 64, float64(m.face.Metrics().Height) / 64
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// labelKind says what a text box holds
+type labelKind string
+
+const (
+	labelRashi       labelKind = "rashi"         // Rashi number, glyph or name
+	labelHouseNumber labelKind = "house_number"  // House number counted from lagna
+	labelMarker      labelKind = "lagna_marker"  // Lagna marker in the South chart
+	labelPlanet      labelKind = "planet"        // Planet, lagna or special lagna
+	labelCenterText  labelKind = "center_text"   // Line of the center text
+	labelHidden      labelKind = "hidden_count"  // "+N" count of the planets a thumbnail house has no room for
+	labelScore       labelKind = "house_score"   // Ashtakavarga score of a house
+	labelStrength    labelKind = "strength"      // Planet or value of a strength bar, beneath the chart
+	labelDasha       labelKind = "dasha"         // Cell of the dasha table, right of the chart
+	labelNakshatra   labelKind = "nakshatra"     // Nakshatra of the ring around the North chart
+	labelHouseLord   labelKind = "house_lord"    // Lord of the rashi of a house
+	labelPanchanga   labelKind = "panchanga"     // Label or value of the panchanga
+	labelNative      labelKind = "native"        // Label or value of the native's details
+	labelStamp       labelKind = "version_stamp" // Version stamp beneath the chart
+	labelColorKey    labelKind = "color_key"     // Category name of the color key beneath the chart
+)
+
+// textBox is the extent of a piece of text drawn on a chart, in pixels
+type textBox struct {
+	text                     string
+	house                    int // Region the text belongs to: the house position in the North chart, the rashi cell in the South
+	kind                     labelKind
+	planet                   string // Planet name of planet labels, "lagna" for the lagna
+	left, top, right, bottom float64
+}
+
+// overlaps reports whether two boxes share any area
+func (b textBox) overlaps(o textBox) bool {
+	return b.left < o.right && o.left < b.right && b.top < o.bottom && o.top < b.bottom
+}
+
+// rotated returns the bounds of the box turned by angle (in radians) about (cx, cy)
+func (b textBox) rotated(angle, cx, cy float64) textBox {
+	sin, cos := math.Sincos(angle)
+	r := b
+	r.left, r.top, r.right, r.bottom = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range []gg.Point{{X: b.left, Y: b.top}, {X: b.right, Y: b.top}, {X: b.right, Y: b.bottom}, {X: b.left, Y: b.bottom}} {
+		x := cx + (p.X-cx)*cos - (p.Y-cy)*sin
+		y := cy + (p.X-cx)*sin + (p.Y-cy)*cos
+		r.left, r.right = math.Min(r.left, x), math.Max(r.right, x)
+		r.top, r.bottom = math.Min(r.top, y), math.Max(r.bottom, y)
+	}
+	return r
+}
+
+// chartBoxes records every house region and text box a chart lays out, so
+// that tests can check for overlapping labels without inspecting the image
+// and callers can map the image back to the chart. Generators take a nil
+// *chartBoxes when nothing needs recording.
+type chartBoxes struct {
+	houses []houseRegion
+	boxes  []textBox
+}
+
+// houseRegion is the region of the chart a house is drawn in
+type houseRegion struct {
+	region  int // As in textBox.house
+	rashi   int
+	polygon []gg.Point
+}
+
+// add records boxes, doing nothing on a nil receiver
+func (c *chartBoxes) add(boxes ...textBox) {
+	if c != nil {
+		c.boxes = append(c.boxes, boxes...)
+	}
+}
+
+// addHouse records the polygon of a region holding a rashi, doing nothing
+// on a nil receiver
+func (c *chartBoxes) addHouse(region, rashi int, polygon []gg.Point) {
+	if c != nil {
+		c.houses = append(c.houses, houseRegion{region: region, rashi: rashi, polygon: polygon})
+	}
+}
+
+// overlapping returns every pair of recorded boxes that overlap
+func (c *chartBoxes) overlapping() [][2]textBox {
+	var pairs [][2]textBox
+	for i, a := range c.boxes {
+		for _, b := range c.boxes[i+1:] {
+			if a.overlaps(b) {
+				pairs = append(pairs, [2]textBox{a, b})
+			}
+		}
+	}
+	return pairs
+}
+
+// houseBoxes returns the boxes of the labels placed in a house
+func houseBoxes(dc *gg.Context, layout houseLayout, house int) []textBox {
+	loadMatangiBold(dc, layout.size)
+	lineHeight := planetLineHeight(layout.size)
+	var boxes []textBox
+	for _, l := range layout.labels {
+		w, top, bottom := labelBox(dc, l, lineHeight)
+		left := l.x - l.ax*w
+		boxes = append(boxes, textBox{text: l.entry.label, house: house, kind: labelPlanet, planet: l.entry.name, left: left, top: top, right: left + w, bottom: bottom})
+	}
+	return boxes
+}
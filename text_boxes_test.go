@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"testing"
+
+	"github.com/fogleman/gg"
+)
+
+// renderWithBoxes renders a chart of either style and returns its text boxes
+func renderWithBoxes(t *testing.T, input ChartInput) *chartBoxes {
+	t.Helper()
+	boxes := &chartBoxes{}
+	var err error
+	switch input.ChartType {
+	case ChartTypeNorth:
+		_, err = renderNorthChart(nil, input, boxes)
+	case ChartTypeSarvashtakavarga:
+		_, err = renderSAVChart(nil, input, boxes)
+	case ChartTypeSuryaKalanala:
+		_, err = renderSuryaKalanalaChart(nil, input, boxes)
+	default:
+		_, err = renderSouthChart(nil, input, boxes)
+	}
+	if err != nil {
+		t.Fatalf("Error rendering %s chart: %v", input.ChartType, err)
+	}
+	return boxes
+}
+
+func TestChartBoxes_NoOverlaps(t *testing.T) {
+	inputs := map[string]func(ChartType) ChartInput{
+		"crowded": crowdedHouseInput,
+		"crowded_names": func(chartType ChartType) ChartInput {
+			input := crowdedHouseInput(chartType)
+			input.Options.RashiLabelMode = RashiLabelSanskritName
+			return input
+		},
+		"crowded_full_names": func(chartType ChartType) ChartInput {
+			input := crowdedHouseInput(chartType)
+			input.Options.DisplayMode = DisplayModeFullName
+			return input
+		},
+		"crowded_glyphs_house_numbers": func(chartType ChartType) ChartInput {
+			input := crowdedHouseInput(chartType)
+			input.Options.RashiLabelMode = RashiLabelGlyph
+			input.Options.ShowHouseNumbers = true
+			return input
+		},
+		"degrees_nakshatras": func(chartType ChartType) ChartInput {
+			return ChartInput{
+				ChartType: chartType,
+				Lagna:     &Planet{Rashi: "leo", Degrees: 12.5, Nakshatra: "Magha", Pada: 3},
+				Planets: map[string]*Planet{
+					"sun":     {Rashi: "leo", Degrees: 2.1, Nakshatra: "Magha", Pada: 1},
+					"moon":    {Rashi: "leo", Degrees: 27.9, Nakshatra: "Uttara Phalguni", Pada: 1},
+					"mercury": {Rashi: "leo", Degrees: 20.4, Nakshatra: "Purva Phalguni", Pada: 3, IsRetrograde: true},
+					"jupiter": {Rashi: "cancer", Degrees: 5, Nakshatra: "Pushya", Pada: 1, IsRetrograde: true},
+					"venus":   {Rashi: "cancer", Degrees: 14, Nakshatra: "Pushya", Pada: 4},
+					"saturn":  {Rashi: "aquarius", Degrees: 29.5, Nakshatra: "Purva Bhadrapada", Pada: 3},
+				},
+				Options: ChartOptions{ShowDegrees: true, DegreeFormat: DegreeFormatDegreeMinute, ShowNakshatra: true},
+			}
+		},
+		"long_display_house_numbers": func(chartType ChartType) ChartInput {
+			return ChartInput{
+				ChartType: chartType,
+				Lagna:     &Planet{Rashi: "aries"},
+				Planets: map[string]*Planet{
+					"jupiter": {Rashi: "taurus", Display: "Jupiter (Guru)", Degrees: 12},
+				},
+				Options: ChartOptions{ShowDegrees: true, ShowHouseNumbers: true},
+			}
+		},
+		"long_display": func(chartType ChartType) ChartInput {
+			return ChartInput{
+				ChartType: chartType,
+				Lagna:     &Planet{Rashi: "leo"},
+				Planets: map[string]*Planet{
+					"jupiter": {Rashi: "gemini", Display: "Jupiter (Guru)"},
+					"mars":    {Rashi: "gemini", Display: "Mangala (Kuja) Bhauma", IsRetrograde: true},
+					"moon":    {Rashi: "virgo", Display: "Chandra (Soma)"},
+				},
+			}
+		},
+	}
+	for name, input := range inputs {
+		for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+			boxes := renderWithBoxes(t, input(chartType))
+			for _, pair := range boxes.overlapping() {
+				t.Errorf("%s %s chart: %q in house %d overlaps %q in house %d", name, chartType, pair[0].text, pair[0].house, pair[1].text, pair[1].house)
+			}
+		}
+	}
+}
+
+func TestChartBoxes_InsideCanvas(t *testing.T) {
+	canvas := image.Rect(0, 0, 800, 800)
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		boxes := renderWithBoxes(t, crowdedHouseInput(chartType))
+		if len(boxes.boxes) < 12+12 {
+			t.Fatalf("%s chart recorded %d boxes, want the 12 rashi numbers and 12 labels", chartType, len(boxes.boxes))
+		}
+		for _, b := range boxes.boxes {
+			if b.left < float64(canvas.Min.X) || b.top < float64(canvas.Min.Y) || b.right > float64(canvas.Max.X) || b.bottom > float64(canvas.Max.Y) {
+				t.Errorf("%s chart: box of %q lies outside the canvas: %+v", chartType, b.text, b)
+			}
+		}
+	}
+}
+
+func TestLabelRegion_AvoidsFixedLabels(t *testing.T) {
+	dc := gg.NewContext(800, 800)
+	// A number in the bottom-right of the region leaves rows level with it
+	// only the left of the region
+	number := textBox{text: "5", left: 170, top: 170, right: 190, bottom: 190}
+	region := rectRegion(0, 0, 200, 200).avoiding(number)
+
+	if _, xmax, ok := region.boxSpan(160, 180); !ok || xmax > number.left {
+		t.Errorf("Span level with the number should end left of it, got xmax %v (ok %v)", xmax, ok)
+	}
+	if _, xmax, _ := region.boxSpan(20, 40); xmax != 200-labelMargin {
+		t.Errorf("Span above the number should reach the region's edge, got xmax %v", xmax)
+	}
+
+	// A label that would end on the number is moved left of it
+	entries := []planetEntry{{label: "Mercury"}}
+	anchor := houseAnchor{leftX: 195, rightX: 200, y: 180, size: 18}
+	layout := layoutHouse(dc, entries, nil, anchor, region, 18)
+	got := houseBoxes(dc, layout, 1)[0]
+	if got.overlaps(number) {
+		t.Errorf("Label box %+v overlaps the number %+v", got, number)
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// thumbnailSize is the side of a thumbnail's canvas unless Width or Height
+// say otherwise
+const thumbnailSize = 200
+
+// thumbnailFontSize is the one font size a thumbnail of thumbnailSize draws
+// all its text at. It scales with larger or smaller thumbnails.
+const thumbnailFontSize = 11.0
+
+// thumbnailLabel is a planet abbreviation placed in a thumbnail house
+type thumbnailLabel struct {
+	name     string // Planet name, "" for the count of hidden labels
+	text     string
+	category PointCategory
+	c        color.Color
+	width    float64
+}
+
+// thumbnail holds what a thumbnail chart draws its houses with: a single
+// face, its metrics and the planets of each house
+type thumbnail struct {
+	input      ChartInput
+	lagnaRashi int
+	size       float64
+	face       font.Face
+	metrics    textMetrics
+	occupants  [13][]string     // Planet names by house, as planetsByHouse
+	labels     []thumbnailLabel // Reused from house to house
+	locale     *chartLocale
+	mode       DisplayMode
+	renamed    map[string]string // As in labelFormat
+}
+
+// newThumbnail loads the thumbnail face for a frame
+func newThumbnail(input ChartInput, frame chartFrame) *thumbnail {
+	size := thumbnailFontSize * frame.px(defaultChartSize) / thumbnailSize
+	t := &thumbnail{
+		input:      input,
+		lagnaRashi: chartLagnaRashi(input),
+		size:       size,
+		face:       embeddedFace(matangiBold, size),
+		metrics:    boldMetrics(size),
+		occupants:  planetsByHouse(input),
+		locale:     localeFor(input.Options),
+		mode:       input.Options.DisplayMode,
+	}
+	if t.mode == "" {
+		t.mode = DisplayModeLetter
+	}
+	t.renamed = distinctNames(input, t.locale, t.mode)
+	return t
+}
+
+// check rejects canvases too small for the thumbnail's text to stay legible
+func (t *thumbnail) check(w, h int) error {
+	return legibility{smallest: t.size}.check(w, h)
+}
+
+// thumbnailLineFrame returns the frame to stroke a thumbnail's lines with.
+// Lines scaled down with the chart would fade at thumbnail sizes, so they
+// stay thicker.
+func thumbnailLineFrame(frame chartFrame) chartFrame {
+	frame.scale *= 3
+	return frame
+}
+
+// houseLabels returns the labels of the planets in a rashi in the order and
+// colors of their categories: the lagna first in saffron, then the planets,
+// and special lagnas last in yellow. Thumbnails show bare names, single
+// letters unless the chart picks another display mode, without status
+// markers or degrees. In grayscale they take their category's shade and
+// brackets, but are not underlined.
+func (t *thumbnail) houseLabels(rashiNum int) []thumbnailLabel {
+	labels := t.labels[:0]
+	add := func(name, text string, category PointCategory) {
+		c := category.color()
+		if t.input.Options.Grayscale {
+			style := category.printStyle()
+			text, c = style.wrap(text), style.shade
+		}
+		labels = append(labels, thumbnailLabel{name: name, text: text, category: category, c: c, width: t.measure(text)})
+	}
+	if t.input.Lagna != nil && rashiNum == t.lagnaRashi {
+		if text := lagnaLabel(t.locale, t.mode, t.input.Options.LagnaLabel, t.input.Lagna); text != "" {
+			add("lagna", text, PointLagna)
+		}
+	}
+	for _, name := range t.occupants[HouseFromLagna(rashiNum, t.lagnaRashi)] {
+		planet := t.input.Planets[name]
+		text, ok := t.renamed[name]
+		if !ok {
+			text = t.locale.displayName(name, planet, t.mode)
+		}
+		add(name, text, PlanetCategory(name, planet))
+	}
+	slices.SortStableFunc(labels, func(a, b thumbnailLabel) int {
+		return compareCategories(a.category, b.category)
+	})
+	t.labels = labels
+	return labels
+}
+
+// measure returns the advance of s in the thumbnail face
+func (t *thumbnail) measure(s string) float64 {
+	return float64(font.MeasureString(t.face, s)) / 64
+}
+
+// pack breaks labels into lines no wider than width, at most maxLines of
+// them. Labels that do not fit are counted in a trailing "+N".
+func (t *thumbnail) pack(labels []thumbnailLabel, width float64, maxLines int) [][]thumbnailLabel {
+	space := t.measure(" ")
+	var lines [][]thumbnailLabel
+	start, lineWidth := 0, 0.0
+	for i, l := range labels {
+		if i > start && lineWidth+space+l.width > width {
+			lines = append(lines, labels[start:i])
+			start, lineWidth = i, 0
+		}
+		if i > start {
+			lineWidth += space
+		}
+		lineWidth += l.width
+	}
+	if start < len(labels) {
+		lines = append(lines, labels[start:])
+	}
+	if len(lines) <= maxLines {
+		return lines
+	}
+
+	// Drop labels from the last line that fits until the count of hidden
+	// ones fits beside it
+	lines = lines[:maxLines]
+	last := append([]thumbnailLabel(nil), lines[maxLines-1]...)
+	shown := 0
+	for _, line := range lines[:maxLines-1] {
+		shown += len(line)
+	}
+	for {
+		more := "+" + strconv.Itoa(len(labels)-shown-len(last))
+		w := t.measure(more)
+		if lineWidthOf(last, space)+space+w <= width || len(last) == 0 {
+			lines[maxLines-1] = append(last, thumbnailLabel{text: more, c: textBlack, width: w})
+			return lines
+		}
+		last = last[:len(last)-1]
+	}
+}
+
+// lineWidthOf returns the width of labels set on one line
+func lineWidthOf(line []thumbnailLabel, space float64) float64 {
+	w := 0.0
+	for i, l := range line {
+		if i > 0 {
+			w += space
+		}
+		w += l.width
+	}
+	return w
+}
+
+// drawHouse draws the labels of a house centered in the box from left, top
+// to right, bottom, returning their boxes
+func (t *thumbnail) drawHouse(dc *gg.Context, labels []thumbnailLabel, house int, left, top, right, bottom float64) []textBox {
+	if len(labels) == 0 {
+		return nil
+	}
+	var boxes []textBox
+	lineHeight := t.metrics.lineHeight()
+	maxLines := max(1, int((bottom-top)/lineHeight))
+	lines := t.pack(labels, right-left, maxLines)
+	space := t.measure(" ")
+	centerX := (left + right) / 2
+	y := (top+bottom)/2 - float64(len(lines)-1)*lineHeight/2
+	for _, line := range lines {
+		x := centerX - lineWidthOf(line, space)/2
+		baseline := t.metrics.baseline(y)
+		for _, l := range line {
+			drawText(dc, t.face, l.c, l.text, x, baseline, 0)
+			kind := labelPlanet
+			if l.name == "" {
+				kind = labelHidden
+			}
+			boxes = append(boxes, textBox{text: l.text, house: house, kind: kind, planet: l.name,
+				left: x, top: baseline - t.metrics.capHeight, right: x + l.width, bottom: baseline + t.metrics.descent})
+			x += l.width + space
+		}
+		y += lineHeight
+	}
+	return boxes
+}
+
+// drawRashiNumber draws a rashi's number in gray, anchored at x by ax and
+// with its digits centered on y, returning its box
+func (t *thumbnail) drawRashiNumber(dc *gg.Context, rashiNum, house int, x, y, ax float64) textBox {
+	number := strconv.Itoa(rashiNum)
+	drawText(dc, t.face, houseNumberGray, number, x, y+t.metrics.capHeight/2, ax)
+	w, halfH := t.measure(number), t.metrics.capHeight/2
+	left := x - ax*w
+	return textBox{text: number, house: house, kind: labelRashi, left: left, top: y - halfH, right: left + w, bottom: y + halfH}
+}
+
+// renderSouthThumbnail draws a South Indian chart as a thumbnail: the grid,
+// rashi numbers and planet abbreviations in a single small font, without
+// markers, center text or status suffixes
+func renderSouthThumbnail(r *renderer, input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, input.Options.AllowStretch)
+	t := newThumbnail(input, frame)
+	if err := t.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+	padding := frame.px(16)
+	gridLeft, gridTop := frame.x+padding, frame.y+padding
+	cellW := (frame.width - 2*padding) / 4
+	cellH := (frame.height - 2*padding) / 4
+
+	houseRects := southHouseRects(gridLeft, gridTop, cellW, cellH)
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, houseFills(input), func(house int) []gg.Point {
+		rashiNum := (t.lagnaRashi+house-2)%12 + 1
+		return rectPolygon(houseRects[southRashiCell(rashiNum, t.lagnaRashi, input.Options.RotateToLagna)])
+	})
+	drawSouthGrid(dc, gridLeft, gridTop, cellW, cellH, thumbnailLineFrame(frame))
+
+	// Rashi numbers sit in the bottom-right corner of each cell, the planets
+	// share the rest of it
+	inset := frame.px(8)
+	capHeight := t.metrics.capHeight
+	for houseNum := 1; houseNum <= 12; houseNum++ {
+		rect := houseRects[houseNum]
+		rashiNum := southCellRashi(houseNum, t.lagnaRashi, input.Options.RotateToLagna)
+		numberY := float64(rect.Max.Y) - inset - capHeight/2
+		boxes.add(t.drawRashiNumber(dc, rashiNum, houseNum, float64(rect.Max.X)-inset, numberY, 1))
+		boxes.addHouse(houseNum, rashiNum, rectPolygon(rect))
+		boxes.add(t.drawHouse(dc, t.houseLabels(rashiNum), houseNum,
+			float64(rect.Min.X)+inset, float64(rect.Min.Y)+inset,
+			float64(rect.Max.X)-inset, numberY-capHeight/2)...)
+	}
+	return dc.Image(), nil
+}
+
+// renderNorthThumbnail draws a North Indian chart as a thumbnail, like
+// renderSouthThumbnail
+func renderNorthThumbnail(r *renderer, input ChartInput, boxes *chartBoxes) (image.Image, error) {
+	canvasW, canvasH := input.Options.canvasSize()
+	frame := newChartFrame(canvasW, canvasH, false)
+	t := newThumbnail(input, frame)
+	if err := t.check(canvasW, canvasH); err != nil {
+		return nil, err
+	}
+	padding := frame.px(16)
+	geo, innerHalfSize := northChartGeometry(frame.x+frame.width/2, frame.y+frame.height/2, frame.width-2*padding)
+	geo.clockwise = input.Options.Direction == DirectionClockwise
+
+	dc := r.context(canvasW, canvasH)
+	drawBackground(dc, input.Options, houseFills(input), geo.housePolygon)
+	drawNorthOutline(dc, geo, innerHalfSize, thumbnailLineFrame(frame))
+
+	for position := 1; position <= 12; position++ {
+		rashiNum := (t.lagnaRashi+position-2)%12 + 1
+		anchor := geo.labelAnchor(position)
+		number := t.drawRashiNumber(dc, rashiNum, position, anchor.X, anchor.Y, 0.5)
+		boxes.add(number)
+		boxes.addHouse(position, rashiNum, geo.housePolygon(position))
+		box := t.northBox(geo, position, number)
+		boxes.add(t.drawHouse(dc, t.houseLabels(rashiNum), position, box.left, box.top, box.right, box.bottom)...)
+	}
+	return dc.Image(), nil
+}
+
+// northBox returns the rectangle a house's planets share in a North
+// thumbnail, clear of the house's rashi number: a triangle's inscribed
+// rectangle, or in a square region two lines beside the number on the side
+// away from the chart center, as wide as the region's sides allow
+func (t *thumbnail) northBox(geo northGeometry, position int, number textBox) textBox {
+	poly := geo.housePolygon(position)
+	gap := t.metrics.descent
+	if len(poly) == 3 {
+		lo, hi := inscribedRect(poly)
+		return textBox{left: lo.X, top: lo.Y, right: hi.X, bottom: hi.Y}.clearOf(number, gap)
+	}
+
+	// Work along the axis u from the region's centroid to its vertex nearest
+	// the chart center, which the number lies on. The region's sides run at
+	// 45°, so a box reaching k+along out along u and across to either side
+	// stays inside it while k+along+across <= d.
+	c := polygonCentroid(poly)
+	inner := geo.towardsInner(position, 1)
+	d := math.Hypot(inner.X-c.X, inner.Y-c.Y)
+	u := gg.Point{X: (inner.X - c.X) / d, Y: (inner.Y - c.Y) / d}
+	var e float64 // From the centroid to the number's near edge
+	switch {
+	case u.Y > 0.5:
+		e = number.top - c.Y
+	case u.Y < -0.5:
+		e = c.Y - number.bottom
+	case u.X > 0:
+		e = number.left - c.X
+	default:
+		e = c.X - number.right
+	}
+	e -= gap
+
+	lines := 2 * t.metrics.lineHeight()
+	var along, across, k float64
+	if math.Abs(u.Y) > 0.5 {
+		// Lines stack along u
+		along = lines / 2
+		k = math.Max(0, along-e)
+		across = d - k - along
+	} else {
+		// Lines run along u
+		across = lines / 2
+		along = (d - across + e) / 2
+		k = math.Max(0, (d-across-e)/2)
+		along = math.Min(along, d-across-k)
+	}
+	center := gg.Point{X: c.X - k*u.X, Y: c.Y - k*u.Y}
+	halfW, halfH := across, along
+	if math.Abs(u.Y) <= 0.5 {
+		halfW, halfH = along, across
+	}
+	return textBox{left: center.X - halfW, top: center.Y - halfH, right: center.X + halfW, bottom: center.Y + halfH}
+}
+
+// clearOf returns b shrunk to stay gap clear of other on the side other
+// lies on, or b unchanged when they do not overlap
+func (b textBox) clearOf(other textBox, gap float64) textBox {
+	if !b.overlaps(other) {
+		return b
+	}
+	dx := ((other.left+other.right)/2 - (b.left+b.right)/2) / (b.right - b.left)
+	dy := ((other.top+other.bottom)/2 - (b.top+b.bottom)/2) / (b.bottom - b.top)
+	switch {
+	case math.Abs(dy) >= math.Abs(dx) && dy > 0:
+		b.bottom = other.top - gap
+	case math.Abs(dy) >= math.Abs(dx):
+		b.top = other.bottom + gap
+	case dx > 0:
+		b.right = other.left - gap
+	default:
+		b.left = other.right + gap
+	}
+	return b
+}
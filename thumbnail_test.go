@@ -0,0 +1,172 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// thumbnailInput returns a chart with a few planets to a house, some of them
+// marked, drawn as a thumbnail
+func thumbnailInput(chartType ChartType) ChartInput {
+	return ChartInput{
+		ChartType: chartType,
+		Lagna:     &Planet{Rashi: "leo", Degrees: 12.5},
+		Planets: map[string]*Planet{
+			"sun":     {Rashi: "leo", Degrees: 4, IsExalted: true},
+			"moon":    {Rashi: "scorpio", IsDebilitated: true},
+			"mars":    {Rashi: "leo", IsCombust: true},
+			"mercury": {Rashi: "virgo", IsRetrograde: true},
+			"jupiter": {Rashi: "pisces"},
+			"venus":   {Rashi: "virgo"},
+			"saturn":  {Rashi: "aquarius", IsRetrograde: true},
+			"rahu":    {Rashi: "taurus"},
+			"ketu":    {Rashi: "scorpio"},
+			"gulika":  {Rashi: "leo"},
+		},
+		CenterText: "Rashi",
+		Options:    ChartOptions{Thumbnail: true, ShowDegrees: true, ShowHouseNumbers: true},
+	}
+}
+
+func generateFor(chartType ChartType) func(ChartInput) ([]byte, error) {
+	if chartType == ChartTypeNorth {
+		return GenerateNorthChart
+	}
+	return GenerateSouthChart
+}
+
+func TestGenerateChart_Thumbnail(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		data, err := generateFor(chartType)(thumbnailInput(chartType))
+		if err != nil {
+			t.Fatalf("Error generating %s thumbnail: %v", chartType, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Error decoding %s thumbnail: %v", chartType, err)
+		}
+		if got := img.Bounds().Size(); got != (image.Point{thumbnailSize, thumbnailSize}) {
+			t.Errorf("%s thumbnail is %v, want %dx%d", chartType, got, thumbnailSize, thumbnailSize)
+		}
+		assertGolden(t, string(chartType)+"_thumbnail", data)
+	}
+}
+
+func TestGenerateChart_ThumbnailSkipsStatuses(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		marked, err := generateFor(chartType)(thumbnailInput(chartType))
+		if err != nil {
+			t.Fatalf("Error generating %s thumbnail: %v", chartType, err)
+		}
+		plain := thumbnailInput(chartType)
+		plain.CenterText = ""
+		plain.Options = ChartOptions{Thumbnail: true}
+		for _, p := range plain.Planets {
+			p.IsExalted, p.IsDebilitated, p.IsCombust, p.IsRetrograde = false, false, false, false
+		}
+		unmarked, err := generateFor(chartType)(plain)
+		if err != nil {
+			t.Fatalf("Error generating %s thumbnail: %v", chartType, err)
+		}
+		if !bytes.Equal(marked, unmarked) {
+			t.Errorf("%s thumbnail changed with statuses, degrees or center text", chartType)
+		}
+	}
+}
+
+func TestGenerateChart_ThumbnailSize(t *testing.T) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := thumbnailInput(chartType)
+		input.Options.Width, input.Options.Height = 300, 150
+		data, err := generateFor(chartType)(input)
+		if err != nil {
+			t.Fatalf("Error generating %s thumbnail: %v", chartType, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Error decoding %s thumbnail: %v", chartType, err)
+		}
+		if got := img.Bounds().Size(); got != (image.Point{300, 150}) {
+			t.Errorf("%s thumbnail is %v, want 300x150", chartType, got)
+		}
+
+		input.Options.Width, input.Options.Height = 60, 60
+		if _, err := generateFor(chartType)(input); err == nil {
+			t.Errorf("Expected an error for a %s thumbnail too small to read", chartType)
+		}
+	}
+}
+
+func TestThumbnail_PackCountsHiddenLabels(t *testing.T) {
+	input := crowdedHouseInput(ChartTypeSouth)
+	th := newThumbnail(input, newChartFrame(thumbnailSize, thumbnailSize, false))
+	labels := th.houseLabels(RashiToNumber(input.Lagna.Rashi))
+	width := 3 * th.measure("Asc")
+	lines := th.pack(labels, width, 2)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	shown := 0
+	for _, line := range lines {
+		if w := lineWidthOf(line, th.measure(" ")); w > width {
+			t.Errorf("line %v is %.1fpx wide, more than %.1fpx", line, w, width)
+		}
+		shown += len(line)
+	}
+	last := lines[1][len(lines[1])-1]
+	if want := "+" + strconv.Itoa(len(labels)-shown+1); last.text != want {
+		t.Errorf("last label is %q, want %q", last.text, want)
+	}
+}
+
+func TestThumbnail_DisplayMode(t *testing.T) {
+	input := thumbnailInput(ChartTypeSouth)
+	texts := func() []string {
+		th := newThumbnail(input, newChartFrame(thumbnailSize, thumbnailSize, false))
+		var texts []string
+		for _, l := range th.houseLabels(RashiToNumber("leo")) {
+			texts = append(texts, l.text)
+		}
+		return texts
+	}
+	// Letters by default, abbreviations for names without one
+	if got, want := texts(), []string{"Asc", "A", "S", "Gu"}; !slices.Equal(got, want) {
+		t.Errorf("labels = %q, want %q", got, want)
+	}
+	input.Options.DisplayMode = DisplayModeAbbreviation
+	if got, want := texts(), []string{"Asc", "Ma", "Su", "Gu"}; !slices.Equal(got, want) {
+		t.Errorf("abbreviation labels = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkGenerateChart_Thumbnail(b *testing.B) {
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		b.Run(string(chartType), func(b *testing.B) {
+			benchmarkGenerate(b, generateFor(chartType), thumbnailInput(chartType))
+		})
+		b.Run(string(chartType)+"_full", func(b *testing.B) {
+			input := thumbnailInput(chartType)
+			input.Options.Thumbnail = false
+			benchmarkGenerate(b, generateFor(chartType), input)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"image"
+	"runtime/debug"
+	"sync"
+
+	"github.com/fogleman/gg"
+)
+
+// modulePath is the import path of this module
+const modulePath = "github.com/tejzpr/go-vedic-astro-charts"
+
+// version is the library's version when set at build time:
+//
+//	go build -ldflags "-X github.com/tejzpr/go-vedic-astro-charts.version=v1.4.0"
+var version string
+
+// buildVersion reads the module's version from the build info once
+var buildVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+})
+
+// Version returns the library's version: the one set with -ldflags when
+// there is one, else the module version recorded in the binary, which is
+// "(devel)" when the module is built from a checkout
+func Version() string {
+	if version != "" {
+		return version
+	}
+	return buildVersion()
+}
+
+// versionStamp is the text of the version stamp
+func versionStamp() string {
+	return "go-vedic-astro-charts " + Version()
+}
+
+// drawVersionStamp writes the version stamp small and gray in the band
+// beneath the chart, flush with the chart's right edge, recording its box in
+// boxes. Custom layouts whose houses leave no room beneath them are left
+// unstamped.
+func drawVersionStamp(img image.Image, input ChartInput, boxes *chartBoxes) image.Image {
+	frame, top, bottom := stampBand(input)
+	size := frame.px(10)
+	metrics := regularMetrics(size)
+	if bottom-top < metrics.lineHeight() {
+		return img
+	}
+	dc := gg.NewContextForImage(img)
+	text := versionStamp()
+	right := frame.x + frame.width - frame.px(40)
+	baseline := (top+bottom)/2 + metrics.capHeight/2
+	loadMatangiRegular(dc, size)
+	w, _ := dc.MeasureString(text)
+	drawText(dc, embeddedFace(matangiRegular, size), houseNumberGray, text, right, baseline, 1)
+	boxes.add(textBox{text: text, kind: labelStamp, left: right - w, top: baseline - metrics.capHeight, right: right, bottom: baseline + metrics.descent})
+	return dc.Image()
+}
+
+// stampBand returns the frame of a chart of input and the top and bottom of
+// the band beneath the chart that the version stamp goes in: the frame's
+// bottom padding, or beneath the lowest house of a custom layout
+func stampBand(input ChartInput) (frame chartFrame, top, bottom float64) {
+	canvasW, canvasH := input.Options.canvasSize()
+	stretch := input.Options.AllowStretch && (input.ChartType == ChartTypeSouth || input.ChartType == ChartTypeSarvashtakavarga)
+	frame = newChartFrame(canvasW, canvasH, stretch)
+	bottom = frame.y + frame.height
+	top = bottom - frame.px(40)
+	if t := registeredLayout(input.ChartType); t != nil {
+		geo := newTemplateGeometry(t, frame)
+		top = frame.y
+		for position := 1; position <= len(t.Houses); position++ {
+			for _, p := range geo.housePolygon(position) {
+				top = max(top, p.Y)
+			}
+		}
+	}
+	return frame, top, bottom
+}
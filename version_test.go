@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Tejus Pratap <tejzpr@gmail.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parashari
+
+import (
+	"testing"
+)
+
+// withVersion sets the version stamped on charts for the rest of a test, so
+// that goldens do not depend on how the test binary was built
+func withVersion(t *testing.T, v string) {
+	t.Helper()
+	old := version
+	version = v
+	t.Cleanup(func() { version = old })
+}
+
+func TestVersion(t *testing.T) {
+	if Version() == "" {
+		t.Error("Version() is empty")
+	}
+	withVersion(t, "v1.4.0")
+	if got := Version(); got != "v1.4.0" {
+		t.Errorf("Version() = %q with the version set, want v1.4.0", got)
+	}
+}
+
+func TestGenerateChart_VersionStamp(t *testing.T) {
+	withVersion(t, "v1.4.0")
+	for _, chartType := range []ChartType{ChartTypeSouth, ChartTypeNorth} {
+		input := houseScoresInput(chartType)
+		input.Options.ShowVersionStamp = true
+		input.Options.NakshatraRing = chartType == ChartTypeNorth
+		data, layout, err := GenerateChartWithLayout(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stamp *LabelLayout
+		for i, l := range layout.Labels {
+			if l.Kind == string(labelStamp) {
+				stamp = &layout.Labels[i]
+			}
+		}
+		if stamp == nil || stamp.Text != "go-vedic-astro-charts v1.4.0" {
+			t.Fatalf("%s: stamp = %+v, want one reading the version", chartType, stamp)
+		}
+		// The stamp sits beneath every house, inside the canvas
+		for _, h := range layout.Houses {
+			for _, p := range h.Polygon {
+				if p[1] > stamp.Top {
+					t.Errorf("%s: house %d reaches %v, below the stamp's top %v", chartType, h.House, p[1], stamp.Top)
+				}
+			}
+		}
+		if stamp.Bottom > float64(layout.Height) || stamp.Right > float64(layout.Width) {
+			t.Errorf("%s: stamp %+v is off the %dx%d canvas", chartType, stamp, layout.Width, layout.Height)
+		}
+		assertGolden(t, string(chartType)+"_version_stamp", data)
+	}
+}
+
+func TestGenerateChart_VersionStampThumbnail(t *testing.T) {
+	input := houseScoresInput(ChartTypeSouth)
+	input.Options.Thumbnail = true
+	input.Options.ShowVersionStamp = true
+	_, layout, err := GenerateChartWithLayout(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layout.Labels {
+		if l.Kind == string(labelStamp) {
+			t.Errorf("thumbnail has a version stamp: %+v", l)
+		}
+	}
+}